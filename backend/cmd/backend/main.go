@@ -1,9 +1,20 @@
 package main
 
 import (
+	"context"
+	"net"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/hackfeed/remrratality/backend/internal/alerts"
+	"github.com/hackfeed/remrratality/backend/internal/config"
+	"github.com/hackfeed/remrratality/backend/internal/jobs"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
 	"github.com/hackfeed/remrratality/backend/internal/server"
+	"github.com/hackfeed/remrratality/backend/internal/tracing"
+	"github.com/hackfeed/remrratality/backend/internal/utils/user_validation"
+	"github.com/hackfeed/remrratality/backend/internal/webhooks"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -26,12 +37,97 @@ import (
 // @name token
 
 func main() {
-	file, err := os.OpenFile("logs.txt", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+	cfg, err := config.Load(os.Args[1:], os.LookupEnv)
 	if err != nil {
-		log.Fatalf("failed to create logs file, error is: %s", err)
+		log.Fatalf("failed to load config, error is: %s", err)
 	}
 
-	log.SetOutput(file)
+	if err := logging.Setup(cfg.Log); err != nil {
+		log.Fatalf("failed to set up logging, error is: %s", err)
+	}
+
+	user_validation.SetSecretKey(cfg.JWT.SecretKey)
+	if cfg.JWT.KeysDir != "" {
+		if err := user_validation.LoadKeys(cfg.JWT.KeysDir, cfg.JWT.SigningKID); err != nil {
+			log.Fatalf("failed to load jwt keys, error is: %s", err)
+		}
+	}
+
+	// Tracer provider is set up before clients are created, as they capture it on creation.
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Fatalf("failed to set up tracing, error is: %s", err)
+	}
+
+	deps, err := server.Connect(context.Background(), cfg)
+	if err != nil {
+		log.Fatalf("failed to connect to backing stores, error is: %s", err)
+	}
+
+	dispatcher := webhooks.NewDispatcher(cfg.Webhooks, deps.WebhookRepo)
+	deps.Webhooks = dispatcher
+
+	scheduler := alerts.NewScheduler(
+		cfg.Alerts,
+		deps.AlertRepo,
+		deps.DatasetRepo,
+		deps.Analyzer(),
+		alerts.Notifiers(cfg.Alerts.SMTP, dispatcher),
+	)
+
+	runner, err := jobs.NewRunner(cfg.Jobs, deps.JobRepo, deps.DatasetRepo, deps.StorageRepo, deps.AuditRepo, dispatcher, scheduler)
+	if err != nil {
+		log.Fatalf("failed to create job runner, error is: %s", err)
+	}
+	deps.Jobs = runner
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	log.Fatalln(server.SetupServer().Run())
+	runner.Start(ctx)
+	dispatcher.Start(ctx)
+	scheduler.Start(ctx)
+
+	srv := server.NewHTTPServer(cfg.Server, server.New(deps))
+	listener, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		log.Fatalf("failed to listen on %s, error is: %s", srv.Addr, err)
+	}
+
+	failed := false
+	if err := server.Serve(ctx, srv, listener, cfg.Server.ShutdownTimeout); err != nil {
+		log.Errorf("server stopped with error, error is: %s", err)
+		failed = true
+	}
+
+	closeCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	// Workers finish the batch they store before stores are closed, interrupted jobs are
+	// resumed on the next start.
+	if err := runner.Wait(closeCtx); err != nil {
+		log.Errorf("failed to stop job workers, error is: %s", err)
+		failed = true
+	}
+	if err := scheduler.Wait(closeCtx); err != nil {
+		log.Errorf("failed to stop alerts scheduler, error is: %s", err)
+		failed = true
+	}
+	if err := dispatcher.Wait(closeCtx); err != nil {
+		log.Errorf("failed to stop webhook workers, error is: %s", err)
+		failed = true
+	}
+	if err := deps.Close(closeCtx); err != nil {
+		log.Errorf("failed to close backing stores, error is: %s", err)
+		failed = true
+	}
+	if err := shutdownTracing(closeCtx); err != nil {
+		log.Errorf("failed to flush spans, error is: %s", err)
+		failed = true
+	}
+	log.Infof("server is stopped")
+
+	if failed {
+		os.Exit(1)
+	}
 }
@@ -31,14 +31,14 @@ var doc = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/analytics/mrr": {
-            "post": {
+        "/account": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Creating MRR analytics data with all components for given period and returning it",
+                "description": "Deleting user, organizations he solely owns with their invoices, cached analytics, jobs, webhooks and alerts after password re-confirmation",
                 "consumes": [
                     "application/json"
                 ],
@@ -46,17 +46,17 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "analytics"
+                    "account"
                 ],
-                "summary": "Create and return MRR analytics data",
+                "summary": "Deleting user's account",
                 "parameters": [
                     {
-                        "description": "Parameters for MRR analytics",
+                        "description": "User's current password",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Period"
+                            "$ref": "#/definitions/models.PasswordConfirmation"
                         }
                     }
                 ],
@@ -64,7 +64,7 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.ResponseSuccessAnalytics"
+                            "$ref": "#/definitions/models.ResponseSuccessDeleteAccount"
                         }
                     },
                     "400": {
@@ -79,6 +79,12 @@ var doc = `{
                             "$ref": "#/definitions/models.Response"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -88,14 +94,14 @@ var doc = `{
                 }
             }
         },
-        "/files": {
+        "/alerts": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Loading invoices files' names, uploaded by user",
+                "description": "Loading the latest alerts triggered by rules of organization, newest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -103,14 +109,88 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "files"
+                    "alerts"
+                ],
+                "summary": "Loading triggered alerts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Alert rule ID, alerts of every rule are loaded if omitted",
+                        "name": "rule_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID, personal organization is used if omitted",
+                        "name": "org",
+                        "in": "header"
+                    }
                 ],
-                "summary": "Loading user's invoices files list",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.ResponseSuccessLoadFiles"
+                            "$ref": "#/definitions/models.ResponseSuccessAlerts"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/alerts/rules": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Loading alert rules of organization in order they were created",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Loading organization's alert rules",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID, personal organization is used if omitted",
+                        "name": "org",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSuccessAlertRules"
                         }
                     },
                     "401": {
@@ -119,6 +199,18 @@ var doc = `{
                             "$ref": "#/definitions/models.Response"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -133,7 +225,7 @@ var doc = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Saving file locally, parsing its content to database and deleting it from the server",
+                "description": "Creating rule which triggers alert when metric of the last complete month of a dataset is above or below threshold.\nRules are evaluated after every import and once a month, each rule triggers once per month of a dataset.\nMetric is an MRR component, net_new, growth_rate or churn_rate, the rates are percents of the previous month's total.\nContraction and churn are compared by their magnitude. With of_previous_total threshold is a percent of the previous month's total.\nRule watches the given dataset or the latest imported one. Alerts are sent through webhook channel to webhooks subscribed to alert.triggered event,\nthrough email channel to recipients, and log channel writes them to the service log.",
                 "consumes": [
                     "application/json"
                 ],
@@ -141,23 +233,31 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "files"
+                    "alerts"
                 ],
-                "summary": "Saving user's file's content",
+                "summary": "Creating alert rule",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "File to upload",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
+                        "description": "Alert rule",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AlertRule"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID, personal organization is used if omitted",
+                        "name": "org",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.ResponseSuccessSaveFileContent"
+                            "$ref": "#/definitions/models.ResponseSuccessAlertRule"
                         }
                     },
                     "400": {
@@ -172,6 +272,18 @@ var doc = `{
                             "$ref": "#/definitions/models.Response"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -181,14 +293,14 @@ var doc = `{
                 }
             }
         },
-        "/files/{filename}": {
+        "/alerts/rules/{id}": {
             "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Deleting invoices linked to file from database",
+                "description": "Deleting alert rule with alerts it has triggered",
                 "consumes": [
                     "application/json"
                 ],
@@ -196,16 +308,22 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "files"
+                    "alerts"
                 ],
-                "summary": "Deleting user's invoices file's content",
+                "summary": "Deleting alert rule",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Invoice file to delete",
-                        "name": "filename",
+                        "description": "Alert rule ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID, personal organization is used if omitted",
+                        "name": "org",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -215,14 +333,20 @@ var doc = `{
                             "$ref": "#/definitions/models.Response"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/models.Response"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/models.Response"
                         }
@@ -236,9 +360,14 @@ var doc = `{
                 }
             }
         },
-        "/login": {
+        "/analytics/bridge": {
             "post": {
-                "description": "Logging user in by retrieving his data from the database",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creating MRR bridge from MRR of the first month of given period to MRR of its last month.\nMovements of every month after the first one are summed up into new, expansion, reactivation, contraction and churn,\nso starting MRR with movements equals ending MRR. With top parameter customers contributing the most\nto every movement are returned too.",
                 "consumes": [
                     "application/json"
                 ],
@@ -246,25 +375,31 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "login"
+                    "analytics"
                 ],
-                "summary": "Logging user in",
+                "summary": "Create MRR bridge",
                 "parameters": [
                     {
-                        "description": "User's email and password",
+                        "description": "Parameters for MRR bridge",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.Bridge"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID, personal organization is used if omitted",
+                        "name": "org",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.ResponseSuccessAuth"
+                            "$ref": "#/definitions/models.ResponseSuccessBridge"
                         }
                     },
                     "400": {
@@ -273,6 +408,42 @@ var doc = `{
                             "$ref": "#/definitions/models.Response"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Seconds to wait before the next request"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -282,9 +453,14 @@ var doc = `{
                 }
             }
         },
-        "/signup": {
+        "/analytics/forecast": {
             "post": {
-                "description": "Signing user up by adding him to the database",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creating MRR analytics data for given period and projecting Total and every component horizon months ahead.\nTrend method fits linear trend to each series, with monthly seasonality once two years are observed.\nCohort method groups customers by their first paying month and projects them with observed month over month\nrates of cohorts of the same age, new MRR of future cohorts is projected with trend method.\nEvery projected value has lower and upper bounds of interval with given confidence.",
                 "consumes": [
                     "application/json"
                 ],
@@ -292,25 +468,31 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "signup"
+                    "analytics"
                 ],
-                "summary": "Signing user up",
+                "summary": "Create MRR analytics with forecast",
                 "parameters": [
                     {
-                        "description": "User's email and password",
+                        "description": "Parameters for MRR forecast",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.Forecast"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID, personal organization is used if omitted",
+                        "name": "org",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.ResponseSuccessAuth"
+                            "$ref": "#/definitions/models.ResponseSuccessForecast"
                         }
                     },
                     "400": {
@@ -319,6 +501,42 @@ var doc = `{
                             "$ref": "#/definitions/models.Response"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Seconds to wait before the next request"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -327,103 +545,2651 @@ var doc = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "domain.File": {
-            "type": "object",
-            "properties": {
-                "name": {
-                    "type": "string"
-                },
-                "uploaded_at": {
-                    "type": "string"
-                }
-            }
         },
-        "domain.TotalMRR": {
-            "type": "object",
-            "properties": {
-                "churn": {
-                    "type": "array",
-                    "items": {
-                        "type": "number"
-                    }
-                },
-                "contraction": {
-                    "type": "array",
-                    "items": {
-                        "type": "number"
+        "/analytics/mrr": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
                     }
-                },
-                "expansion": {
-                    "type": "array",
-                    "items": {
-                        "type": "number"
+                ],
+                "description": "Creating MRR analytics data with all components for given period and returning it.\nAnalytics is returned as table with one row per month when format is csv, xlsx or ndjson,\nformat may be requested with format parameter or Accept header.\nWith events format progress events are streamed as Server-Sent Events while analytics is computed,\nstream ends with result event carrying analytics or failed event.\nAnalytics is bucketed by reporting periods of configured calendar, calendar months by default. Months are labels of\nperiods, \"M.YYYY\" for calendar months and like \"P1.FY2022\" for fiscal periods, periods are the same as ISO intervals of dates.\nWith metrics parameter json and events responses include ARR, ARPA, customer churn, LTV and quick ratio of every month.\nWith compare set json and events responses include analytics of the previous period of the same length, the same period\na year before or a custom period, aligned by month relative to period start, with absolute and percentage deltas and CMGR.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/x-ndjson",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Create and return MRR analytics data",
+                "parameters": [
+                    {
+                        "description": "Parameters for MRR analytics",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Analytics"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xlsx",
+                            "ndjson",
+                            "events"
+                        ],
+                        "type": "string",
+                        "description": "Response format, takes precedence over Accept header",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include metrics block",
+                        "name": "metrics",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID, personal organization is used if omitted",
+                        "name": "org",
+                        "in": "header"
                     }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSuccessAnalytics"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Seconds to wait before the next request"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/analytics/scenario": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creating MRR analytics data for given period as it is and as it would be with adjustments, side by side.\nPlan multipliers scale paid amounts of monthly and annually invoices, excluded customers are left out.\nChurn reduction is the share of churned customers who keep paying their last amount instead,\nchurn rate is the share of paying customers who churn every month on top of observed churn,\nand expansion rate grows revenue of every paying customer every month.\nCustomers are picked for synthetic churn in order of their IDs, so scenario is reproducible.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Create MRR analytics of what-if scenario",
+                "parameters": [
+                    {
+                        "description": "Parameters for MRR scenario",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Scenario"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID, personal organization is used if omitted",
+                        "name": "org",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSuccessScenario"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Seconds to wait before the next request"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/audit": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Loading security and data events of logged in user for given time range, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "Loading user's audit log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "RFC3339 range start, 30 days before range end if omitted",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 range end, current time if omitted",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSuccessAuditEvents"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Loading datasets uploaded to organization, filtered by label, display name and upload date",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Loading user's invoices files list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID, personal organization is used if omitted",
+                        "name": "org",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label datasets should have",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Case insensitive substring of display name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Earliest upload date",
+                        "name": "uploaded_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Latest upload date, inclusive",
+                        "name": "uploaded_to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "name",
+                            "-name",
+                            "uploaded_at",
+                            "-uploaded_at"
+                        ],
+                        "type": "string",
+                        "description": "Sort field, prefixed with - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSuccessLoadFiles"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Saving file and queueing job parsing its content to database, job's progress is available at /jobs/{id}",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Saving user's file's content",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "File to upload",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID, personal organization is used if omitted",
+                        "name": "org",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSuccessSaveFileContent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Seconds to wait before the next request"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Loading dataset with its row and customer counts, period start range, total amount and import status",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Loading invoices file's metadata",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice file ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID, personal organization is used if omitted",
+                        "name": "org",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSuccessGetFile"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deleting invoices linked to file from database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Deleting user's invoices file's content",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice file ID to delete",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID, personal organization is used if omitted",
+                        "name": "org",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Renaming dataset, changing its description or replacing its labels, omitted fields are left untouched",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Updating invoices file's metadata",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice file ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Dataset fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DatasetUpdate"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID, personal organization is used if omitted",
+                        "name": "org",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSuccessGetFile"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/{id}/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Streaming stored invoices as CSV in the upload format, gzip-compressed if requested",
+                "produces": [
+                    "text/csv",
+                    "application/gzip"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Downloading invoices file's content",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice file ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Compress file with gzip",
+                        "name": "gzip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID, personal organization is used if omitted",
+                        "name": "org",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Loading state and progress of the job, failed job lists what went wrong",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Loading background job's status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID, personal organization is used if omitted",
+                        "name": "org",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSuccessGetJob"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Streaming events of the job as Server-Sent Events: progress events report stage and rows processed,\nwarning events describe suspicious rows, stream ends with succeeded or failed event.\nEvents are numbered, stream is resumed after the event given in Last-Event-ID header.\nStream is also ended before write timeout of the server expires and when server shuts down, clients reconnect then.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Streaming background job's progress",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of the last received event",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID, personal organization is used if omitted",
+                        "name": "org",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.JobEvent"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Logging user in by retrieving his data from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "login"
+                ],
+                "summary": "Logging user in",
+                "parameters": [
+                    {
+                        "description": "User's email and password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSuccessAuth"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Seconds to wait before the next attempt"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Loading organizations user is a member of",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Loading user's organizations list",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSuccessOrganizations"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creating organization with logged in user as its owner",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Creating organization",
+                "parameters": [
+                    {
+                        "description": "Organization's name",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Organization"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSuccessOrganization"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{org_id}/members": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adding registered user to organization or changing role of existing member",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Adding member to organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "org_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Member's email and role",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Member"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSuccessOrganization"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{org_id}/members/{user_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removing member from organization, the last owner can't be removed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Removing member from organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "org_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Member's user ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSuccessOrganization"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/signup": {
+            "post": {
+                "description": "Signing user up by adding him to the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "signup"
+                ],
+                "summary": "Signing user up",
+                "parameters": [
+                    {
+                        "description": "User's email and password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSuccessAuth"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Seconds to wait before the next attempt"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Loading webhooks of organization, secrets aren't included",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Loading organization's webhooks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID, personal organization is used if omitted",
+                        "name": "org",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSuccessWebhooks"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creating webhook receiving given events of organization. Payloads are signed with the secret returned in response, it isn't shown again.\nWebhook URL should point to a public address, loopback, private, link-local and multicast ones are refused",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Creating webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook's URL and events",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID, personal organization is used if omitted",
+                        "name": "org",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSuccessWebhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deleting webhook with its deliveries, pending deliveries aren't sent",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Deleting webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID, personal organization is used if omitted",
+                        "name": "org",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Loading the latest deliveries of webhook with all their attempts, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Loading webhook's deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID, personal organization is used if omitted",
+                        "name": "org",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSuccessWebhookDeliveries"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/test": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sending sample event of the given type to webhook once, the delivery is recorded, but not retried. Payload of the sample has \"test\": true",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Sending sample event to webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "dataset.imported",
+                            "dataset.deleted",
+                            "dataset.import_failed",
+                            "alert.triggered"
+                        ],
+                        "type": "string",
+                        "description": "Event type, dataset.imported if omitted",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID, personal organization is used if omitted",
+                        "name": "org",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSuccessWebhookDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.Alert": {
+            "type": "object",
+            "properties": {
+                "condition": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "dataset_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "metric": {
+                    "type": "string"
+                },
+                "month": {
+                    "type": "string"
+                },
+                "org_id": {
+                    "type": "string"
+                },
+                "rule_id": {
+                    "type": "string"
+                },
+                "threshold": {
+                    "type": "number"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.AlertRule": {
+            "type": "object",
+            "properties": {
+                "channels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "condition": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "dataset_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "metric": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "of_previous_total": {
+                    "type": "boolean"
+                },
+                "org_id": {
+                    "type": "string"
+                },
+                "recipients": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "threshold": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.AuditEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "org_id": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "success": {
+                    "type": "boolean"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CustomerMovement": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "customerID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Dataset": {
+            "type": "object",
+            "properties": {
+                "content_hash": {
+                    "type": "string"
+                },
+                "customer_count": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "labels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "org_id": {
+                    "type": "string"
+                },
+                "original_filename": {
+                    "type": "string"
+                },
+                "period_start_max": {
+                    "type": "string"
+                },
+                "period_start_min": {
+                    "type": "string"
+                },
+                "row_count": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "total_amount": {
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "uploaded_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ForecastSeries": {
+            "type": "object",
+            "properties": {
+                "lower": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "upper": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "value": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "domain.Job": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "dataset_id": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "org_id": {
+                    "type": "string"
+                },
+                "original_filename": {
+                    "type": "string"
+                },
+                "rows_processed": {
+                    "type": "integer"
+                },
+                "rows_total": {
+                    "type": "integer"
+                },
+                "stage": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.JobEvent": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "rows_processed": {
+                    "type": "integer"
+                },
+                "rows_total": {
+                    "type": "integer"
+                },
+                "seq": {
+                    "type": "integer"
+                },
+                "stage": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.MRRBridge": {
+            "type": "object",
+            "properties": {
+                "churn": {
+                    "type": "number"
+                },
+                "contraction": {
+                    "type": "number"
+                },
+                "endMRR": {
+                    "type": "number"
+                },
+                "endMonth": {
+                    "type": "string"
+                },
+                "endPeriod": {
+                    "type": "string"
+                },
+                "expansion": {
+                    "type": "number"
+                },
+                "new": {
+                    "type": "number"
+                },
+                "reactivation": {
+                    "type": "number"
+                },
+                "startMRR": {
+                    "type": "number"
+                },
+                "startMonth": {
+                    "type": "string"
+                },
+                "startPeriod": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.MRRComparison": {
+            "type": "object",
+            "properties": {
+                "cmgr": {
+                    "type": "number"
+                },
+                "comparisonCMGR": {
+                    "type": "number"
+                },
+                "delta": {
+                    "$ref": "#/definitions/domain.TotalMRR"
+                },
+                "deltaPercent": {
+                    "$ref": "#/definitions/domain.TotalMRR"
+                },
+                "months": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "mrr": {
+                    "$ref": "#/definitions/domain.TotalMRR"
+                },
+                "periods": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.MRRForecast": {
+            "type": "object",
+            "properties": {
+                "churn": {
+                    "$ref": "#/definitions/domain.ForecastSeries"
+                },
+                "confidence": {
+                    "type": "number"
+                },
+                "contraction": {
+                    "$ref": "#/definitions/domain.ForecastSeries"
+                },
+                "expansion": {
+                    "$ref": "#/definitions/domain.ForecastSeries"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "months": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "new": {
+                    "$ref": "#/definitions/domain.ForecastSeries"
+                },
+                "old": {
+                    "$ref": "#/definitions/domain.ForecastSeries"
+                },
+                "periods": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "reactivation": {
+                    "$ref": "#/definitions/domain.ForecastSeries"
+                },
+                "total": {
+                    "$ref": "#/definitions/domain.ForecastSeries"
+                }
+            }
+        },
+        "domain.MRRMetrics": {
+            "type": "object",
+            "properties": {
+                "accounts": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "arpa": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "arr": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "customerChurn": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "ltv": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "mrr": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "quickRatio": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "domain.Member": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Organization": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Member"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "org_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.TotalMRR": {
+            "type": "object",
+            "properties": {
+                "churn": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "contraction": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "expansion": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "new": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "old": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "reactivation": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "total": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "domain.Webhook": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "org_id": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WebhookAttempt": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.WebhookAttempt"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "org_id": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "webhook_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AlertRule": {
+            "type": "object",
+            "required": [
+                "channels",
+                "condition",
+                "metric",
+                "name",
+                "threshold"
+            ],
+            "properties": {
+                "channels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "webhook"
+                    ]
+                },
+                "condition": {
+                    "type": "string",
+                    "example": "above"
+                },
+                "dataset_id": {
+                    "type": "string",
+                    "example": "filename.csv"
+                },
+                "metric": {
+                    "type": "string",
+                    "example": "churn"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Churn spike"
+                },
+                "of_previous_total": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "recipients": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "owner@example.com"
+                    ]
+                },
+                "threshold": {
+                    "type": "number",
+                    "example": 5
+                }
+            }
+        },
+        "models.Analytics": {
+            "type": "object",
+            "required": [
+                "filename",
+                "period_end",
+                "period_start"
+            ],
+            "properties": {
+                "compare": {
+                    "$ref": "#/definitions/models.Comparison"
+                },
+                "filename": {
+                    "type": "string",
+                    "example": "filename.csv"
+                },
+                "period_end": {
+                    "type": "string",
+                    "example": "2021-01-01"
+                },
+                "period_start": {
+                    "type": "string",
+                    "example": "2019-01-01"
+                }
+            }
+        },
+        "models.Bridge": {
+            "type": "object",
+            "required": [
+                "filename",
+                "period_end",
+                "period_start"
+            ],
+            "properties": {
+                "filename": {
+                    "type": "string",
+                    "example": "filename.csv"
+                },
+                "period_end": {
+                    "type": "string",
+                    "example": "2021-01-01"
+                },
+                "period_start": {
+                    "type": "string",
+                    "example": "2019-01-01"
+                },
+                "top": {
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "models.Comparison": {
+            "type": "object",
+            "required": [
+                "mode"
+            ],
+            "properties": {
+                "mode": {
+                    "type": "string",
+                    "example": "last_year"
+                },
+                "period_end": {
+                    "type": "string",
+                    "example": "2020-01-01"
+                },
+                "period_start": {
+                    "type": "string",
+                    "example": "2018-01-01"
+                }
+            }
+        },
+        "models.DatasetUpdate": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Invoices exported from billing"
+                },
+                "display_name": {
+                    "type": "string",
+                    "example": "Invoices 2021"
+                },
+                "labels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "billing",
+                        "2021"
+                    ]
+                }
+            }
+        },
+        "models.Forecast": {
+            "type": "object",
+            "required": [
+                "filename",
+                "horizon",
+                "period_end",
+                "period_start"
+            ],
+            "properties": {
+                "confidence": {
+                    "type": "number",
+                    "example": 0.95
+                },
+                "filename": {
+                    "type": "string",
+                    "example": "filename.csv"
+                },
+                "horizon": {
+                    "type": "integer",
+                    "example": 6
+                },
+                "method": {
+                    "type": "string",
+                    "example": "trend"
+                },
+                "period_end": {
+                    "type": "string",
+                    "example": "2021-01-01"
+                },
+                "period_start": {
+                    "type": "string",
+                    "example": "2019-01-01"
+                }
+            }
+        },
+        "models.Member": {
+            "type": "object",
+            "required": [
+                "email",
+                "role"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "test@test.com"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "viewer"
+                }
+            }
+        },
+        "models.Organization": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Finance"
+                }
+            }
+        },
+        "models.PasswordConfirmation": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "password123"
+                }
+            }
+        },
+        "models.Response": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ResponseSuccessAlertRule": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Alert rule is created"
                 },
-                "new": {
+                "rule": {
+                    "$ref": "#/definitions/domain.AlertRule"
+                }
+            }
+        },
+        "models.ResponseSuccessAlertRules": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Alert rules are loaded"
+                },
+                "rules": {
                     "type": "array",
                     "items": {
-                        "type": "number"
+                        "$ref": "#/definitions/domain.AlertRule"
                     }
-                },
-                "old": {
+                }
+            }
+        },
+        "models.ResponseSuccessAlerts": {
+            "type": "object",
+            "properties": {
+                "alerts": {
                     "type": "array",
                     "items": {
-                        "type": "number"
+                        "$ref": "#/definitions/domain.Alert"
                     }
                 },
-                "reactivation": {
+                "message": {
+                    "type": "string",
+                    "example": "Alerts are loaded"
+                }
+            }
+        },
+        "models.ResponseSuccessAnalytics": {
+            "type": "object",
+            "properties": {
+                "comparison": {
+                    "$ref": "#/definitions/domain.MRRComparison"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Analytics is loaded"
+                },
+                "metrics": {
+                    "$ref": "#/definitions/domain.MRRMetrics"
+                },
+                "months": {
                     "type": "array",
                     "items": {
-                        "type": "number"
+                        "type": "string"
                     }
                 },
-                "total": {
+                "mrr": {
+                    "$ref": "#/definitions/domain.TotalMRR"
+                },
+                "periods": {
                     "type": "array",
                     "items": {
-                        "type": "number"
-                    }
+                        "type": "string"
+                    },
+                    "example": [
+                        "2021-10-01/2021-10-31"
+                    ]
                 }
             }
         },
-        "models.Period": {
+        "models.ResponseSuccessAuditEvents": {
             "type": "object",
-            "required": [
-                "filename",
-                "period_end",
-                "period_start"
-            ],
             "properties": {
-                "filename": {
-                    "type": "string",
-                    "example": "filename.csv"
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.AuditEvent"
+                    }
                 },
-                "period_end": {
-                    "type": "string",
-                    "example": "2021-01-01"
+                "from": {
+                    "type": "string"
                 },
-                "period_start": {
+                "message": {
                     "type": "string",
-                    "example": "2019-01-01"
+                    "example": "Audit events are loaded"
+                },
+                "to": {
+                    "type": "string"
                 }
             }
         },
-        "models.Response": {
+        "models.ResponseSuccessAuth": {
             "type": "object",
             "properties": {
+                "expires_at": {
+                    "type": "integer"
+                },
+                "id_token": {
+                    "type": "string"
+                },
+                "local_id": {
+                    "type": "string"
+                },
                 "message": {
                     "type": "string"
                 }
             }
         },
-        "models.ResponseSuccessAnalytics": {
+        "models.ResponseSuccessBridge": {
             "type": "object",
             "properties": {
+                "bridge": {
+                    "$ref": "#/definitions/domain.MRRBridge"
+                },
                 "message": {
                     "type": "string",
-                    "example": "Analytics is loaded"
+                    "example": "Bridge is created"
+                },
+                "top_customers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "$ref": "#/definitions/domain.CustomerMovement"
+                        }
+                    }
+                }
+            }
+        },
+        "models.ResponseSuccessDeleteAccount": {
+            "type": "object",
+            "properties": {
+                "alert_rules": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "cache_keys": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "files": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "invoices": {
+                    "type": "integer",
+                    "example": 1500
+                },
+                "jobs": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Account is deleted"
+                },
+                "organizations": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "webhooks": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.ResponseSuccessForecast": {
+            "type": "object",
+            "properties": {
+                "forecast": {
+                    "$ref": "#/definitions/domain.MRRForecast"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Forecast is created"
                 },
                 "months": {
                     "type": "array",
@@ -433,23 +3199,39 @@ var doc = `{
                 },
                 "mrr": {
                     "$ref": "#/definitions/domain.TotalMRR"
+                },
+                "periods": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "2021-10-01/2021-10-31"
+                    ]
                 }
             }
         },
-        "models.ResponseSuccessAuth": {
+        "models.ResponseSuccessGetFile": {
             "type": "object",
             "properties": {
-                "expires_at": {
-                    "type": "integer"
+                "file": {
+                    "$ref": "#/definitions/domain.Dataset"
                 },
-                "id_token": {
-                    "type": "string"
-                },
-                "local_id": {
-                    "type": "string"
+                "message": {
+                    "type": "string",
+                    "example": "File is loaded"
+                }
+            }
+        },
+        "models.ResponseSuccessGetJob": {
+            "type": "object",
+            "properties": {
+                "job": {
+                    "$ref": "#/definitions/domain.Job"
                 },
                 "message": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "Job is loaded"
                 }
             }
         },
@@ -459,7 +3241,7 @@ var doc = `{
                 "files": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/domain.File"
+                        "$ref": "#/definitions/domain.Dataset"
                     }
                 },
                 "message": {
@@ -468,6 +3250,33 @@ var doc = `{
                 }
             }
         },
+        "models.ResponseSuccessOrganization": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Organization is created"
+                },
+                "organization": {
+                    "$ref": "#/definitions/domain.Organization"
+                }
+            }
+        },
+        "models.ResponseSuccessOrganizations": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Organizations are loaded"
+                },
+                "organizations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Organization"
+                    }
+                }
+            }
+        },
         "models.ResponseSuccessSaveFileContent": {
             "type": "object",
             "properties": {
@@ -475,9 +3284,156 @@ var doc = `{
                     "type": "string",
                     "example": "filename.csv"
                 },
+                "job": {
+                    "$ref": "#/definitions/domain.Job"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "File is queued for processing"
+                }
+            }
+        },
+        "models.ResponseSuccessScenario": {
+            "type": "object",
+            "properties": {
+                "baseline": {
+                    "$ref": "#/definitions/domain.TotalMRR"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Scenario is created"
+                },
+                "months": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "periods": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "2021-10-01/2021-10-31"
+                    ]
+                },
+                "scenario": {
+                    "$ref": "#/definitions/domain.TotalMRR"
+                }
+            }
+        },
+        "models.ResponseSuccessWebhook": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Webhook is created"
+                },
+                "webhook": {
+                    "$ref": "#/definitions/domain.Webhook"
+                }
+            }
+        },
+        "models.ResponseSuccessWebhookDeliveries": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.WebhookDelivery"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Deliveries are loaded"
+                }
+            }
+        },
+        "models.ResponseSuccessWebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "delivery": {
+                    "$ref": "#/definitions/domain.WebhookDelivery"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Test event is delivered"
+                }
+            }
+        },
+        "models.ResponseSuccessWebhooks": {
+            "type": "object",
+            "properties": {
                 "message": {
                     "type": "string",
-                    "example": "File is uploaded"
+                    "example": "Webhooks are loaded"
+                },
+                "webhooks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Webhook"
+                    }
+                }
+            }
+        },
+        "models.Scenario": {
+            "type": "object",
+            "required": [
+                "filename",
+                "period_end",
+                "period_start"
+            ],
+            "properties": {
+                "adjustments": {
+                    "$ref": "#/definitions/models.ScenarioAdjustments"
+                },
+                "filename": {
+                    "type": "string",
+                    "example": "filename.csv"
+                },
+                "period_end": {
+                    "type": "string",
+                    "example": "2021-01-01"
+                },
+                "period_start": {
+                    "type": "string",
+                    "example": "2019-01-01"
+                }
+            }
+        },
+        "models.ScenarioAdjustments": {
+            "type": "object",
+            "properties": {
+                "churn_rate": {
+                    "type": "number",
+                    "example": 0
+                },
+                "churn_reduction": {
+                    "type": "number",
+                    "example": 0.2
+                },
+                "excluded_customers": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        42
+                    ]
+                },
+                "expansion_rate": {
+                    "type": "number",
+                    "example": 0.01
+                },
+                "plan_multipliers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    },
+                    "example": {
+                        "annually": 1.1
+                    }
                 }
             }
         },
@@ -497,6 +3453,28 @@ var doc = `{
                     "example": "password123"
                 }
             }
+        },
+        "models.Webhook": {
+            "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "dataset.imported"
+                    ]
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/webhook"
+                }
+            }
         }
     },
     "securityDefinitions": {
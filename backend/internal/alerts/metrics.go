@@ -0,0 +1,111 @@
+package alerts
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
+
+var metricTitles = map[string]string{
+	domain.AlertMetricNew:          "New MRR",
+	domain.AlertMetricOld:          "Old MRR",
+	domain.AlertMetricReactivation: "Reactivation MRR",
+	domain.AlertMetricExpansion:    "Expansion MRR",
+	domain.AlertMetricContraction:  "Contraction MRR",
+	domain.AlertMetricChurn:        "Churn MRR",
+	domain.AlertMetricTotal:        "Total MRR",
+	domain.AlertMetricNetNew:       "Net new MRR",
+	domain.AlertMetricGrowthRate:   "MRR growth rate",
+	domain.AlertMetricChurnRate:    "MRR churn rate",
+}
+
+// IsRate reports whether metric is a percent of the previous month's total by itself, so it
+// can't be taken relative to the previous month's total once more.
+func IsRate(metric string) bool {
+	return metric == domain.AlertMetricGrowthRate || metric == domain.AlertMetricChurnRate
+}
+
+// Value returns metric of the rule for the month with the given index in units of the rule's
+// threshold. False is returned when metric is unknown or relative to the previous month's
+// total, while there is no previous month or its total is zero.
+func Value(rule domain.AlertRule, mrr domain.TotalMRR, month int) (float64, bool) {
+	if month < 0 || month >= len(mrr.Total) {
+		return 0, false
+	}
+	previousTotal := 0.0
+	if month > 0 {
+		previousTotal = float64(mrr.Total[month-1])
+	}
+	relative := rule.OfPreviousTotal || IsRate(rule.Metric)
+	if relative && previousTotal == 0 {
+		return 0, false
+	}
+
+	var value float64
+	switch rule.Metric {
+	case domain.AlertMetricNew:
+		value = float64(mrr.New[month])
+	case domain.AlertMetricOld:
+		value = float64(mrr.Old[month])
+	case domain.AlertMetricReactivation:
+		value = float64(mrr.Reactivation[month])
+	case domain.AlertMetricExpansion:
+		value = float64(mrr.Expansion[month])
+	case domain.AlertMetricContraction:
+		value = math.Abs(float64(mrr.Contraction[month]))
+	case domain.AlertMetricChurn, domain.AlertMetricChurnRate:
+		value = math.Abs(float64(mrr.Churn[month]))
+	case domain.AlertMetricTotal:
+		value = float64(mrr.Total[month])
+	case domain.AlertMetricNetNew:
+		value = float64(mrr.New[month] + mrr.Reactivation[month] + mrr.Expansion[month] + mrr.Contraction[month] + mrr.Churn[month])
+	case domain.AlertMetricGrowthRate:
+		value = float64(mrr.Total[month]) - previousTotal
+	default:
+		return 0, false
+	}
+
+	if relative {
+		value = value / previousTotal * 100
+	}
+
+	return value, true
+}
+
+// Exceeds reports whether value crosses threshold of the rule in the rule's direction.
+func Exceeds(rule domain.AlertRule, value float64) bool {
+	switch rule.Condition {
+	case domain.AlertAbove:
+		return value > rule.Threshold
+	case domain.AlertBelow:
+		return value < rule.Threshold
+	}
+	return false
+}
+
+// Message describes value of the rule's metric for the month and the threshold it has crossed.
+func Message(rule domain.AlertRule, month string, value float64) string {
+	title, ok := metricTitles[rule.Metric]
+	if !ok {
+		title = rule.Metric
+	}
+
+	format := "%.2f"
+	switch {
+	case IsRate(rule.Metric):
+		format = "%.2f%%"
+	case rule.OfPreviousTotal:
+		format = "%.2f%% of the previous month's total"
+	}
+
+	return fmt.Sprintf("%s of %s is "+format+", %s the threshold of %.2f%s",
+		title, month, value, rule.Condition, rule.Threshold, unit(rule))
+}
+
+func unit(rule domain.AlertRule) string {
+	if IsRate(rule.Metric) || rule.OfPreviousTotal {
+		return "%"
+	}
+	return ""
+}
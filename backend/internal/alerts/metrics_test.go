@@ -0,0 +1,114 @@
+package alerts
+
+import (
+	"testing"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+var testMRR = domain.TotalMRR{
+	New:          []float32{1000, 200},
+	Old:          []float32{0, 700},
+	Reactivation: []float32{0, 50},
+	Expansion:    []float32{0, 100},
+	Contraction:  []float32{0, -50},
+	Churn:        []float32{0, -150},
+	Total:        []float32{1000, 1050},
+}
+
+func TestValue(t *testing.T) {
+	type testInput struct {
+		rule  domain.AlertRule
+		month int
+	}
+	type testWant struct {
+		value float64
+		ok    bool
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{rule: domain.AlertRule{Metric: domain.AlertMetricNew}, month: 1},
+			want:  testWant{value: 200, ok: true},
+		},
+		{
+			input: testInput{rule: domain.AlertRule{Metric: domain.AlertMetricChurn}, month: 1},
+			want:  testWant{value: 150, ok: true},
+		},
+		{
+			input: testInput{rule: domain.AlertRule{Metric: domain.AlertMetricChurn, OfPreviousTotal: true}, month: 1},
+			want:  testWant{value: 15, ok: true},
+		},
+		{
+			input: testInput{rule: domain.AlertRule{Metric: domain.AlertMetricContraction}, month: 1},
+			want:  testWant{value: 50, ok: true},
+		},
+		{
+			input: testInput{rule: domain.AlertRule{Metric: domain.AlertMetricNetNew}, month: 1},
+			want:  testWant{value: 150, ok: true},
+		},
+		{
+			input: testInput{rule: domain.AlertRule{Metric: domain.AlertMetricGrowthRate}, month: 1},
+			want:  testWant{value: 5, ok: true},
+		},
+		{
+			input: testInput{rule: domain.AlertRule{Metric: domain.AlertMetricChurnRate}, month: 1},
+			want:  testWant{value: 15, ok: true},
+		},
+		{
+			input: testInput{rule: domain.AlertRule{Metric: domain.AlertMetricTotal}, month: 0},
+			want:  testWant{value: 1000, ok: true},
+		},
+		{
+			input: testInput{rule: domain.AlertRule{Metric: domain.AlertMetricChurnRate}, month: 0},
+			want:  testWant{ok: false},
+		},
+		{
+			input: testInput{rule: domain.AlertRule{Metric: domain.AlertMetricNew}, month: 2},
+			want:  testWant{ok: false},
+		},
+		{
+			input: testInput{rule: domain.AlertRule{Metric: "arr"}, month: 1},
+			want:  testWant{ok: false},
+		},
+	}
+
+	for _, test := range tests {
+		value, ok := Value(test.input.rule, testMRR, test.input.month)
+		assert.Equal(t, test.want.ok, ok)
+		assert.InDelta(t, test.want.value, value, 0.001)
+	}
+}
+
+func TestExceeds(t *testing.T) {
+	above := domain.AlertRule{Condition: domain.AlertAbove, Threshold: 5}
+	below := domain.AlertRule{Condition: domain.AlertBelow, Threshold: 5}
+
+	assert.Equal(t, true, Exceeds(above, 5.5))
+	assert.Equal(t, false, Exceeds(above, 5))
+	assert.Equal(t, true, Exceeds(below, 4.5))
+	assert.Equal(t, false, Exceeds(below, 5))
+	assert.Equal(t, false, Exceeds(domain.AlertRule{Threshold: 5}, 10))
+}
+
+func TestMessage(t *testing.T) {
+	assert.Equal(
+		t,
+		"Churn MRR of 2.2021 is 15.00% of the previous month's total, above the threshold of 5.00%",
+		Message(domain.AlertRule{Metric: domain.AlertMetricChurn, Condition: domain.AlertAbove, Threshold: 5, OfPreviousTotal: true}, "2.2021", 15),
+	)
+	assert.Equal(
+		t,
+		"New MRR of 2.2021 is 200.00, below the threshold of 500.00",
+		Message(domain.AlertRule{Metric: domain.AlertMetricNew, Condition: domain.AlertBelow, Threshold: 500}, "2.2021", 200),
+	)
+	assert.Equal(
+		t,
+		"MRR growth rate of 2.2021 is 5.00%, below the threshold of 10.00%",
+		Message(domain.AlertRule{Metric: domain.AlertMetricGrowthRate, Condition: domain.AlertBelow, Threshold: 10}, "2.2021", 5),
+	)
+}
@@ -0,0 +1,5 @@
+package alerts
+
+type TriggerMock struct{}
+
+func (tm *TriggerMock) DatasetImported(_, _ string) {}
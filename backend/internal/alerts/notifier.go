@@ -0,0 +1,111 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"github.com/hackfeed/remrratality/backend/internal/config"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
+	"github.com/hackfeed/remrratality/backend/internal/webhooks"
+	log "github.com/sirupsen/logrus"
+)
+
+// Notifier delivers triggered alert through a single channel.
+type Notifier interface {
+	Notify(ctx context.Context, rule domain.AlertRule, alert domain.Alert) error
+}
+
+// Notifiers returns notifiers of every channel: webhooks of organization are sent alert.triggered
+// event, emails are sent to the rule's recipients through SMTP server and log channel writes
+// a warning to the service log.
+func Notifiers(cfg config.SMTP, notifier webhooks.Notifier) map[string]Notifier {
+	return map[string]Notifier{
+		domain.AlertChannelWebhook: &WebhookNotifier{notifier: notifier},
+		domain.AlertChannelEmail:   NewEmailNotifier(cfg),
+		domain.AlertChannelLog:     &LogNotifier{},
+	}
+}
+
+type LogNotifier struct{}
+
+func (ln *LogNotifier) Notify(ctx context.Context, rule domain.AlertRule, alert domain.Alert) error {
+	logging.FromContext(ctx).WithFields(log.Fields{
+		"org_id":     alert.OrgID,
+		"rule_id":    alert.RuleID,
+		"dataset_id": alert.DatasetID,
+	}).Warnf("alert %q is triggered: %s", rule.Name, alert.Message)
+	return nil
+}
+
+type WebhookNotifier struct {
+	notifier webhooks.Notifier
+}
+
+func (wn *WebhookNotifier) Notify(ctx context.Context, _ domain.AlertRule, alert domain.Alert) error {
+	if err := wn.notifier.Notify(ctx, alert.OrgID, domain.WebhookAlertTriggered, alert); err != nil {
+		return fmt.Errorf("failed to notify webhooks, error is: %s", err)
+	}
+	return nil
+}
+
+// sendMail sends message through SMTP server at addr, smtp.SendMail is used outside of tests.
+type sendMail func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+
+type EmailNotifier struct {
+	cfg  config.SMTP
+	send sendMail
+}
+
+func NewEmailNotifier(cfg config.SMTP) *EmailNotifier {
+	return &EmailNotifier{
+		cfg:  cfg,
+		send: smtp.SendMail,
+	}
+}
+
+func (en *EmailNotifier) Notify(_ context.Context, rule domain.AlertRule, alert domain.Alert) error {
+	if en.cfg.Host == "" {
+		return errors.New("email notifications are disabled, alerts.smtp.host is not set")
+	}
+	if len(rule.Recipients) == 0 {
+		return errors.New("alert rule has no recipients")
+	}
+
+	var auth smtp.Auth
+	if en.cfg.Username != "" {
+		auth = smtp.PlainAuth("", en.cfg.Username, en.cfg.Password, en.cfg.Host)
+	}
+
+	addr := net.JoinHostPort(en.cfg.Host, strconv.Itoa(en.cfg.Port))
+	if err := en.send(addr, auth, en.cfg.From, rule.Recipients, message(en.cfg.From, rule, alert)); err != nil {
+		return fmt.Errorf("failed to send email, error is: %s", err)
+	}
+
+	return nil
+}
+
+// message returns plain text email describing the alert. Rule's name is user input, so line
+// breaks are removed from it before it's put into the subject.
+func message(from string, rule domain.AlertRule, alert domain.Alert) []byte {
+	name := strings.NewReplacer("\r", " ", "\n", " ").Replace(rule.Name)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(rule.Recipients, ", "))
+	fmt.Fprintf(&buf, "Subject: Alert %q is triggered\r\n", name)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("\r\n")
+	fmt.Fprintf(&buf, "%s\r\n\r\n", alert.Message)
+	fmt.Fprintf(&buf, "Dataset: %s\r\n", alert.DatasetID)
+	fmt.Fprintf(&buf, "Month: %s\r\n", alert.Month)
+
+	return buf.Bytes()
+}
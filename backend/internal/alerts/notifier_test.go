@@ -0,0 +1,74 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"net/smtp"
+	"strings"
+	"testing"
+
+	"github.com/hackfeed/remrratality/backend/internal/config"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/webhooks"
+	"github.com/stretchr/testify/assert"
+)
+
+var testAlert = domain.Alert{
+	ID:        "alert",
+	RuleID:    "rule",
+	OrgID:     "org",
+	DatasetID: "file.csv",
+	Month:     "10.2021",
+	Message:   "Churn MRR of 10.2021 is 15.00% of the previous month's total, above the threshold of 5.00%",
+}
+
+func TestEmailNotifier(t *testing.T) {
+	rule := domain.AlertRule{Name: "Churn\r\nBcc: spy@example.com", Recipients: []string{"owner@example.com", "cfo@example.com"}}
+
+	var sent struct {
+		addr string
+		auth smtp.Auth
+		from string
+		to   []string
+		msg  string
+	}
+	notifier := NewEmailNotifier(config.SMTP{Host: "smtp.example.com", Port: 587, Username: "user", Password: "pass", From: "alerts@example.com"})
+	notifier.send = func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+		sent.addr, sent.auth, sent.from, sent.to, sent.msg = addr, auth, from, to, string(msg)
+		return nil
+	}
+
+	assert.NoError(t, notifier.Notify(context.Background(), rule, testAlert))
+	assert.Equal(t, "smtp.example.com:587", sent.addr)
+	assert.NotNil(t, sent.auth)
+	assert.Equal(t, "alerts@example.com", sent.from)
+	assert.Equal(t, rule.Recipients, sent.to)
+	assert.Equal(t, true, strings.Contains(sent.msg, "To: owner@example.com, cfo@example.com\r\n"))
+	assert.Equal(t, true, strings.Contains(sent.msg, "Subject: Alert \"Churn  Bcc: spy@example.com\" is triggered\r\n"))
+	assert.Equal(t, true, strings.Contains(sent.msg, "\r\n\r\n"+testAlert.Message+"\r\n"))
+
+	notifier.send = func(string, smtp.Auth, string, []string, []byte) error {
+		return errors.New("connection refused")
+	}
+	assert.EqualError(t, notifier.Notify(context.Background(), rule, testAlert), "failed to send email, error is: connection refused")
+
+	rule.Recipients = nil
+	assert.EqualError(t, notifier.Notify(context.Background(), rule, testAlert), "alert rule has no recipients")
+
+	notifier = NewEmailNotifier(config.SMTP{})
+	assert.EqualError(t, notifier.Notify(context.Background(), rule, testAlert), "email notifications are disabled, alerts.smtp.host is not set")
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	notifier := Notifiers(config.SMTP{}, &webhooks.NotifierMock{})[domain.AlertChannelWebhook]
+
+	assert.NoError(t, notifier.Notify(context.Background(), domain.AlertRule{}, testAlert))
+
+	alert := testAlert
+	alert.OrgID = "errorNotify"
+	assert.EqualError(
+		t,
+		notifier.Notify(context.Background(), domain.AlertRule{}, alert),
+		"failed to notify webhooks, error is: error while notifying webhooks",
+	)
+}
@@ -0,0 +1,301 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/config"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
+	alertrepo "github.com/hackfeed/remrratality/backend/internal/store/alert_repo"
+	datasetrepo "github.com/hackfeed/remrratality/backend/internal/store/dataset_repo"
+	"github.com/hackfeed/remrratality/backend/internal/tracing"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const layout = "2006-01-02"
+
+// Analyzer returns MRR analytics of the dataset for months between periodStart and periodEnd,
+// which are formatted as 2006-01-02, and labels of these months.
+type Analyzer func(ctx context.Context, orgID, datasetID, periodStart, periodEnd string) ([]string, domain.TotalMRR, error)
+
+// Trigger requests evaluation of alert rules.
+type Trigger interface {
+	// DatasetImported queues evaluation of organization's rules watching the imported dataset.
+	DatasetImported(orgID, datasetID string)
+}
+
+type imported struct {
+	orgID     string
+	datasetID string
+}
+
+// Scheduler evaluates alert rules after imports and once a month, against the last complete
+// month of the dataset. Every rule triggers once per month of a dataset, as triggered alerts
+// are recorded before notifications are sent, so several instances may evaluate rules at once.
+type Scheduler struct {
+	cfg         config.Alerts
+	alertRepo   alertrepo.AlertRepository
+	datasetRepo datasetrepo.DatasetRepository
+	analyze     Analyzer
+	notifiers   map[string]Notifier
+
+	imports        chan imported
+	evaluatedMonth string
+	wg             sync.WaitGroup
+}
+
+func NewScheduler(
+	cfg config.Alerts,
+	alertRepo alertrepo.AlertRepository,
+	datasetRepo datasetrepo.DatasetRepository,
+	analyze Analyzer,
+	notifiers map[string]Notifier,
+) *Scheduler {
+	return &Scheduler{
+		cfg:         cfg,
+		alertRepo:   alertRepo,
+		datasetRepo: datasetRepo,
+		analyze:     analyze,
+		notifiers:   notifiers,
+		imports:     make(chan imported, cfg.QueueSize),
+	}
+}
+
+// DatasetImported doesn't block, evaluation is skipped while the queue is full and the rules are
+// evaluated against the dataset on the next monthly run.
+func (s *Scheduler) DatasetImported(orgID, datasetID string) {
+	select {
+	case s.imports <- imported{orgID: orgID, datasetID: datasetID}:
+	default:
+		log.Warnf("alerts queue is full, rules of org_id %s are not evaluated against dataset %s", orgID, datasetID)
+	}
+}
+
+// Start starts evaluation of rules, which stops once ctx is done. Monthly evaluation runs on
+// start and whenever a new month begins.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.wg.Add(1)
+	go s.work(ctx)
+}
+
+// Wait blocks until evaluation which is in progress finishes.
+func (s *Scheduler) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to wait for alerts scheduler to stop, error is: %w", ctx.Err())
+	}
+}
+
+func (s *Scheduler) work(stop context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for stop.Err() == nil {
+		s.evaluateMonthly(context.Background())
+
+		select {
+		case <-stop.Done():
+		case imp := <-s.imports:
+			s.evaluateImport(context.Background(), imp.orgID, imp.datasetID)
+		case <-ticker.C:
+		}
+	}
+}
+
+// evaluateMonthly evaluates every rule once a month, it's retried on the next check if rules
+// can't be loaded.
+func (s *Scheduler) evaluateMonthly(ctx context.Context) {
+	month := now().Format("2006-01")
+	if month == s.evaluatedMonth {
+		return
+	}
+
+	rules, err := s.alertRepo.GetAllRules(ctx)
+	if err != nil {
+		log.Errorf("failed to get alert rules for monthly evaluation, error is: %s", err)
+		return
+	}
+	s.evaluatedMonth = month
+
+	latest := make(map[string]domain.Dataset)
+	for _, rule := range rules {
+		dataset, err := s.watchedDataset(ctx, rule, latest)
+		if errors.Is(err, datasetrepo.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			log.Errorf("failed to get dataset watched by alert rule %s, error is: %s", rule.ID, err)
+			continue
+		}
+		if _, err := s.Evaluate(ctx, rule, dataset); err != nil {
+			log.Errorf("failed to evaluate alert rule %s, error is: %s", rule.ID, err)
+		}
+	}
+}
+
+func (s *Scheduler) evaluateImport(ctx context.Context, orgID, datasetID string) {
+	rules, err := s.alertRepo.GetRules(ctx, orgID)
+	if err != nil {
+		log.Errorf("failed to get alert rules of org_id %s, error is: %s", orgID, err)
+		return
+	}
+	if len(rules) == 0 {
+		return
+	}
+
+	dataset, err := s.datasetRepo.GetDataset(ctx, orgID, datasetID)
+	if err != nil {
+		log.Errorf("failed to get imported dataset %s, error is: %s", datasetID, err)
+		return
+	}
+
+	for _, rule := range rules {
+		if !rule.Watches(datasetID) {
+			continue
+		}
+		if _, err := s.Evaluate(ctx, rule, dataset); err != nil {
+			log.Errorf("failed to evaluate alert rule %s, error is: %s", rule.ID, err)
+		}
+	}
+}
+
+// watchedDataset returns dataset of the rule or the latest ready dataset of its organization,
+// latest datasets are cached by organization.
+func (s *Scheduler) watchedDataset(ctx context.Context, rule domain.AlertRule, latest map[string]domain.Dataset) (domain.Dataset, error) {
+	if rule.DatasetID != "" {
+		return s.datasetRepo.GetDataset(ctx, rule.OrgID, rule.DatasetID)
+	}
+	if dataset, ok := latest[rule.OrgID]; ok {
+		return dataset, nil
+	}
+
+	datasets, err := s.datasetRepo.GetDatasets(ctx, rule.OrgID, domain.DatasetQuery{SortBy: "uploaded_at", Desc: true})
+	if err != nil {
+		return domain.Dataset{}, err
+	}
+	for _, dataset := range datasets {
+		if dataset.Status == domain.DatasetReady {
+			latest[rule.OrgID] = dataset
+			return dataset, nil
+		}
+	}
+
+	return domain.Dataset{}, datasetrepo.ErrNotFound
+}
+
+// Evaluate checks the rule against the last complete month of the dataset and notifies about
+// alert through every channel of the rule, if it's triggered for the first time. Triggered alert
+// is returned, nil is returned when rule isn't triggered or has already triggered for the month.
+// Failed notifications are logged, as alert is already recorded by then.
+func (s *Scheduler) Evaluate(ctx context.Context, rule domain.AlertRule, dataset domain.Dataset) (*domain.Alert, error) {
+	ctx, span := tracing.Start(ctx, "alert evaluation", trace.WithAttributes(
+		attribute.String("alert.rule_id", rule.ID),
+		attribute.String("dataset.id", dataset.Name),
+	))
+	defer span.End()
+	logger := logging.FromContext(ctx).WithField("rule_id", rule.ID)
+
+	periodStart, periodEnd, ok := evaluatedPeriod(dataset)
+	if !ok {
+		return nil, nil
+	}
+
+	months, mrr, err := s.analyze(ctx, rule.OrgID, dataset.Name, periodStart.Format(layout), periodEnd.Format(layout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MRR analytics of dataset %s, error is: %s", dataset.Name, err)
+	}
+	if len(months) == 0 || len(months) != len(mrr.Total) {
+		return nil, nil
+	}
+
+	month := len(months) - 1
+	value, ok := Value(rule, mrr, month)
+	if !ok || !Exceeds(rule, value) {
+		return nil, nil
+	}
+
+	alert, err := s.alertRepo.AddAlert(ctx, domain.Alert{
+		RuleID:    rule.ID,
+		OrgID:     rule.OrgID,
+		DatasetID: dataset.Name,
+		Month:     months[month],
+		Metric:    rule.Metric,
+		Condition: rule.Condition,
+		Threshold: rule.Threshold,
+		Value:     value,
+		Message:   Message(rule, months[month], value),
+	})
+	if errors.Is(err, alertrepo.ErrExists) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record alert, error is: %s", err)
+	}
+
+	for _, channel := range rule.Channels {
+		notifier, ok := s.notifiers[channel]
+		if !ok {
+			logger.Errorf("unknown alert channel %s", channel)
+			continue
+		}
+		if err := notifier.Notify(ctx, rule, alert); err != nil {
+			logger.Errorf("failed to notify about alert through %s channel, error is: %s", channel, err)
+		}
+	}
+
+	return &alert, nil
+}
+
+// evaluatedPeriod returns months from the first month of the dataset to its last complete month,
+// which is the last month of the dataset unless it's the current one or later. False is returned
+// when the dataset has no complete months yet.
+func evaluatedPeriod(dataset domain.Dataset) (time.Time, time.Time, bool) {
+	if dataset.Status != domain.DatasetReady {
+		return time.Time{}, time.Time{}, false
+	}
+	first, err := time.Parse(layout, dataset.PeriodStartMin)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	last, err := time.Parse(layout, dataset.PeriodStartMax)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	first = monthStart(first)
+	last = monthStart(last)
+	if previous := monthStart(now()).AddDate(0, -1, 0); last.After(previous) {
+		last = previous
+	}
+	if last.Before(first) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	return first, last, true
+}
+
+func monthStart(t time.Time) time.Time {
+	year, month, _ := t.Date()
+	return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// now is a variable, so tests may pin the current month.
+var now = func() time.Time {
+	return time.Now().UTC()
+}
@@ -0,0 +1,207 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/config"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	alertrepo "github.com/hackfeed/remrratality/backend/internal/store/alert_repo"
+	datasetrepo "github.com/hackfeed/remrratality/backend/internal/store/dataset_repo"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingNotifier keeps alerts it's notified about as rule ID and month.
+type recordingNotifier struct {
+	mu     sync.Mutex
+	alerts []string
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, rule domain.AlertRule, alert domain.Alert) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.alerts = append(n.alerts, rule.ID+" "+alert.Month)
+	return nil
+}
+
+// datedDatasetRepo returns datasets with the period of the mock's dataset from GetDatasets.
+type datedDatasetRepo struct {
+	datasetrepo.DatasetRepositoryMock
+}
+
+func (r *datedDatasetRepo) GetDatasets(ctx context.Context, orgID string, query domain.DatasetQuery) ([]domain.Dataset, error) {
+	datasets, err := r.DatasetRepositoryMock.GetDatasets(ctx, orgID, query)
+	for i := range datasets {
+		dataset, _ := r.GetDataset(ctx, orgID, datasets[i].Name)
+		datasets[i].PeriodStartMin = dataset.PeriodStartMin
+		datasets[i].PeriodStartMax = dataset.PeriodStartMax
+	}
+	return datasets, err
+}
+
+// analyzerStub returns two months of testMRR and keeps datasets and periods it's asked for.
+type analyzerStub struct {
+	calls []string
+}
+
+func (a *analyzerStub) analyze(_ context.Context, _, datasetID, periodStart, periodEnd string) ([]string, domain.TotalMRR, error) {
+	a.calls = append(a.calls, datasetID+" "+periodStart+" "+periodEnd)
+	if datasetID == "errorAnalyze" {
+		return nil, domain.TotalMRR{}, errors.New("error while analyzing")
+	}
+	return []string{"9.2021", "10.2021"}, testMRR, nil
+}
+
+func pinNow(t *testing.T, at time.Time) {
+	previous := now
+	now = func() time.Time { return at }
+	t.Cleanup(func() { now = previous })
+}
+
+func newTestScheduler() (*Scheduler, *analyzerStub, *recordingNotifier) {
+	analyzer := &analyzerStub{}
+	notifier := &recordingNotifier{}
+	scheduler := NewScheduler(
+		config.Alerts{CheckInterval: time.Hour, QueueSize: 1},
+		&alertrepo.AlertRepositoryMock{},
+		&datedDatasetRepo{},
+		analyzer.analyze,
+		map[string]Notifier{domain.AlertChannelLog: notifier},
+	)
+	return scheduler, analyzer, notifier
+}
+
+func TestEvaluate(t *testing.T) {
+	pinNow(t, time.Date(2021, 12, 15, 0, 0, 0, 0, time.UTC))
+
+	type testInput struct {
+		orgID     string
+		threshold float64
+		datasetID string
+	}
+	type testWant struct {
+		triggered bool
+		err       string
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{orgID: "org", threshold: 5, datasetID: "file.csv"},
+			want:  testWant{triggered: true},
+		},
+		{
+			input: testInput{orgID: "org", threshold: 20, datasetID: "file.csv"},
+			want:  testWant{triggered: false},
+		},
+		{
+			input: testInput{orgID: "existingAlert", threshold: 5, datasetID: "file.csv"},
+			want:  testWant{triggered: false},
+		},
+		{
+			input: testInput{orgID: "errorAddAlert", threshold: 5, datasetID: "file.csv"},
+			want:  testWant{err: "failed to record alert, error is: error while adding alert"},
+		},
+		{
+			input: testInput{orgID: "org", threshold: 5, datasetID: "errorAnalyze"},
+			want:  testWant{err: "failed to get MRR analytics of dataset errorAnalyze, error is: error while analyzing"},
+		},
+		{
+			input: testInput{orgID: "org", threshold: 5, datasetID: "importingDataset"},
+			want:  testWant{triggered: false},
+		},
+	}
+
+	for _, test := range tests {
+		scheduler, analyzer, notifier := newTestScheduler()
+		rule, _ := scheduler.alertRepo.GetRule(context.Background(), "rule")
+		rule.OrgID = test.input.orgID
+		rule.Threshold = test.input.threshold
+		dataset, _ := scheduler.datasetRepo.GetDataset(context.Background(), rule.OrgID, test.input.datasetID)
+
+		alert, err := scheduler.Evaluate(context.Background(), rule, dataset)
+		if test.want.err != "" {
+			assert.EqualError(t, err, test.want.err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, test.want.triggered, alert != nil)
+		if !test.want.triggered {
+			assert.Equal(t, 0, len(notifier.alerts))
+			continue
+		}
+
+		assert.Equal(t, []string{"file.csv 2021-09-01 2021-10-01"}, analyzer.calls)
+		assert.Equal(t, "10.2021", alert.Month)
+		assert.InDelta(t, 15, alert.Value, 0.001)
+		assert.Equal(t, "Churn MRR of 10.2021 is 15.00% of the previous month's total, above the threshold of 5.00%", alert.Message)
+		assert.Equal(t, []string{"rule 10.2021"}, notifier.alerts)
+	}
+}
+
+func TestEvaluatedPeriod(t *testing.T) {
+	dataset := domain.Dataset{PeriodStartMin: "2021-09-15", PeriodStartMax: "2021-12-20", Status: domain.DatasetReady}
+
+	pinNow(t, time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC))
+	first, last, ok := evaluatedPeriod(dataset)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC), first)
+	assert.Equal(t, time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC), last)
+
+	pinNow(t, time.Date(2021, 12, 25, 0, 0, 0, 0, time.UTC))
+	_, last, ok = evaluatedPeriod(dataset)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC), last)
+
+	pinNow(t, time.Date(2021, 9, 25, 0, 0, 0, 0, time.UTC))
+	_, _, ok = evaluatedPeriod(dataset)
+	assert.Equal(t, false, ok)
+
+	_, _, ok = evaluatedPeriod(domain.Dataset{Status: domain.DatasetReady})
+	assert.Equal(t, false, ok)
+}
+
+func TestEvaluateImport(t *testing.T) {
+	pinNow(t, time.Date(2021, 12, 15, 0, 0, 0, 0, time.UTC))
+
+	scheduler, analyzer, notifier := newTestScheduler()
+	scheduler.evaluateImport(context.Background(), "org", "file.csv")
+	assert.Equal(t, []string{"file.csv 2021-09-01 2021-10-01"}, analyzer.calls)
+	assert.Equal(t, []string{"rule 10.2021"}, notifier.alerts)
+
+	scheduler, analyzer, _ = newTestScheduler()
+	scheduler.evaluateImport(context.Background(), "errorGetRules", "file.csv")
+	scheduler.evaluateImport(context.Background(), "org", "notFoundDataset")
+	assert.Equal(t, 0, len(analyzer.calls))
+}
+
+func TestEvaluateMonthly(t *testing.T) {
+	pinNow(t, time.Date(2021, 12, 15, 0, 0, 0, 0, time.UTC))
+
+	scheduler, analyzer, notifier := newTestScheduler()
+	scheduler.evaluateMonthly(context.Background())
+	assert.Equal(t, "2021-12", scheduler.evaluatedMonth)
+	assert.Equal(t, []string{"first.csv 2021-09-01 2021-10-01"}, analyzer.calls)
+	assert.Equal(t, []string{"rule 10.2021"}, notifier.alerts)
+
+	scheduler.evaluateMonthly(context.Background())
+	assert.Equal(t, 1, len(analyzer.calls))
+
+	pinNow(t, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	scheduler.evaluateMonthly(context.Background())
+	assert.Equal(t, 2, len(analyzer.calls))
+}
+
+func TestDatasetImported(t *testing.T) {
+	scheduler, _, _ := newTestScheduler()
+
+	scheduler.DatasetImported("org", "first.csv")
+	scheduler.DatasetImported("org", "second.csv")
+	assert.Equal(t, 1, len(scheduler.imports))
+	assert.Equal(t, imported{orgID: "org", datasetID: "first.csv"}, <-scheduler.imports)
+}
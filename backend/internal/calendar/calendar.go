@@ -0,0 +1,353 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/config"
+)
+
+const dateLayout = "2006-01-02"
+
+// ErrOutOfRange is returned for dates no period of calendar contains.
+var ErrOutOfRange = errors.New("date is out of range of calendar")
+
+type calendarKey struct{}
+
+// Period is a reporting period starting on Start and ending before End, both are midnights in UTC.
+type Period struct {
+	Label string
+	Start time.Time
+	End   time.Time
+}
+
+// ISO returns the first and the last days of the period as ISO 8601 interval of dates.
+func (p Period) ISO() string {
+	return fmt.Sprintf("%s/%s", p.Start.Format(dateLayout), p.End.AddDate(0, 0, -1).Format(dateLayout))
+}
+
+// Calendar splits time into consecutive reporting periods analytics is bucketed into.
+type Calendar interface {
+	// Period returns period containing calendar date of date in its location.
+	Period(date time.Time) (Period, error)
+	Next(p Period) (Period, error)
+	Previous(p Period) (Period, error)
+	// Key distinguishes analytics computed with the calendar in cache, it's empty for the default one.
+	Key() string
+}
+
+// New returns calendar cfg describes.
+func New(cfg config.Calendar) (Calendar, error) {
+	fiscalYearStart := time.Month(cfg.FiscalYearStart)
+	if fiscalYearStart < time.January || fiscalYearStart > time.December {
+		return nil, fmt.Errorf("fiscal year start should be a month number, got %d", cfg.FiscalYearStart)
+	}
+
+	switch cfg.Type {
+	case "monthly":
+		return Monthly{FiscalYearStart: fiscalYearStart}, nil
+	case "445", "454":
+		weekStart, ok := config.Weekdays[strings.ToLower(cfg.WeekStart)]
+		if !ok {
+			return nil, fmt.Errorf("unknown week start %q", cfg.WeekStart)
+		}
+		weeks := [3]int{4, 4, 5}
+		if cfg.Type == "454" {
+			weeks = [3]int{4, 5, 4}
+		}
+		return Weekly{FiscalYearStart: fiscalYearStart, WeekStart: weekStart, Weeks: weeks}, nil
+	case "custom":
+		periods := make([]Period, len(cfg.Periods))
+		for i, period := range cfg.Periods {
+			start, err := time.Parse(dateLayout, period.Start)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse start of period %d, error is: %s", i, err)
+			}
+			end, err := time.Parse(dateLayout, period.End)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse end of period %d, error is: %s", i, err)
+			}
+			periods[i] = Period{Label: period.Label, Start: start, End: end.AddDate(0, 0, 1)}
+		}
+		return NewCustom(periods)
+	}
+
+	return nil, fmt.Errorf("unknown calendar type %q", cfg.Type)
+}
+
+// WithContext returns context analytics computed with is bucketed by cal.
+func WithContext(ctx context.Context, cal Calendar) context.Context {
+	return context.WithValue(ctx, calendarKey{}, cal)
+}
+
+// FromContext returns calendar ctx carries, calendar months are used outside of requests.
+func FromContext(ctx context.Context) Calendar {
+	if cal, ok := ctx.Value(calendarKey{}).(Calendar); ok && cal != nil {
+		return cal
+	}
+
+	return Monthly{FiscalYearStart: time.January}
+}
+
+// Between returns periods from the one containing start to the one containing end.
+func Between(cal Calendar, start, end time.Time) ([]Period, error) {
+	first, err := cal.Period(start)
+	if err != nil {
+		return nil, err
+	}
+	last, err := cal.Period(end)
+	if err != nil {
+		return nil, err
+	}
+	if last.Start.Before(first.Start) {
+		return nil, errors.New("period start should be less than period end")
+	}
+
+	periods := []Period{first}
+	for period := first; period.Start.Before(last.Start); {
+		if period, err = cal.Next(period); err != nil {
+			return nil, err
+		}
+		periods = append(periods, period)
+	}
+
+	return periods, nil
+}
+
+// After returns count periods following p.
+func After(cal Calendar, p Period, count int) ([]Period, error) {
+	periods := make([]Period, 0, count)
+	for i := 0; i < count; i++ {
+		var err error
+		if p, err = cal.Next(p); err != nil {
+			return nil, err
+		}
+		periods = append(periods, p)
+	}
+
+	return periods, nil
+}
+
+// Before returns count periods preceding p, the earliest one goes first.
+func Before(cal Calendar, p Period, count int) ([]Period, error) {
+	periods := make([]Period, count)
+	for i := count - 1; i >= 0; i-- {
+		var err error
+		if p, err = cal.Previous(p); err != nil {
+			return nil, err
+		}
+		periods[i] = p
+	}
+
+	return periods, nil
+}
+
+// Labels returns labels of periods.
+func Labels(periods []Period) []string {
+	labels := make([]string, len(periods))
+	for i, period := range periods {
+		labels[i] = period.Label
+	}
+
+	return labels
+}
+
+// ISOLabels returns periods as ISO 8601 intervals of dates.
+func ISOLabels(periods []Period) []string {
+	labels := make([]string, len(periods))
+	for i, period := range periods {
+		labels[i] = period.ISO()
+	}
+
+	return labels
+}
+
+// Monthly calendar has calendar months as periods. They're labeled "M.YYYY" when fiscal year
+// starts in January and by number within fiscal year otherwise, like "P1.FY2022" for February
+// 2021. Fiscal years are named after the calendar year they end in.
+type Monthly struct {
+	FiscalYearStart time.Month
+}
+
+func (m Monthly) Period(date time.Time) (Period, error) {
+	start := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	period := Period{Start: start, End: start.AddDate(0, 1, 0)}
+
+	if m.FiscalYearStart <= time.January {
+		period.Label = fmt.Sprintf("%d.%d", start.Month(), start.Year())
+		return period, nil
+	}
+
+	fiscalYear := start.Year()
+	if start.Month() >= m.FiscalYearStart {
+		fiscalYear++
+	}
+	number := (int(start.Month())-int(m.FiscalYearStart)+12)%12 + 1
+	period.Label = fmt.Sprintf("P%d.FY%d", number, fiscalYear)
+
+	return period, nil
+}
+
+func (m Monthly) Next(p Period) (Period, error) {
+	return m.Period(p.End)
+}
+
+func (m Monthly) Previous(p Period) (Period, error) {
+	return m.Period(p.Start.AddDate(0, 0, -1))
+}
+
+func (m Monthly) Key() string {
+	if m.FiscalYearStart <= time.January {
+		return ""
+	}
+
+	return fmt.Sprintf("monthly-%d", m.FiscalYearStart)
+}
+
+// Weekly calendar splits every quarter of fiscal year into three periods of whole weeks, 4-4-5
+// or 4-5-4 of them. Fiscal year starts on the first WeekStart day of FiscalYearStart month, the
+// extra week of 53-week years goes to the last period. Periods are labeled like "P1.FY2022".
+type Weekly struct {
+	FiscalYearStart time.Month
+	WeekStart       time.Weekday
+	Weeks           [3]int
+}
+
+func (w Weekly) Period(date time.Time) (Period, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	fiscalYear := day.Year()
+	if w.FiscalYearStart > time.January && day.Month() >= w.FiscalYearStart {
+		fiscalYear++
+	}
+	if day.Before(w.yearStart(fiscalYear)) {
+		fiscalYear--
+	}
+
+	yearEnd := w.yearStart(fiscalYear + 1)
+	start := w.yearStart(fiscalYear)
+	for i := 0; i < 12; i++ {
+		end := start.AddDate(0, 0, 7*w.Weeks[i%3])
+		if i == 11 {
+			end = yearEnd
+		}
+		if day.Before(end) {
+			return Period{Label: fmt.Sprintf("P%d.FY%d", i+1, fiscalYear), Start: start, End: end}, nil
+		}
+		start = end
+	}
+
+	return Period{}, ErrOutOfRange
+}
+
+// yearStart returns the first day of fiscal year.
+func (w Weekly) yearStart(fiscalYear int) time.Time {
+	year := fiscalYear
+	if w.FiscalYearStart > time.January {
+		year--
+	}
+
+	first := time.Date(year, w.FiscalYearStart, 1, 0, 0, 0, 0, time.UTC)
+	return first.AddDate(0, 0, (int(w.WeekStart)-int(first.Weekday())+7)%7)
+}
+
+func (w Weekly) Next(p Period) (Period, error) {
+	return w.Period(p.End)
+}
+
+func (w Weekly) Previous(p Period) (Period, error) {
+	return w.Period(p.Start.AddDate(0, 0, -1))
+}
+
+func (w Weekly) Key() string {
+	return fmt.Sprintf("%d%d%d-%d-%d", w.Weeks[0], w.Weeks[1], w.Weeks[2], w.FiscalYearStart, w.WeekStart)
+}
+
+// Custom calendar consists of the table of periods, dates between or outside of them are out of range.
+type Custom struct {
+	periods []Period
+}
+
+// NewCustom returns calendar of periods, which should be sorted and shouldn't overlap. Periods
+// without label are labeled as ISO interval.
+func NewCustom(periods []Period) (Custom, error) {
+	if len(periods) == 0 {
+		return Custom{}, errors.New("custom calendar should have periods")
+	}
+
+	sorted := make([]Period, len(periods))
+	for i, period := range periods {
+		if !period.Start.Before(period.End) {
+			return Custom{}, fmt.Errorf("period %d should start before it ends", i)
+		}
+		if i > 0 && period.Start.Before(sorted[i-1].End) {
+			return Custom{}, fmt.Errorf("period %d should start after period %d ends", i, i-1)
+		}
+		if period.Label == "" {
+			period.Label = period.ISO()
+		}
+		sorted[i] = period
+	}
+
+	return Custom{periods: sorted}, nil
+}
+
+func (c Custom) Period(date time.Time) (Period, error) {
+	index, err := c.index(date)
+	if err != nil {
+		return Period{}, err
+	}
+
+	return c.periods[index], nil
+}
+
+func (c Custom) Next(p Period) (Period, error) {
+	index, err := c.index(p.Start)
+	if err != nil {
+		return Period{}, err
+	}
+	if index+1 == len(c.periods) {
+		return Period{}, ErrOutOfRange
+	}
+
+	return c.periods[index+1], nil
+}
+
+func (c Custom) Previous(p Period) (Period, error) {
+	index, err := c.index(p.Start)
+	if err != nil {
+		return Period{}, err
+	}
+	if index == 0 {
+		return Period{}, ErrOutOfRange
+	}
+
+	return c.periods[index-1], nil
+}
+
+func (c Custom) Key() string {
+	hash := fnv.New32a()
+	for _, period := range c.periods {
+		fmt.Fprintf(hash, "%s=%s;", period.Label, period.ISO())
+	}
+
+	return fmt.Sprintf("custom-%x", hash.Sum32())
+}
+
+func (c Custom) index(date time.Time) (int, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	index := sort.Search(len(c.periods), func(i int) bool {
+		return day.Before(c.periods[i].End)
+	})
+	if index == len(c.periods) || day.Before(c.periods[index].Start) {
+		return 0, ErrOutOfRange
+	}
+
+	return index, nil
+}
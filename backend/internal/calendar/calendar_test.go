@@ -0,0 +1,158 @@
+package calendar
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestMonthly(t *testing.T) {
+	tests := []struct {
+		cal    Monthly
+		date   time.Time
+		period Period
+	}{
+		{
+			cal:    Monthly{FiscalYearStart: time.January},
+			date:   date(2021, time.October, 15),
+			period: Period{Label: "10.2021", Start: date(2021, time.October, 1), End: date(2021, time.November, 1)},
+		},
+		{
+			cal:    Monthly{FiscalYearStart: time.February},
+			date:   date(2021, time.February, 1),
+			period: Period{Label: "P1.FY2022", Start: date(2021, time.February, 1), End: date(2021, time.March, 1)},
+		},
+		{
+			cal:    Monthly{FiscalYearStart: time.February},
+			date:   date(2022, time.January, 31),
+			period: Period{Label: "P12.FY2022", Start: date(2022, time.January, 1), End: date(2022, time.February, 1)},
+		},
+	}
+
+	for _, test := range tests {
+		period, err := test.cal.Period(test.date)
+		assert.NoError(t, err)
+		assert.Equal(t, test.period, period)
+	}
+}
+
+func TestMonthlyLocation(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	assert.NoError(t, err)
+
+	// It's still October in UTC, but calendar date in Moscow is used.
+	period, err := Monthly{FiscalYearStart: time.January}.Period(time.Date(2021, time.November, 1, 1, 0, 0, 0, moscow))
+	assert.NoError(t, err)
+	assert.Equal(t, "11.2021", period.Label)
+}
+
+func TestWeekly(t *testing.T) {
+	cal := Weekly{FiscalYearStart: time.February, WeekStart: time.Monday, Weeks: [3]int{4, 4, 5}}
+
+	periods, err := Between(cal, date(2021, time.February, 1), date(2022, time.February, 6))
+	assert.NoError(t, err)
+	assert.Len(t, periods, 12)
+	assert.Equal(t, Period{Label: "P1.FY2022", Start: date(2021, time.February, 1), End: date(2021, time.March, 1)}, periods[0])
+	assert.Equal(t, Period{Label: "P3.FY2022", Start: date(2021, time.March, 29), End: date(2021, time.May, 3)}, periods[2])
+	// The 53rd week goes to the last period.
+	assert.Equal(t, Period{Label: "P12.FY2022", Start: date(2021, time.December, 27), End: date(2022, time.February, 7)}, periods[11])
+
+	period, err := cal.Period(date(2021, time.January, 31))
+	assert.NoError(t, err)
+	assert.Equal(t, "P12.FY2021", period.Label)
+
+	next, err := cal.Next(periods[11])
+	assert.NoError(t, err)
+	assert.Equal(t, Period{Label: "P1.FY2023", Start: date(2022, time.February, 7), End: date(2022, time.March, 7)}, next)
+
+	period, err = Weekly{FiscalYearStart: time.January, WeekStart: time.Sunday, Weeks: [3]int{4, 5, 4}}.Period(date(2021, time.February, 10))
+	assert.NoError(t, err)
+	assert.Equal(t, Period{Label: "P2.FY2021", Start: date(2021, time.January, 31), End: date(2021, time.March, 7)}, period)
+}
+
+func TestCustom(t *testing.T) {
+	cal, err := NewCustom([]Period{
+		{Label: "Q1", Start: date(2021, time.January, 1), End: date(2021, time.April, 1)},
+		{Start: date(2021, time.April, 1), End: date(2021, time.July, 1)},
+		{Label: "H2", Start: date(2021, time.August, 1), End: date(2022, time.January, 1)},
+	})
+	assert.NoError(t, err)
+
+	periods, err := Between(cal, date(2021, time.February, 10), date(2021, time.December, 31))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Q1", "2021-04-01/2021-06-30", "H2"}, Labels(periods))
+
+	_, err = cal.Period(date(2021, time.July, 15))
+	assert.Equal(t, ErrOutOfRange, err)
+	_, err = cal.Next(periods[2])
+	assert.Equal(t, ErrOutOfRange, err)
+
+	previous, err := Before(cal, periods[2], 2)
+	assert.NoError(t, err)
+	assert.Equal(t, periods[:2], previous)
+
+	_, err = NewCustom([]Period{
+		{Start: date(2021, time.January, 1), End: date(2021, time.April, 1)},
+		{Start: date(2021, time.March, 1), End: date(2021, time.July, 1)},
+	})
+	assert.EqualError(t, err, "period 1 should start after period 0 ends")
+}
+
+func TestBetween(t *testing.T) {
+	cal := Monthly{FiscalYearStart: time.January}
+
+	periods, err := Between(cal, date(2021, time.November, 15), date(2022, time.January, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"11.2021", "12.2021", "1.2022"}, Labels(periods))
+	assert.Equal(t, []string{"2021-11-01/2021-11-30", "2021-12-01/2021-12-31", "2022-01-01/2022-01-31"}, ISOLabels(periods))
+
+	after, err := After(cal, periods[2], 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2.2022", "3.2022"}, Labels(after))
+
+	before, err := Before(cal, periods[0], 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"9.2021", "10.2021"}, Labels(before))
+
+	_, err = Between(cal, date(2022, time.January, 1), date(2021, time.November, 15))
+	assert.EqualError(t, err, "period start should be less than period end")
+}
+
+func TestNew(t *testing.T) {
+	cal, err := New(config.Calendar{Type: "monthly", FiscalYearStart: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, Monthly{FiscalYearStart: time.February}, cal)
+	assert.Equal(t, "monthly-2", cal.Key())
+
+	cal, err = New(config.Calendar{Type: "454", FiscalYearStart: 1, WeekStart: "Sunday"})
+	assert.NoError(t, err)
+	assert.Equal(t, Weekly{FiscalYearStart: time.January, WeekStart: time.Sunday, Weeks: [3]int{4, 5, 4}}, cal)
+
+	cal, err = New(config.Calendar{Type: "custom", FiscalYearStart: 1, Periods: []config.CalendarPeriod{
+		{Label: "Launch", Start: "2021-01-01", End: "2021-03-31"},
+	}})
+	assert.NoError(t, err)
+	period, err := cal.Period(date(2021, time.March, 31))
+	assert.NoError(t, err)
+	assert.Equal(t, Period{Label: "Launch", Start: date(2021, time.January, 1), End: date(2021, time.April, 1)}, period)
+
+	_, err = New(config.Calendar{Type: "weekly", FiscalYearStart: 1})
+	assert.EqualError(t, err, "unknown calendar type \"weekly\"")
+	_, err = New(config.Calendar{Type: "custom", FiscalYearStart: 1, Periods: []config.CalendarPeriod{{Start: "2021-01-01", End: "March"}}})
+	assert.Error(t, err)
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Equal(t, Monthly{FiscalYearStart: time.January}, FromContext(context.Background()))
+	assert.Equal(t, "", FromContext(context.Background()).Key())
+
+	cal := Monthly{FiscalYearStart: time.March}
+	assert.Equal(t, cal, FromContext(WithContext(context.Background(), cal)))
+}
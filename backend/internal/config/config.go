@@ -0,0 +1,542 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+type Config struct {
+	Server   Server   `yaml:"server"`
+	Log      Log      `yaml:"log"`
+	JWT      JWT      `yaml:"jwt"`
+	Mongo    Mongo    `yaml:"mongo"`
+	Postgres Postgres `yaml:"postgres"`
+	Redis    Redis    `yaml:"redis"`
+	Cache    Cache    `yaml:"cache"`
+	Tracing  Tracing  `yaml:"tracing"`
+	Jobs     Jobs     `yaml:"jobs"`
+	Webhooks Webhooks `yaml:"webhooks"`
+	Alerts   Alerts   `yaml:"alerts"`
+	Calendar Calendar `yaml:"calendar"`
+}
+
+type Server struct {
+	Port              string        `yaml:"port"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"`
+	// TrustedProxies are addresses or CIDRs of proxies X-Forwarded-For header is taken from, it's
+	// ignored when they're empty and client's address is the one of connection.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+}
+
+type Log struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+	File   string `yaml:"file"`
+}
+
+type JWT struct {
+	SecretKey  string `yaml:"secret_key"`
+	KeysDir    string `yaml:"keys_dir"`
+	SigningKID string `yaml:"signing_kid"`
+}
+
+type Mongo struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	DB       string `yaml:"db"`
+}
+
+type Postgres struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	DB       string `yaml:"db"`
+}
+
+type Redis struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	Password string `yaml:"password"`
+	DB       int    `yaml:"db"`
+}
+
+type Cache struct {
+	TTL time.Duration `yaml:"ttl"`
+}
+
+// Tracing configures where spans are exported: none, otlp (OTLP over HTTP, endpoint defaults to
+// OTEL_EXPORTER_OTLP_ENDPOINT), stdout or file.
+type Tracing struct {
+	Exporter    string  `yaml:"exporter"`
+	Endpoint    string  `yaml:"endpoint"`
+	Insecure    bool    `yaml:"insecure"`
+	File        string  `yaml:"file"`
+	ServiceName string  `yaml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// Jobs configures background processing of uploads. Uploads are kept in UploadDir until they're
+// processed by the instance which received them, Node identifies the instance and defaults to
+// hostname, so both should survive restarts for interrupted jobs to be resumed.
+type Jobs struct {
+	Workers      int           `yaml:"workers"`
+	UploadDir    string        `yaml:"upload_dir"`
+	Node         string        `yaml:"node"`
+	PollInterval time.Duration `yaml:"poll_interval"`
+}
+
+// Webhooks configures delivery of webhook events. Failed delivery is attempted MaxAttempts times
+// in total, the second attempt is made after Backoff and each next one waits twice as long, but
+// no longer than MaxBackoff. Timeout limits a single attempt.
+type Webhooks struct {
+	Workers      int           `yaml:"workers"`
+	PollInterval time.Duration `yaml:"poll_interval"`
+	Timeout      time.Duration `yaml:"timeout"`
+	MaxAttempts  int           `yaml:"max_attempts"`
+	Backoff      time.Duration `yaml:"backoff"`
+	MaxBackoff   time.Duration `yaml:"max_backoff"`
+}
+
+// Alerts configures evaluation of alert rules. Rules are evaluated after every import and once a
+// month, CheckInterval is how often the scheduler checks whether the monthly evaluation is due.
+// Email notifications are sent through SMTP server, they fail while SMTP.Host is empty.
+type Alerts struct {
+	CheckInterval time.Duration `yaml:"check_interval"`
+	QueueSize     int           `yaml:"queue_size"`
+	SMTP          SMTP          `yaml:"smtp"`
+}
+
+type SMTP struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
+// Calendar configures reporting periods analytics is calculated for, Type is monthly, 445, 454 or
+// custom. Fiscal years start in FiscalYearStart month, 445 and 454 calendars start them on the
+// first WeekStart day of the month and split every quarter into periods of 4, 4 and 5 or 4, 5
+// and 4 weeks. Custom calendar consists of Periods, their dates are inclusive.
+type Calendar struct {
+	Type            string           `yaml:"type"`
+	FiscalYearStart int              `yaml:"fiscal_year_start"`
+	WeekStart       string           `yaml:"week_start"`
+	Periods         []CalendarPeriod `yaml:"periods"`
+}
+
+type CalendarPeriod struct {
+	Label string `yaml:"label"`
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+}
+
+// Weekdays maps names of days of week calendar.week_start accepts to them.
+var Weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// LookupEnv returns value of environment variable and whether it's set, os.LookupEnv is used outside of tests.
+type LookupEnv func(string) (string, bool)
+
+// Default returns config with all optional values set.
+func Default() Config {
+	return Config{
+		Server: Server{
+			Port:              "8080",
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       5 * time.Minute,
+			WriteTimeout:      5 * time.Minute,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   25 * time.Second,
+		},
+		Log:   Log{Level: "info", Format: "json"},
+		Mongo: Mongo{DB: "mrr"},
+		Cache: Cache{TTL: time.Hour},
+		Tracing: Tracing{
+			Exporter:    "none",
+			ServiceName: "remrratality-backend",
+			SampleRatio: 1,
+		},
+		Jobs: Jobs{
+			Workers:      2,
+			UploadDir:    "/tmp",
+			PollInterval: 5 * time.Second,
+		},
+		Webhooks: Webhooks{
+			Workers:      2,
+			PollInterval: 5 * time.Second,
+			Timeout:      10 * time.Second,
+			MaxAttempts:  6,
+			Backoff:      30 * time.Second,
+			MaxBackoff:   time.Hour,
+		},
+		Alerts: Alerts{
+			CheckInterval: time.Hour,
+			QueueSize:     100,
+			SMTP:          SMTP{Port: 587, From: "alerts@remrratality.local"},
+		},
+		Calendar: Calendar{
+			Type:            "monthly",
+			FiscalYearStart: 1,
+			WeekStart:       "monday",
+		},
+	}
+}
+
+// Load builds config from defaults, YAML file, environment variables and command line flags,
+// each of them overrides values set by the previous ones. YAML file is read from the path given
+// with -config flag or CONFIG_FILE variable, it's not required.
+func Load(args []string, lookupEnv LookupEnv) (Config, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("backend", flag.ContinueOnError)
+	configFile := fs.String("config", "", "path to YAML config file")
+	port := fs.String("port", "", "port server listens on")
+	logLevel := fs.String("log-level", "", "minimal level of logged entries")
+	logFile := fs.String("log-file", "", "file logs are appended to instead of stdout")
+	cacheTTL := fs.Duration("cache-ttl", 0, "time MRR analytics is cached for")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 0, "time in-flight requests are given to complete on shutdown")
+	tracingExporter := fs.String("tracing-exporter", "", "exporter of spans: none, otlp, stdout or file")
+	if err := fs.Parse(args); err != nil {
+		return Config{}, fmt.Errorf("failed to parse flags, error is: %s", err)
+	}
+
+	path, ok := lookupEnv("CONFIG_FILE")
+	if *configFile != "" {
+		path, ok = *configFile, true
+	}
+	if ok && path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if err := cfg.loadEnv(lookupEnv); err != nil {
+		return Config{}, err
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "port":
+			cfg.Server.Port = *port
+		case "log-level":
+			cfg.Log.Level = *logLevel
+		case "log-file":
+			cfg.Log.File = *logFile
+		case "cache-ttl":
+			cfg.Cache.TTL = *cacheTTL
+		case "shutdown-timeout":
+			cfg.Server.ShutdownTimeout = *shutdownTimeout
+		case "tracing-exporter":
+			cfg.Tracing.Exporter = *tracingExporter
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+func (cfg *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s, error is: %s", path, err)
+	}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return fmt.Errorf("failed to parse config file %s, error is: %s", path, err)
+	}
+
+	return nil
+}
+
+func (cfg *Config) loadEnv(lookupEnv LookupEnv) error {
+	strs := map[string]*string{
+		"PORT":                 &cfg.Server.Port,
+		"LOG_LEVEL":            &cfg.Log.Level,
+		"LOG_FORMAT":           &cfg.Log.Format,
+		"LOG_FILE":             &cfg.Log.File,
+		"SECRET_KEY":           &cfg.JWT.SecretKey,
+		"JWT_KEYS_DIR":         &cfg.JWT.KeysDir,
+		"JWT_SIGNING_KID":      &cfg.JWT.SigningKID,
+		"MONGO_HOST":           &cfg.Mongo.Host,
+		"MONGO_PORT":           &cfg.Mongo.Port,
+		"MONGO_USER":           &cfg.Mongo.User,
+		"MONGO_PASS":           &cfg.Mongo.Password,
+		"MONGO_DB":             &cfg.Mongo.DB,
+		"POSTGRES_HOST":        &cfg.Postgres.Host,
+		"POSTGRES_PORT":        &cfg.Postgres.Port,
+		"POSTGRES_USER":        &cfg.Postgres.User,
+		"POSTGRES_PASS":        &cfg.Postgres.Password,
+		"POSTGRES_DB":          &cfg.Postgres.DB,
+		"REDIS_HOST":           &cfg.Redis.Host,
+		"REDIS_PORT":           &cfg.Redis.Port,
+		"REDIS_PASS":           &cfg.Redis.Password,
+		"TRACING_EXPORTER":     &cfg.Tracing.Exporter,
+		"TRACING_ENDPOINT":     &cfg.Tracing.Endpoint,
+		"TRACING_FILE":         &cfg.Tracing.File,
+		"TRACING_SERVICE_NAME": &cfg.Tracing.ServiceName,
+		"JOBS_UPLOAD_DIR":      &cfg.Jobs.UploadDir,
+		"JOBS_NODE":            &cfg.Jobs.Node,
+		"SMTP_HOST":            &cfg.Alerts.SMTP.Host,
+		"SMTP_USER":            &cfg.Alerts.SMTP.Username,
+		"SMTP_PASS":            &cfg.Alerts.SMTP.Password,
+		"SMTP_FROM":            &cfg.Alerts.SMTP.From,
+		"CALENDAR_TYPE":        &cfg.Calendar.Type,
+		"CALENDAR_WEEK_START":  &cfg.Calendar.WeekStart,
+	}
+	for name, field := range strs {
+		if value, ok := lookupEnv(name); ok {
+			*field = value
+		}
+	}
+
+	ints := map[string]*int{
+		"REDIS_DB":                   &cfg.Redis.DB,
+		"JOBS_WORKERS":               &cfg.Jobs.Workers,
+		"WEBHOOKS_WORKERS":           &cfg.Webhooks.Workers,
+		"WEBHOOKS_MAX_ATTEMPTS":      &cfg.Webhooks.MaxAttempts,
+		"ALERTS_QUEUE_SIZE":          &cfg.Alerts.QueueSize,
+		"SMTP_PORT":                  &cfg.Alerts.SMTP.Port,
+		"CALENDAR_FISCAL_YEAR_START": &cfg.Calendar.FiscalYearStart,
+	}
+	for name, field := range ints {
+		value, ok := lookupEnv(name)
+		if !ok || value == "" {
+			continue
+		}
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s, error is: %s", name, err)
+		}
+		*field = number
+	}
+
+	if value, ok := lookupEnv("SERVER_TRUSTED_PROXIES"); ok {
+		cfg.Server.TrustedProxies = nil
+		for _, proxy := range strings.Split(value, ",") {
+			if proxy = strings.TrimSpace(proxy); proxy != "" {
+				cfg.Server.TrustedProxies = append(cfg.Server.TrustedProxies, proxy)
+			}
+		}
+	}
+
+	if value, ok := lookupEnv("TRACING_INSECURE"); ok && value != "" {
+		insecure, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("failed to parse TRACING_INSECURE, error is: %s", err)
+		}
+		cfg.Tracing.Insecure = insecure
+	}
+
+	if value, ok := lookupEnv("TRACING_SAMPLE_RATIO"); ok && value != "" {
+		ratio, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse TRACING_SAMPLE_RATIO, error is: %s", err)
+		}
+		cfg.Tracing.SampleRatio = ratio
+	}
+
+	durations := map[string]*time.Duration{
+		"CACHE_TTL":                  &cfg.Cache.TTL,
+		"SERVER_READ_HEADER_TIMEOUT": &cfg.Server.ReadHeaderTimeout,
+		"SERVER_READ_TIMEOUT":        &cfg.Server.ReadTimeout,
+		"SERVER_WRITE_TIMEOUT":       &cfg.Server.WriteTimeout,
+		"SERVER_IDLE_TIMEOUT":        &cfg.Server.IdleTimeout,
+		"SERVER_SHUTDOWN_TIMEOUT":    &cfg.Server.ShutdownTimeout,
+		"JOBS_POLL_INTERVAL":         &cfg.Jobs.PollInterval,
+		"WEBHOOKS_POLL_INTERVAL":     &cfg.Webhooks.PollInterval,
+		"WEBHOOKS_TIMEOUT":           &cfg.Webhooks.Timeout,
+		"WEBHOOKS_BACKOFF":           &cfg.Webhooks.Backoff,
+		"WEBHOOKS_MAX_BACKOFF":       &cfg.Webhooks.MaxBackoff,
+		"ALERTS_CHECK_INTERVAL":      &cfg.Alerts.CheckInterval,
+	}
+	for name, field := range durations {
+		value, ok := lookupEnv(name)
+		if !ok || value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s, error is: %s", name, err)
+		}
+		*field = duration
+	}
+
+	return nil
+}
+
+// Validate reports all missing and invalid values at once.
+func (cfg Config) Validate() error {
+	var problems []string
+
+	required := []struct {
+		name, value string
+	}{
+		{"server.port", cfg.Server.Port},
+		{"mongo.host", cfg.Mongo.Host},
+		{"mongo.port", cfg.Mongo.Port},
+		{"mongo.db", cfg.Mongo.DB},
+		{"postgres.host", cfg.Postgres.Host},
+		{"postgres.port", cfg.Postgres.Port},
+		{"postgres.db", cfg.Postgres.DB},
+		{"redis.host", cfg.Redis.Host},
+		{"redis.port", cfg.Redis.Port},
+		{"jobs.upload_dir", cfg.Jobs.UploadDir},
+	}
+	for _, field := range required {
+		if field.value == "" {
+			problems = append(problems, fmt.Sprintf("%s is required", field.name))
+		}
+	}
+
+	ports := []struct {
+		name, value string
+	}{
+		{"server.port", cfg.Server.Port},
+		{"mongo.port", cfg.Mongo.Port},
+		{"postgres.port", cfg.Postgres.Port},
+		{"redis.port", cfg.Redis.Port},
+	}
+	for _, field := range ports {
+		if field.value == "" {
+			continue
+		}
+		if port, err := strconv.Atoi(field.value); err != nil || port <= 0 || port > 65535 {
+			problems = append(problems, fmt.Sprintf("%s should be a port number, got %q", field.name, field.value))
+		}
+	}
+
+	if _, err := log.ParseLevel(cfg.Log.Level); err != nil {
+		problems = append(problems, fmt.Sprintf("log.level should be one of trace, debug, info, warning, error, fatal or panic, got %q", cfg.Log.Level))
+	}
+	if cfg.Log.Format != "json" && cfg.Log.Format != "text" {
+		problems = append(problems, fmt.Sprintf("log.format should be json or text, got %q", cfg.Log.Format))
+	}
+	if cfg.JWT.SecretKey == "" && cfg.JWT.KeysDir == "" {
+		problems = append(problems, "jwt.secret_key or jwt.keys_dir is required")
+	}
+	if cfg.JWT.SigningKID != "" && cfg.JWT.KeysDir == "" {
+		problems = append(problems, "jwt.signing_kid requires jwt.keys_dir")
+	}
+	if cfg.Redis.DB < 0 {
+		problems = append(problems, "redis.db should not be negative")
+	}
+	if cfg.Cache.TTL <= 0 {
+		problems = append(problems, "cache.ttl should be positive")
+	}
+	if cfg.Jobs.Workers <= 0 {
+		problems = append(problems, "jobs.workers should be positive")
+	}
+	if cfg.Webhooks.Workers <= 0 {
+		problems = append(problems, "webhooks.workers should be positive")
+	}
+	if cfg.Webhooks.MaxAttempts <= 0 {
+		problems = append(problems, "webhooks.max_attempts should be positive")
+	}
+	if cfg.Alerts.QueueSize <= 0 {
+		problems = append(problems, "alerts.queue_size should be positive")
+	}
+	if cfg.Alerts.SMTP.Host != "" {
+		if cfg.Alerts.SMTP.Port <= 0 || cfg.Alerts.SMTP.Port > 65535 {
+			problems = append(problems, fmt.Sprintf("alerts.smtp.port should be a port number, got %d", cfg.Alerts.SMTP.Port))
+		}
+		if cfg.Alerts.SMTP.From == "" {
+			problems = append(problems, "alerts.smtp.from is required by alerts.smtp.host")
+		}
+	}
+
+	for _, proxy := range cfg.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			problems = append(problems, fmt.Sprintf("server.trusted_proxies should be IP addresses or CIDRs, got %q", proxy))
+		}
+	}
+
+	switch cfg.Calendar.Type {
+	case "monthly", "445", "454":
+	case "custom":
+		if len(cfg.Calendar.Periods) == 0 {
+			problems = append(problems, "calendar.periods are required by custom calendar")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("calendar.type should be one of monthly, 445, 454 or custom, got %q", cfg.Calendar.Type))
+	}
+	if cfg.Calendar.FiscalYearStart < 1 || cfg.Calendar.FiscalYearStart > 12 {
+		problems = append(problems, fmt.Sprintf("calendar.fiscal_year_start should be a month number, got %d", cfg.Calendar.FiscalYearStart))
+	}
+	if _, ok := Weekdays[strings.ToLower(cfg.Calendar.WeekStart)]; !ok {
+		problems = append(problems, fmt.Sprintf("calendar.week_start should be a day of week, got %q", cfg.Calendar.WeekStart))
+	}
+	for i, period := range cfg.Calendar.Periods {
+		start, startErr := time.Parse("2006-01-02", period.Start)
+		end, endErr := time.Parse("2006-01-02", period.End)
+		if startErr != nil || endErr != nil || end.Before(start) {
+			problems = append(problems, fmt.Sprintf("calendar.periods[%d] should have start and end dates as 2006-01-02, start no later than end", i))
+		}
+	}
+
+	switch cfg.Tracing.Exporter {
+	case "none", "otlp", "stdout":
+	case "file":
+		if cfg.Tracing.File == "" {
+			problems = append(problems, "tracing.file is required by file exporter")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("tracing.exporter should be one of none, otlp, stdout or file, got %q", cfg.Tracing.Exporter))
+	}
+	if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+		problems = append(problems, fmt.Sprintf("tracing.sample_ratio should be between 0 and 1, got %v", cfg.Tracing.SampleRatio))
+	}
+
+	timeouts := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"server.read_header_timeout", cfg.Server.ReadHeaderTimeout},
+		{"server.read_timeout", cfg.Server.ReadTimeout},
+		{"server.write_timeout", cfg.Server.WriteTimeout},
+		{"server.idle_timeout", cfg.Server.IdleTimeout},
+		{"server.shutdown_timeout", cfg.Server.ShutdownTimeout},
+		{"jobs.poll_interval", cfg.Jobs.PollInterval},
+		{"webhooks.poll_interval", cfg.Webhooks.PollInterval},
+		{"webhooks.timeout", cfg.Webhooks.Timeout},
+		{"webhooks.backoff", cfg.Webhooks.Backoff},
+		{"webhooks.max_backoff", cfg.Webhooks.MaxBackoff},
+		{"alerts.check_interval", cfg.Alerts.CheckInterval},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value <= 0 {
+			problems = append(problems, fmt.Sprintf("%s should be positive", timeout.name))
+		}
+	}
+
+	if len(problems) != 0 {
+		return errors.New("invalid config: " + strings.Join(problems, "; "))
+	}
+
+	return nil
+}
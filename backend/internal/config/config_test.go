@@ -0,0 +1,244 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testEnv = map[string]string{
+	"SECRET_KEY":    "secret",
+	"MONGO_HOST":    "mongo",
+	"MONGO_PORT":    "27017",
+	"MONGO_USER":    "user",
+	"MONGO_PASS":    "pass",
+	"MONGO_DB":      "db",
+	"POSTGRES_HOST": "postgres",
+	"POSTGRES_PORT": "5432",
+	"POSTGRES_USER": "user",
+	"POSTGRES_PASS": "pass",
+	"POSTGRES_DB":   "db",
+	"REDIS_HOST":    "redis",
+	"REDIS_PORT":    "6379",
+	"REDIS_PASS":    "pass",
+	"REDIS_DB":      "1",
+}
+
+func lookupEnv(env map[string]string) LookupEnv {
+	return func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+}
+
+func withEnv(overrides map[string]string) map[string]string {
+	env := make(map[string]string)
+	for name, value := range testEnv {
+		env[name] = value
+	}
+	for name, value := range overrides {
+		env[name] = value
+	}
+	return env
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	configFile := writeConfigFile(t, `
+server:
+  port: "9000"
+log:
+  level: debug
+  format: text
+  file: backend.log
+mongo:
+  host: mongo-from-file
+  db: db-from-file
+cache:
+  ttl: 30m
+jobs:
+  upload_dir: /var/lib/uploads
+`)
+
+	type testInput struct {
+		args []string
+		env  map[string]string
+	}
+	type testWant struct {
+		cfg Config
+		err error
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{
+				args: nil,
+				env:  testEnv,
+			},
+			want: testWant{
+				cfg: Config{
+					Server:   Default().Server,
+					Log:      Log{Level: "info", Format: "json"},
+					JWT:      JWT{SecretKey: "secret"},
+					Mongo:    Mongo{Host: "mongo", Port: "27017", User: "user", Password: "pass", DB: "db"},
+					Postgres: Postgres{Host: "postgres", Port: "5432", User: "user", Password: "pass", DB: "db"},
+					Redis:    Redis{Host: "redis", Port: "6379", Password: "pass", DB: 1},
+					Cache:    Cache{TTL: time.Hour},
+					Tracing:  Default().Tracing,
+					Jobs:     Default().Jobs,
+					Webhooks: Default().Webhooks,
+					Alerts:   Default().Alerts,
+					Calendar: Default().Calendar,
+				},
+				err: nil,
+			},
+		},
+		{
+			input: testInput{
+				args: []string{"-config", configFile, "-port", "9001"},
+				env:  withEnv(map[string]string{"MONGO_HOST": "", "CACHE_TTL": "10m"}),
+			},
+			want: testWant{
+				err: errors.New("invalid config: mongo.host is required"),
+			},
+		},
+		{
+			input: testInput{
+				args: []string{"-log-file", "flag.log", "-cache-ttl", "5m", "-shutdown-timeout", "1m", "-tracing-exporter", "file"},
+				env: withEnv(map[string]string{
+					"CONFIG_FILE":                configFile,
+					"MONGO_DB":                   "db-from-env",
+					"SERVER_READ_TIMEOUT":        "1m",
+					"LOG_LEVEL":                  "warning",
+					"TRACING_FILE":               "spans.json",
+					"TRACING_SAMPLE_RATIO":       "0.5",
+					"JOBS_WORKERS":               "4",
+					"JOBS_NODE":                  "backend-1",
+					"WEBHOOKS_MAX_ATTEMPTS":      "3",
+					"WEBHOOKS_BACKOFF":           "1m",
+					"SMTP_HOST":                  "smtp",
+					"SMTP_PORT":                  "25",
+					"CALENDAR_TYPE":              "445",
+					"SERVER_TRUSTED_PROXIES":     "10.0.0.0/8, 192.168.1.10",
+					"CALENDAR_FISCAL_YEAR_START": "2",
+				}),
+			},
+			want: testWant{
+				cfg: Config{
+					Server: Server{
+						Port:              "9000",
+						ReadHeaderTimeout: 10 * time.Second,
+						ReadTimeout:       time.Minute,
+						WriteTimeout:      5 * time.Minute,
+						IdleTimeout:       2 * time.Minute,
+						ShutdownTimeout:   time.Minute,
+						TrustedProxies:    []string{"10.0.0.0/8", "192.168.1.10"},
+					},
+					Log:      Log{Level: "warning", Format: "text", File: "flag.log"},
+					JWT:      JWT{SecretKey: "secret"},
+					Mongo:    Mongo{Host: "mongo", Port: "27017", User: "user", Password: "pass", DB: "db-from-env"},
+					Postgres: Postgres{Host: "postgres", Port: "5432", User: "user", Password: "pass", DB: "db"},
+					Redis:    Redis{Host: "redis", Port: "6379", Password: "pass", DB: 1},
+					Cache:    Cache{TTL: 5 * time.Minute},
+					Tracing:  Tracing{Exporter: "file", File: "spans.json", ServiceName: "remrratality-backend", SampleRatio: 0.5},
+					Jobs:     Jobs{Workers: 4, UploadDir: "/var/lib/uploads", Node: "backend-1", PollInterval: 5 * time.Second},
+					Webhooks: Webhooks{
+						Workers:      2,
+						PollInterval: 5 * time.Second,
+						Timeout:      10 * time.Second,
+						MaxAttempts:  3,
+						Backoff:      time.Minute,
+						MaxBackoff:   time.Hour,
+					},
+					Alerts: Alerts{
+						CheckInterval: time.Hour,
+						QueueSize:     100,
+						SMTP:          SMTP{Host: "smtp", Port: 25, From: "alerts@remrratality.local"},
+					},
+					Calendar: Calendar{Type: "445", FiscalYearStart: 2, WeekStart: "monday"},
+				},
+				err: nil,
+			},
+		},
+		{
+			input: testInput{
+				args: nil,
+				env:  withEnv(map[string]string{"REDIS_DB": "first"}),
+			},
+			want: testWant{
+				err: errors.New("failed to parse REDIS_DB, error is: strconv.Atoi: parsing \"first\": invalid syntax"),
+			},
+		},
+		{
+			input: testInput{
+				args: []string{"-log-level", "loud", "-tracing-exporter", "jaeger"},
+				env:  withEnv(map[string]string{"LOG_FORMAT": "xml", "JOBS_WORKERS": "0", "WEBHOOKS_TIMEOUT": "-1s", "SMTP_HOST": "smtp", "SMTP_FROM": "", "CALENDAR_TYPE": "custom", "CALENDAR_FISCAL_YEAR_START": "13", "SERVER_TRUSTED_PROXIES": "proxy"}),
+			},
+			want: testWant{
+				err: errors.New("invalid config: log.level should be one of trace, debug, info, warning, error, fatal or panic, got \"loud\"; log.format should be json or text, got \"xml\"; jobs.workers should be positive; alerts.smtp.from is required by alerts.smtp.host; server.trusted_proxies should be IP addresses or CIDRs, got \"proxy\"; calendar.periods are required by custom calendar; calendar.fiscal_year_start should be a month number, got 13; tracing.exporter should be one of none, otlp, stdout or file, got \"jaeger\"; webhooks.timeout should be positive"),
+			},
+		},
+		{
+			input: testInput{
+				args: []string{"-unknown"},
+				env:  testEnv,
+			},
+			want: testWant{
+				err: errors.New("failed to parse flags, error is: flag provided but not defined: -unknown"),
+			},
+		},
+		{
+			input: testInput{
+				args: nil,
+				env:  map[string]string{"MONGO_PORT": "mongo"},
+			},
+			want: testWant{
+				err: errors.New("invalid config: mongo.host is required; postgres.host is required; postgres.port is required; postgres.db is required; redis.host is required; redis.port is required; mongo.port should be a port number, got \"mongo\"; jwt.secret_key or jwt.keys_dir is required"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		cfg, err := Load(test.input.args, lookupEnv(test.input.env))
+		if test.want.err != nil {
+			assert.EqualError(t, err, test.want.err.Error())
+			assert.Equal(t, Config{}, cfg)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, test.want.cfg, cfg)
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	unknownKey := writeConfigFile(t, "mongo:\n  hots: mongo\n")
+
+	_, err := Load([]string{"-config", unknownKey}, lookupEnv(testEnv))
+	assert.Error(t, err)
+
+	_, err = Load([]string{"-config", filepath.Join(t.TempDir(), "missing.yml")}, lookupEnv(testEnv))
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	cfg, err := Load(nil, lookupEnv(testEnv))
+	assert.NoError(t, err)
+
+	cfg.JWT = JWT{SecretKey: "secret", SigningKID: "kid"}
+	cfg.Redis.DB = -1
+	cfg.Cache.TTL = 0
+	cfg.Server.ShutdownTimeout = -time.Second
+	assert.EqualError(t, cfg.Validate(), "invalid config: jwt.signing_kid requires jwt.keys_dir; redis.db should not be negative; cache.ttl should be positive; server.shutdown_timeout should be positive")
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/go-redis/redis/extra/redisotel/v8"
 	"github.com/go-redis/redis/v8"
 )
 
@@ -31,7 +32,7 @@ func NewRedisClient(ctx context.Context, options *Options) (*RedisClient, error)
 	defer lock.Unlock()
 
 	if redisClient == nil {
-		client, err := getRedisClient(ctx, options)
+		client, err := getRedisClient(options)
 		if err != nil {
 			return nil, fmt.Errorf("failed to initialize redis client, error is: %s", err)
 		}
@@ -44,23 +45,29 @@ func NewRedisClient(ctx context.Context, options *Options) (*RedisClient, error)
 	return redisClient, nil
 }
 
-func getRedisClient(ctx context.Context, options *Options) (*redis.Client, error) {
+// getRedisClient doesn't connect to redis, so server is able to start when it's down.
+func getRedisClient(options *Options) (*redis.Client, error) {
 	dbOpt, _ := strconv.Atoi(options.DB)
 	opts := redis.Options{
 		Addr:     fmt.Sprintf("%s:%s", options.Host, options.Port),
 		Password: options.Password,
 		DB:       dbOpt,
 	}
-	client := redis.NewClient(&opts)
 
-	if err := client.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("redis client with ttl %s failed to ping address %s, error is: %s",
-			opts.IdleTimeout, opts.Addr, err)
-	}
+	client := redis.NewClient(&opts)
+	client.AddHook(redisotel.NewTracingHook())
 
 	return client, nil
 }
 
+func (rc *RedisClient) Ping(ctx context.Context) error {
+	return rc.Client.Ping(ctx).Err()
+}
+
+func (rc *RedisClient) Close() error {
+	return rc.Client.Close()
+}
+
 func (rc *RedisClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
 	return rc.Client.Set(ctx, key, value, expiration).Err()
 }
@@ -68,3 +75,42 @@ func (rc *RedisClient) Set(ctx context.Context, key string, value interface{}, e
 func (rc *RedisClient) Get(ctx context.Context, key string) ([]byte, error) {
 	return rc.Client.Get(ctx, key).Bytes()
 }
+
+func (rc *RedisClient) Keys(ctx context.Context, pattern string) ([]string, error) {
+	var (
+		keys   []string
+		cursor uint64
+	)
+
+	for {
+		batch, next, err := rc.Client.Scan(ctx, cursor, pattern, 100).Result()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, batch...)
+		if next == 0 {
+			return keys, nil
+		}
+		cursor = next
+	}
+}
+
+func (rc *RedisClient) Del(ctx context.Context, keys ...string) (int64, error) {
+	return rc.Client.Del(ctx, keys...).Result()
+}
+
+func (rc *RedisClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	return rc.Client.Eval(ctx, script, keys, args...).Result()
+}
+
+func (rc *RedisClient) Incr(ctx context.Context, key string) (int64, error) {
+	return rc.Client.Incr(ctx, key).Result()
+}
+
+func (rc *RedisClient) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	return rc.Client.Expire(ctx, key, expiration).Err()
+}
+
+func (rc *RedisClient) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return rc.Client.PTTL(ctx, key).Result()
+}
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -51,3 +52,59 @@ func TestGet(t *testing.T) {
 
 	assert.Equal(t, []byte("key"), res)
 }
+
+func TestKeys(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	redisTestClient = &RedisClient{
+		Client: db,
+	}
+
+	mock.ExpectScan(0, "user.*", 100).SetVal([]string{"user.first"}, 5)
+	mock.ExpectScan(5, "user.*", 100).SetVal([]string{"user.second"}, 0)
+
+	res, err := redisTestClient.Keys(ctx, "user.*")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user.first", "user.second"}, res)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		assert.Error(t, err)
+	}
+}
+
+func TestDel(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	redisTestClient = &RedisClient{
+		Client: db,
+	}
+
+	mock.ExpectDel("first", "second").SetVal(2)
+
+	res, err := redisTestClient.Del(ctx, "first", "second")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), res)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		assert.Error(t, err)
+	}
+}
+
+func TestPing(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	redisTestClient = &RedisClient{
+		Client: db,
+	}
+
+	mock.ExpectPing().SetVal("PONG")
+
+	err := redisTestClient.Ping(ctx)
+	assert.NoError(t, err)
+
+	mock.ExpectPing().SetErr(errors.New("connection refused"))
+
+	err = redisTestClient.Ping(ctx)
+	assert.EqualError(t, err, "connection refused")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		assert.Error(t, err)
+	}
+}
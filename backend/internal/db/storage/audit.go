@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type AuditEvent struct {
+	UserID    string
+	OrgID     string
+	Action    string
+	Success   bool
+	Status    int
+	IP        string
+	RequestID string
+	Details   map[string]interface{}
+	CreatedAt time.Time
+}
+
+var AuditFields = []string{
+	"user_id",
+	"org_id",
+	"action",
+	"success",
+	"status",
+	"ip",
+	"request_id",
+	"details",
+	"created_at",
+}
+
+func (pc *PostgresClient) CreateAuditEvent(ctx context.Context, table string, event AuditEvent) (err error) {
+	ctx, span := startSpan(ctx, "INSERT", table)
+	defer func() { endSpan(span, err) }()
+
+	details := event.Details
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+
+	if _, err := pc.Client.Exec(
+		ctx,
+		fmt.Sprintf(
+			"INSERT INTO %s (%s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)",
+			table,
+			strings.Join(AuditFields, ","),
+		),
+		event.UserID,
+		event.OrgID,
+		event.Action,
+		event.Success,
+		event.Status,
+		event.IP,
+		event.RequestID,
+		details,
+		event.CreatedAt,
+	); err != nil {
+		return fmt.Errorf("failed to run postgres query, error is: %s", err)
+	}
+
+	return nil
+}
+
+func (pc *PostgresClient) ReadAuditEvents(ctx context.Context, table, userID string, from, to time.Time) (data []AuditEvent, err error) {
+	ctx, span := startSpan(ctx, "SELECT", table)
+	defer func() { endSpan(span, err) }()
+
+	rows, err := pc.Client.Query(
+		ctx,
+		fmt.Sprintf(
+			"SELECT %s FROM %s WHERE user_id = $1 AND created_at >= $2 AND created_at <= $3 ORDER BY created_at DESC",
+			strings.Join(AuditFields, ","),
+			table,
+		),
+		userID,
+		from,
+		to,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run postgres query, error is: %s", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		event := AuditEvent{}
+		if err := rows.Scan(
+			&event.UserID,
+			&event.OrgID,
+			&event.Action,
+			&event.Success,
+			&event.Status,
+			&event.IP,
+			&event.RequestID,
+			&event.Details,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to map row to data, error is: %s", err)
+		}
+		data = append(data, event)
+	}
+
+	return data, rows.Err()
+}
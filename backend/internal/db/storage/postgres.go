@@ -6,8 +6,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/hackfeed/remrratality/backend/internal/tracing"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type PostgresClient struct {
@@ -64,7 +68,14 @@ func NewPostgresClient(ctx context.Context, options *Options) (*PostgresClient,
 func getPostgresClient(ctx context.Context, options *Options) (*pgxpool.Pool, error) {
 	dbURL := fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s",
 		options.Host, options.Port, options.User, options.DB, options.Password)
-	client, err := pgxpool.Connect(ctx, dbURL)
+	config, err := pgxpool.ParseConfig(dbURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse postgres config, error is: %s", err)
+	}
+	// Connections are established on first use, so server is able to start when postgres is down.
+	config.LazyConnect = true
+
+	client, err := pgxpool.ConnectConfig(ctx, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect postgres client, error is: %s", err)
 	}
@@ -72,7 +83,42 @@ func getPostgresClient(ctx context.Context, options *Options) (*pgxpool.Pool, er
 	return client, nil
 }
 
-func (pc *PostgresClient) Create(ctx context.Context, table string, fields []string, invoices []Invoice) error {
+func (pc *PostgresClient) Ping(ctx context.Context) error {
+	return pc.Client.Ping(ctx)
+}
+
+// Close waits for acquired connections to be released, so it should be called once
+// no more queries are running.
+func (pc *PostgresClient) Close() {
+	pc.Client.Close()
+}
+
+// startSpan starts client span of the query, pgx doesn't trace queries on its own.
+func startSpan(ctx context.Context, operation, table string) (context.Context, trace.Span) {
+	return tracing.Start(
+		ctx,
+		fmt.Sprintf("postgres %s %s", operation, table),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationKey.String(operation),
+			semconv.DBSQLTableKey.String(table),
+		),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (pc *PostgresClient) Create(ctx context.Context, table string, fields []string, invoices []Invoice) (err error) {
+	ctx, span := startSpan(ctx, "COPY", table)
+	defer func() { endSpan(span, err) }()
+
 	tx, err := pc.Client.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin postgres transaction, error is: %s", err)
@@ -104,7 +150,9 @@ func (pc *PostgresClient) ReadByPeriod(
 	table string,
 	fields []string,
 	userID, fileID string,
-	periodStart, periodEnd time.Time) ([]Invoice, error) {
+	periodStart, periodEnd time.Time) (data []Invoice, err error) {
+	ctx, span := startSpan(ctx, "SELECT", table)
+	defer func() { endSpan(span, err) }()
 
 	cols := ""
 	for _, field := range fields {
@@ -129,7 +177,6 @@ func (pc *PostgresClient) ReadByPeriod(
 		return nil, fmt.Errorf("failed to run postgres query, error is: %s", err)
 	}
 
-	var data []Invoice
 	for rows.Next() {
 		invoice := Invoice{}
 		if err := rows.Scan(
@@ -149,18 +196,75 @@ func (pc *PostgresClient) ReadByPeriod(
 	return data, nil
 }
 
-func (pc *PostgresClient) Delete(ctx context.Context, table, userID, fileID string) error {
-	if _, err := pc.Client.Query(
+// ReadByFile calls fn for every invoice of the file one by one, so the file is never loaded into memory.
+func (pc *PostgresClient) ReadByFile(ctx context.Context, table string, fields []string, userID, fileID string, fn func(Invoice) error) (err error) {
+	ctx, span := startSpan(ctx, "SELECT", table)
+	defer func() { endSpan(span, err) }()
+
+	rows, err := pc.Client.Query(
 		ctx,
 		fmt.Sprintf(
-			"DELETE FROM %s WHERE user_id = '%s' AND file_id = '%s'",
+			"SELECT %s FROM %s WHERE user_id = $1 AND file_id = $2 ORDER BY period_start, customer_id",
+			strings.Join(fields, ","),
 			table,
-			userID,
-			fileID,
 		),
+		userID,
+		fileID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to run postgres query, error is: %s", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		invoice := Invoice{}
+		if err := rows.Scan(
+			&invoice.UserID,
+			&invoice.FileID,
+			&invoice.CustomerID,
+			&invoice.PeriodStart,
+			&invoice.PaidPlan,
+			&invoice.PaidAmount,
+			&invoice.PeriodEnd,
+		); err != nil {
+			return fmt.Errorf("failed to map row to data, error is: %s", err)
+		}
+		if err := fn(invoice); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (pc *PostgresClient) Delete(ctx context.Context, table, userID, fileID string) (err error) {
+	ctx, span := startSpan(ctx, "DELETE", table)
+	defer func() { endSpan(span, err) }()
+
+	if _, err = pc.Client.Exec(
+		ctx,
+		fmt.Sprintf("DELETE FROM %s WHERE user_id = $1 AND file_id = $2", table),
+		userID,
+		fileID,
 	); err != nil {
 		return fmt.Errorf("failed to run postgres query, error is: %s", err)
 	}
 
 	return nil
 }
+
+func (pc *PostgresClient) DeleteByUser(ctx context.Context, table, userID string) (deleted int64, err error) {
+	ctx, span := startSpan(ctx, "DELETE", table)
+	defer func() { endSpan(span, err) }()
+
+	tag, err := pc.Client.Exec(
+		ctx,
+		fmt.Sprintf("DELETE FROM %s WHERE user_id = $1", table),
+		userID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to run postgres query, error is: %s", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,162 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AlertRule struct {
+	RuleID          string    `bson:"rule_id"`
+	OrgID           string    `bson:"org_id"`
+	Name            string    `bson:"name"`
+	DatasetID       string    `bson:"dataset_id"`
+	Metric          string    `bson:"metric"`
+	Condition       string    `bson:"condition"`
+	Threshold       float64   `bson:"threshold"`
+	OfPreviousTotal bool      `bson:"of_previous_total"`
+	Channels        []string  `bson:"channels"`
+	Recipients      []string  `bson:"recipients"`
+	CreatedAt       time.Time `bson:"created_at"`
+}
+
+// Alert is stored under Key, which is unique for the rule, dataset and month, so the same
+// month never triggers the rule twice, even when it's evaluated by several instances.
+type Alert struct {
+	Key       string    `bson:"_id"`
+	AlertID   string    `bson:"alert_id"`
+	RuleID    string    `bson:"rule_id"`
+	OrgID     string    `bson:"org_id"`
+	DatasetID string    `bson:"dataset_id"`
+	Month     string    `bson:"month"`
+	Metric    string    `bson:"metric"`
+	Condition string    `bson:"condition"`
+	Threshold float64   `bson:"threshold"`
+	Value     float64   `bson:"value"`
+	Message   string    `bson:"message"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+func (mc *MongoClient) CreateAlertRule(ctx context.Context, rule AlertRule) (AlertRule, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	if _, err := mc.Client.Database(mc.DB).Collection("alert_rule").InsertOne(ctx, rule); err != nil {
+		return AlertRule{}, fmt.Errorf("failed to run mongo insertOne method, error is: %s", err)
+	}
+
+	return rule, nil
+}
+
+func (mc *MongoClient) ReadAlertRule(ctx context.Context, filter bson.M) (AlertRule, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	var rule AlertRule
+
+	if err := mc.Client.Database(mc.DB).Collection("alert_rule").FindOne(ctx, filter).Decode(&rule); err != nil {
+		return AlertRule{}, fmt.Errorf("failed to run mongo decode method, error is: %w", err)
+	}
+
+	return rule, nil
+}
+
+func (mc *MongoClient) ReadAlertRules(ctx context.Context, filter bson.M) ([]AlertRule, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := mc.Client.Database(mc.DB).Collection("alert_rule").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run mongo find method, error is: %s", err)
+	}
+
+	rules := make([]AlertRule, 0)
+
+	if err = cursor.All(ctx, &rules); err != nil {
+		return nil, fmt.Errorf("failed to run mongo decode method, error is: %s", err)
+	}
+
+	return rules, nil
+}
+
+// DeleteAlertRule deletes rule matching filter together with its alerts.
+func (mc *MongoClient) DeleteAlertRule(ctx context.Context, filter bson.M) error {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	var rule AlertRule
+
+	if err := mc.Client.Database(mc.DB).Collection("alert_rule").FindOneAndDelete(ctx, filter).Decode(&rule); err != nil {
+		return fmt.Errorf("failed to run mongo findOneAndDelete method, error is: %w", err)
+	}
+	if _, err := mc.
+		Client.
+		Database(mc.DB).
+		Collection("alert").
+		DeleteMany(ctx, bson.M{"rule_id": rule.RuleID}); err != nil {
+		return fmt.Errorf("failed to run mongo deleteMany method, error is: %s", err)
+	}
+
+	return nil
+}
+
+// DeleteAlertRules deletes rules matching filter together with their alerts. Alerts are deleted
+// first, so rules are still found when deletion is retried after failure.
+func (mc *MongoClient) DeleteAlertRules(ctx context.Context, filter bson.M) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	ruleIDs, err := mc.Client.Database(mc.DB).Collection("alert_rule").Distinct(ctx, "rule_id", filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to run mongo distinct method, error is: %s", err)
+	}
+	if _, err = mc.
+		Client.
+		Database(mc.DB).
+		Collection("alert").
+		DeleteMany(ctx, bson.M{"rule_id": bson.M{"$in": ruleIDs}}); err != nil {
+		return 0, fmt.Errorf("failed to run mongo deleteMany method, error is: %s", err)
+	}
+
+	res, err := mc.Client.Database(mc.DB).Collection("alert_rule").DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to run mongo deleteMany method, error is: %s", err)
+	}
+
+	return res.DeletedCount, nil
+}
+
+func (mc *MongoClient) CreateAlert(ctx context.Context, alert Alert) (Alert, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	if _, err := mc.Client.Database(mc.DB).Collection("alert").InsertOne(ctx, alert); err != nil {
+		return Alert{}, fmt.Errorf("failed to run mongo insertOne method, error is: %w", err)
+	}
+
+	return alert, nil
+}
+
+// ReadAlerts returns at most limit alerts matching filter, newest first.
+func (mc *MongoClient) ReadAlerts(ctx context.Context, filter bson.M, limit int64) ([]Alert, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(limit)
+	cursor, err := mc.Client.Database(mc.DB).Collection("alert").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run mongo find method, error is: %s", err)
+	}
+
+	alerts := make([]Alert, 0)
+
+	if err = cursor.All(ctx, &alerts); err != nil {
+		return nil, fmt.Errorf("failed to run mongo decode method, error is: %s", err)
+	}
+
+	return alerts, nil
+}
@@ -0,0 +1,102 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Dataset struct {
+	OrgID            string    `bson:"org_id"`
+	Name             string    `bson:"name"`
+	DisplayName      string    `bson:"display_name"`
+	Description      string    `bson:"description"`
+	Labels           []string  `bson:"labels"`
+	OriginalFilename string    `bson:"original_filename"`
+	RowCount         int       `bson:"row_count"`
+	CustomerCount    int       `bson:"customer_count"`
+	PeriodStartMin   string    `bson:"period_start_min"`
+	PeriodStartMax   string    `bson:"period_start_max"`
+	TotalAmount      float64   `bson:"total_amount"`
+	ContentHash      string    `bson:"content_hash"`
+	Status           string    `bson:"status"`
+	UploadedAt       time.Time `bson:"uploaded_at"`
+	UpdatedAt        time.Time `bson:"updated_at"`
+}
+
+func (mc *MongoClient) CreateDataset(ctx context.Context, dataset Dataset) (Dataset, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	if _, err := mc.Client.Database(mc.DB).Collection("dataset").InsertOne(ctx, dataset); err != nil {
+		return Dataset{}, fmt.Errorf("failed to run mongo insertOne method, error is: %s", err)
+	}
+
+	return dataset, nil
+}
+
+func (mc *MongoClient) ReadDataset(ctx context.Context, filter bson.M) (Dataset, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	var dataset Dataset
+
+	if err := mc.Client.Database(mc.DB).Collection("dataset").FindOne(ctx, filter).Decode(&dataset); err != nil {
+		return Dataset{}, fmt.Errorf("failed to run mongo decode method, error is: %w", err)
+	}
+
+	return dataset, nil
+}
+
+func (mc *MongoClient) ReadDatasets(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]Dataset, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	cursor, err := mc.Client.Database(mc.DB).Collection("dataset").Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run mongo find method, error is: %s", err)
+	}
+
+	datasets := make([]Dataset, 0)
+
+	if err = cursor.All(ctx, &datasets); err != nil {
+		return nil, fmt.Errorf("failed to run mongo decode method, error is: %s", err)
+	}
+
+	return datasets, nil
+}
+
+func (mc *MongoClient) UpdateDataset(ctx context.Context, obj interface{}, filter bson.M) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	res, err := mc.
+		Client.
+		Database(mc.DB).
+		Collection("dataset").
+		UpdateOne(
+			ctx,
+			filter,
+			bson.D{{Key: "$set", Value: obj}},
+		)
+	if err != nil {
+		return 0, fmt.Errorf("failed to run mongo updateOne method, error is: %s", err)
+	}
+
+	return res.MatchedCount, nil
+}
+
+func (mc *MongoClient) DeleteDatasets(ctx context.Context, filter bson.M) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	res, err := mc.Client.Database(mc.DB).Collection("dataset").DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to run mongo deleteMany method, error is: %s", err)
+	}
+
+	return res.DeletedCount, nil
+}
@@ -0,0 +1,197 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Job struct {
+	JobID            string     `bson:"job_id"`
+	Type             string     `bson:"type"`
+	OrgID            string     `bson:"org_id"`
+	UserID           string     `bson:"user_id"`
+	State            string     `bson:"state"`
+	Node             string     `bson:"node"`
+	File             string     `bson:"file"`
+	OriginalFilename string     `bson:"original_filename"`
+	DatasetID        string     `bson:"dataset_id"`
+	RowsTotal        int        `bson:"rows_total"`
+	RowsProcessed    int        `bson:"rows_processed"`
+	Stage            string     `bson:"stage"`
+	Errors           []string   `bson:"errors"`
+	Warnings         []string   `bson:"warnings"`
+	Events           int        `bson:"events"`
+	CreatedAt        time.Time  `bson:"created_at"`
+	UpdatedAt        time.Time  `bson:"updated_at"`
+	StartedAt        *time.Time `bson:"started_at,omitempty"`
+	FinishedAt       *time.Time `bson:"finished_at,omitempty"`
+}
+
+type JobEvent struct {
+	JobID         string    `bson:"job_id"`
+	Seq           int       `bson:"seq"`
+	Type          string    `bson:"type"`
+	Stage         string    `bson:"stage"`
+	RowsTotal     int       `bson:"rows_total"`
+	RowsProcessed int       `bson:"rows_processed"`
+	Message       string    `bson:"message"`
+	Errors        []string  `bson:"errors"`
+	CreatedAt     time.Time `bson:"created_at"`
+}
+
+func (mc *MongoClient) CreateJob(ctx context.Context, job Job) (Job, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	if _, err := mc.Client.Database(mc.DB).Collection("job").InsertOne(ctx, job); err != nil {
+		return Job{}, fmt.Errorf("failed to run mongo insertOne method, error is: %s", err)
+	}
+
+	return job, nil
+}
+
+func (mc *MongoClient) ReadJob(ctx context.Context, filter bson.M) (Job, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	var job Job
+
+	if err := mc.Client.Database(mc.DB).Collection("job").FindOne(ctx, filter).Decode(&job); err != nil {
+		return Job{}, fmt.Errorf("failed to run mongo decode method, error is: %w", err)
+	}
+
+	return job, nil
+}
+
+func (mc *MongoClient) ReadJobs(ctx context.Context, filter bson.M) ([]Job, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	cursor, err := mc.Client.Database(mc.DB).Collection("job").Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run mongo find method, error is: %s", err)
+	}
+
+	jobs := make([]Job, 0)
+
+	if err = cursor.All(ctx, &jobs); err != nil {
+		return nil, fmt.Errorf("failed to run mongo decode method, error is: %s", err)
+	}
+
+	return jobs, nil
+}
+
+// ClaimJob atomically applies update to the oldest job matching filter and returns the updated job,
+// so concurrent workers never get the same job.
+func (mc *MongoClient) ClaimJob(ctx context.Context, filter bson.M, update interface{}) (Job, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	var job Job
+
+	opts := options.
+		FindOneAndUpdate().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetReturnDocument(options.After)
+	if err := mc.
+		Client.
+		Database(mc.DB).
+		Collection("job").
+		FindOneAndUpdate(ctx, filter, bson.D{{Key: "$set", Value: update}}, opts).
+		Decode(&job); err != nil {
+		return Job{}, fmt.Errorf("failed to run mongo findOneAndUpdate method, error is: %w", err)
+	}
+
+	return job, nil
+}
+
+func (mc *MongoClient) UpdateJob(ctx context.Context, obj interface{}, filter bson.M) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	res, err := mc.
+		Client.
+		Database(mc.DB).
+		Collection("job").
+		UpdateOne(
+			ctx,
+			filter,
+			bson.D{{Key: "$set", Value: obj}},
+		)
+	if err != nil {
+		return 0, fmt.Errorf("failed to run mongo updateOne method, error is: %s", err)
+	}
+
+	return res.MatchedCount, nil
+}
+
+// DeleteJobs deletes jobs matching filter together with their events. Events are deleted first,
+// so jobs are still found when deletion is retried after failure.
+func (mc *MongoClient) DeleteJobs(ctx context.Context, filter bson.M) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	jobIDs, err := mc.Client.Database(mc.DB).Collection("job").Distinct(ctx, "job_id", filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to run mongo distinct method, error is: %s", err)
+	}
+	if _, err = mc.
+		Client.
+		Database(mc.DB).
+		Collection("job_event").
+		DeleteMany(ctx, bson.M{"job_id": bson.M{"$in": jobIDs}}); err != nil {
+		return 0, fmt.Errorf("failed to run mongo deleteMany method, error is: %s", err)
+	}
+
+	res, err := mc.Client.Database(mc.DB).Collection("job").DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to run mongo deleteMany method, error is: %s", err)
+	}
+
+	return res.DeletedCount, nil
+}
+
+// CreateJobEvent replaces event with the same job_id and seq, so event which is emitted again
+// after restart doesn't duplicate.
+func (mc *MongoClient) CreateJobEvent(ctx context.Context, event JobEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	if _, err := mc.
+		Client.
+		Database(mc.DB).
+		Collection("job_event").
+		ReplaceOne(
+			ctx,
+			bson.M{"job_id": event.JobID, "seq": event.Seq},
+			event,
+			options.Replace().SetUpsert(true),
+		); err != nil {
+		return fmt.Errorf("failed to run mongo replaceOne method, error is: %s", err)
+	}
+
+	return nil
+}
+
+func (mc *MongoClient) ReadJobEvents(ctx context.Context, filter bson.M) ([]JobEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "seq", Value: 1}})
+	cursor, err := mc.Client.Database(mc.DB).Collection("job_event").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run mongo find method, error is: %s", err)
+	}
+
+	events := make([]JobEvent, 0)
+
+	if err = cursor.All(ctx, &events); err != nil {
+		return nil, fmt.Errorf("failed to run mongo decode method, error is: %s", err)
+	}
+
+	return events, nil
+}
@@ -9,10 +9,13 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
 )
 
 type MongoClient struct {
 	Client *mongo.Client
+	DB     string
 }
 
 type Options struct {
@@ -50,6 +53,7 @@ func NewMongoClient(ctx context.Context, options *Options) (*MongoClient, error)
 		}
 		mongoClient = &MongoClient{
 			Client: client,
+			DB:     options.DB,
 		}
 		return mongoClient, nil
 	}
@@ -59,7 +63,9 @@ func NewMongoClient(ctx context.Context, options *Options) (*MongoClient, error)
 
 func getMongoClient(ctx context.Context, opts *Options) (*mongo.Client, error) {
 	dbURL := fmt.Sprintf("mongodb://%s:%s@%s:%s/%s", opts.User, opts.Password, opts.Host, opts.Port, opts.DB)
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(dbURL))
+	// Commands aren't attached to spans as they carry user documents with credentials.
+	monitor := otelmongo.NewMonitor(otelmongo.WithCommandAttributeDisabled(true))
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(dbURL).SetMonitor(monitor))
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect mongo client, error is: %s", err)
 	}
@@ -67,32 +73,40 @@ func getMongoClient(ctx context.Context, opts *Options) (*mongo.Client, error) {
 	return client, nil
 }
 
-func (mc *MongoClient) Create(user User) (User, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+func (mc *MongoClient) Ping(ctx context.Context) error {
+	return mc.Client.Ping(ctx, readpref.Primary())
+}
+
+func (mc *MongoClient) Close(ctx context.Context) error {
+	return mc.Client.Disconnect(ctx)
+}
+
+func (mc *MongoClient) Create(ctx context.Context, user User) (User, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
 	defer cancel()
 
-	if _, err := mc.Client.Database("mrr").Collection("user").InsertOne(ctx, user); err != nil {
+	if _, err := mc.Client.Database(mc.DB).Collection("user").InsertOne(ctx, user); err != nil {
 		return User{}, fmt.Errorf("failed to run mongo insertOne method, error is: %s", err)
 	}
 
 	return user, nil
 }
 
-func (mc *MongoClient) Read(key string, value interface{}) (User, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+func (mc *MongoClient) Read(ctx context.Context, key string, value interface{}) (User, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
 	defer cancel()
 
 	var user User
 
-	if err := mc.Client.Database("mrr").Collection("user").FindOne(ctx, bson.M{key: value}).Decode(&user); err != nil {
+	if err := mc.Client.Database(mc.DB).Collection("user").FindOne(ctx, bson.M{key: value}).Decode(&user); err != nil {
 		return User{}, fmt.Errorf("failed to run mongo decode method, error is: %s", err)
 	}
 
 	return user, nil
 }
 
-func (mc *MongoClient) Update(obj interface{}, key string, value interface{}) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+func (mc *MongoClient) Update(ctx context.Context, obj interface{}, key string, value interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
 	defer cancel()
 
 	upsert := true
@@ -102,7 +116,7 @@ func (mc *MongoClient) Update(obj interface{}, key string, value interface{}) er
 
 	if _, err := mc.
 		Client.
-		Database("mrr").
+		Database(mc.DB).
 		Collection("user").
 		UpdateOne(
 			ctx,
@@ -115,3 +129,15 @@ func (mc *MongoClient) Update(obj interface{}, key string, value interface{}) er
 
 	return nil
 }
+
+func (mc *MongoClient) Delete(ctx context.Context, key string, value interface{}) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	res, err := mc.Client.Database(mc.DB).Collection("user").DeleteOne(ctx, bson.M{key: value})
+	if err != nil {
+		return 0, fmt.Errorf("failed to run mongo deleteOne method, error is: %s", err)
+	}
+
+	return res.DeletedCount, nil
+}
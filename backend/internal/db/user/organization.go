@@ -0,0 +1,97 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type Member struct {
+	UserID string `bson:"user_id"`
+	Email  string `bson:"email"`
+	Role   string `bson:"role"`
+}
+
+type Organization struct {
+	OrgID     string    `bson:"org_id"`
+	Name      string    `bson:"name"`
+	Members   []Member  `bson:"members"`
+	Files     []File    `bson:"files"`
+	CreatedAt time.Time `bson:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+func (mc *MongoClient) CreateOrganization(ctx context.Context, org Organization) (Organization, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	if _, err := mc.Client.Database(mc.DB).Collection("organization").InsertOne(ctx, org); err != nil {
+		return Organization{}, fmt.Errorf("failed to run mongo insertOne method, error is: %s", err)
+	}
+
+	return org, nil
+}
+
+func (mc *MongoClient) ReadOrganization(ctx context.Context, key string, value interface{}) (Organization, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	var org Organization
+
+	if err := mc.Client.Database(mc.DB).Collection("organization").FindOne(ctx, bson.M{key: value}).Decode(&org); err != nil {
+		return Organization{}, fmt.Errorf("failed to run mongo decode method, error is: %w", err)
+	}
+
+	return org, nil
+}
+
+func (mc *MongoClient) ReadOrganizations(ctx context.Context, key string, value interface{}) ([]Organization, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	cursor, err := mc.Client.Database(mc.DB).Collection("organization").Find(ctx, bson.M{key: value})
+	if err != nil {
+		return nil, fmt.Errorf("failed to run mongo find method, error is: %s", err)
+	}
+
+	orgs := make([]Organization, 0)
+
+	if err = cursor.All(ctx, &orgs); err != nil {
+		return nil, fmt.Errorf("failed to run mongo decode method, error is: %s", err)
+	}
+
+	return orgs, nil
+}
+
+func (mc *MongoClient) UpdateOrganization(ctx context.Context, obj interface{}, key string, value interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	if _, err := mc.
+		Client.
+		Database(mc.DB).
+		Collection("organization").
+		UpdateOne(
+			ctx,
+			bson.M{key: value},
+			bson.D{{Key: "$set", Value: obj}},
+		); err != nil {
+		return fmt.Errorf("failed to run mongo updateOne method, error is: %s", err)
+	}
+
+	return nil
+}
+
+func (mc *MongoClient) DeleteOrganization(ctx context.Context, key string, value interface{}) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	res, err := mc.Client.Database(mc.DB).Collection("organization").DeleteOne(ctx, bson.M{key: value})
+	if err != nil {
+		return 0, fmt.Errorf("failed to run mongo deleteOne method, error is: %s", err)
+	}
+
+	return res.DeletedCount, nil
+}
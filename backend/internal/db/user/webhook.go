@@ -0,0 +1,204 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Webhook struct {
+	WebhookID string    `bson:"webhook_id"`
+	OrgID     string    `bson:"org_id"`
+	URL       string    `bson:"url"`
+	Secret    string    `bson:"secret"`
+	Events    []string  `bson:"events"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+type WebhookAttempt struct {
+	At         time.Time `bson:"at"`
+	StatusCode int       `bson:"status_code"`
+	Error      string    `bson:"error"`
+	DurationMs int64     `bson:"duration_ms"`
+}
+
+type WebhookDelivery struct {
+	DeliveryID    string           `bson:"delivery_id"`
+	WebhookID     string           `bson:"webhook_id"`
+	OrgID         string           `bson:"org_id"`
+	Event         string           `bson:"event"`
+	Payload       string           `bson:"payload"`
+	State         string           `bson:"state"`
+	Attempts      []WebhookAttempt `bson:"attempts"`
+	NextAttemptAt *time.Time       `bson:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time        `bson:"created_at"`
+	UpdatedAt     time.Time        `bson:"updated_at"`
+}
+
+func (mc *MongoClient) CreateWebhook(ctx context.Context, webhook Webhook) (Webhook, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	if _, err := mc.Client.Database(mc.DB).Collection("webhook").InsertOne(ctx, webhook); err != nil {
+		return Webhook{}, fmt.Errorf("failed to run mongo insertOne method, error is: %s", err)
+	}
+
+	return webhook, nil
+}
+
+func (mc *MongoClient) ReadWebhook(ctx context.Context, filter bson.M) (Webhook, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	var webhook Webhook
+
+	if err := mc.Client.Database(mc.DB).Collection("webhook").FindOne(ctx, filter).Decode(&webhook); err != nil {
+		return Webhook{}, fmt.Errorf("failed to run mongo decode method, error is: %w", err)
+	}
+
+	return webhook, nil
+}
+
+func (mc *MongoClient) ReadWebhooks(ctx context.Context, filter bson.M) ([]Webhook, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := mc.Client.Database(mc.DB).Collection("webhook").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run mongo find method, error is: %s", err)
+	}
+
+	webhooks := make([]Webhook, 0)
+
+	if err = cursor.All(ctx, &webhooks); err != nil {
+		return nil, fmt.Errorf("failed to run mongo decode method, error is: %s", err)
+	}
+
+	return webhooks, nil
+}
+
+// DeleteWebhook deletes webhook matching filter together with its deliveries.
+func (mc *MongoClient) DeleteWebhook(ctx context.Context, filter bson.M) error {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	var webhook Webhook
+
+	if err := mc.Client.Database(mc.DB).Collection("webhook").FindOneAndDelete(ctx, filter).Decode(&webhook); err != nil {
+		return fmt.Errorf("failed to run mongo findOneAndDelete method, error is: %w", err)
+	}
+	if _, err := mc.
+		Client.
+		Database(mc.DB).
+		Collection("webhook_delivery").
+		DeleteMany(ctx, bson.M{"webhook_id": webhook.WebhookID}); err != nil {
+		return fmt.Errorf("failed to run mongo deleteMany method, error is: %s", err)
+	}
+
+	return nil
+}
+
+// DeleteWebhooks deletes webhooks matching filter together with their deliveries. Deliveries are
+// deleted first, so webhooks are still found when deletion is retried after failure.
+func (mc *MongoClient) DeleteWebhooks(ctx context.Context, filter bson.M) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	webhookIDs, err := mc.Client.Database(mc.DB).Collection("webhook").Distinct(ctx, "webhook_id", filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to run mongo distinct method, error is: %s", err)
+	}
+	if _, err = mc.
+		Client.
+		Database(mc.DB).
+		Collection("webhook_delivery").
+		DeleteMany(ctx, bson.M{"webhook_id": bson.M{"$in": webhookIDs}}); err != nil {
+		return 0, fmt.Errorf("failed to run mongo deleteMany method, error is: %s", err)
+	}
+
+	res, err := mc.Client.Database(mc.DB).Collection("webhook").DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to run mongo deleteMany method, error is: %s", err)
+	}
+
+	return res.DeletedCount, nil
+}
+
+func (mc *MongoClient) CreateWebhookDelivery(ctx context.Context, delivery WebhookDelivery) (WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	if _, err := mc.Client.Database(mc.DB).Collection("webhook_delivery").InsertOne(ctx, delivery); err != nil {
+		return WebhookDelivery{}, fmt.Errorf("failed to run mongo insertOne method, error is: %s", err)
+	}
+
+	return delivery, nil
+}
+
+// ReadWebhookDeliveries returns at most limit deliveries matching filter, newest first.
+func (mc *MongoClient) ReadWebhookDeliveries(ctx context.Context, filter bson.M, limit int64) ([]WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(limit)
+	cursor, err := mc.Client.Database(mc.DB).Collection("webhook_delivery").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run mongo find method, error is: %s", err)
+	}
+
+	deliveries := make([]WebhookDelivery, 0)
+
+	if err = cursor.All(ctx, &deliveries); err != nil {
+		return nil, fmt.Errorf("failed to run mongo decode method, error is: %s", err)
+	}
+
+	return deliveries, nil
+}
+
+// ClaimWebhookDelivery atomically applies update to the delivery matching filter which is due
+// the longest and returns the updated delivery, so concurrent workers never get the same one.
+func (mc *MongoClient) ClaimWebhookDelivery(ctx context.Context, filter bson.M, update interface{}) (WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	var delivery WebhookDelivery
+
+	opts := options.
+		FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)
+	if err := mc.
+		Client.
+		Database(mc.DB).
+		Collection("webhook_delivery").
+		FindOneAndUpdate(ctx, filter, bson.D{{Key: "$set", Value: update}}, opts).
+		Decode(&delivery); err != nil {
+		return WebhookDelivery{}, fmt.Errorf("failed to run mongo findOneAndUpdate method, error is: %w", err)
+	}
+
+	return delivery, nil
+}
+
+func (mc *MongoClient) UpdateWebhookDelivery(ctx context.Context, obj interface{}, filter bson.M) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	res, err := mc.
+		Client.
+		Database(mc.DB).
+		Collection("webhook_delivery").
+		UpdateOne(
+			ctx,
+			filter,
+			bson.D{{Key: "$set", Value: obj}},
+		)
+	if err != nil {
+		return 0, fmt.Errorf("failed to run mongo updateOne method, error is: %s", err)
+	}
+
+	return res.MatchedCount, nil
+}
@@ -0,0 +1,80 @@
+package domain
+
+import "time"
+
+const (
+	AlertMetricNew          = "new"
+	AlertMetricOld          = "old"
+	AlertMetricReactivation = "reactivation"
+	AlertMetricExpansion    = "expansion"
+	AlertMetricContraction  = "contraction"
+	AlertMetricChurn        = "churn"
+	AlertMetricTotal        = "total"
+	AlertMetricNetNew       = "net_new"
+	AlertMetricGrowthRate   = "growth_rate"
+	AlertMetricChurnRate    = "churn_rate"
+
+	AlertAbove = "above"
+	AlertBelow = "below"
+
+	AlertChannelWebhook = "webhook"
+	AlertChannelEmail   = "email"
+	AlertChannelLog     = "log"
+)
+
+// AlertMetrics are metrics alert rule may watch: MRR components, net new MRR and month over
+// month growth and churn rates, which are percents of the previous month's total.
+var AlertMetrics = []string{
+	AlertMetricNew,
+	AlertMetricOld,
+	AlertMetricReactivation,
+	AlertMetricExpansion,
+	AlertMetricContraction,
+	AlertMetricChurn,
+	AlertMetricTotal,
+	AlertMetricNetNew,
+	AlertMetricGrowthRate,
+	AlertMetricChurnRate,
+}
+
+// AlertChannels are channels alert notifications may be sent through.
+var AlertChannels = []string{AlertChannelWebhook, AlertChannelEmail, AlertChannelLog}
+
+// AlertRule triggers an alert when Metric of the last complete month of a dataset is above or
+// below Threshold. Contraction and churn are compared by their magnitude. With OfPreviousTotal
+// threshold is a percent of the previous month's total MRR instead of an amount. Rule watches
+// DatasetID, or the latest imported dataset of organization if it's empty.
+type AlertRule struct {
+	ID              string    `json:"id"`
+	OrgID           string    `json:"org_id"`
+	Name            string    `json:"name"`
+	DatasetID       string    `json:"dataset_id,omitempty"`
+	Metric          string    `json:"metric"`
+	Condition       string    `json:"condition"`
+	Threshold       float64   `json:"threshold"`
+	OfPreviousTotal bool      `json:"of_previous_total"`
+	Channels        []string  `json:"channels"`
+	Recipients      []string  `json:"recipients"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// Watches reports whether rule is evaluated against the dataset when it's imported.
+func (r AlertRule) Watches(datasetID string) bool {
+	return r.DatasetID == "" || r.DatasetID == datasetID
+}
+
+// Alert is a rule triggered by a month of a dataset, every rule triggers once per month of
+// a dataset. Value is compared with the rule's threshold in the same units.
+type Alert struct {
+	ID        string    `json:"id"`
+	RuleID    string    `json:"rule_id"`
+	OrgID     string    `json:"org_id"`
+	DatasetID string    `json:"dataset_id"`
+	Month     string    `json:"month"`
+	Metric    string    `json:"metric"`
+	Condition string    `json:"condition"`
+	Threshold float64   `json:"threshold"`
+	Value     float64   `json:"value"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+const (
+	AuditSignUp          = "signup"
+	AuditLogin           = "login"
+	AuditFileUpload      = "file_upload"
+	AuditFileImport      = "file_import"
+	AuditFileDelete      = "file_delete"
+	AuditAnalytics       = "analytics"
+	AuditWebhookCreate   = "webhook_create"
+	AuditWebhookDelete   = "webhook_delete"
+	AuditAlertRuleCreate = "alert_rule_create"
+	AuditAlertRuleDelete = "alert_rule_delete"
+)
+
+// AuditEvent is an append-only record of security or data related request. Success
+// reflects the response status, so failed logins are stored as unsuccessful login events.
+type AuditEvent struct {
+	UserID    string                 `json:"user_id"`
+	OrgID     string                 `json:"org_id"`
+	Action    string                 `json:"action"`
+	Success   bool                   `json:"success"`
+	Status    int                    `json:"status"`
+	IP        string                 `json:"ip"`
+	RequestID string                 `json:"request_id"`
+	Details   map[string]interface{} `json:"details"`
+	CreatedAt time.Time              `json:"created_at"`
+}
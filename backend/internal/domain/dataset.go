@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+const (
+	DatasetImporting = "importing"
+	DatasetReady     = "ready"
+	DatasetFailed    = "failed"
+)
+
+// Dataset describes uploaded invoices file. Name is the ID invoices are stored under,
+// dates are formatted as 2006-01-02, so they can be passed to analytics as is.
+type Dataset struct {
+	Name             string    `json:"name"`
+	OrgID            string    `json:"org_id"`
+	DisplayName      string    `json:"display_name"`
+	Description      string    `json:"description"`
+	Labels           []string  `json:"labels"`
+	OriginalFilename string    `json:"original_filename"`
+	RowCount         int       `json:"row_count"`
+	CustomerCount    int       `json:"customer_count"`
+	PeriodStartMin   string    `json:"period_start_min"`
+	PeriodStartMax   string    `json:"period_start_max"`
+	TotalAmount      float64   `json:"total_amount"`
+	ContentHash      string    `json:"content_hash"`
+	Status           string    `json:"status"`
+	UploadedAt       time.Time `json:"uploaded_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// DatasetUpdate holds dataset fields to change, nil fields are left untouched.
+type DatasetUpdate struct {
+	DisplayName *string
+	Description *string
+	Labels      *[]string
+}
+
+// DatasetQuery filters datasets by label, display name substring and upload time range
+// and sorts them by SortBy field, which is either "name" or "uploaded_at".
+type DatasetQuery struct {
+	Label        string
+	Name         string
+	UploadedFrom time.Time
+	UploadedTo   time.Time
+	SortBy       string
+	Desc         bool
+}
@@ -0,0 +1,70 @@
+package domain
+
+import "time"
+
+const (
+	JobUpload = "upload"
+
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+
+	// Stages upload job goes through while it's running.
+	StageParsing    = "parsing"
+	StageValidating = "validating"
+	StageInserting  = "inserting"
+
+	// Job events, job's final event has type of its final state.
+	JobEventProgress = "progress"
+	JobEventWarning  = "warning"
+)
+
+// Job is a background task, currently only upload of invoices file. File is the name the
+// upload is stored under on Node, the backend instance which processes the job, so it isn't
+// exposed. DatasetID is the name of dataset the job creates. Events is the number of events
+// the job has emitted, it's the sequence number of the last one.
+type Job struct {
+	ID               string     `json:"id"`
+	Type             string     `json:"type"`
+	OrgID            string     `json:"org_id"`
+	UserID           string     `json:"user_id"`
+	State            string     `json:"state"`
+	Node             string     `json:"-"`
+	File             string     `json:"-"`
+	OriginalFilename string     `json:"original_filename"`
+	DatasetID        string     `json:"dataset_id"`
+	RowsTotal        int        `json:"rows_total"`
+	RowsProcessed    int        `json:"rows_processed"`
+	Stage            string     `json:"stage,omitempty"`
+	Errors           []string   `json:"errors"`
+	Warnings         []string   `json:"warnings"`
+	Events           int        `json:"-"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	StartedAt        *time.Time `json:"started_at,omitempty"`
+	FinishedAt       *time.Time `json:"finished_at,omitempty"`
+}
+
+// Finished reports whether the job won't change anymore.
+func (j Job) Finished() bool {
+	return j.State == JobSucceeded || j.State == JobFailed
+}
+
+// JobEvent reports a change of the job, events of a job are numbered with Seq starting from 1.
+type JobEvent struct {
+	JobID         string    `json:"job_id"`
+	Seq           int       `json:"seq"`
+	Type          string    `json:"type"`
+	Stage         string    `json:"stage,omitempty"`
+	RowsTotal     int       `json:"rows_total"`
+	RowsProcessed int       `json:"rows_processed"`
+	Message       string    `json:"message,omitempty"`
+	Errors        []string  `json:"errors,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Final reports whether it's the last event of the job.
+func (e JobEvent) Final() bool {
+	return e.Type == JobSucceeded || e.Type == JobFailed
+}
@@ -0,0 +1,7 @@
+package domain
+
+// Limit describes token bucket refilled with Rate tokens per second and holding up to Burst tokens.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
@@ -18,3 +18,83 @@ type TotalMRR struct {
 	Churn        []float32
 	Total        []float32
 }
+
+// ForecastSeries is a series projected month by month with bounds of its confidence interval.
+type ForecastSeries struct {
+	Value []float32
+	Lower []float32
+	Upper []float32
+}
+
+// MRRForecast projects Total and every component of TotalMRR for Months following the analyzed
+// period, Periods are the same reporting periods as ISO intervals of dates. Confidence is the
+// probability projected value falls between its bounds.
+type MRRForecast struct {
+	Method       string
+	Confidence   float64
+	Months       []string
+	Periods      []string
+	New          ForecastSeries
+	Old          ForecastSeries
+	Reactivation ForecastSeries
+	Expansion    ForecastSeries
+	Contraction  ForecastSeries
+	Churn        ForecastSeries
+	Total        ForecastSeries
+}
+
+// MRRMetrics are SaaS metrics of analyzed period month by month. MRR is revenue of paying
+// Accounts, unlike Total of TotalMRR summing MRR movements, and ARR is its annual run rate.
+// ARPA is MRR per account, CustomerChurn is the share of the previous month's accounts which
+// stopped paying and LTV is ARPA over CustomerChurn. QuickRatio is MRR gained with new,
+// expansion and reactivation over MRR lost with churn and contraction. LTV and QuickRatio are
+// 0 when nothing is lost.
+type MRRMetrics struct {
+	MRR           []float32
+	ARR           []float32
+	Accounts      []int
+	ARPA          []float32
+	CustomerChurn []float32
+	LTV           []float32
+	QuickRatio    []float32
+}
+
+// MRRBridge walks MRR of StartMonth to MRR of EndMonth, EndMRR is StartMRR with New, Expansion
+// and Reactivation of months after StartMonth added and Contraction and Churn, which are
+// negative, subtracted. StartPeriod and EndPeriod are the same reporting periods as ISO
+// intervals of dates.
+type MRRBridge struct {
+	StartMonth   string
+	EndMonth     string
+	StartPeriod  string
+	EndPeriod    string
+	StartMRR     float32
+	New          float32
+	Expansion    float32
+	Reactivation float32
+	Contraction  float32
+	Churn        float32
+	EndMRR       float32
+}
+
+// CustomerMovement is MRR movement of a customer within a bucket of MRRBridge.
+type CustomerMovement struct {
+	CustomerID uint32
+	Amount     float32
+}
+
+// MRRComparison is analytics of the period analytics is compared with, aligned by month relative
+// to the start of each period. Delta is analytics less MRR of the comparison period and
+// DeltaPercent is Delta in percents of its magnitude, 0 where it's 0, both are given for months the periods
+// have in common. CMGR and ComparisonCMGR are compound monthly growth rates of Total of both
+// periods in percents, 0 when Total of the first month isn't positive. Periods are Months as ISO
+// intervals of dates.
+type MRRComparison struct {
+	Months         []string
+	Periods        []string
+	MRR            TotalMRR
+	Delta          TotalMRR
+	DeltaPercent   TotalMRR
+	CMGR           float32
+	ComparisonCMGR float32
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+type Member struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+}
+
+// Organization owns datasets shared between its members. Datasets are stored under
+// organization's ID, personal organizations reuse the ID of their owner, so the data
+// uploaded before organizations were introduced stays in place.
+type Organization struct {
+	OrgID     string    `json:"org_id"`
+	Name      string    `json:"name"`
+	Members   []Member  `json:"members"`
+	Files     []File    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -19,3 +19,13 @@ type User struct {
 	UpdatedAt    time.Time
 	Files        []File
 }
+
+type DeletedAccount struct {
+	Organizations int
+	Files         int
+	Invoices      int64
+	CacheKeys     int64
+	Jobs          int64
+	Webhooks      int64
+	AlertRules    int64
+}
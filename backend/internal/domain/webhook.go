@@ -0,0 +1,81 @@
+package domain
+
+import "time"
+
+const (
+	WebhookDatasetImported     = "dataset.imported"
+	WebhookDatasetDeleted      = "dataset.deleted"
+	WebhookDatasetImportFailed = "dataset.import_failed"
+	WebhookAlertTriggered      = "alert.triggered"
+
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookEvents are event types webhook may subscribe to.
+var WebhookEvents = []string{WebhookDatasetImported, WebhookDatasetDeleted, WebhookDatasetImportFailed, WebhookAlertTriggered}
+
+// Webhook receives events of organization it's subscribed to. Payloads are signed with
+// Secret, which is shown only once, when webhook is created.
+type Webhook struct {
+	ID        string    `json:"id"`
+	OrgID     string    `json:"org_id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Subscribed reports whether webhook receives events of the given type.
+func (w Webhook) Subscribed(event string) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is a single event sent to the webhook. Payload is stored as it's sent, so
+// every attempt delivers the same body. Pending delivery is attempted at NextAttemptAt.
+type WebhookDelivery struct {
+	ID            string           `json:"id"`
+	WebhookID     string           `json:"webhook_id"`
+	OrgID         string           `json:"org_id"`
+	Event         string           `json:"event"`
+	Payload       string           `json:"payload"`
+	State         string           `json:"state"`
+	Attempts      []WebhookAttempt `json:"attempts"`
+	NextAttemptAt *time.Time       `json:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+}
+
+// WebhookAttempt is a result of sending delivery once, StatusCode is zero when webhook
+// hasn't responded.
+type WebhookAttempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
+// WebhookPayload is the body POSTed to the webhook, Test is set for sample events.
+type WebhookPayload struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	OrgID     string      `json:"org_id"`
+	Test      bool        `json:"test,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// DatasetEvent is data of dataset.* events, JobID and Rows are set for events of import.
+type DatasetEvent struct {
+	DatasetID        string   `json:"dataset_id"`
+	OriginalFilename string   `json:"original_filename,omitempty"`
+	JobID            string   `json:"job_id,omitempty"`
+	Rows             int      `json:"rows,omitempty"`
+	Errors           []string `json:"errors,omitempty"`
+}
@@ -0,0 +1,96 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp          = "up"
+	StatusDown        = "down"
+	StatusOK          = "ok"
+	StatusDegraded    = "degraded"
+	StatusUnavailable = "unavailable"
+)
+
+// Pinger is implemented by clients of backing stores.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Check is a dependency server needs. Server can't serve requests without critical dependency,
+// while the rest of them only degrade it.
+type Check struct {
+	Name     string
+	Pinger   Pinger
+	Critical bool
+}
+
+type Result struct {
+	Status    string `json:"status"`
+	Critical  bool   `json:"critical"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"-"`
+}
+
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Run pings all dependencies concurrently, each of them is given timeout to respond.
+// Report status is unavailable if any critical dependency is down, degraded if any other
+// dependency is down and ok otherwise.
+func Run(ctx context.Context, checks []Check, timeout time.Duration) Report {
+	results := make([]Result, len(checks))
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = ping(ctx, check, timeout)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status: StatusOK,
+		Checks: make(map[string]Result, len(checks)),
+	}
+	for i, check := range checks {
+		result := results[i]
+		report.Checks[check.Name] = result
+
+		if result.Status == StatusUp {
+			continue
+		}
+		if check.Critical {
+			report.Status = StatusUnavailable
+		} else if report.Status == StatusOK {
+			report.Status = StatusDegraded
+		}
+	}
+
+	return report
+}
+
+func ping(ctx context.Context, check Check, timeout time.Duration) Result {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Pinger.Ping(ctx)
+	result := Result{
+		Status:    StatusUp,
+		Critical:  check.Critical,
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
@@ -0,0 +1,105 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type pingerMock struct {
+	err   error
+	delay time.Duration
+}
+
+func (pm pingerMock) Ping(ctx context.Context) error {
+	select {
+	case <-time.After(pm.delay):
+		return pm.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestRun(t *testing.T) {
+	type testInput struct {
+		checks []Check
+	}
+	type testWant struct {
+		status   string
+		statuses map[string]string
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{
+				checks: []Check{
+					{Name: "mongo", Pinger: pingerMock{}, Critical: true},
+					{Name: "redis", Pinger: pingerMock{}},
+				},
+			},
+			want: testWant{
+				status:   StatusOK,
+				statuses: map[string]string{"mongo": StatusUp, "redis": StatusUp},
+			},
+		},
+		{
+			input: testInput{
+				checks: []Check{
+					{Name: "mongo", Pinger: pingerMock{}, Critical: true},
+					{Name: "redis", Pinger: pingerMock{err: errors.New("connection refused")}},
+				},
+			},
+			want: testWant{
+				status:   StatusDegraded,
+				statuses: map[string]string{"mongo": StatusUp, "redis": StatusDown},
+			},
+		},
+		{
+			input: testInput{
+				checks: []Check{
+					{Name: "mongo", Pinger: pingerMock{delay: time.Second}, Critical: true},
+					{Name: "redis", Pinger: pingerMock{err: errors.New("connection refused")}},
+				},
+			},
+			want: testWant{
+				status:   StatusUnavailable,
+				statuses: map[string]string{"mongo": StatusDown, "redis": StatusDown},
+			},
+		},
+		{
+			input: testInput{
+				checks: nil,
+			},
+			want: testWant{
+				status:   StatusOK,
+				statuses: map[string]string{},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		report := Run(context.Background(), test.input.checks, 50*time.Millisecond)
+		assert.Equal(t, test.want.status, report.Status)
+
+		statuses := make(map[string]string)
+		for name, result := range report.Checks {
+			statuses[name] = result.Status
+		}
+		assert.Equal(t, test.want.statuses, statuses)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	report := Run(context.Background(), []Check{
+		{Name: "postgres", Pinger: pingerMock{delay: time.Minute}, Critical: true},
+	}, 10*time.Millisecond)
+
+	assert.Equal(t, StatusUnavailable, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["postgres"].Error)
+}
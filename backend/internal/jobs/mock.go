@@ -0,0 +1,22 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
+
+type QueueMock struct{}
+
+func (qm *QueueMock) SubmitUpload(_ context.Context, job domain.Job, _ io.Reader) (domain.Job, error) {
+	if job.OrgID == "errorSubmitUpload" {
+		return domain.Job{}, errors.New("error while submitting upload")
+	}
+	job.ID = "job"
+	job.Type = domain.JobUpload
+	job.State = domain.JobQueued
+	job.Errors = make([]string, 0)
+	return job, nil
+}
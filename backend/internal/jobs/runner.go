@@ -0,0 +1,353 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hackfeed/remrratality/backend/internal/alerts"
+	"github.com/hackfeed/remrratality/backend/internal/config"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
+	auditrepo "github.com/hackfeed/remrratality/backend/internal/store/audit_repo"
+	datasetrepo "github.com/hackfeed/remrratality/backend/internal/store/dataset_repo"
+	jobrepo "github.com/hackfeed/remrratality/backend/internal/store/job_repo"
+	storagerepo "github.com/hackfeed/remrratality/backend/internal/store/storage_repo"
+	"github.com/hackfeed/remrratality/backend/internal/tracing"
+	"github.com/hackfeed/remrratality/backend/internal/webhooks"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// errInterrupted is returned by job which is stopped by shutdown, it's resumed on the next start.
+var errInterrupted = errors.New("job is interrupted by shutdown")
+
+// Queue accepts jobs for background processing.
+type Queue interface {
+	// SubmitUpload stores content of the uploaded file and queues job importing it as
+	// dataset job.DatasetID of organization job.OrgID.
+	SubmitUpload(context.Context, domain.Job, io.Reader) (domain.Job, error)
+}
+
+// Runner processes jobs of its node with a pool of workers. Jobs are claimed from the job
+// repository, so they survive restarts and are never processed twice.
+type Runner struct {
+	cfg         config.Jobs
+	jobRepo     jobrepo.JobRepository
+	datasetRepo datasetrepo.DatasetRepository
+	storageRepo storagerepo.StorageRepository
+	auditRepo   auditrepo.AuditRepository
+	notifier    webhooks.Notifier
+	alerts      alerts.Trigger
+
+	wake chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewRunner(
+	cfg config.Jobs,
+	jobRepo jobrepo.JobRepository,
+	datasetRepo datasetrepo.DatasetRepository,
+	storageRepo storagerepo.StorageRepository,
+	auditRepo auditrepo.AuditRepository,
+	notifier webhooks.Notifier,
+	alerts alerts.Trigger,
+) (*Runner, error) {
+	if cfg.Node == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get hostname to use as node, error is: %s", err)
+		}
+		cfg.Node = hostname
+	}
+	if err := os.MkdirAll(cfg.UploadDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create upload dir, error is: %s", err)
+	}
+
+	return &Runner{
+		cfg:         cfg,
+		jobRepo:     jobRepo,
+		datasetRepo: datasetRepo,
+		storageRepo: storageRepo,
+		auditRepo:   auditRepo,
+		notifier:    notifier,
+		alerts:      alerts,
+		wake:        make(chan struct{}, cfg.Workers),
+	}, nil
+}
+
+func (r *Runner) SubmitUpload(ctx context.Context, job domain.Job, content io.Reader) (domain.Job, error) {
+	job.ID = uuid.NewString()
+	job.Type = domain.JobUpload
+	job.State = domain.JobQueued
+	job.Node = r.cfg.Node
+	job.File = job.DatasetID
+
+	path := r.uploadPath(job.File)
+	if err := writeFile(path, content); err != nil {
+		return domain.Job{}, err
+	}
+
+	job, err := r.jobRepo.AddJob(ctx, job)
+	if err != nil {
+		if err := os.Remove(path); err != nil {
+			logging.FromContext(ctx).Errorf("failed to remove upload of not queued job, error is: %s", err)
+		}
+		return domain.Job{}, fmt.Errorf("failed to queue job, error is: %s", err)
+	}
+
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+
+	return job, nil
+}
+
+// Start recovers jobs interrupted by the previous run of the node and starts workers, which stop
+// once ctx is done. Recovery is retried until job repository is reachable, workers start after it.
+func (r *Runner) Start(ctx context.Context) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		for {
+			err := r.recover(context.Background())
+			if err == nil {
+				break
+			}
+			log.Warnf("failed to recover interrupted jobs, retrying in %s, error is: %s", r.cfg.PollInterval, err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(r.cfg.PollInterval):
+			}
+		}
+
+		for i := 0; i < r.cfg.Workers; i++ {
+			r.wg.Add(1)
+			go r.work(ctx)
+		}
+	}()
+}
+
+// Wait blocks until workers stop, which happens once ctx passed to Start is done and running
+// jobs reach a point they can be resumed from.
+func (r *Runner) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to wait for workers to stop, error is: %w", ctx.Err())
+	}
+}
+
+func (r *Runner) work(stop context.Context) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for stop.Err() == nil {
+		// Jobs are claimed and processed without stop, so stores are never left half updated.
+		job, err := r.jobRepo.ClaimJob(context.Background(), r.cfg.Node)
+		if err == nil {
+			r.run(stop, job)
+			continue
+		}
+		if !errors.Is(err, jobrepo.ErrNotFound) {
+			log.Errorf("failed to claim job, error is: %s", err)
+		}
+
+		select {
+		case <-stop.Done():
+		case <-r.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Runner) run(stop context.Context, job domain.Job) {
+	ctx, span := tracing.Start(context.Background(), "upload job", trace.WithAttributes(
+		attribute.String("job.id", job.ID),
+		attribute.String("dataset.id", job.DatasetID),
+	))
+	defer span.End()
+	logger := logging.FromContext(ctx).WithField("job_id", job.ID)
+
+	err := r.processUpload(ctx, stop, &job)
+	if errors.Is(err, errInterrupted) {
+		logger.Infof("job is interrupted by shutdown and will be resumed on start")
+		return
+	}
+
+	finishedAt := now()
+	job.FinishedAt = &finishedAt
+	job.State = domain.JobSucceeded
+	message := "Dataset is imported"
+	if err != nil {
+		logger.Errorf("job failed, error is: %s", err)
+		job.State = domain.JobFailed
+		message = "Dataset import failed"
+	}
+	r.emit(ctx, &job, domain.JobEvent{Type: job.State, Message: message, Errors: job.Errors})
+	if err := r.jobRepo.UpdateJob(ctx, job); err != nil {
+		logger.Errorf("failed to save result of the job, error is: %s", err)
+	}
+	r.audit(ctx, job)
+	r.notify(ctx, job)
+	if job.State == domain.JobSucceeded {
+		r.alerts.DatasetImported(job.OrgID, job.DatasetID)
+	}
+
+	if err := os.Remove(r.uploadPath(job.File)); err != nil && !os.IsNotExist(err) {
+		logger.Errorf("failed to remove uploaded file, error is: %s", err)
+	}
+}
+
+// recover prepares jobs left running by the previous run of the node to be processed from
+// scratch: invoices and dataset they've created are deleted. Jobs which upload is lost fail.
+func (r *Runner) recover(ctx context.Context) error {
+	interrupted, err := r.jobRepo.GetJobs(ctx, r.cfg.Node, domain.JobRunning)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range interrupted {
+		if err := r.discardDataset(ctx, job); err != nil {
+			return err
+		}
+		if err := r.datasetRepo.DeleteDataset(ctx, job.OrgID, job.DatasetID); err != nil && !errors.Is(err, datasetrepo.ErrNotFound) {
+			return fmt.Errorf("failed to delete dataset of interrupted job %s, error is: %s", job.ID, err)
+		}
+
+		job.RowsTotal = 0
+		job.RowsProcessed = 0
+		job.Stage = ""
+		job.Errors = make([]string, 0)
+		job.Warnings = make([]string, 0)
+		job.StartedAt = nil
+		job.State = domain.JobQueued
+		event := domain.JobEvent{Type: domain.JobEventProgress, Message: "Job is restarted after interruption"}
+		if _, err := os.Stat(r.uploadPath(job.File)); err != nil {
+			finishedAt := now()
+			job.State = domain.JobFailed
+			job.FinishedAt = &finishedAt
+			job.Errors = append(job.Errors, "Uploaded file is lost on restart, please upload it again")
+			event = domain.JobEvent{Type: domain.JobFailed, Message: "Dataset import failed", Errors: job.Errors}
+		}
+		r.emit(ctx, &job, event)
+
+		if err := r.jobRepo.UpdateJob(ctx, job); err != nil {
+			return fmt.Errorf("failed to reset interrupted job %s, error is: %s", job.ID, err)
+		}
+		if job.Finished() {
+			r.notify(ctx, job)
+		}
+		log.Infof("interrupted job %s is %s", job.ID, job.State)
+	}
+
+	return nil
+}
+
+// emit stores event of the job, job's counter of events is saved on its next update. Failure
+// to store event doesn't fail the job, as events only report its progress.
+func (r *Runner) emit(ctx context.Context, job *domain.Job, event domain.JobEvent) {
+	job.Events++
+	event.JobID = job.ID
+	event.Seq = job.Events
+	event.Stage = job.Stage
+	event.RowsTotal = job.RowsTotal
+	event.RowsProcessed = job.RowsProcessed
+	event.CreatedAt = now()
+
+	if err := r.jobRepo.AddJobEvent(ctx, event); err != nil {
+		logging.FromContext(ctx).Warnf("failed to add event %d of job %s, error is: %s", event.Seq, job.ID, err)
+	}
+}
+
+// audit records result of the finished job for its user, as the upload request is audited before
+// rows are counted. Failed job stores no rows.
+func (r *Runner) audit(ctx context.Context, job domain.Job) {
+	event := domain.AuditEvent{
+		UserID:  job.UserID,
+		OrgID:   job.OrgID,
+		Action:  domain.AuditFileImport,
+		Success: job.State == domain.JobSucceeded,
+		Details: map[string]interface{}{
+			"filename":          job.DatasetID,
+			"original_filename": job.OriginalFilename,
+			"job_id":            job.ID,
+			"rows":              job.RowsProcessed,
+		},
+		CreatedAt: time.Now().UTC(),
+	}
+	if !event.Success {
+		event.Details["rows"] = 0
+	}
+
+	if err := r.auditRepo.AddEvent(ctx, event); err != nil {
+		logging.FromContext(ctx).Errorf("failed to record %s event of job %s, error is: %s", event.Action, job.ID, err)
+	}
+}
+
+// notify sends result of the finished job to webhooks of its organization. Failure to queue
+// deliveries doesn't fail the job, as the dataset is already imported or failed by then.
+func (r *Runner) notify(ctx context.Context, job domain.Job) {
+	event := domain.WebhookDatasetImported
+	data := domain.DatasetEvent{
+		DatasetID:        job.DatasetID,
+		OriginalFilename: job.OriginalFilename,
+		JobID:            job.ID,
+		Rows:             job.RowsProcessed,
+	}
+	if job.State == domain.JobFailed {
+		event = domain.WebhookDatasetImportFailed
+		data.Rows = 0
+		data.Errors = job.Errors
+	}
+
+	if err := r.notifier.Notify(ctx, job.OrgID, event, data); err != nil {
+		logging.FromContext(ctx).Errorf("failed to notify webhooks about job %s, error is: %s", job.ID, err)
+	}
+}
+
+func (r *Runner) uploadPath(name string) string {
+	return filepath.Join(r.cfg.UploadDir, filepath.Base(name))
+}
+
+func writeFile(path string, content io.Reader) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create upload file, error is: %s", err)
+	}
+
+	if _, err := io.Copy(file, content); err != nil {
+		// nolint
+		file.Close()
+		// nolint
+		os.Remove(path)
+		return fmt.Errorf("failed to write upload file, error is: %s", err)
+	}
+
+	return file.Close()
+}
+
+// now returns current time in UTC with precision of stored timestamps.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
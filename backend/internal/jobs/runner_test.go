@@ -0,0 +1,312 @@
+package jobs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/config"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	auditrepo "github.com/hackfeed/remrratality/backend/internal/store/audit_repo"
+	datasetrepo "github.com/hackfeed/remrratality/backend/internal/store/dataset_repo"
+	jobrepo "github.com/hackfeed/remrratality/backend/internal/store/job_repo"
+	storagerepo "github.com/hackfeed/remrratality/backend/internal/store/storage_repo"
+	"github.com/hackfeed/remrratality/backend/internal/webhooks"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryJobRepo keeps jobs in memory, so tests can follow them through their states.
+type memoryJobRepo struct {
+	mu     sync.Mutex
+	jobs   []domain.Job
+	events []domain.JobEvent
+}
+
+func (m *memoryJobRepo) AddJob(_ context.Context, job domain.Job) (domain.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs = append(m.jobs, job)
+	return job, nil
+}
+
+func (m *memoryJobRepo) GetJob(_ context.Context, jobID string) (domain.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, job := range m.jobs {
+		if job.ID == jobID {
+			return job, nil
+		}
+	}
+	return domain.Job{}, jobrepo.ErrNotFound
+}
+
+func (m *memoryJobRepo) GetJobs(_ context.Context, node, state string) ([]domain.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jobs := make([]domain.Job, 0)
+	for _, job := range m.jobs {
+		if job.Node == node && job.State == state {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func (m *memoryJobRepo) ClaimJob(_ context.Context, node string) (domain.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, job := range m.jobs {
+		if job.Node == node && job.State == domain.JobQueued {
+			m.jobs[i].State = domain.JobRunning
+			return m.jobs[i], nil
+		}
+	}
+	return domain.Job{}, jobrepo.ErrNotFound
+}
+
+func (m *memoryJobRepo) UpdateJob(_ context.Context, job domain.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.jobs {
+		if m.jobs[i].ID == job.ID {
+			m.jobs[i] = job
+			return nil
+		}
+	}
+	return jobrepo.ErrNotFound
+}
+
+func (m *memoryJobRepo) AddJobEvent(_ context.Context, event domain.JobEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *memoryJobRepo) GetJobEvents(_ context.Context, jobID string, after int) ([]domain.JobEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	events := make([]domain.JobEvent, 0)
+	for _, event := range m.events {
+		if event.JobID == jobID && event.Seq > after {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (m *memoryJobRepo) DeleteJobs(_ context.Context, orgID string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jobs := make([]domain.Job, 0)
+	for _, job := range m.jobs {
+		if job.OrgID != orgID {
+			jobs = append(jobs, job)
+		}
+	}
+	deleted := int64(len(m.jobs) - len(jobs))
+	m.jobs = jobs
+	return deleted, nil
+}
+
+// recordingNotifier keeps events webhooks are notified about as event type and job ID.
+type recordingNotifier struct {
+	webhooks.NotifierMock
+	mu     sync.Mutex
+	events []string
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, _, event string, data interface{}) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.events = append(n.events, event+" "+data.(domain.DatasetEvent).JobID)
+	return nil
+}
+
+func (n *recordingNotifier) notified() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.events...)
+}
+
+// recordingAuditRepo keeps recorded audit events.
+type recordingAuditRepo struct {
+	auditrepo.AuditRepositoryMock
+	mu     sync.Mutex
+	events []domain.AuditEvent
+}
+
+func (a *recordingAuditRepo) AddEvent(_ context.Context, event domain.AuditEvent) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = append(a.events, event)
+	return nil
+}
+
+func (a *recordingAuditRepo) recorded() []domain.AuditEvent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]domain.AuditEvent(nil), a.events...)
+}
+
+// recordingTrigger keeps datasets alert rules are evaluated against.
+type recordingTrigger struct {
+	mu       sync.Mutex
+	datasets []string
+}
+
+func (tr *recordingTrigger) DatasetImported(_, datasetID string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.datasets = append(tr.datasets, datasetID)
+}
+
+func newTestRunner(t *testing.T, jobRepo jobrepo.JobRepository) *Runner {
+	runner, err := NewRunner(
+		config.Jobs{Workers: 2, UploadDir: t.TempDir(), Node: "node", PollInterval: time.Second},
+		jobRepo,
+		&datasetrepo.DatasetRepositoryMock{},
+		&storagerepo.StorageRepositoryMock{},
+		&recordingAuditRepo{},
+		&recordingNotifier{},
+		&recordingTrigger{},
+	)
+	assert.NoError(t, err)
+
+	return runner
+}
+
+func TestSubmitUpload(t *testing.T) {
+	runner := newTestRunner(t, &jobrepo.JobRepositoryMock{})
+
+	job, err := runner.SubmitUpload(context.Background(), domain.Job{OrgID: "org", DatasetID: "file.csv"}, strings.NewReader(header))
+	assert.NoError(t, err)
+	assert.NotEqual(t, "", job.ID)
+	assert.Equal(t, domain.JobUpload, job.Type)
+	assert.Equal(t, domain.JobQueued, job.State)
+	assert.Equal(t, "node", job.Node)
+
+	content, err := os.ReadFile(filepath.Join(runner.cfg.UploadDir, "file.csv"))
+	assert.NoError(t, err)
+	assert.Equal(t, header, string(content))
+
+	_, err = runner.SubmitUpload(context.Background(), domain.Job{OrgID: "errorAddJob", DatasetID: "other.csv"}, strings.NewReader(header))
+	assert.EqualError(t, err, "failed to queue job, error is: error while adding job")
+	_, err = os.Stat(filepath.Join(runner.cfg.UploadDir, "other.csv"))
+	assert.Equal(t, true, os.IsNotExist(err))
+}
+
+func TestRunnerProcessesJobs(t *testing.T) {
+	jobRepo := &memoryJobRepo{}
+	runner := newTestRunner(t, jobRepo)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runner.Start(ctx)
+
+	succeeded, err := runner.SubmitUpload(ctx, domain.Job{OrgID: "org", UserID: "user", DatasetID: "file.csv"}, strings.NewReader(
+		header+"1,01.10.2021,monthly,100,31.10.2021\n",
+	))
+	assert.NoError(t, err)
+	failed, err := runner.SubmitUpload(ctx, domain.Job{OrgID: "org", DatasetID: "invalid.csv"}, strings.NewReader(
+		header+"1,01.10.2021,monthly,-100,31.10.2021\n",
+	))
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		first, _ := jobRepo.GetJob(ctx, succeeded.ID)
+		second, _ := jobRepo.GetJob(ctx, failed.ID)
+		return first.Finished() && second.Finished()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.NoError(t, runner.Wait(context.Background()))
+
+	succeeded, _ = jobRepo.GetJob(ctx, succeeded.ID)
+	assert.Equal(t, domain.JobSucceeded, succeeded.State)
+	assert.Equal(t, 1, succeeded.RowsProcessed)
+	assert.NotNil(t, succeeded.FinishedAt)
+
+	events, _ := jobRepo.GetJobEvents(ctx, succeeded.ID, 0)
+	stages := make([]string, len(events))
+	for i, event := range events {
+		assert.Equal(t, i+1, event.Seq)
+		stages[i] = event.Type + " " + event.Stage
+	}
+	assert.Equal(t, []string{
+		"progress parsing",
+		"progress validating",
+		"progress inserting",
+		"progress inserting",
+		"succeeded inserting",
+	}, stages)
+	assert.Equal(t, 1, events[len(events)-1].RowsProcessed)
+	assert.Equal(t, len(events), succeeded.Events)
+
+	failed, _ = jobRepo.GetJob(ctx, failed.ID)
+	assert.Equal(t, domain.JobFailed, failed.State)
+	assert.Equal(t, []string{"Row 2: paid_amount -100 should not be negative"}, failed.Errors)
+
+	events, _ = jobRepo.GetJobEvents(ctx, failed.ID, 2)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, true, events[0].Final())
+	assert.Equal(t, failed.Errors, events[0].Errors)
+
+	assert.ElementsMatch(t, []string{
+		"dataset.imported " + succeeded.ID,
+		"dataset.import_failed " + failed.ID,
+	}, runner.notifier.(*recordingNotifier).notified())
+	assert.Equal(t, []string{"file.csv"}, runner.alerts.(*recordingTrigger).datasets)
+
+	imports := map[string]domain.AuditEvent{}
+	for _, event := range runner.auditRepo.(*recordingAuditRepo).recorded() {
+		imports[event.Details["job_id"].(string)] = event
+	}
+	assert.Equal(t, 2, len(imports))
+	assert.Equal(t, domain.AuditFileImport, imports[succeeded.ID].Action)
+	assert.Equal(t, "user", imports[succeeded.ID].UserID)
+	assert.Equal(t, true, imports[succeeded.ID].Success)
+	assert.Equal(t, 1, imports[succeeded.ID].Details["rows"])
+	assert.Equal(t, false, imports[failed.ID].Success)
+	assert.Equal(t, 0, imports[failed.ID].Details["rows"])
+
+	files, _ := os.ReadDir(runner.cfg.UploadDir)
+	assert.Equal(t, 0, len(files))
+}
+
+func TestRecover(t *testing.T) {
+	jobRepo := &memoryJobRepo{jobs: []domain.Job{
+		{ID: "resumed", OrgID: "org", Node: "node", State: domain.JobRunning, File: "resumed.csv", RowsTotal: 3, RowsProcessed: 1},
+		{ID: "lost", OrgID: "org", Node: "node", State: domain.JobRunning, File: "lost.csv"},
+		{ID: "other", OrgID: "org", Node: "other", State: domain.JobRunning, File: "other.csv"},
+	}}
+	runner := newTestRunner(t, jobRepo)
+	assert.NoError(t, os.WriteFile(filepath.Join(runner.cfg.UploadDir, "resumed.csv"), []byte(header), 0600))
+
+	assert.NoError(t, runner.recover(context.Background()))
+
+	resumed, _ := jobRepo.GetJob(context.Background(), "resumed")
+	assert.Equal(t, domain.JobQueued, resumed.State)
+	assert.Equal(t, 0, resumed.RowsTotal)
+	assert.Equal(t, 0, resumed.RowsProcessed)
+
+	lost, _ := jobRepo.GetJob(context.Background(), "lost")
+	assert.Equal(t, domain.JobFailed, lost.State)
+	assert.Equal(t, []string{"Uploaded file is lost on restart, please upload it again"}, lost.Errors)
+
+	events, _ := jobRepo.GetJobEvents(context.Background(), "lost", 0)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, domain.JobFailed, events[0].Type)
+
+	assert.Equal(t, []string{"dataset.import_failed lost"}, runner.notifier.(*recordingNotifier).notified())
+
+	other, _ := jobRepo.GetJob(context.Background(), "other")
+	assert.Equal(t, domain.JobRunning, other.State)
+
+	runner.cfg.Node = "errorGetJobs"
+	runner.jobRepo = &jobrepo.JobRepositoryMock{}
+	assert.EqualError(t, runner.recover(context.Background()), "error while getting jobs")
+}
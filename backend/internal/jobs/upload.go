@@ -0,0 +1,258 @@
+package jobs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/gocarina/gocsv"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
+	"github.com/hackfeed/remrratality/backend/internal/metrics"
+	storagerepo "github.com/hackfeed/remrratality/backend/internal/store/storage_repo"
+	"github.com/hackfeed/remrratality/backend/internal/utils/dataset_summary"
+)
+
+const (
+	// batchSize is number of invoices stored at once, job reports progress after every batch.
+	batchSize = 1000
+	// maxRowErrors limits number of rows described by job's errors and warnings.
+	maxRowErrors = 20
+)
+
+var uploadLayout = "02.01.2006"
+
+type Invoice struct {
+	CustomerID  uint32  `csv:"customer_id"`
+	PeriodStart string  `csv:"period_start"`
+	PaidPlan    string  `csv:"paid_plan"`
+	PaidAmount  float32 `csv:"paid_amount"`
+	PeriodEnd   string  `csv:"period_end"`
+}
+
+// processUpload parses and validates uploaded file and stores its invoices as a new dataset.
+// Messages for the user are added to job.Errors, returned error describes the failure in detail.
+// Nothing is stored if the file has invalid rows, failure while storing invoices or marking
+// dataset as ready removes the stored ones and marks dataset as failed.
+func (r *Runner) processUpload(ctx, stop context.Context, job *domain.Job) error {
+	r.progress(ctx, job, domain.StageParsing, "Parsing file")
+
+	file, err := os.Open(r.uploadPath(job.File))
+	if err != nil {
+		job.Errors = append(job.Errors, "Unable to read the file")
+		return fmt.Errorf("failed to open uploaded file, error is: %s", err)
+	}
+	defer file.Close()
+
+	contentHash, err := hashFile(file)
+	if err != nil {
+		job.Errors = append(job.Errors, "Unable to read the file")
+		return fmt.Errorf("failed to hash uploaded file, error is: %s", err)
+	}
+
+	var invoices []*Invoice
+
+	if err = gocsv.UnmarshalFile(file, &invoices); err != nil {
+		job.Errors = append(job.Errors, fmt.Sprintf("Failed to parse given CSV file: %s", err))
+		return fmt.Errorf("failed to unmarshal uploaded file, error is: %s", err)
+	}
+
+	job.RowsTotal = len(invoices)
+	r.progress(ctx, job, domain.StageValidating, fmt.Sprintf("%d rows are parsed", len(invoices)))
+
+	if rowErrors := validateInvoices(invoices); len(rowErrors) != 0 {
+		job.Errors = append(job.Errors, rowErrors...)
+		return fmt.Errorf("uploaded file has invalid rows")
+	}
+	for _, warning := range warnInvoices(invoices) {
+		job.Warnings = append(job.Warnings, warning)
+		r.emit(ctx, job, domain.JobEvent{Type: domain.JobEventWarning, Message: warning})
+	}
+
+	mappedInvoices := mapInvoices(job.OrgID, job.DatasetID, invoices)
+
+	dataset := domain.Dataset{
+		Name:             job.DatasetID,
+		OrgID:            job.OrgID,
+		DisplayName:      job.OriginalFilename,
+		OriginalFilename: job.OriginalFilename,
+		ContentHash:      contentHash,
+		Status:           domain.DatasetImporting,
+	}
+	dataset_summary.Summarize(&dataset, mappedInvoices, uploadLayout)
+
+	if _, err = r.datasetRepo.AddDataset(ctx, dataset); err != nil {
+		job.Errors = append(job.Errors, "Unable to save dataset")
+		return fmt.Errorf("failed to add dataset, error is: %s", err)
+	}
+
+	r.progress(ctx, job, domain.StageInserting, "Storing invoices")
+	for start := 0; start < len(mappedInvoices); start += batchSize {
+		if stop.Err() != nil {
+			return errInterrupted
+		}
+
+		end := start + batchSize
+		if end > len(mappedInvoices) {
+			end = len(mappedInvoices)
+		}
+		if err = uploadFileContent(ctx, r.storageRepo, mappedInvoices[start:end]); err != nil {
+			job.Errors = append(job.Errors, "Failed to upload data to database")
+			r.failDataset(ctx, *job)
+			return err
+		}
+
+		job.RowsProcessed = end
+		r.progress(ctx, job, domain.StageInserting, fmt.Sprintf("%d of %d rows are stored", end, len(mappedInvoices)))
+	}
+
+	if err = r.datasetRepo.UpdateDatasetStatus(ctx, job.OrgID, job.DatasetID, domain.DatasetReady); err != nil {
+		job.Errors = append(job.Errors, "Unable to update dataset status")
+		r.failDataset(ctx, *job)
+		return fmt.Errorf("failed to mark dataset as ready, error is: %s", err)
+	}
+	metrics.UploadRows.Observe(float64(len(mappedInvoices)))
+
+	return nil
+}
+
+// progress moves job to the stage, emits progress event and saves the job. Failure to save
+// progress doesn't fail the job, as it's only reported to the user.
+func (r *Runner) progress(ctx context.Context, job *domain.Job, stage, message string) {
+	job.Stage = stage
+	r.emit(ctx, job, domain.JobEvent{Type: domain.JobEventProgress, Message: message})
+
+	if err := r.jobRepo.UpdateJob(ctx, *job); err != nil {
+		logging.FromContext(ctx).Warnf("failed to save progress of job %s, error is: %s", job.ID, err)
+	}
+}
+
+// failDataset keeps dataset of the failed job, so user sees the upload has failed, but
+// removes invoices it has stored.
+func (r *Runner) failDataset(ctx context.Context, job domain.Job) {
+	logger := logging.FromContext(ctx)
+
+	if err := r.discardDataset(ctx, job); err != nil {
+		logger.Errorf("failed to remove invoices of job %s, error is: %s", job.ID, err)
+	}
+	if err := r.datasetRepo.UpdateDatasetStatus(ctx, job.OrgID, job.DatasetID, domain.DatasetFailed); err != nil {
+		logger.Errorf("failed to mark dataset %s as failed, error is: %s", job.DatasetID, err)
+	}
+}
+
+func (r *Runner) discardDataset(ctx context.Context, job domain.Job) error {
+	if err := r.storageRepo.DeleteInvoices(ctx, job.OrgID, job.DatasetID); err != nil {
+		return fmt.Errorf("failed to delete invoices of dataset %s, error is: %s", job.DatasetID, err)
+	}
+
+	return nil
+}
+
+// validateInvoices returns messages describing invalid rows, which prevent file from being imported.
+func validateInvoices(invoices []*Invoice) []string {
+	return checkInvoices(invoices, validateInvoice, "invalid")
+}
+
+// warnInvoices returns messages describing rows which are imported, but may be counted not
+// the way user expects.
+func warnInvoices(invoices []*Invoice) []string {
+	return checkInvoices(invoices, warnInvoice, "suspicious")
+}
+
+// checkInvoices describes rows check finds problem with, rows are numbered as lines of the file,
+// so header is the first one. Only first maxRowErrors rows are described.
+func checkInvoices(invoices []*Invoice, check func(*Invoice) string, kind string) []string {
+	messages := make([]string, 0)
+	found := 0
+
+	for i, invoice := range invoices {
+		problem := check(invoice)
+		if problem == "" {
+			continue
+		}
+		found++
+		if found <= maxRowErrors {
+			messages = append(messages, fmt.Sprintf("Row %d: %s", i+2, problem))
+		}
+	}
+
+	if found > maxRowErrors {
+		messages = append(messages, fmt.Sprintf("%d more rows are %s", found-maxRowErrors, kind))
+	}
+
+	return messages
+}
+
+func validateInvoice(invoice *Invoice) string {
+	periodStart, err := time.Parse(uploadLayout, invoice.PeriodStart)
+	if err != nil {
+		return fmt.Sprintf("period_start %q should be a date formatted as DD.MM.YYYY", invoice.PeriodStart)
+	}
+	periodEnd, err := time.Parse(uploadLayout, invoice.PeriodEnd)
+	if err != nil {
+		return fmt.Sprintf("period_end %q should be a date formatted as DD.MM.YYYY", invoice.PeriodEnd)
+	}
+	if periodEnd.Before(periodStart) {
+		return fmt.Sprintf("period_end %s should not be before period_start %s", invoice.PeriodEnd, invoice.PeriodStart)
+	}
+	if invoice.PaidAmount < 0 {
+		return fmt.Sprintf("paid_amount %v should not be negative", invoice.PaidAmount)
+	}
+
+	return ""
+}
+
+func warnInvoice(invoice *Invoice) string {
+	if invoice.PaidPlan != "monthly" && invoice.PaidPlan != "annually" {
+		return fmt.Sprintf("paid_plan %q is neither monthly nor annually, it's counted as monthly", invoice.PaidPlan)
+	}
+	if invoice.PaidAmount == 0 {
+		return "paid_amount is 0, the row doesn't add revenue"
+	}
+
+	return ""
+}
+
+func mapInvoices(orgID, fileID string, invoices []*Invoice) []domain.Invoice {
+	mappedInvoices := make([]domain.Invoice, len(invoices))
+
+	for i, invoice := range invoices {
+		mappedInvoice := domain.Invoice{
+			UserID:      orgID,
+			FileID:      fileID,
+			CustomerID:  invoice.CustomerID,
+			PeriodStart: invoice.PeriodStart,
+			PaidPlan:    invoice.PaidPlan,
+			PaidAmount:  invoice.PaidAmount,
+			PeriodEnd:   invoice.PeriodEnd,
+		}
+		mappedInvoices[i] = mappedInvoice
+	}
+
+	return mappedInvoices
+}
+
+func uploadFileContent(ctx context.Context, storageRepo storagerepo.StorageRepository, invoices []domain.Invoice) error {
+	if _, err := storageRepo.AddInvoices(ctx, invoices); err != nil {
+		return fmt.Errorf("failed upload invoices to db, error is: %s", err)
+	}
+
+	return nil
+}
+
+// hashFile returns hex encoded SHA-256 of the file content and rewinds the file.
+func hashFile(file io.ReadSeeker) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to read file, error is: %s", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to rewind file, error is: %s", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,275 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	jobrepo "github.com/hackfeed/remrratality/backend/internal/store/job_repo"
+	storagerepo "github.com/hackfeed/remrratality/backend/internal/store/storage_repo"
+	"github.com/stretchr/testify/assert"
+)
+
+const header = "customer_id,period_start,paid_plan,paid_amount,period_end\n"
+
+func TestProcessUpload(t *testing.T) {
+	type testInput struct {
+		orgID   string
+		content string
+	}
+	type testWant struct {
+		err           error
+		errors        []string
+		warnings      []string
+		rowsTotal     int
+		rowsProcessed int
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{
+				orgID:   "org",
+				content: header + "1,01.10.2021,monthly,100,31.10.2021\n2,01.10.2021,annual,1200,30.09.2022\n",
+			},
+			want: testWant{
+				err:           nil,
+				errors:        []string{},
+				warnings:      []string{"Row 3: paid_plan \"annual\" is neither monthly nor annually, it's counted as monthly"},
+				rowsTotal:     2,
+				rowsProcessed: 2,
+			},
+		},
+		{
+			input: testInput{
+				orgID:   "org",
+				content: "customer_id\nabc\n",
+			},
+			want: testWant{
+				err:    errors.New("failed to unmarshal uploaded file, error is: "),
+				errors: []string{"Failed to parse given CSV file: "},
+			},
+		},
+		{
+			input: testInput{
+				orgID:   "org",
+				content: header + "1,2021-10-01,monthly,100,31.10.2021\n",
+			},
+			want: testWant{
+				err:       errors.New("uploaded file has invalid rows"),
+				errors:    []string{"Row 2: period_start \"2021-10-01\" should be a date formatted as DD.MM.YYYY"},
+				rowsTotal: 1,
+			},
+		},
+		{
+			input: testInput{
+				orgID:   "errorAddDataset",
+				content: header + "1,01.10.2021,monthly,100,31.10.2021\n",
+			},
+			want: testWant{
+				err:       errors.New("failed to add dataset, error is: error while adding dataset"),
+				errors:    []string{"Unable to save dataset"},
+				rowsTotal: 1,
+			},
+		},
+		{
+			input: testInput{
+				orgID:   "org",
+				content: header,
+			},
+			want: testWant{
+				err:    nil,
+				errors: []string{},
+			},
+		},
+		{
+			input: testInput{
+				orgID:   "errorUpdateDatasetStatus",
+				content: header + "1,01.10.2021,monthly,100,31.10.2021\n",
+			},
+			want: testWant{
+				err:           errors.New("failed to mark dataset as ready, error is: error while updating dataset status"),
+				errors:        []string{"Unable to update dataset status"},
+				rowsTotal:     1,
+				rowsProcessed: 1,
+			},
+		},
+	}
+
+	runner := newTestRunner(t, &jobrepo.JobRepositoryMock{})
+
+	for i, test := range tests {
+		name := fmt.Sprintf("%d.csv", i)
+		assert.NoError(t, os.WriteFile(filepath.Join(runner.cfg.UploadDir, name), []byte(test.input.content), 0600))
+		job := domain.Job{ID: "job", OrgID: test.input.orgID, DatasetID: name, File: name, Errors: make([]string, 0)}
+
+		err := runner.processUpload(context.Background(), context.Background(), &job)
+		if test.want.err == nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Error(t, err)
+			assert.Equal(t, true, strings.HasPrefix(err.Error(), test.want.err.Error()))
+		}
+		assert.Equal(t, len(test.want.errors), len(job.Errors))
+		for j := range test.want.errors {
+			assert.Equal(t, true, strings.HasPrefix(job.Errors[j], test.want.errors[j]))
+		}
+		assert.Equal(t, len(test.want.warnings), len(job.Warnings))
+		for j := range test.want.warnings {
+			assert.Equal(t, test.want.warnings[j], job.Warnings[j])
+		}
+		assert.Equal(t, test.want.rowsTotal, job.RowsTotal)
+		assert.Equal(t, test.want.rowsProcessed, job.RowsProcessed)
+	}
+}
+
+func TestProcessUploadInterrupted(t *testing.T) {
+	runner := newTestRunner(t, &jobrepo.JobRepositoryMock{})
+	assert.NoError(t, os.WriteFile(
+		filepath.Join(runner.cfg.UploadDir, "file.csv"),
+		[]byte(header+"1,01.10.2021,monthly,100,31.10.2021\n"),
+		0600,
+	))
+
+	stop, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	job := domain.Job{ID: "job", OrgID: "org", DatasetID: "file.csv", File: "file.csv"}
+	err := runner.processUpload(context.Background(), stop, &job)
+	assert.Equal(t, errInterrupted, err)
+	assert.Equal(t, 0, job.RowsProcessed)
+}
+
+func TestValidateInvoices(t *testing.T) {
+	type testInput struct {
+		invoices []*Invoice
+	}
+	type testWant struct {
+		errors []string
+	}
+
+	valid := &Invoice{CustomerID: 1, PeriodStart: "01.10.2021", PaidPlan: "monthly", PaidAmount: 100, PeriodEnd: "31.10.2021"}
+	tooMany := make([]*Invoice, 0, maxRowErrors+2)
+	for i := 0; i < maxRowErrors+2; i++ {
+		tooMany = append(tooMany, &Invoice{PeriodStart: "01.10.2021", PaidAmount: -1, PeriodEnd: "31.10.2021"})
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{invoices: []*Invoice{valid}},
+			want:  testWant{errors: []string{}},
+		},
+		{
+			input: testInput{invoices: []*Invoice{
+				valid,
+				{PeriodStart: "01.10.2021", PeriodEnd: "31/10/2021"},
+				{PeriodStart: "01.10.2021", PeriodEnd: "30.09.2021"},
+				{PeriodStart: "01.10.2021", PaidAmount: -1, PeriodEnd: "31.10.2021"},
+			}},
+			want: testWant{errors: []string{
+				"Row 3: period_end \"31/10/2021\" should be a date formatted as DD.MM.YYYY",
+				"Row 4: period_end 30.09.2021 should not be before period_start 01.10.2021",
+				"Row 5: paid_amount -1 should not be negative",
+			}},
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want.errors, validateInvoices(test.input.invoices))
+	}
+
+	rowErrors := validateInvoices(tooMany)
+	assert.Equal(t, maxRowErrors+1, len(rowErrors))
+	assert.Equal(t, "2 more rows are invalid", rowErrors[maxRowErrors])
+}
+
+func TestWarnInvoices(t *testing.T) {
+	invoices := []*Invoice{
+		{PeriodStart: "01.10.2021", PaidPlan: "monthly", PaidAmount: 100, PeriodEnd: "31.10.2021"},
+		{PeriodStart: "01.10.2021", PaidPlan: "weekly", PaidAmount: 100, PeriodEnd: "31.10.2021"},
+		{PeriodStart: "01.10.2021", PaidPlan: "annually", PaidAmount: 0, PeriodEnd: "30.09.2022"},
+	}
+
+	assert.Equal(t, []string{
+		"Row 3: paid_plan \"weekly\" is neither monthly nor annually, it's counted as monthly",
+		"Row 4: paid_amount is 0, the row doesn't add revenue",
+	}, warnInvoices(invoices))
+}
+
+func TestMapInvoices(t *testing.T) {
+	invoices := []*Invoice{
+		{CustomerID: 1, PeriodStart: "01.10.2021", PaidPlan: "monthly", PaidAmount: 100, PeriodEnd: "31.10.2021"},
+	}
+
+	assert.Equal(t, []domain.Invoice{
+		{
+			UserID:      "org",
+			FileID:      "file.csv",
+			CustomerID:  1,
+			PeriodStart: "01.10.2021",
+			PaidPlan:    "monthly",
+			PaidAmount:  100,
+			PeriodEnd:   "31.10.2021",
+		},
+	}, mapInvoices("org", "file.csv", invoices))
+}
+
+func TestUploadFileContent(t *testing.T) {
+	type testInput struct {
+		invoices []domain.Invoice
+	}
+	type testWant struct {
+		err error
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{
+				invoices: make([]domain.Invoice, 0),
+			},
+			want: testWant{
+				err: errors.New("failed upload invoices to db, error is: error while adding invoices"),
+			},
+		},
+		{
+			input: testInput{
+				invoices: []domain.Invoice{{}},
+			},
+			want: testWant{
+				err: nil,
+			},
+		},
+	}
+
+	storageMock := &storagerepo.StorageRepositoryMock{}
+
+	for _, test := range tests {
+		err := uploadFileContent(context.Background(), storageMock, test.input.invoices)
+		assert.Equal(t, test.want.err, err)
+	}
+}
+
+func TestHashFile(t *testing.T) {
+	file := strings.NewReader("customer_id,period_start,paid_plan,paid_amount,period_end\n")
+
+	hash, err := hashFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t, 64, len(hash))
+
+	rest, _ := io.ReadAll(file)
+	assert.Equal(t, "customer_id,period_start,paid_plan,paid_amount,period_end\n", string(rest))
+}
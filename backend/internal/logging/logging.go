@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hackfeed/remrratality/backend/internal/config"
+	"github.com/hackfeed/remrratality/backend/internal/tracing"
+	log "github.com/sirupsen/logrus"
+)
+
+type requestIDKey struct{}
+
+// Setup configures the standard logger: logs are written as JSON to stdout, or to file if
+// it's configured, with secrets redacted.
+func Setup(cfg config.Log) error {
+	level, err := log.ParseLevel(cfg.Level)
+	if err != nil {
+		return fmt.Errorf("failed to parse log level, error is: %s", err)
+	}
+
+	var out io.Writer = os.Stdout
+	if cfg.File != "" {
+		file, err := os.OpenFile(cfg.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+		if err != nil {
+			return fmt.Errorf("failed to open log file, error is: %s", err)
+		}
+		out = file
+	}
+
+	configure(log.StandardLogger(), out, level, cfg.Format)
+
+	return nil
+}
+
+func configure(logger *log.Logger, out io.Writer, level log.Level, format string) {
+	logger.SetOutput(out)
+	logger.SetLevel(level)
+	if format == "text" {
+		logger.SetFormatter(&log.TextFormatter{DisableColors: true, FullTimestamp: true})
+	} else {
+		logger.SetFormatter(&log.JSONFormatter{})
+	}
+	logger.ReplaceHooks(make(log.LevelHooks))
+	logger.AddHook(RedactHook{})
+}
+
+// WithRequestID returns context carrying request ID, loggers created from it add the ID to every entry.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns request ID ctx carries, it's empty outside of requests.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// FromContext returns logger which adds request ID and trace ID from ctx to entries.
+func FromContext(ctx context.Context) *log.Entry {
+	entry := log.NewEntry(log.StandardLogger())
+	if requestID := RequestID(ctx); requestID != "" {
+		entry = entry.WithField("request_id", requestID)
+	}
+	if traceID := tracing.TraceID(ctx); traceID != "" {
+		entry = entry.WithField("trace_id", traceID)
+	}
+
+	return entry
+}
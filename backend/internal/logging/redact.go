@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	secretFields = map[string]struct{}{
+		"token":         {},
+		"refresh_token": {},
+		"password":      {},
+		"secret":        {},
+		"authorization": {},
+	}
+
+	secretPatterns = []struct {
+		pattern     *regexp.Regexp
+		replacement string
+	}{
+		// JSON Web Tokens
+		{regexp.MustCompile(`eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), redacted},
+		// key=value and key: value pairs
+		{regexp.MustCompile(`(?i)\b((?:password|passwd|pwd|secret|token)\s*[=:]\s*)[^\s,;&]+`), "${1}" + redacted},
+		// credentials in connection strings
+		{regexp.MustCompile(`://([^:/@\s]+):[^@\s]+@`), "://${1}:" + redacted + "@"},
+	}
+)
+
+// RedactHook replaces secrets in messages and fields of log entries, so they can't leak
+// through error messages.
+type RedactHook struct{}
+
+func (RedactHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (RedactHook) Fire(entry *log.Entry) error {
+	entry.Message = Redact(entry.Message)
+
+	for key, value := range entry.Data {
+		if _, ok := secretFields[strings.ToLower(key)]; ok {
+			entry.Data[key] = redacted
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			entry.Data[key] = Redact(v)
+		case error:
+			entry.Data[key] = Redact(v.Error())
+		}
+	}
+
+	return nil
+}
+
+// Redact replaces tokens, passwords and connection string credentials in s.
+func Redact(s string) string {
+	for _, secret := range secretPatterns {
+		s = secret.pattern.ReplaceAllString(s, secret.replacement)
+	}
+
+	return s
+}
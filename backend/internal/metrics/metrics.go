@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "remrratality"
+
+var (
+	registry = prometheus.NewRegistry()
+
+	// RequestDuration is latency of HTTP requests by method, route pattern and status code.
+	RequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// CacheRequests counts MRR cache lookups by result, which is one of hit, miss or error.
+	CacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "requests_total",
+		Help:      "MRR cache lookups by result.",
+	}, []string{"result"})
+
+	// UploadRows is number of invoices in successfully imported files.
+	UploadRows = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "upload",
+		Name:      "rows",
+		Help:      "Number of invoices in imported files.",
+		Buckets:   prometheus.ExponentialBuckets(10, 10, 6),
+	})
+
+	// AnalyticsDuration is time spent computing MRR analytics missing in cache.
+	AnalyticsDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "analytics",
+		Name:      "duration_seconds",
+		Help:      "Time spent computing MRR analytics missing in cache.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 12),
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		RequestDuration,
+		CacheRequests,
+		UploadRows,
+		AnalyticsDuration,
+	)
+}
+
+// Register adds collectors to the registry served by Handler.
+func Register(cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := registry.Register(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Handler serves all registered metrics in Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
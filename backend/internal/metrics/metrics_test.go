@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegister(t *testing.T) {
+	counter := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "test_total",
+		Help:      "Test counter.",
+	})
+
+	assert.NoError(t, Register(counter))
+	assert.Error(t, Register(counter))
+	assert.Equal(t, true, registry.Unregister(counter))
+}
+
+func TestHandler(t *testing.T) {
+	UploadRows.Observe(42)
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, true, strings.Contains(w.Body.String(), "remrratality_upload_rows_count"))
+	assert.Equal(t, true, strings.Contains(w.Body.String(), "go_goroutines"))
+}
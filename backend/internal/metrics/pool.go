@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolCollector exposes pgxpool statistics, they are read from the pool on every scrape.
+type PoolCollector struct {
+	pool *pgxpool.Pool
+
+	acquireCount         *prometheus.Desc
+	acquireDuration      *prometheus.Desc
+	canceledAcquireCount *prometheus.Desc
+	emptyAcquireCount    *prometheus.Desc
+	acquiredConns        *prometheus.Desc
+	constructingConns    *prometheus.Desc
+	idleConns            *prometheus.Desc
+	totalConns           *prometheus.Desc
+	maxConns             *prometheus.Desc
+}
+
+func NewPoolCollector(pool *pgxpool.Pool) *PoolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "postgres_pool", name), help, nil, nil)
+	}
+
+	return &PoolCollector{
+		pool:                 pool,
+		acquireCount:         desc("acquires_total", "Number of successful connection acquires from the pool."),
+		acquireDuration:      desc("acquire_duration_seconds_total", "Total time spent on successful connection acquires."),
+		canceledAcquireCount: desc("canceled_acquires_total", "Number of connection acquires canceled by context."),
+		emptyAcquireCount:    desc("empty_acquires_total", "Number of successful acquires that waited for a connection."),
+		acquiredConns:        desc("acquired_connections", "Number of connections currently in use."),
+		constructingConns:    desc("constructing_connections", "Number of connections being established."),
+		idleConns:            desc("idle_connections", "Number of idle connections."),
+		totalConns:           desc("connections", "Number of connections in the pool."),
+		maxConns:             desc("max_connections", "Maximum size of the pool."),
+	}
+}
+
+func (pc *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- pc.acquireCount
+	ch <- pc.acquireDuration
+	ch <- pc.canceledAcquireCount
+	ch <- pc.emptyAcquireCount
+	ch <- pc.acquiredConns
+	ch <- pc.constructingConns
+	ch <- pc.idleConns
+	ch <- pc.totalConns
+	ch <- pc.maxConns
+}
+
+func (pc *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := pc.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(pc.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(pc.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(pc.canceledAcquireCount, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(pc.emptyAcquireCount, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(pc.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(pc.constructingConns, prometheus.GaugeValue, float64(stat.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(pc.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(pc.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(pc.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+}
@@ -0,0 +1,255 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
+	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	alertrepo "github.com/hackfeed/remrratality/backend/internal/store/alert_repo"
+	cacherepo "github.com/hackfeed/remrratality/backend/internal/store/cache_repo"
+	datasetrepo "github.com/hackfeed/remrratality/backend/internal/store/dataset_repo"
+	jobrepo "github.com/hackfeed/remrratality/backend/internal/store/job_repo"
+	orgrepo "github.com/hackfeed/remrratality/backend/internal/store/org_repo"
+	storagerepo "github.com/hackfeed/remrratality/backend/internal/store/storage_repo"
+	userrepo "github.com/hackfeed/remrratality/backend/internal/store/user_repo"
+	webhookrepo "github.com/hackfeed/remrratality/backend/internal/store/webhook_repo"
+	"github.com/hackfeed/remrratality/backend/internal/utils/user_validation"
+)
+
+// DeleteAccount godoc
+// @Summary Deleting user's account
+// @Description Deleting user, organizations he solely owns with their invoices, cached analytics, jobs, webhooks and alerts after password re-confirmation
+// @Tags account
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} models.ResponseSuccessDeleteAccount
+// @Failure 400 {object} models.Response
+// @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 500 {object} models.Response
+// @Security ApiKeyAuth
+// @Param request body models.PasswordConfirmation true "User's current password"
+// @Router /account [delete]
+func DeleteAccount(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	email, ok := c.MustGet("email").(string)
+	if !ok {
+		logger.Errorf("failed to get email from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Unable to determine logged in user",
+		})
+		return
+	}
+	userID, ok := c.MustGet("user_id").(string)
+	if !ok {
+		logger.Errorf("failed to get user_id from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Unable to determine logged in user",
+		})
+		return
+	}
+	userRepo, ok := c.MustGet("user_repo").(userrepo.UserRepository)
+	if !ok {
+		logger.Errorf("failed to get user_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get user_repo",
+		})
+		return
+	}
+	orgRepo, ok := c.MustGet("org_repo").(orgrepo.OrgRepository)
+	if !ok {
+		logger.Errorf("failed to get org_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get org_repo",
+		})
+		return
+	}
+	datasetRepo, ok := c.MustGet("dataset_repo").(datasetrepo.DatasetRepository)
+	if !ok {
+		logger.Errorf("failed to get dataset_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get dataset_repo",
+		})
+		return
+	}
+	storageRepo, ok := c.MustGet("storage_repo").(storagerepo.StorageRepository)
+	if !ok {
+		logger.Errorf("failed to get storage_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get storage_repo",
+		})
+		return
+	}
+	cacheRepo, ok := c.MustGet("cache_repo").(cacherepo.CacheRepository)
+	if !ok {
+		logger.Errorf("failed to get cache_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get cache_repo",
+		})
+		return
+	}
+	jobRepo, ok := c.MustGet("job_repo").(jobrepo.JobRepository)
+	if !ok {
+		logger.Errorf("failed to get job_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get job_repo",
+		})
+		return
+	}
+	webhookRepo, ok := c.MustGet("webhook_repo").(webhookrepo.WebhookRepository)
+	if !ok {
+		logger.Errorf("failed to get webhook_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get webhook_repo",
+		})
+		return
+	}
+	alertRepo, ok := c.MustGet("alert_repo").(alertrepo.AlertRepository)
+	if !ok {
+		logger.Errorf("failed to get alert_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get alert_repo",
+		})
+		return
+	}
+
+	var req models.PasswordConfirmation
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Errorf("failed to parse request body, error is: %s", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Message: "Failed to parse request body",
+		})
+		return
+	}
+
+	user, err := userRepo.GetUser(c.Request.Context(), email)
+	if err != nil {
+		logger.Errorf("failed to get user with email %s, error is: %s", email, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to fetch user",
+		})
+		return
+	}
+
+	if err = user_validation.VerifyPassword(user.Password, req.Password); err != nil {
+		logger.Errorf("failed to verify password for user %s, error is: %s", userID, err)
+		c.AbortWithStatusJSON(http.StatusForbidden, models.Response{
+			Message: "Password is incorrect",
+		})
+		return
+	}
+
+	deleted, err := deleteAccount(c.Request.Context(), userRepo, orgRepo, datasetRepo, storageRepo, cacheRepo, jobRepo, webhookRepo, alertRepo, user)
+	if err != nil {
+		logger.Errorf("failed to delete account for user %s, error is: %s", userID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to delete account. Please, try again later",
+		})
+		return
+	}
+
+	logger.Infof("account of user %s is deleted with %d organizations, %d files, %d invoices, %d cache keys, %d jobs, %d webhooks and %d alert rules",
+		userID, deleted.Organizations, deleted.Files, deleted.Invoices, deleted.CacheKeys, deleted.Jobs, deleted.Webhooks, deleted.AlertRules)
+
+	c.JSON(http.StatusOK, models.ResponseSuccessDeleteAccount{
+		Message:       "Account is deleted",
+		Organizations: deleted.Organizations,
+		Files:         deleted.Files,
+		Invoices:      deleted.Invoices,
+		CacheKeys:     deleted.CacheKeys,
+		Jobs:          deleted.Jobs,
+		Webhooks:      deleted.Webhooks,
+		AlertRules:    deleted.AlertRules,
+	})
+}
+
+// deleteAccount removes personal organization of the user and organizations they are the only owner of
+// together with their invoices, cache, jobs, webhooks and alert rules, and leaves the rest of
+// organizations. Files are counted by
+// deleted datasets, as files embedded into user and organizations are migrated to them. User document
+// which is needed to retry the request is removed last.
+func deleteAccount(ctx context.Context, userRepo userrepo.UserRepository, orgRepo orgrepo.OrgRepository, datasetRepo datasetrepo.DatasetRepository, storageRepo storagerepo.StorageRepository, cacheRepo cacherepo.CacheRepository, jobRepo jobrepo.JobRepository, webhookRepo webhookrepo.WebhookRepository, alertRepo alertrepo.AlertRepository, user domain.User) (domain.DeletedAccount, error) {
+	deleted := domain.DeletedAccount{}
+
+	orgs, err := orgRepo.GetOrganizations(ctx, user.UserID)
+	if err != nil {
+		return domain.DeletedAccount{}, fmt.Errorf("failed to get organizations, error is: %s", err)
+	}
+
+	ownerIDs := []string{user.UserID}
+	orgIDs := make([]string, 0)
+
+	for _, org := range orgs {
+		if org.OrgID == user.UserID {
+			orgIDs = append(orgIDs, org.OrgID)
+			continue
+		}
+		if isSoleOwner(org, user.UserID) {
+			ownerIDs = append(ownerIDs, org.OrgID)
+			orgIDs = append(orgIDs, org.OrgID)
+			continue
+		}
+
+		org.Members = removeMember(org.Members, user.UserID)
+		if err = orgRepo.UpdateOrganization(ctx, org.OrgID, org); err != nil {
+			return domain.DeletedAccount{}, fmt.Errorf("failed to leave organization, error is: %s", err)
+		}
+	}
+
+	for _, ownerID := range ownerIDs {
+		invoices, err := storageRepo.DeleteUserInvoices(ctx, ownerID)
+		if err != nil {
+			return domain.DeletedAccount{}, fmt.Errorf("failed to delete invoices from db, error is: %s", err)
+		}
+		deleted.Invoices += invoices
+
+		cacheKeys, err := cacheRepo.DeleteMRR(ctx, ownerID)
+		if err != nil {
+			return domain.DeletedAccount{}, fmt.Errorf("failed to delete mrr from cache, error is: %s", err)
+		}
+		deleted.CacheKeys += cacheKeys
+	}
+
+	for _, orgID := range orgIDs {
+		jobs, err := jobRepo.DeleteJobs(ctx, orgID)
+		if err != nil {
+			return domain.DeletedAccount{}, fmt.Errorf("failed to delete jobs, error is: %s", err)
+		}
+		deleted.Jobs += jobs
+
+		webhooks, err := webhookRepo.DeleteWebhooks(ctx, orgID)
+		if err != nil {
+			return domain.DeletedAccount{}, fmt.Errorf("failed to delete webhooks, error is: %s", err)
+		}
+		deleted.Webhooks += webhooks
+
+		alertRules, err := alertRepo.DeleteRules(ctx, orgID)
+		if err != nil {
+			return domain.DeletedAccount{}, fmt.Errorf("failed to delete alert rules, error is: %s", err)
+		}
+		deleted.AlertRules += alertRules
+
+		files, err := datasetRepo.DeleteDatasets(ctx, orgID)
+		if err != nil {
+			return domain.DeletedAccount{}, fmt.Errorf("failed to delete datasets, error is: %s", err)
+		}
+		deleted.Files += int(files)
+
+		if err = orgRepo.DeleteOrganization(ctx, orgID); err != nil {
+			return domain.DeletedAccount{}, fmt.Errorf("failed to delete organization, error is: %s", err)
+		}
+		deleted.Organizations++
+	}
+
+	if err = userRepo.DeleteUser(ctx, user.UserID); err != nil {
+		return domain.DeletedAccount{}, fmt.Errorf("failed to delete user, error is: %s", err)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,432 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	alertrepo "github.com/hackfeed/remrratality/backend/internal/store/alert_repo"
+	cacherepo "github.com/hackfeed/remrratality/backend/internal/store/cache_repo"
+	datasetrepo "github.com/hackfeed/remrratality/backend/internal/store/dataset_repo"
+	jobrepo "github.com/hackfeed/remrratality/backend/internal/store/job_repo"
+	orgrepo "github.com/hackfeed/remrratality/backend/internal/store/org_repo"
+	storagerepo "github.com/hackfeed/remrratality/backend/internal/store/storage_repo"
+	userrepo "github.com/hackfeed/remrratality/backend/internal/store/user_repo"
+	webhookrepo "github.com/hackfeed/remrratality/backend/internal/store/webhook_repo"
+	internalTesting "github.com/hackfeed/remrratality/backend/internal/utils/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteAccountHandler(t *testing.T) {
+	type testInput struct {
+		keys map[string]interface{}
+		body interface{}
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"email": 1,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine logged in user",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"email":   "test@test.com",
+				"user_id": 1,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine logged in user",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"email":     "test@test.com",
+				"user_id":   "id",
+				"user_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get user_repo",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"email":     "test@test.com",
+				"user_id":   "id",
+				"user_repo": &userrepo.UserRepositoryMock{},
+				"org_repo":  "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get org_repo",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"email":        "test@test.com",
+				"user_id":      "id",
+				"user_repo":    &userrepo.UserRepositoryMock{},
+				"org_repo":     &orgrepo.OrgRepositoryMock{},
+				"dataset_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get dataset_repo",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"email":        "test@test.com",
+				"user_id":      "id",
+				"user_repo":    &userrepo.UserRepositoryMock{},
+				"org_repo":     &orgrepo.OrgRepositoryMock{},
+				"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+				"storage_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get storage_repo",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"email":        "test@test.com",
+				"user_id":      "id",
+				"user_repo":    &userrepo.UserRepositoryMock{},
+				"org_repo":     &orgrepo.OrgRepositoryMock{},
+				"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+				"storage_repo": &storagerepo.StorageRepositoryMock{},
+				"cache_repo":   "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get cache_repo",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"email":        "test@test.com",
+				"user_id":      "id",
+				"user_repo":    &userrepo.UserRepositoryMock{},
+				"org_repo":     &orgrepo.OrgRepositoryMock{},
+				"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+				"storage_repo": &storagerepo.StorageRepositoryMock{},
+				"cache_repo":   &cacherepo.CacheRepositoryMock{},
+				"job_repo":     "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get job_repo",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"email":        "test@test.com",
+				"user_id":      "id",
+				"user_repo":    &userrepo.UserRepositoryMock{},
+				"org_repo":     &orgrepo.OrgRepositoryMock{},
+				"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+				"storage_repo": &storagerepo.StorageRepositoryMock{},
+				"cache_repo":   &cacherepo.CacheRepositoryMock{},
+				"job_repo":     &jobrepo.JobRepositoryMock{},
+				"webhook_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get webhook_repo",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"email":        "test@test.com",
+				"user_id":      "id",
+				"user_repo":    &userrepo.UserRepositoryMock{},
+				"org_repo":     &orgrepo.OrgRepositoryMock{},
+				"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+				"storage_repo": &storagerepo.StorageRepositoryMock{},
+				"cache_repo":   &cacherepo.CacheRepositoryMock{},
+				"job_repo":     &jobrepo.JobRepositoryMock{},
+				"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+				"alert_repo":   "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get alert_repo",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"email":        "test@test.com",
+				"user_id":      "id",
+				"user_repo":    &userrepo.UserRepositoryMock{},
+				"org_repo":     &orgrepo.OrgRepositoryMock{},
+				"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+				"storage_repo": &storagerepo.StorageRepositoryMock{},
+				"cache_repo":   &cacherepo.CacheRepositoryMock{},
+				"job_repo":     &jobrepo.JobRepositoryMock{},
+				"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+				"alert_repo":   &alertrepo.AlertRepositoryMock{},
+			}},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Failed to parse request body",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"email":        "errorGetUser",
+					"user_id":      "id",
+					"user_repo":    &userrepo.UserRepositoryMock{},
+					"org_repo":     &orgrepo.OrgRepositoryMock{},
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+					"storage_repo": &storagerepo.StorageRepositoryMock{},
+					"cache_repo":   &cacherepo.CacheRepositoryMock{},
+					"job_repo":     &jobrepo.JobRepositoryMock{},
+					"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+					"alert_repo":   &alertrepo.AlertRepositoryMock{},
+				},
+				body: models.PasswordConfirmation{
+					Password: "somePass",
+				},
+			},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to fetch user",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"email":        "test@test.com",
+					"user_id":      "id",
+					"user_repo":    &userrepo.UserRepositoryMock{},
+					"org_repo":     &orgrepo.OrgRepositoryMock{},
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+					"storage_repo": &storagerepo.StorageRepositoryMock{},
+					"cache_repo":   &cacherepo.CacheRepositoryMock{},
+					"job_repo":     &jobrepo.JobRepositoryMock{},
+					"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+					"alert_repo":   &alertrepo.AlertRepositoryMock{},
+				},
+				body: models.PasswordConfirmation{
+					Password: "fakePass",
+				},
+			},
+			want: testWant{
+				code:    http.StatusForbidden,
+				message: "Password is incorrect",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"email":        "test@test.com",
+					"user_id":      "id",
+					"user_repo":    &userrepo.UserRepositoryMock{},
+					"org_repo":     &orgrepo.OrgRepositoryMock{},
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+					"storage_repo": &storagerepo.StorageRepositoryMock{},
+					"cache_repo":   &cacherepo.CacheRepositoryMock{},
+					"job_repo":     &jobrepo.JobRepositoryMock{},
+					"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+					"alert_repo":   &alertrepo.AlertRepositoryMock{},
+				},
+				body: models.PasswordConfirmation{
+					Password: "somePass",
+				},
+			},
+			want: testWant{
+				code:    http.StatusOK,
+				message: "{\"message\":\"Account is deleted\",\"organizations\":1,\"files\":2,\"invoices\":3,\"cache_keys\":2,\"jobs\":3,\"webhooks\":1,\"alert_rules\":2}",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(test.input.keys, test.input.body, nil)
+		DeleteAccount(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message))
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	type testInput struct {
+		user domain.User
+	}
+	type testWant struct {
+		deleted domain.DeletedAccount
+		err     error
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{
+				user: domain.User{UserID: "errorGetOrganizations"},
+			},
+			want: testWant{
+				deleted: domain.DeletedAccount{},
+				err:     errors.New("failed to get organizations, error is: error while getting organizations"),
+			},
+		},
+		{
+			input: testInput{
+				user: domain.User{UserID: "errorDeleteUserInvoices"},
+			},
+			want: testWant{
+				deleted: domain.DeletedAccount{},
+				err:     errors.New("failed to delete invoices from db, error is: error while deleting user invoices"),
+			},
+		},
+		{
+			input: testInput{
+				user: domain.User{UserID: "errorDeleteMRR"},
+			},
+			want: testWant{
+				deleted: domain.DeletedAccount{},
+				err:     errors.New("failed to delete mrr from cache, error is: error while deleting mrr from cache"),
+			},
+		},
+		{
+			input: testInput{
+				user: domain.User{UserID: "errorDeleteDatasets"},
+			},
+			want: testWant{
+				deleted: domain.DeletedAccount{},
+				err:     errors.New("failed to delete datasets, error is: error while deleting datasets"),
+			},
+		},
+		{
+			input: testInput{
+				user: domain.User{UserID: "errorDeleteOrganization"},
+			},
+			want: testWant{
+				deleted: domain.DeletedAccount{},
+				err:     errors.New("failed to delete organization, error is: error while deleting organization"),
+			},
+		},
+		{
+			input: testInput{
+				user: domain.User{UserID: "errorDeleteJobs"},
+			},
+			want: testWant{
+				deleted: domain.DeletedAccount{},
+				err:     errors.New("failed to delete jobs, error is: error while deleting jobs"),
+			},
+		},
+		{
+			input: testInput{
+				user: domain.User{UserID: "errorDeleteWebhooks"},
+			},
+			want: testWant{
+				deleted: domain.DeletedAccount{},
+				err:     errors.New("failed to delete webhooks, error is: error while deleting webhooks"),
+			},
+		},
+		{
+			input: testInput{
+				user: domain.User{UserID: "errorDeleteRules"},
+			},
+			want: testWant{
+				deleted: domain.DeletedAccount{},
+				err:     errors.New("failed to delete alert rules, error is: error while deleting alert rules"),
+			},
+		},
+		{
+			input: testInput{
+				user: domain.User{UserID: "errorDeleteUser"},
+			},
+			want: testWant{
+				deleted: domain.DeletedAccount{},
+				err:     errors.New("failed to delete user, error is: error while deleting user"),
+			},
+		},
+		{
+			input: testInput{
+				user: domain.User{UserID: "soleOwner"},
+			},
+			want: testWant{
+				deleted: domain.DeletedAccount{
+					Organizations: 1,
+					Files:         2,
+					Invoices:      6,
+					CacheKeys:     4,
+					Jobs:          3,
+					Webhooks:      1,
+					AlertRules:    2,
+				},
+				err: nil,
+			},
+		},
+		{
+			input: testInput{
+				user: domain.User{UserID: "user", Files: make([]domain.File, 2)},
+			},
+			want: testWant{
+				deleted: domain.DeletedAccount{
+					Organizations: 1,
+					Files:         2,
+					Invoices:      3,
+					CacheKeys:     2,
+					Jobs:          3,
+					Webhooks:      1,
+					AlertRules:    2,
+				},
+				err: nil,
+			},
+		},
+		{
+			input: testInput{
+				// File is migrated to dataset, but clearing it from the user has failed.
+				user: domain.User{UserID: "user", Files: []domain.File{{Name: "file.csv"}}},
+			},
+			want: testWant{
+				deleted: domain.DeletedAccount{
+					Organizations: 1,
+					Files:         2,
+					Invoices:      3,
+					CacheKeys:     2,
+					Jobs:          3,
+					Webhooks:      1,
+					AlertRules:    2,
+				},
+				err: nil,
+			},
+		},
+	}
+
+	userMock := &userrepo.UserRepositoryMock{}
+	orgMock := &orgrepo.OrgRepositoryMock{}
+	datasetMock := &datasetrepo.DatasetRepositoryMock{}
+	storageMock := &storagerepo.StorageRepositoryMock{}
+	cacheMock := &cacherepo.CacheRepositoryMock{}
+	jobMock := &jobrepo.JobRepositoryMock{}
+	webhookMock := &webhookrepo.WebhookRepositoryMock{}
+	alertMock := &alertrepo.AlertRepositoryMock{}
+
+	for _, test := range tests {
+		deleted, err := deleteAccount(context.Background(), userMock, orgMock, datasetMock, storageMock, cacheMock, jobMock, webhookMock, alertMock, test.input.user)
+		assert.Equal(t, test.want.deleted, deleted)
+		assert.Equal(t, test.want.err, err)
+	}
+}
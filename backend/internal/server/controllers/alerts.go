@@ -0,0 +1,304 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/alerts"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
+	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	alertrepo "github.com/hackfeed/remrratality/backend/internal/store/alert_repo"
+	datasetrepo "github.com/hackfeed/remrratality/backend/internal/store/dataset_repo"
+)
+
+// LoadAlertRules godoc
+// @Summary Loading organization's alert rules
+// @Description Loading alert rules of organization in order they were created
+// @Tags alerts
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} models.ResponseSuccessAlertRules
+// @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 404 {object} models.Response
+// @Failure 500 {object} models.Response
+// @Security ApiKeyAuth
+// @Param org header string false "Organization ID, personal organization is used if omitted"
+// @Router /alerts/rules [get]
+func LoadAlertRules(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	orgID, ok := c.MustGet("org_id").(string)
+	if !ok {
+		logger.Errorf("failed to get org_id from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Unable to determine organization",
+		})
+		return
+	}
+	alertRepo, ok := c.MustGet("alert_repo").(alertrepo.AlertRepository)
+	if !ok {
+		logger.Errorf("failed to get alert_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get alert_repo",
+		})
+		return
+	}
+
+	rules, err := alertRepo.GetRules(c.Request.Context(), orgID)
+	if err != nil {
+		logger.Errorf("failed to load alert rules for org_id %s, error is: %s", orgID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to fetch alert rules",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseSuccessAlertRules{
+		Message: "Alert rules are loaded",
+		Rules:   rules,
+	})
+}
+
+// CreateAlertRule godoc
+// @Summary Creating alert rule
+// @Description Creating rule which triggers alert when metric of the last complete month of a dataset is above or below threshold.
+// @Description Rules are evaluated after every import and once a month, each rule triggers once per month of a dataset.
+// @Description Metric is an MRR component, net_new, growth_rate or churn_rate, the rates are percents of the previous month's total.
+// @Description Contraction and churn are compared by their magnitude. With of_previous_total threshold is a percent of the previous month's total.
+// @Description Rule watches the given dataset or the latest imported one. Alerts are sent through webhook channel to webhooks subscribed to alert.triggered event,
+// @Description through email channel to recipients, and log channel writes them to the service log.
+// @Tags alerts
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} models.ResponseSuccessAlertRule
+// @Failure 400 {object} models.Response
+// @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 404 {object} models.Response
+// @Failure 500 {object} models.Response
+// @Security ApiKeyAuth
+// @Param request body models.AlertRule true "Alert rule"
+// @Param org header string false "Organization ID, personal organization is used if omitted"
+// @Router /alerts/rules [post]
+func CreateAlertRule(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	orgID, ok := c.MustGet("org_id").(string)
+	if !ok {
+		logger.Errorf("failed to get org_id from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Unable to determine organization",
+		})
+		return
+	}
+	alertRepo, ok := c.MustGet("alert_repo").(alertrepo.AlertRepository)
+	if !ok {
+		logger.Errorf("failed to get alert_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get alert_repo",
+		})
+		return
+	}
+	datasetRepo, ok := c.MustGet("dataset_repo").(datasetrepo.DatasetRepository)
+	if !ok {
+		logger.Errorf("failed to get dataset_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get dataset_repo",
+		})
+		return
+	}
+
+	var req models.AlertRule
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Errorf("failed to parse request body, error is: %s", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Message: "Failed to parse request body",
+		})
+		return
+	}
+	channels := uniqueStrings(req.Channels)
+	recipients := uniqueStrings(req.Recipients)
+	if req.OfPreviousTotal && alerts.IsRate(req.Metric) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Message: "Rate metrics are percents of the previous month's total already, of_previous_total can't be set for them",
+		})
+		return
+	}
+	if len(recipients) == 0 && hasChannel(channels, domain.AlertChannelEmail) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Message: "Recipients are required by email channel",
+		})
+		return
+	}
+	c.Set("audit_details", map[string]interface{}{"name": req.Name, "metric": req.Metric, "channels": channels})
+
+	if req.DatasetID != "" {
+		_, err := datasetRepo.GetDataset(c.Request.Context(), orgID, req.DatasetID)
+		if errors.Is(err, datasetrepo.ErrNotFound) {
+			logger.Infof("file %s of org_id %s doesn't exist", req.DatasetID, orgID)
+			c.AbortWithStatusJSON(http.StatusNotFound, models.Response{
+				Message: "File not found",
+			})
+			return
+		}
+		if err != nil {
+			logger.Errorf("failed to get file %s for org_id %s, error is: %s", req.DatasetID, orgID, err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+				Message: "Failed to get file",
+			})
+			return
+		}
+	}
+
+	rule, err := alertRepo.AddRule(c.Request.Context(), domain.AlertRule{
+		OrgID:           orgID,
+		Name:            req.Name,
+		DatasetID:       req.DatasetID,
+		Metric:          req.Metric,
+		Condition:       req.Condition,
+		Threshold:       *req.Threshold,
+		OfPreviousTotal: req.OfPreviousTotal,
+		Channels:        channels,
+		Recipients:      recipients,
+	})
+	if err != nil {
+		logger.Errorf("failed to add alert rule for org_id %s, error is: %s", orgID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to create alert rule",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseSuccessAlertRule{
+		Message: "Alert rule is created",
+		Rule:    rule,
+	})
+}
+
+// DeleteAlertRule godoc
+// @Summary Deleting alert rule
+// @Description Deleting alert rule with alerts it has triggered
+// @Tags alerts
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} models.Response
+// @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 404 {object} models.Response
+// @Failure 500 {object} models.Response
+// @Security ApiKeyAuth
+// @Param id path string true "Alert rule ID"
+// @Param org header string false "Organization ID, personal organization is used if omitted"
+// @Router /alerts/rules/{id} [delete]
+func DeleteAlertRule(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	orgID, ok := c.MustGet("org_id").(string)
+	if !ok {
+		logger.Errorf("failed to get org_id from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Unable to determine organization",
+		})
+		return
+	}
+	alertRepo, ok := c.MustGet("alert_repo").(alertrepo.AlertRepository)
+	if !ok {
+		logger.Errorf("failed to get alert_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get alert_repo",
+		})
+		return
+	}
+
+	ruleID := c.Param("id")
+	c.Set("audit_details", map[string]interface{}{"rule_id": ruleID})
+
+	rule, err := alertRepo.GetRule(c.Request.Context(), ruleID)
+	if err == nil && rule.OrgID != orgID {
+		err = alertrepo.ErrNotFound
+	}
+	if err == nil {
+		err = alertRepo.DeleteRule(c.Request.Context(), ruleID)
+	}
+	if errors.Is(err, alertrepo.ErrNotFound) {
+		logger.Infof("alert rule %s of org_id %s doesn't exist", ruleID, orgID)
+		c.AbortWithStatusJSON(http.StatusNotFound, models.Response{
+			Message: "Alert rule not found",
+		})
+		return
+	}
+	if err != nil {
+		logger.Errorf("failed to delete alert rule %s for org_id %s, error is: %s", ruleID, orgID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to delete alert rule",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Message: "Alert rule is deleted",
+	})
+}
+
+// LoadAlerts godoc
+// @Summary Loading triggered alerts
+// @Description Loading the latest alerts triggered by rules of organization, newest first
+// @Tags alerts
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} models.ResponseSuccessAlerts
+// @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 404 {object} models.Response
+// @Failure 500 {object} models.Response
+// @Security ApiKeyAuth
+// @Param rule_id query string false "Alert rule ID, alerts of every rule are loaded if omitted"
+// @Param org header string false "Organization ID, personal organization is used if omitted"
+// @Router /alerts [get]
+func LoadAlerts(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	orgID, ok := c.MustGet("org_id").(string)
+	if !ok {
+		logger.Errorf("failed to get org_id from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Unable to determine organization",
+		})
+		return
+	}
+	alertRepo, ok := c.MustGet("alert_repo").(alertrepo.AlertRepository)
+	if !ok {
+		logger.Errorf("failed to get alert_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get alert_repo",
+		})
+		return
+	}
+
+	triggered, err := alertRepo.GetAlerts(c.Request.Context(), orgID, c.Query("rule_id"))
+	if err != nil {
+		logger.Errorf("failed to load alerts for org_id %s, error is: %s", orgID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to fetch alerts",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseSuccessAlerts{
+		Message: "Alerts are loaded",
+		Alerts:  triggered,
+	})
+}
+
+func hasChannel(channels []string, channel string) bool {
+	for _, c := range channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,372 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	alertrepo "github.com/hackfeed/remrratality/backend/internal/store/alert_repo"
+	datasetrepo "github.com/hackfeed/remrratality/backend/internal/store/dataset_repo"
+	internalTesting "github.com/hackfeed/remrratality/backend/internal/utils/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadAlertRulesHandler(t *testing.T) {
+	type testInput struct {
+		keys map[string]interface{}
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id": 1,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine organization",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":     "org",
+				"alert_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get alert_repo",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":     "errorGetRules",
+				"alert_repo": &alertrepo.AlertRepositoryMock{},
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to fetch alert rules",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":     "org",
+				"alert_repo": &alertrepo.AlertRepositoryMock{},
+			}},
+			want: testWant{
+				code:    http.StatusOK,
+				message: "Alert rules are loaded",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(test.input.keys, nil, nil)
+		LoadAlertRules(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message))
+	}
+}
+
+func TestCreateAlertRuleHandler(t *testing.T) {
+	type testInput struct {
+		keys map[string]interface{}
+		body interface{}
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	threshold := 5.0
+	repos := func(orgID string) map[string]interface{} {
+		return map[string]interface{}{
+			"org_id":       orgID,
+			"alert_repo":   &alertrepo.AlertRepositoryMock{},
+			"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+		}
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id": 1,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine organization",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":     "org",
+				"alert_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get alert_repo",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":       "org",
+				"alert_repo":   &alertrepo.AlertRepositoryMock{},
+				"dataset_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get dataset_repo",
+			},
+		},
+		{
+			input: testInput{
+				keys: repos("org"),
+				body: models.AlertRule{Name: "Churn spike", Metric: "arr", Condition: "above", Threshold: &threshold, Channels: []string{"log"}},
+			},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Failed to parse request body",
+			},
+		},
+		{
+			input: testInput{
+				keys: repos("org"),
+				body: models.AlertRule{Name: "Churn spike", Metric: "churn", Condition: "above", Channels: []string{"log"}},
+			},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Failed to parse request body",
+			},
+		},
+		{
+			input: testInput{
+				keys: repos("org"),
+				body: models.AlertRule{Name: "Churn spike", Metric: "churn_rate", Condition: "above", Threshold: &threshold, OfPreviousTotal: true, Channels: []string{"log"}},
+			},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "of_previous_total can't be set for them",
+			},
+		},
+		{
+			input: testInput{
+				keys: repos("org"),
+				body: models.AlertRule{Name: "Churn spike", Metric: "churn", Condition: "above", Threshold: &threshold, Channels: []string{"email"}},
+			},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Recipients are required by email channel",
+			},
+		},
+		{
+			input: testInput{
+				keys: repos("org"),
+				body: models.AlertRule{Name: "Churn spike", DatasetID: "notFoundDataset", Metric: "churn", Condition: "above", Threshold: &threshold, Channels: []string{"log"}},
+			},
+			want: testWant{
+				code:    http.StatusNotFound,
+				message: "File not found",
+			},
+		},
+		{
+			input: testInput{
+				keys: repos("org"),
+				body: models.AlertRule{Name: "Churn spike", DatasetID: "errorGetDataset", Metric: "churn", Condition: "above", Threshold: &threshold, Channels: []string{"log"}},
+			},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get file",
+			},
+		},
+		{
+			input: testInput{
+				keys: repos("errorAddRule"),
+				body: models.AlertRule{Name: "Churn spike", Metric: "churn", Condition: "above", Threshold: &threshold, Channels: []string{"log"}},
+			},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to create alert rule",
+			},
+		},
+		{
+			input: testInput{
+				keys: repos("org"),
+				body: models.AlertRule{
+					Name:            "Churn spike",
+					DatasetID:       "file.csv",
+					Metric:          "churn",
+					Condition:       "above",
+					Threshold:       &threshold,
+					OfPreviousTotal: true,
+					Channels:        []string{"email", "log", "email"},
+					Recipients:      []string{"owner@example.com"},
+				},
+			},
+			want: testWant{
+				code:    http.StatusOK,
+				message: `"channels":["email","log"]`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(test.input.keys, test.input.body, nil)
+		CreateAlertRule(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message))
+	}
+}
+
+func TestDeleteAlertRuleHandler(t *testing.T) {
+	type testInput struct {
+		keys   map[string]interface{}
+		params []gin.Param
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id": 1,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine organization",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":     "org",
+				"alert_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get alert_repo",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":     "org",
+					"alert_repo": &alertrepo.AlertRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "otherOrgRule"}},
+			},
+			want: testWant{
+				code:    http.StatusNotFound,
+				message: "Alert rule not found",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":     "org",
+					"alert_repo": &alertrepo.AlertRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "errorGetRule"}},
+			},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to delete alert rule",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":     "org",
+					"alert_repo": &alertrepo.AlertRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "rule"}},
+			},
+			want: testWant{
+				code:    http.StatusOK,
+				message: "Alert rule is deleted",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(test.input.keys, nil, test.input.params)
+		DeleteAlertRule(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message))
+	}
+}
+
+func TestLoadAlertsHandler(t *testing.T) {
+	type testInput struct {
+		keys map[string]interface{}
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id": 1,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine organization",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":     "org",
+				"alert_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get alert_repo",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":     "errorGetAlerts",
+				"alert_repo": &alertrepo.AlertRepositoryMock{},
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to fetch alerts",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":     "org",
+				"alert_repo": &alertrepo.AlertRepositoryMock{},
+			}},
+			want: testWant{
+				code:    http.StatusOK,
+				message: "Alerts are loaded",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(test.input.keys, nil, nil)
+		LoadAlerts(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message))
+	}
+}
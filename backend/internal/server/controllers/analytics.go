@@ -1,48 +1,123 @@
 package controllers
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/calendar"
 	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
+	"github.com/hackfeed/remrratality/backend/internal/metrics"
 	"github.com/hackfeed/remrratality/backend/internal/server/models"
 	cacherepo "github.com/hackfeed/remrratality/backend/internal/store/cache_repo"
+	datasetrepo "github.com/hackfeed/remrratality/backend/internal/store/dataset_repo"
 	storagerepo "github.com/hackfeed/remrratality/backend/internal/store/storage_repo"
-	log "github.com/sirupsen/logrus"
+	"github.com/hackfeed/remrratality/backend/internal/tracing"
+	analyticsexport "github.com/hackfeed/remrratality/backend/internal/utils/analytics_export"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
 	layout = "2006-01-02"
+
+	errNoData = errors.New("no data found for given period")
 )
 
+// eventStreamFormat streams progress of analytics computation as Server-Sent Events, the last
+// event carries analytics or describes the failure.
+const eventStreamFormat = "events"
+
+type progressKey struct{}
+
+// analyticsFormat describes tabular representation MRR analytics can be exported in.
+type analyticsFormat struct {
+	extension   string
+	contentType string
+	write       func(io.Writer, []analyticsexport.Row) error
+}
+
+var analyticsFormats = map[string]analyticsFormat{
+	"csv": {
+		extension:   "csv",
+		contentType: "text/csv",
+		write:       analyticsexport.WriteCSV,
+	},
+	"xlsx": {
+		extension:   "xlsx",
+		contentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		write:       analyticsexport.WriteXLSX,
+	},
+	"ndjson": {
+		extension:   "ndjson",
+		contentType: "application/x-ndjson",
+		write:       analyticsexport.WriteNDJSON,
+	},
+}
+
 // CreateAnalytics godoc
 // @Summary Create and return MRR analytics data
-// @Description Creating MRR analytics data with all components for given period and returning it
+// @Description Creating MRR analytics data with all components for given period and returning it.
+// @Description Analytics is returned as table with one row per month when format is csv, xlsx or ndjson,
+// @Description format may be requested with format parameter or Accept header.
+// @Description With events format progress events are streamed as Server-Sent Events while analytics is computed,
+// @Description stream ends with result event carrying analytics or failed event.
+// @Description Analytics is bucketed by reporting periods of configured calendar, calendar months by default. Months are labels of
+// @Description periods, "M.YYYY" for calendar months and like "P1.FY2022" for fiscal periods, periods are the same as ISO intervals of dates.
+// @Description With metrics parameter json and events responses include ARR, ARPA, customer churn, LTV and quick ratio of every month.
+// @Description With compare set json and events responses include analytics of the previous period of the same length, the same period
+// @Description a year before or a custom period, aligned by month relative to period start, with absolute and percentage deltas and CMGR.
 // @Tags analytics
 // @Accept  json
 // @Produce  json
+// @Produce  text/csv
+// @Produce  application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce  application/x-ndjson
+// @Produce  text/event-stream
 // @Success 200 {object} models.ResponseSuccessAnalytics
 // @Failure 400 {object} models.Response
 // @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 404 {object} models.Response
+// @Failure 409 {object} models.Response
+// @Failure 429 {object} models.Response
+// @Header 429 {integer} Retry-After "Seconds to wait before the next request"
 // @Failure 500 {object} models.Response
 // @Security ApiKeyAuth
-// @Param request body models.Period true "Parameters for MRR analytics"
+// @Param request body models.Analytics true "Parameters for MRR analytics"
+// @Param format query string false "Response format, takes precedence over Accept header" Enums(json, csv, xlsx, ndjson, events)
+// @Param metrics query bool false "Include metrics block"
+// @Param org header string false "Organization ID, personal organization is used if omitted"
 // @Router /analytics/mrr [post]
 func CreateAnalytics(c *gin.Context) {
-	userID, ok := c.MustGet("user_id").(string)
+	logger := logging.FromContext(c.Request.Context())
+
+	orgID, ok := c.MustGet("org_id").(string)
 	if !ok {
-		log.Errorf("failed to get user_id from gin.Context")
+		logger.Errorf("failed to get org_id from gin.Context")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
-			Message: "Unable to determine logged in user",
+			Message: "Unable to determine organization",
+		})
+		return
+	}
+	datasetRepo, ok := c.MustGet("dataset_repo").(datasetrepo.DatasetRepository)
+	if !ok {
+		logger.Errorf("failed to get dataset_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get dataset_repo",
 		})
 		return
 	}
 	storageRepo, ok := c.MustGet("storage_repo").(storagerepo.StorageRepository)
 	if !ok {
-		log.Errorf("failed to get storage_repo from gin.Context")
+		logger.Errorf("failed to get storage_repo from gin.Context")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
 			Message: "Failed to get storage_repo",
 		})
@@ -50,79 +125,335 @@ func CreateAnalytics(c *gin.Context) {
 	}
 	cacheRepo, ok := c.MustGet("cache_repo").(cacherepo.CacheRepository)
 	if !ok {
-		log.Errorf("failed to get cache_repo from gin.Context")
+		logger.Errorf("failed to get cache_repo from gin.Context")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
 			Message: "Failed to get cache_repo",
 		})
 		return
 	}
 
-	var req models.Period
+	format, err := negotiateAnalyticsFormat(c)
+	if err != nil {
+		logger.Errorf("failed to determine analytics format, error is: %s", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Message: "Unknown analytics format, supported formats are json, csv, xlsx, ndjson and events",
+		})
+		return
+	}
+
+	withMetrics := false
+	if value := c.Query("metrics"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			logger.Errorf("failed to parse metrics query parameter, error is: %s", err)
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+				Message: "Failed to parse query parameters",
+			})
+			return
+		}
+		withMetrics = parsed
+	}
+
+	var req models.Analytics
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Errorf("failed to parse request body, error is: %s", err)
+		logger.Errorf("failed to parse request body, error is: %s", err)
 		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
 			Message: "Failed to parse request body",
 		})
 		return
 	}
 
-	months, mrr, err := createAnalytics(storageRepo, cacheRepo, userID, req.Filename, req.PeriodStart, req.PeriodEnd)
+	c.Set("audit_details", map[string]interface{}{
+		"filename":     req.Filename,
+		"period_start": req.PeriodStart,
+		"period_end":   req.PeriodEnd,
+		"format":       format,
+		"metrics":      withMetrics,
+		"compare":      req.Compare,
+	})
+
+	if _, ok := readyDataset(c, datasetRepo, orgID, req.Filename); !ok {
+		return
+	}
+
+	if format == eventStreamFormat {
+		streamAnalytics(c, storageRepo, cacheRepo, orgID, req, withMetrics)
+		return
+	}
+
+	periods, mrr, err := createAnalytics(c.Request.Context(), storageRepo, cacheRepo, orgID, req.Filename, req.PeriodStart, req.PeriodEnd)
 	if err != nil {
-		log.Errorf("failed to get MRR analytics, error is: %s", err)
+		logger.Errorf("failed to get MRR analytics, error is: %s", err)
 		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
 			Message: "Failed to get analytics. Please ensure that period start is earlier than period end and data exists in given period",
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, models.ResponseSuccessAnalytics{
-		Message: "Analytics is loaded",
-		Months:  months,
-		MRR:     mrr,
+	exportFormat, ok := analyticsFormats[format]
+	if !ok {
+		mrrMetrics, comparison, err := createAnalyticsDetails(c.Request.Context(), storageRepo, cacheRepo, orgID, req, periods, mrr, withMetrics)
+		if err != nil {
+			logger.Errorf("failed to get MRR analytics details, error is: %s", err)
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+				Message: "Failed to get analytics. Please ensure that period start is earlier than period end and data exists in given period",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.ResponseSuccessAnalytics{
+			Message:    "Analytics is loaded",
+			Months:     calendar.Labels(periods),
+			Periods:    calendar.ISOLabels(periods),
+			MRR:        mrr,
+			Metrics:    mrrMetrics,
+			Comparison: comparison,
+		})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := exportFormat.write(&buf, analyticsexport.Rows(calendar.Labels(periods), mrr)); err != nil {
+		logger.Errorf("failed to export MRR analytics as %s, error is: %s", format, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to export analytics",
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("mrr_%s_%s.%s", req.PeriodStart, req.PeriodEnd, exportFormat.extension)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Data(http.StatusOK, exportFormat.contentType, buf.Bytes())
+}
+
+// negotiateAnalyticsFormat returns format requested with format query parameter, or the one
+// matching Accept header. JSON is used when neither of them asks for a tabular format.
+func negotiateAnalyticsFormat(c *gin.Context) (string, error) {
+	if format, ok := c.GetQuery("format"); ok {
+		if _, known := analyticsFormats[format]; !known && format != "json" && format != eventStreamFormat {
+			return "", fmt.Errorf("unknown format %s", format)
+		}
+		return format, nil
+	}
+
+	offered := []string{gin.MIMEJSON}
+	formats := map[string]string{gin.MIMEJSON: "json"}
+	for _, name := range []string{"csv", "xlsx", "ndjson"} {
+		offered = append(offered, analyticsFormats[name].contentType)
+		formats[analyticsFormats[name].contentType] = name
+	}
+	offered = append(offered, "text/event-stream")
+	formats["text/event-stream"] = eventStreamFormat
+
+	if format, ok := formats[c.NegotiateFormat(offered...)]; ok {
+		return format, nil
+	}
+
+	return "json", nil
+}
+
+// streamAnalytics computes analytics reporting stages of computation as progress events.
+func streamAnalytics(c *gin.Context, storageRepo storagerepo.StorageRepository, cacheRepo cacherepo.CacheRepository, orgID string, req models.Analytics, withMetrics bool) {
+	logger := logging.FromContext(c.Request.Context())
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	type result struct {
+		periods    []calendar.Period
+		mrr        domain.TotalMRR
+		metrics    *domain.MRRMetrics
+		comparison *domain.MRRComparison
+		err        error
+	}
+
+	progress := make(chan models.AnalyticsProgress)
+	done := make(chan result, 1)
+	go func() {
+		// Progress is sent unbuffered, so it's always written before the result.
+		progressCtx := withProgress(ctx, func(event models.AnalyticsProgress) {
+			select {
+			case progress <- event:
+			case <-ctx.Done():
+			}
+		})
+		periods, mrr, err := createAnalytics(progressCtx, storageRepo, cacheRepo, orgID, req.Filename, req.PeriodStart, req.PeriodEnd)
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		mrrMetrics, comparison, err := createAnalyticsDetails(progressCtx, storageRepo, cacheRepo, orgID, req, periods, mrr, withMetrics)
+		done <- result{periods: periods, mrr: mrr, metrics: mrrMetrics, comparison: comparison, err: err}
+	}()
+
+	startEventStream(c)
+
+	// Computation is bounded, so stream is drained on shutdown as other requests are, but it's
+	// ended with failed event if computation is about to outlast write timeout.
+	deadline, stop := streamDeadline(c.Request.Context())
+	defer stop()
+
+	for {
+		select {
+		case event := <-progress:
+			writeEvent(c, "", "progress", event)
+		case res := <-done:
+			if res.err != nil {
+				logger.Errorf("failed to get MRR analytics, error is: %s", res.err)
+				writeEvent(c, "", "failed", models.Response{
+					Message: "Failed to get analytics. Please ensure that period start is earlier than period end and data exists in given period",
+				})
+				return
+			}
+			writeEvent(c, "", "result", models.ResponseSuccessAnalytics{
+				Message:    "Analytics is loaded",
+				Months:     calendar.Labels(res.periods),
+				Periods:    calendar.ISOLabels(res.periods),
+				MRR:        res.mrr,
+				Metrics:    res.metrics,
+				Comparison: res.comparison,
+			})
+			return
+		case <-deadline:
+			logger.Errorf("failed to get MRR analytics before write timeout")
+			writeEvent(c, "", "failed", models.Response{
+				Message: "Analytics is taking too long to compute, please try again later",
+			})
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// withProgress returns context analytics pipeline reports its stages to with report.
+func withProgress(ctx context.Context, report func(models.AnalyticsProgress)) context.Context {
+	return context.WithValue(ctx, progressKey{}, report)
+}
+
+// reportProgress reports stage of analytics computation, if ctx has a receiver of reports.
+func reportProgress(ctx context.Context, stage, format string, args ...interface{}) {
+	report, ok := ctx.Value(progressKey{}).(func(models.AnalyticsProgress))
+	if !ok {
+		return
+	}
+
+	report(models.AnalyticsProgress{
+		Stage:   stage,
+		Message: fmt.Sprintf(format, args...),
 	})
 }
 
-func createAnalytics(storageRepo storagerepo.StorageRepository, cacheRepo cacherepo.CacheRepository, userID, fileID, periodStart, periodEnd string) ([]string, domain.TotalMRR, error) {
+// createAnalyticsDetails computes metrics of analytics if they're asked for and compares it with
+// analytics of another period if req sets one.
+func createAnalyticsDetails(ctx context.Context, storageRepo storagerepo.StorageRepository, cacheRepo cacherepo.CacheRepository, orgID string, req models.Analytics, periods []calendar.Period, mrr domain.TotalMRR, withMetrics bool) (*domain.MRRMetrics, *domain.MRRComparison, error) {
 	var (
-		mrr    domain.TotalMRR
-		months []string
+		mrrMetrics *domain.MRRMetrics
+		comparison *domain.MRRComparison
 	)
 
-	periodStartDate, err := time.Parse(layout, periodStart)
-	if err != nil {
-		return months, mrr, fmt.Errorf("failed parse period start date, error is: %s", err)
+	if withMetrics {
+		calculated, err := createMetrics(ctx, storageRepo, orgID, req.Filename, periods, mrr)
+		if err != nil {
+			return nil, nil, err
+		}
+		mrrMetrics = &calculated
 	}
-	periodEndDate, err := time.Parse(layout, periodEnd)
-	if err != nil {
-		return months, mrr, fmt.Errorf("failed parse period end date, error is: %s", err)
+	if req.Compare != nil {
+		compared, err := createComparison(ctx, storageRepo, cacheRepo, orgID, req, mrr)
+		if err != nil {
+			return nil, nil, err
+		}
+		comparison = &compared
 	}
 
-	if periodStartDate.After(periodEndDate) {
-		return months, mrr, errors.New("period start should be less than period end")
+	return mrrMetrics, comparison, nil
+}
+
+// MRRAnalyzer returns function computing MRR analytics of a dataset for a period the same way
+// analytics endpoint does, it's used where analytics is needed outside of requests. Analytics is
+// bucketed by the calendar ctx carries and labeled with labels of its periods.
+func MRRAnalyzer(storageRepo storagerepo.StorageRepository, cacheRepo cacherepo.CacheRepository) func(ctx context.Context, orgID, fileID, periodStart, periodEnd string) ([]string, domain.TotalMRR, error) {
+	return func(ctx context.Context, orgID, fileID, periodStart, periodEnd string) ([]string, domain.TotalMRR, error) {
+		periods, mrr, err := createAnalytics(ctx, storageRepo, cacheRepo, orgID, fileID, periodStart, periodEnd)
+		return calendar.Labels(periods), mrr, err
 	}
+}
 
-	userFilePeriod := fmt.Sprintf("%s.%s-%s-%s", userID, fileID, periodStart, periodEnd)
-	mrr, err = cacheRepo.GetMRR(userFilePeriod)
+// createAnalytics computes MRR analytics of reporting periods of the calendar ctx carries from
+// the one containing periodStart to the one containing periodEnd.
+func createAnalytics(ctx context.Context, storageRepo storagerepo.StorageRepository, cacheRepo cacherepo.CacheRepository, orgID, fileID, periodStart, periodEnd string) ([]calendar.Period, domain.TotalMRR, error) {
+	var mrr domain.TotalMRR
+
+	periods, err := analyzedPeriods(ctx, periodStart, periodEnd)
 	if err != nil {
-		return months, mrr, fmt.Errorf("failed to get mrr from cache, error is: %s", err)
+		return nil, mrr, err
+	}
+
+	orgFilePeriod := fmt.Sprintf("%s.%s-%s-%s", orgID, fileID, periodStart, periodEnd)
+	if key := calendar.FromContext(ctx).Key(); key != "" {
+		orgFilePeriod = fmt.Sprintf("%s-%s", orgFilePeriod, key)
+	}
+	// Analytics is computed without cache while it's unavailable.
+	mrr, err = cacheRepo.GetMRR(ctx, orgFilePeriod)
+	if err != nil {
+		logging.FromContext(ctx).Warnf("failed to get mrr from cache, error is: %s", err)
 	}
 
-	months = getMonthsBetween(periodEndDate, periodStartDate)
 	if len(mrr.Total) != 0 {
-		return months, mrr, nil
+		reportProgress(ctx, "cache", "Analytics is loaded from cache")
+		return periods, mrr, nil
 	}
 
-	formedMPP, err := formMPP(storageRepo, months, userID, fileID, periodStartDate, periodEndDate)
+	start := time.Now()
+	formedMPP, err := formMPP(ctx, storageRepo, periods, orgID, fileID)
 	if err != nil {
-		return months, mrr, fmt.Errorf("failed to form mpp, error is: %s", err)
+		return periods, mrr, fmt.Errorf("failed to form mpp, error is: %w", err)
 	}
+	_, span := tracing.Start(ctx, "calculateTotalMRR", trace.WithAttributes(attribute.Int("mpp.entries", len(formedMPP))))
 	mrr = convertRawMRR(calculateTotalMRR(formedMPP))
-	if _, err = cacheRepo.SetMRR(userFilePeriod, mrr); err != nil {
-		return months, mrr, fmt.Errorf("failed to set mrr to cache, error is: %s", err)
+	span.End()
+	reportProgress(ctx, "mrr", "MRR is calculated for %d periods", len(periods))
+	metrics.AnalyticsDuration.Observe(time.Since(start).Seconds())
+	if _, err = cacheRepo.SetMRR(ctx, orgFilePeriod, mrr); err != nil {
+		logging.FromContext(ctx).Warnf("failed to set mrr to cache, error is: %s", err)
+	}
+
+	return periods, mrr, nil
+}
+
+// analyzedPeriods returns reporting periods of the calendar ctx carries from the one containing
+// periodStart to the one containing periodEnd.
+func analyzedPeriods(ctx context.Context, periodStart, periodEnd string) ([]calendar.Period, error) {
+	periodStartDate, periodEndDate, err := parsePeriod(periodStart, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	periods, err := calendar.Between(calendar.FromContext(ctx), periodStartDate, periodEndDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reporting periods, error is: %s", err)
+	}
+
+	return periods, nil
+}
+
+// parsePeriod parses dates of the period, which should start no later than it ends.
+func parsePeriod(periodStart, periodEnd string) (time.Time, time.Time, error) {
+	periodStartDate, err := time.Parse(layout, periodStart)
+	if err != nil {
+		return periodStartDate, periodStartDate, fmt.Errorf("failed parse period start date, error is: %s", err)
+	}
+	periodEndDate, err := time.Parse(layout, periodEnd)
+	if err != nil {
+		return periodStartDate, periodEndDate, fmt.Errorf("failed parse period end date, error is: %s", err)
+	}
+	if periodStartDate.After(periodEndDate) {
+		return periodStartDate, periodEndDate, errors.New("period start should be less than period end")
 	}
 
-	return months, mrr, nil
+	return periodStartDate, periodEndDate, nil
 }
 
 func convertRawMRR(rawMRR []domain.MRR) domain.TotalMRR {
@@ -191,22 +522,45 @@ func calculateClientMRR(mpp domain.MPP) []domain.MRR {
 	return clientMRR
 }
 
-func formMPP(storageRepo storagerepo.StorageRepository, months []string, userID, fileID string, periodStart, periodEnd time.Time) ([]domain.MPP, error) {
-	fixedPeriodEnd := periodEnd.AddDate(0, 1, -1)
+func formMPP(ctx context.Context, storageRepo storagerepo.StorageRepository, periods []calendar.Period, orgID, fileID string) ([]domain.MPP, error) {
+	invoices, err := loadInvoices(ctx, storageRepo, orgID, fileID, periods)
+	if err != nil {
+		return nil, err
+	}
 
-	invoices, err := storageRepo.GetInvoicesByPeriod(userID, fileID, periodStart, fixedPeriodEnd)
+	return spreadInvoices(ctx, invoices, periods), nil
+}
+
+// loadInvoices loads invoices starting within periods.
+func loadInvoices(ctx context.Context, storageRepo storagerepo.StorageRepository, orgID, fileID string, periods []calendar.Period) ([]domain.Invoice, error) {
+	periodStart := periods[0].Start
+	periodEnd := periods[len(periods)-1].End.AddDate(0, 0, -1)
+
+	invoices, err := storageRepo.GetInvoicesByPeriod(ctx, orgID, fileID, periodStart, periodEnd)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get invoices from storage, error is: %s", err)
 	}
 
 	if len(invoices) == 0 {
-		return nil, errors.New("no data found for given period")
+		return nil, errNoData
 	}
+	reportProgress(ctx, "invoices", "%d invoices are loaded", len(invoices))
+
+	return invoices, nil
+}
 
-	mpp := formMPPEntries(invoices, len(months), periodStart)
+// spreadInvoices spreads paid amounts of invoices over reporting periods, one MPP per customer.
+func spreadInvoices(ctx context.Context, invoices []domain.Invoice, periods []calendar.Period) []domain.MPP {
+	_, span := tracing.Start(ctx, "formMPPEntries", trace.WithAttributes(attribute.Int("invoices", len(invoices))))
+	mpp := formMPPEntries(invoices, periods)
+	span.End()
+
+	_, span = tracing.Start(ctx, "fixMPP", trace.WithAttributes(attribute.Int("mpp.entries", len(mpp))))
 	fixedMPP := fixMPP(mpp)
+	span.End()
+	reportProgress(ctx, "mpp", "Revenue of %d customers is spread over %d periods", len(fixedMPP), len(periods))
 
-	return fixedMPP, nil
+	return fixedMPP
 }
 
 func fixMPP(mpp []domain.MPP) []domain.MPP {
@@ -233,33 +587,33 @@ func fixMPP(mpp []domain.MPP) []domain.MPP {
 	return fixedMPP
 }
 
-func formMPPEntries(invoices []domain.Invoice, monthsCount int, periodStart time.Time) []domain.MPP {
+// formMPPEntries spreads every invoice over periods as MRR, which is paid amount per month. Invoice
+// is paid for a month or for 12 months from its start, period takes MRR of the invoice if it's
+// paid on the last day of the period or if the invoice starts within the period.
+func formMPPEntries(invoices []domain.Invoice, periods []calendar.Period) []domain.MPP {
 	invoicesCount := len(invoices)
 	mppEntries := make([]domain.MPP, invoicesCount)
 
 	for i, invoice := range invoices {
-		moneyPerMonth := make([]float32, monthsCount)
+		moneyPerMonth := make([]float32, len(periods))
 		paidAmount := invoice.PaidAmount
-		periodLen := 1
-
-		invoicePeriodStart, _ := time.Parse(layout, invoice.PeriodStart)
-		startMonth := getMonthsDiff(invoicePeriodStart, periodStart)
-		if startMonth < 0 {
-			periodLen += startMonth
-			startMonth = 0
-		}
+		paidMonths := 1
 
 		if invoice.PaidPlan == "annually" {
 			paidAmount /= 12
-			periodLen = 12
+			paidMonths = 12
 		}
 
-		for j := startMonth; j < monthsCount; j++ {
-			if periodLen <= 0 {
-				paidAmount = 0
+		paidFrom, _ := time.Parse(layout, invoice.PeriodStart)
+		paidTill := addMonths(paidFrom, paidMonths)
+
+		for j, period := range periods {
+			lastDay := period.End.AddDate(0, 0, -1)
+			paidOnLastDay := !lastDay.Before(paidFrom) && lastDay.Before(paidTill)
+			startsWithin := !paidFrom.Before(period.Start) && paidFrom.Before(period.End)
+			if paidOnLastDay || startsWithin {
+				moneyPerMonth[j] = paidAmount
 			}
-			moneyPerMonth[j] = paidAmount
-			periodLen--
 		}
 
 		moneyFlow := domain.MPP{
@@ -273,49 +627,13 @@ func formMPPEntries(invoices []domain.Invoice, monthsCount int, periodStart time
 	return mppEntries
 }
 
-func getMonthsBetween(fdate, sdate time.Time) []string {
-	if fdate.Location() != sdate.Location() {
-		sdate = sdate.In(fdate.Location())
-	}
-	if fdate.After(sdate) {
-		fdate, sdate = sdate, fdate
-	}
-
-	fyear, fmonth, _ := fdate.Date()
-	syear, smonth, _ := sdate.Date()
-
-	count := int(smonth - fmonth)
-	count += 12*(syear-fyear) + 1
-
-	var months []string
-
-	for i := 0; i < count; i++ {
-		yearsDif := (int(fmonth) + i) / 12
-		month := (int(fmonth) + i) % 12
-		if month == 0 {
-			yearsDif -= 1
-			month = 12
-		}
-		months = append(months, fmt.Sprintf("%v.%v", month, fyear+yearsDif))
-	}
-
-	return months
-}
-
-func getMonthsDiff(fdate, sdate time.Time) int {
-	if fdate.Location() != sdate.Location() {
-		sdate = sdate.In(fdate.Location())
+// addMonths adds months to date, the day is clamped to the last day of resulting month.
+func addMonths(date time.Time, months int) time.Time {
+	firstDay := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	lastDay := firstDay.AddDate(0, months+1, -1)
+	if date.Day() > lastDay.Day() {
+		return lastDay
 	}
 
-	fyear, fmonth, _ := fdate.Date()
-	syear, smonth, _ := sdate.Date()
-
-	if fyear > syear {
-		fyear, syear = syear, fyear
-	}
-
-	count := int(fmonth - smonth)
-	count += 12 * (syear - fyear)
-
-	return count
+	return firstDay.AddDate(0, months, date.Day()-1)
 }
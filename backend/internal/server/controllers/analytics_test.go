@@ -1,17 +1,27 @@
 package controllers
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/hackfeed/remrratality/backend/internal/calendar"
 	"github.com/hackfeed/remrratality/backend/internal/domain"
 	"github.com/hackfeed/remrratality/backend/internal/server/models"
 	cacherepo "github.com/hackfeed/remrratality/backend/internal/store/cache_repo"
+	datasetrepo "github.com/hackfeed/remrratality/backend/internal/store/dataset_repo"
 	storagerepo "github.com/hackfeed/remrratality/backend/internal/store/storage_repo"
+	"github.com/hackfeed/remrratality/backend/internal/tracing"
 	internalTesting "github.com/hackfeed/remrratality/backend/internal/utils/testing"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestCreateAnalyticsHandler(t *testing.T) {
@@ -30,16 +40,27 @@ func TestCreateAnalyticsHandler(t *testing.T) {
 	}{
 		{
 			input: testInput{keys: map[string]interface{}{
-				"user_id": 5,
+				"org_id": 5,
 			}},
 			want: testWant{
 				code:    http.StatusInternalServerError,
-				message: "{\"message\":\"Unable to determine logged in user\"}",
+				message: "{\"message\":\"Unable to determine organization\"}",
 			},
 		},
 		{
 			input: testInput{keys: map[string]interface{}{
-				"user_id":      "flex",
+				"org_id":       "flex",
+				"dataset_repo": "invalidType",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "{\"message\":\"Failed to get dataset_repo\"}",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":       "flex",
+				"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
 				"storage_repo": "invalidType",
 			}},
 			want: testWant{
@@ -49,7 +70,8 @@ func TestCreateAnalyticsHandler(t *testing.T) {
 		},
 		{
 			input: testInput{keys: map[string]interface{}{
-				"user_id":      "flex",
+				"org_id":       "flex",
+				"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
 				"storage_repo": &storagerepo.StorageRepositoryMock{},
 				"cache_repo":   "invalidType",
 			}},
@@ -60,7 +82,8 @@ func TestCreateAnalyticsHandler(t *testing.T) {
 		},
 		{
 			input: testInput{keys: map[string]interface{}{
-				"user_id":      "flex",
+				"org_id":       "flex",
+				"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
 				"storage_repo": &storagerepo.StorageRepositoryMock{},
 				"cache_repo":   &cacherepo.CacheRepositoryMock{},
 			}},
@@ -72,7 +95,44 @@ func TestCreateAnalyticsHandler(t *testing.T) {
 		{
 			input: testInput{
 				keys: map[string]interface{}{
-					"user_id":      "flex",
+					"org_id":       "flex",
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+					"storage_repo": &storagerepo.StorageRepositoryMock{},
+					"cache_repo":   &cacherepo.CacheRepositoryMock{},
+				},
+				body: models.Period{
+					Filename:    "notFoundDataset",
+					PeriodStart: "2021-10-01",
+					PeriodEnd:   "2021-11-01",
+				}},
+			want: testWant{
+				code:    http.StatusNotFound,
+				message: "{\"message\":\"File not found\"}",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "flex",
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+					"storage_repo": &storagerepo.StorageRepositoryMock{},
+					"cache_repo":   &cacherepo.CacheRepositoryMock{},
+				},
+				body: models.Period{
+					Filename:    "importingDataset",
+					PeriodStart: "2021-10-01",
+					PeriodEnd:   "2021-11-01",
+				}},
+			want: testWant{
+				code:    http.StatusConflict,
+				message: "{\"message\":\"File is not imported yet\"}",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "flex",
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
 					"storage_repo": &storagerepo.StorageRepositoryMock{},
 					"cache_repo":   &cacherepo.CacheRepositoryMock{},
 				},
@@ -89,7 +149,8 @@ func TestCreateAnalyticsHandler(t *testing.T) {
 		{
 			input: testInput{
 				keys: map[string]interface{}{
-					"user_id":      "flex",
+					"org_id":       "flex",
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
 					"storage_repo": &storagerepo.StorageRepositoryMock{},
 					"cache_repo":   &cacherepo.CacheRepositoryMock{},
 				},
@@ -100,7 +161,7 @@ func TestCreateAnalyticsHandler(t *testing.T) {
 				}},
 			want: testWant{
 				code:    http.StatusOK,
-				message: "{\"message\":\"Analytics is loaded\",\"months\":[\"1.2017\",\"2.2017\"],\"mrr\":{\"New\":[0,0],\"Old\":[0,0],\"Reactivation\":[0,0],\"Expansion\":[0,0],\"Contraction\":[0,0],\"Churn\":[0,0],\"Total\":[0,0]}}",
+				message: "{\"message\":\"Analytics is loaded\",\"months\":[\"1.2017\",\"2.2017\"],\"periods\":[\"2017-01-01/2017-01-31\",\"2017-02-01/2017-02-28\"],\"mrr\":{\"New\":[0,0],\"Old\":[0,0],\"Reactivation\":[0,0],\"Expansion\":[0,0],\"Contraction\":[0,0],\"Churn\":[0,0],\"Total\":[0,0]}}",
 			},
 		},
 	}
@@ -135,7 +196,7 @@ func TestCreateAnalytics(t *testing.T) {
 				periodEnd:   "",
 			},
 			want: testWant{
-				months: nil,
+				months: []string{},
 				mrr:    domain.TotalMRR{},
 				err:    errors.New("failed parse period start date, error is: parsing time \"wrongPeriod\" as \"2006-01-02\": cannot parse \"wrongPeriod\" as \"2006\""),
 			},
@@ -148,7 +209,7 @@ func TestCreateAnalytics(t *testing.T) {
 				periodEnd:   "wrongPeriod",
 			},
 			want: testWant{
-				months: nil,
+				months: []string{},
 				mrr:    domain.TotalMRR{},
 				err:    errors.New("failed parse period end date, error is: parsing time \"wrongPeriod\" as \"2006-01-02\": cannot parse \"wrongPeriod\" as \"2006\""),
 			},
@@ -161,7 +222,7 @@ func TestCreateAnalytics(t *testing.T) {
 				periodEnd:   "2021-01-02",
 			},
 			want: testWant{
-				months: nil,
+				months: []string{},
 				mrr:    domain.TotalMRR{},
 				err:    errors.New("period start should be less than period end"),
 			},
@@ -174,9 +235,16 @@ func TestCreateAnalytics(t *testing.T) {
 				periodEnd:   "2021-02-02",
 			},
 			want: testWant{
-				months: nil,
-				mrr:    domain.TotalMRR{},
-				err:    errors.New("failed to get mrr from cache, error is: error while fetching mrr from cache"),
+				months: []string{"1.2021", "2.2021"},
+				mrr: domain.TotalMRR{
+					New:          []float32{0, 0},
+					Old:          []float32{0, 0},
+					Reactivation: []float32{0, 0},
+					Expansion:    []float32{0, 0},
+					Contraction:  []float32{0, 0},
+					Churn:        []float32{0, 0},
+					Total:        []float32{0, 0}},
+				err: nil,
 			},
 		},
 		{
@@ -202,7 +270,7 @@ func TestCreateAnalytics(t *testing.T) {
 			want: testWant{
 				months: []string{"10.2021"},
 				mrr:    domain.TotalMRR{},
-				err:    errors.New("failed to form mpp, error is: failed to get invoices from storage, error is: error while getting invoices by period"),
+				err:    fmt.Errorf("failed to form mpp, error is: %w", errors.New("failed to get invoices from storage, error is: error while getting invoices by period")),
 			},
 		},
 		{
@@ -222,7 +290,7 @@ func TestCreateAnalytics(t *testing.T) {
 					Contraction:  []float32{0},
 					Churn:        []float32{0},
 					Total:        []float32{100}},
-				err: errors.New("failed to set mrr to cache, error is: error while setting mrr to cache"),
+				err: nil,
 			},
 		},
 		{
@@ -251,13 +319,64 @@ func TestCreateAnalytics(t *testing.T) {
 	cacheMock := &cacherepo.CacheRepositoryMock{}
 
 	for _, test := range tests {
-		months, mrr, err := createAnalytics(storageMock, cacheMock, test.input.userID, test.input.fileID, test.input.periodStart, test.input.periodEnd)
-		assert.Equal(t, test.want.months, months)
+		periods, mrr, err := createAnalytics(context.Background(), storageMock, cacheMock, test.input.userID, test.input.fileID, test.input.periodStart, test.input.periodEnd)
+		assert.Equal(t, test.want.months, calendar.Labels(periods))
 		assert.Equal(t, test.want.mrr, mrr)
 		assert.Equal(t, test.want.err, err)
 	}
 }
 
+func TestCreateAnalyticsSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	ctx, parent := tracing.Start(context.Background(), "request")
+	_, _, err := createAnalytics(ctx, &storagerepo.StorageRepositoryMock{}, &cacherepo.CacheRepositoryMock{}, "userGood", "file", "2021-10-01", "2021-10-31")
+	parent.End()
+	assert.NoError(t, err)
+
+	names := make([]string, 0)
+	for _, span := range recorder.Ended() {
+		assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+		names = append(names, span.Name())
+	}
+	assert.Equal(t, []string{"formMPPEntries", "fixMPP", "calculateTotalMRR", "request"}, names)
+}
+
+func TestCreateAnalyticsCalendar(t *testing.T) {
+	storageMock := &storagerepo.StorageRepositoryMock{}
+	cacheMock := &cacherepo.CacheRepositoryMock{}
+
+	periods, mrr, err := createAnalytics(context.Background(), storageMock, cacheMock, "fiscal", "file", "2021-02-01", "2021-04-30")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2.2021", "3.2021", "4.2021"}, calendar.Labels(periods))
+	assert.Equal(t, []float32{100, 500, 0}, mrr.New)
+	assert.Equal(t, []float32{100, 400, -500}, mrr.Total)
+
+	// Invoice of March 29 starts the third period of 4-4-5 calendar, not the second one.
+	ctx := calendar.WithContext(context.Background(), calendar.Weekly{FiscalYearStart: time.February, WeekStart: time.Monday, Weeks: [3]int{4, 4, 5}})
+	periods, mrr, err = createAnalytics(ctx, storageMock, cacheMock, "fiscal", "file", "2021-02-01", "2021-04-30")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"P1.FY2022", "P2.FY2022", "P3.FY2022"}, calendar.Labels(periods))
+	assert.Equal(t, []string{"2021-02-01/2021-02-28", "2021-03-01/2021-03-28", "2021-03-29/2021-05-02"}, calendar.ISOLabels(periods))
+	assert.Equal(t, []float32{100, 200, 300}, mrr.New)
+	assert.Equal(t, []float32{0, -100, -200}, mrr.Churn)
+	assert.Equal(t, []float32{100, 100, 100}, mrr.Total)
+
+	// Cache mock has analytics of calendar months for the period, it isn't served for other calendars.
+	_, mrr, err = createAnalytics(context.Background(), storageMock, cacheMock, "user", "file", "2021-10-01", "2021-10-31")
+	assert.NoError(t, err)
+	assert.Equal(t, []float32{0, 0}, mrr.Total)
+
+	ctx = calendar.WithContext(context.Background(), calendar.Monthly{FiscalYearStart: time.February})
+	periods, mrr, err = createAnalytics(ctx, storageMock, cacheMock, "user", "file", "2021-10-01", "2021-10-31")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"P9.FY2022"}, calendar.Labels(periods))
+	assert.Equal(t, []string{"2021-10-01/2021-10-31"}, calendar.ISOLabels(periods))
+	assert.Equal(t, []float32{100}, mrr.Total)
+}
+
 func TestConvertRawMRR(t *testing.T) {
 	type testInput struct {
 		mrr []domain.MRR
@@ -385,26 +504,29 @@ func TestCalculateClientMRR(t *testing.T) {
 
 func TestFormMPP(t *testing.T) {
 	type testInput struct {
-		months                 []string
-		userID, fileID         string
-		periodStart, periodEnd time.Time
+		periods        []calendar.Period
+		userID, fileID string
 	}
 	type testWant struct {
 		mpp []domain.MPP
 		err error
 	}
 
+	october := []calendar.Period{{
+		Label: "10.2021",
+		Start: time.Date(2021, time.October, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2021, time.November, 1, 0, 0, 0, 0, time.UTC),
+	}}
+
 	tests := []struct {
 		input testInput
 		want  testWant
 	}{
 		{
 			input: testInput{
-				months:      []string{"10.2021"},
-				userID:      "errorGetInvoicesByPeriod",
-				fileID:      "",
-				periodStart: time.Now(),
-				periodEnd:   time.Now(),
+				periods: october,
+				userID:  "errorGetInvoicesByPeriod",
+				fileID:  "",
 			},
 			want: testWant{
 				mpp: nil,
@@ -413,11 +535,9 @@ func TestFormMPP(t *testing.T) {
 		},
 		{
 			input: testInput{
-				months:      []string{"10.2021"},
-				userID:      "emptyGetInvoicesByPeriod",
-				fileID:      "",
-				periodStart: time.Now(),
-				periodEnd:   time.Now(),
+				periods: october,
+				userID:  "emptyGetInvoicesByPeriod",
+				fileID:  "",
 			},
 			want: testWant{
 				mpp: nil,
@@ -426,11 +546,9 @@ func TestFormMPP(t *testing.T) {
 		},
 		{
 			input: testInput{
-				months:      []string{"10.2021"},
-				userID:      "userID",
-				fileID:      "",
-				periodStart: time.Date(2021, time.October, 1, 0, 0, 0, 0, time.UTC),
-				periodEnd:   time.Now(),
+				periods: october,
+				userID:  "userID",
+				fileID:  "",
 			},
 			want: testWant{
 				mpp: []domain.MPP{
@@ -447,7 +565,7 @@ func TestFormMPP(t *testing.T) {
 	storageMock := &storagerepo.StorageRepositoryMock{}
 
 	for _, test := range tests {
-		mpp, err := formMPP(storageMock, test.input.months, test.input.userID, test.input.fileID, test.input.periodStart, test.input.periodEnd)
+		mpp, err := formMPP(context.Background(), storageMock, test.input.periods, test.input.userID, test.input.fileID)
 		assert.Equal(t, test.want.mpp, mpp)
 		assert.Equal(t, test.want.err, err)
 	}
@@ -497,14 +615,18 @@ func TestFixMPP(t *testing.T) {
 
 func TestFormMPPEntries(t *testing.T) {
 	type testInput struct {
-		invoices    []domain.Invoice
-		monthsCount int
-		periodStart time.Time
+		invoices []domain.Invoice
+		periods  []calendar.Period
 	}
 	type testWant struct {
 		mpp []domain.MPP
 	}
 
+	monthly, err := calendar.Between(calendar.Monthly{FiscalYearStart: time.January}, time.Date(2021, time.October, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, time.December, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	fiscal, err := calendar.Between(calendar.Weekly{FiscalYearStart: time.February, WeekStart: time.Monday, Weeks: [3]int{4, 4, 5}}, time.Date(2021, time.February, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, time.May, 3, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+
 	tests := []struct {
 		input testInput
 		want  testWant
@@ -522,8 +644,7 @@ func TestFormMPPEntries(t *testing.T) {
 						PeriodEnd:   "2021-10-31",
 					},
 				},
-				monthsCount: 1,
-				periodStart: time.Date(2021, time.October, 1, 0, 0, 0, 0, time.UTC),
+				periods: monthly[:1],
 			},
 			want: testWant{
 				mpp: []domain.MPP{
@@ -547,8 +668,7 @@ func TestFormMPPEntries(t *testing.T) {
 						PeriodEnd:   "2021-09-31",
 					},
 				},
-				monthsCount: 1,
-				periodStart: time.Date(2021, time.October, 1, 0, 0, 0, 0, time.UTC),
+				periods: monthly[:1],
 			},
 			want: testWant{
 				mpp: []domain.MPP{
@@ -572,8 +692,7 @@ func TestFormMPPEntries(t *testing.T) {
 						PeriodEnd:   "2022-09-31",
 					},
 				},
-				monthsCount: 3,
-				periodStart: time.Date(2021, time.October, 1, 0, 0, 0, 0, time.UTC),
+				periods: monthly,
 			},
 			want: testWant{
 				mpp: []domain.MPP{
@@ -584,20 +703,69 @@ func TestFormMPPEntries(t *testing.T) {
 				},
 			},
 		},
+		{
+			input: testInput{
+				invoices: []domain.Invoice{
+					{
+						CustomerID:  0,
+						PeriodStart: "2021-03-01",
+						PaidPlan:    "monthly",
+						PaidAmount:  100.0,
+						PeriodEnd:   "2021-03-31",
+					},
+					{
+						CustomerID:  1,
+						PeriodStart: "2021-04-05",
+						PaidPlan:    "monthly",
+						PaidAmount:  50.0,
+						PeriodEnd:   "2021-05-04",
+					},
+				},
+				// P3.FY2022 is 5 weeks long, from 2021-03-29 to 2021-05-02.
+				periods: fiscal,
+			},
+			want: testWant{
+				mpp: []domain.MPP{
+					{
+						CustomerID: 0,
+						Months:     []float32{0.0, 100.0, 0.0, 0.0},
+					},
+					{
+						CustomerID: 1,
+						Months:     []float32{0.0, 0.0, 50.0, 0.0},
+					},
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
-		mpp := formMPPEntries(test.input.invoices, test.input.monthsCount, test.input.periodStart)
+		mpp := formMPPEntries(test.input.invoices, test.input.periods)
 		assert.Equal(t, test.want.mpp, mpp)
 	}
 }
 
-func TestGetMonthsBetween(t *testing.T) {
+func TestCreateAnalyticsHandlerFormats(t *testing.T) {
 	type testInput struct {
-		fdate, sdate time.Time
+		query  string
+		accept string
 	}
 	type testWant struct {
-		months []string
+		code        int
+		contentType string
+		body        string
+	}
+
+	keys := map[string]interface{}{
+		"org_id":       "flex",
+		"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+		"storage_repo": &storagerepo.StorageRepositoryMock{},
+		"cache_repo":   &cacherepo.CacheRepositoryMock{},
+	}
+	body := models.Period{
+		Filename:    "flex",
+		PeriodStart: "2017-01-01",
+		PeriodEnd:   "2017-02-01",
 	}
 
 	tests := []struct {
@@ -606,45 +774,116 @@ func TestGetMonthsBetween(t *testing.T) {
 	}{
 		{
 			input: testInput{
-				fdate: timeInLocation(time.Now(), "Europe/Moscow"),
-				sdate: timeInLocation(time.Now(), "Europe/Monaco"),
+				query: "format=pdf",
 			},
 			want: testWant{
-				months: []string{"10.2021"},
+				code:        http.StatusBadRequest,
+				contentType: "application/json; charset=utf-8",
+				body:        "{\"message\":\"Unknown analytics format, supported formats are json, csv, xlsx, ndjson and events\"}",
 			},
 		},
 		{
 			input: testInput{
-				fdate: time.Now().AddDate(0, 2, 0),
-				sdate: time.Now().AddDate(0, 0, 0),
+				query: "format=csv",
 			},
 			want: testWant{
-				months: []string{"10.2021", "11.2021", "12.2021"},
+				code:        http.StatusOK,
+				contentType: "text/csv",
+				body: "month,new,old,reactivation,expansion,contraction,churn,total\n" +
+					"2017-01,0,0,0,0,0,0,0\n" +
+					"2017-02,0,0,0,0,0,0,0\n",
 			},
 		},
 		{
 			input: testInput{
-				fdate: time.Now().AddDate(0, 3, 0),
-				sdate: time.Now().AddDate(0, 0, 0),
+				query:  "format=json",
+				accept: "text/csv",
 			},
 			want: testWant{
-				months: []string{"10.2021", "11.2021", "12.2021", "1.2022"},
+				code:        http.StatusOK,
+				contentType: "application/json; charset=utf-8",
+				body:        "{\"message\":\"Analytics is loaded\",\"months\":[\"1.2017\",\"2.2017\"],\"periods\":[\"2017-01-01/2017-01-31\",\"2017-02-01/2017-02-28\"],\"mrr\":{\"New\":[0,0],\"Old\":[0,0],\"Reactivation\":[0,0],\"Expansion\":[0,0],\"Contraction\":[0,0],\"Churn\":[0,0],\"Total\":[0,0]}}",
+			},
+		},
+		{
+			input: testInput{
+				accept: "application/x-ndjson",
+			},
+			want: testWant{
+				code:        http.StatusOK,
+				contentType: "application/x-ndjson",
+				body: "{\"month\":\"2017-01\",\"new\":0,\"old\":0,\"reactivation\":0,\"expansion\":0,\"contraction\":0,\"churn\":0,\"total\":0}\n" +
+					"{\"month\":\"2017-02\",\"new\":0,\"old\":0,\"reactivation\":0,\"expansion\":0,\"contraction\":0,\"churn\":0,\"total\":0}\n",
+			},
+		},
+		{
+			input: testInput{
+				query: "metrics=yes",
+			},
+			want: testWant{
+				code:        http.StatusBadRequest,
+				contentType: "application/json; charset=utf-8",
+				body:        "{\"message\":\"Failed to parse query parameters\"}",
+			},
+		},
+		{
+			input: testInput{
+				query: "metrics=true",
+			},
+			want: testWant{
+				code:        http.StatusOK,
+				contentType: "application/json; charset=utf-8",
+				body:        "{\"message\":\"Analytics is loaded\",\"months\":[\"1.2017\",\"2.2017\"],\"periods\":[\"2017-01-01/2017-01-31\",\"2017-02-01/2017-02-28\"],\"mrr\":{\"New\":[0,0],\"Old\":[0,0],\"Reactivation\":[0,0],\"Expansion\":[0,0],\"Contraction\":[0,0],\"Churn\":[0,0],\"Total\":[0,0]},\"metrics\":{\"MRR\":[0,0],\"ARR\":[0,0],\"Accounts\":[0,0],\"ARPA\":[0,0],\"CustomerChurn\":[0,0],\"LTV\":[0,0],\"QuickRatio\":[0,0]}}",
+			},
+		},
+		{
+			input: testInput{
+				accept: "image/png",
+			},
+			want: testWant{
+				code:        http.StatusOK,
+				contentType: "application/json; charset=utf-8",
+				body:        "{\"message\":\"Analytics is loaded\",\"months\":[\"1.2017\",\"2.2017\"],\"periods\":[\"2017-01-01/2017-01-31\",\"2017-02-01/2017-02-28\"],\"mrr\":{\"New\":[0,0],\"Old\":[0,0],\"Reactivation\":[0,0],\"Expansion\":[0,0],\"Contraction\":[0,0],\"Churn\":[0,0],\"Total\":[0,0]}}",
 			},
 		},
 	}
 
 	for _, test := range tests {
-		months := getMonthsBetween(test.input.fdate, test.input.sdate)
-		assert.Equal(t, test.want.months, months)
+		c, w := internalTesting.CreateGinContext(keys, body, nil)
+		c.Request.URL.RawQuery = test.input.query
+		c.Request.Header.Set("Accept", test.input.accept)
+		CreateAnalytics(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, test.want.contentType, w.Header().Get("Content-Type"))
+		assert.Equal(t, test.want.body, w.Body.String())
 	}
 }
 
-func TestGetMonthsDiff(t *testing.T) {
+func TestCreateAnalyticsHandlerXLSX(t *testing.T) {
+	c, w := internalTesting.CreateGinContext(map[string]interface{}{
+		"org_id":       "flex",
+		"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+		"storage_repo": &storagerepo.StorageRepositoryMock{},
+		"cache_repo":   &cacherepo.CacheRepositoryMock{},
+	}, models.Period{
+		Filename:    "flex",
+		PeriodStart: "2017-01-01",
+		PeriodEnd:   "2017-02-01",
+	}, nil)
+	c.Request.URL.RawQuery = "format=xlsx"
+	CreateAnalytics(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "attachment; filename=\"mrr_2017-01-01_2017-02-01.xlsx\"", w.Header().Get("Content-Disposition"))
+	assert.Equal(t, true, strings.HasPrefix(w.Body.String(), "PK"))
+}
+
+func TestCreateAnalyticsHandlerEvents(t *testing.T) {
 	type testInput struct {
-		fdate, sdate time.Time
+		orgID string
 	}
 	type testWant struct {
-		count int
+		body string
 	}
 
 	tests := []struct {
@@ -653,31 +892,41 @@ func TestGetMonthsDiff(t *testing.T) {
 	}{
 		{
 			input: testInput{
-				fdate: timeInLocation(time.Now(), "Europe/Moscow"),
-				sdate: timeInLocation(time.Now(), "Europe/Monaco"),
+				orgID: "flex",
 			},
 			want: testWant{
-				count: 0,
+				body: "event:progress\ndata:{\"stage\":\"invoices\",\"message\":\"1 invoices are loaded\"}\n\n" +
+					"event:progress\ndata:{\"stage\":\"mpp\",\"message\":\"Revenue of 1 customers is spread over 2 periods\"}\n\n" +
+					"event:progress\ndata:{\"stage\":\"mrr\",\"message\":\"MRR is calculated for 2 periods\"}\n\n" +
+					"event:result\ndata:{\"message\":\"Analytics is loaded\",\"months\":[\"1.2017\",\"2.2017\"],\"periods\":[\"2017-01-01/2017-01-31\",\"2017-02-01/2017-02-28\"],\"mrr\":{\"New\":[0,0],\"Old\":[0,0],\"Reactivation\":[0,0],\"Expansion\":[0,0],\"Contraction\":[0,0],\"Churn\":[0,0],\"Total\":[0,0]}}\n\n",
 			},
 		},
 		{
 			input: testInput{
-				fdate: time.Now().AddDate(1, 0, 0),
-				sdate: time.Now().AddDate(0, 0, -1),
+				orgID: "emptyGetInvoicesByPeriod",
 			},
 			want: testWant{
-				count: 12,
+				body: "event:failed\ndata:{\"message\":\"Failed to get analytics. Please ensure that period start is earlier than period end and data exists in given period\"}\n\n",
 			},
 		},
 	}
 
 	for _, test := range tests {
-		count := getMonthsDiff(test.input.fdate, test.input.sdate)
-		assert.Equal(t, test.want.count, count)
-	}
-}
+		c, w := internalTesting.CreateGinContext(map[string]interface{}{
+			"org_id":       test.input.orgID,
+			"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+			"storage_repo": &storagerepo.StorageRepositoryMock{},
+			"cache_repo":   &cacherepo.CacheRepositoryMock{},
+		}, models.Period{
+			Filename:    "flex",
+			PeriodStart: "2017-01-01",
+			PeriodEnd:   "2017-02-01",
+		}, nil)
+		c.Request.Header.Set("Accept", "text/event-stream")
+		CreateAnalytics(c)
 
-func timeInLocation(tm time.Time, loc string) time.Time {
-	loadedLoc, _ := time.LoadLocation(loc)
-	return tm.In(loadedLoc)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.Equal(t, test.want.body, w.Body.String())
+	}
 }
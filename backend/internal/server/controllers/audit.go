@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
+	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	auditrepo "github.com/hackfeed/remrratality/backend/internal/store/audit_repo"
+)
+
+var defaultAuditPeriod = 30 * 24 * time.Hour
+
+// LoadAuditEvents godoc
+// @Summary Loading user's audit log
+// @Description Loading security and data events of logged in user for given time range, newest first
+// @Tags audit
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} models.ResponseSuccessAuditEvents
+// @Failure 400 {object} models.Response
+// @Failure 401 {object} models.Response
+// @Failure 500 {object} models.Response
+// @Security ApiKeyAuth
+// @Param from query string false "RFC3339 range start, 30 days before range end if omitted" example(2021-10-01T00:00:00Z)
+// @Param to query string false "RFC3339 range end, current time if omitted" example(2021-10-31T00:00:00Z)
+// @Router /audit [get]
+func LoadAuditEvents(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	userID, ok := c.MustGet("user_id").(string)
+	if !ok {
+		logger.Errorf("failed to get user_id from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Unable to determine logged in user",
+		})
+		return
+	}
+	auditRepo, ok := c.MustGet("audit_repo").(auditrepo.AuditRepository)
+	if !ok {
+		logger.Errorf("failed to get audit_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get audit_repo",
+		})
+		return
+	}
+
+	from, to, err := parseAuditPeriod(c.Query("from"), c.Query("to"), time.Now().UTC())
+	if err != nil {
+		logger.Errorf("failed to parse audit period, error is: %s", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Message: "Failed to parse time range. Please provide RFC3339 timestamps with from earlier than to",
+		})
+		return
+	}
+
+	events, err := auditRepo.GetEvents(c.Request.Context(), userID, from, to)
+	if err != nil {
+		logger.Errorf("failed to load audit events for user_id %s, error is: %s", userID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to fetch audit events",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseSuccessAuditEvents{
+		Message: "Audit events are loaded",
+		From:    from,
+		To:      to,
+		Events:  events,
+	})
+}
+
+func parseAuditPeriod(from, to string, now time.Time) (time.Time, time.Time, error) {
+	var (
+		fromTime time.Time
+		toTime   = now
+		err      error
+	)
+
+	if to != "" {
+		if toTime, err = time.Parse(time.RFC3339, to); err != nil {
+			return fromTime, toTime, fmt.Errorf("failed to parse range end, error is: %s", err)
+		}
+	}
+
+	fromTime = toTime.Add(-defaultAuditPeriod)
+	if from != "" {
+		if fromTime, err = time.Parse(time.RFC3339, from); err != nil {
+			return fromTime, toTime, fmt.Errorf("failed to parse range start, error is: %s", err)
+		}
+	}
+
+	if fromTime.After(toTime) {
+		return fromTime, toTime, errors.New("range start should be less than range end")
+	}
+
+	return fromTime, toTime, nil
+}
@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	auditrepo "github.com/hackfeed/remrratality/backend/internal/store/audit_repo"
+	internalTesting "github.com/hackfeed/remrratality/backend/internal/utils/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadAuditEventsHandler(t *testing.T) {
+	type testInput struct {
+		keys  map[string]interface{}
+		query string
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"user_id": 1,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine logged in user",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"user_id":    "user",
+				"audit_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get audit_repo",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"user_id":    "user",
+					"audit_repo": &auditrepo.AuditRepositoryMock{},
+				},
+				query: "from=yesterday",
+			},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Failed to parse time range",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"user_id":    "errorGetEvents",
+				"audit_repo": &auditrepo.AuditRepositoryMock{},
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to fetch audit events",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"user_id":    "user",
+					"audit_repo": &auditrepo.AuditRepositoryMock{},
+				},
+				query: "from=2021-10-01T00:00:00Z&to=2021-10-31T00:00:00Z",
+			},
+			want: testWant{
+				code:    http.StatusOK,
+				message: "\"action\":\"login\"",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(test.input.keys, nil, nil)
+		c.Request.URL = &url.URL{RawQuery: test.input.query}
+		LoadAuditEvents(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message))
+	}
+}
+
+func TestParseAuditPeriod(t *testing.T) {
+	now := time.Date(2021, time.October, 31, 0, 0, 0, 0, time.UTC)
+
+	type testInput struct {
+		from string
+		to   string
+	}
+	type testWant struct {
+		from time.Time
+		to   time.Time
+		err  error
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{},
+			want: testWant{
+				from: time.Date(2021, time.October, 1, 0, 0, 0, 0, time.UTC),
+				to:   now,
+				err:  nil,
+			},
+		},
+		{
+			input: testInput{
+				from: "2021-10-30T00:00:00Z",
+			},
+			want: testWant{
+				from: time.Date(2021, time.October, 30, 0, 0, 0, 0, time.UTC),
+				to:   now,
+				err:  nil,
+			},
+		},
+		{
+			input: testInput{
+				to: "2021-09-30T00:00:00Z",
+			},
+			want: testWant{
+				from: time.Date(2021, time.August, 31, 0, 0, 0, 0, time.UTC),
+				to:   time.Date(2021, time.September, 30, 0, 0, 0, 0, time.UTC),
+				err:  nil,
+			},
+		},
+		{
+			input: testInput{
+				from: "2021-11-01T00:00:00Z",
+			},
+			want: testWant{
+				from: time.Date(2021, time.November, 1, 0, 0, 0, 0, time.UTC),
+				to:   now,
+				err:  errors.New("range start should be less than range end"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		from, to, err := parseAuditPeriod(test.input.from, test.input.to, now)
+		assert.Equal(t, test.want.from, from)
+		assert.Equal(t, test.want.to, to)
+		assert.Equal(t, test.want.err, err)
+	}
+}
@@ -1,14 +1,28 @@
 package controllers
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/hackfeed/remrratality/backend/internal/utils/user_validation"
 
 	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
+	"github.com/hackfeed/remrratality/backend/internal/server/middlewares"
 	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	limitrepo "github.com/hackfeed/remrratality/backend/internal/store/limit_repo"
+	orgrepo "github.com/hackfeed/remrratality/backend/internal/store/org_repo"
 	userrepo "github.com/hackfeed/remrratality/backend/internal/store/user_repo"
-	log "github.com/sirupsen/logrus"
+)
+
+var (
+	loginLimit          = domain.Limit{Rate: 5.0 / 60, Burst: 5}
+	maxLoginFailures    = int64(5)
+	loginFailuresWindow = 15 * time.Minute
+	loginLockout        = 15 * time.Minute
 )
 
 // SignUp godoc
@@ -19,50 +33,78 @@ import (
 // @Produce  json
 // @Success 200 {object} models.ResponseSuccessAuth
 // @Failure 400 {object} models.Response
+// @Failure 429 {object} models.Response
+// @Header 429 {integer} Retry-After "Seconds to wait before the next attempt"
 // @Failure 500 {object} models.Response
 // @Param request body models.User true "User's email and password"
 // @Router /signup [post]
 func SignUp(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
 	userRepo, ok := c.MustGet("user_repo").(userrepo.UserRepository)
 	if !ok {
-		log.Errorf("failed to get user_repo from gin.Context")
+		logger.Errorf("failed to get user_repo from gin.Context")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
 			Message: "Failed to get user_repo",
 		})
 		return
 	}
+	orgRepo, ok := c.MustGet("org_repo").(orgrepo.OrgRepository)
+	if !ok {
+		logger.Errorf("failed to get org_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get org_repo",
+		})
+		return
+	}
 
 	var req models.User
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Errorf("failed to parse request body, error is: %s", err)
+		logger.Errorf("failed to parse request body, error is: %s", err)
 		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
 			Message: "Failed to parse request body",
 		})
 		return
 	}
 
-	existingUser, _ := userRepo.GetUser(req.Email)
+	c.Set("audit_details", map[string]interface{}{"email": req.Email})
+
+	existingUser, _ := userRepo.GetUser(c.Request.Context(), req.Email)
 	if existingUser.Email != "" {
-		log.Infof("user with email %s already exists", existingUser.Email)
+		logger.Infof("user with email %s already exists", existingUser.Email)
 		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
 			Message: "This email is already taken",
 		})
 		return
 	}
 
-	user, err := userRepo.AddUser(req.Email, req.Password)
+	user, err := userRepo.AddUser(c.Request.Context(), req.Email, req.Password)
 	if err != nil {
-		log.Errorf("failed to add user with email %s, error is: %s", req.Email, err)
+		logger.Errorf("failed to add user with email %s, error is: %s", req.Email, err)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
 			Message: "Failed to create new user. Please, try again later",
 		})
 		return
 	}
 
+	c.Set("user_id", user.UserID)
+
+	if _, err = orgRepo.AddOrganization(c.Request.Context(), domain.Organization{
+		OrgID:   user.UserID,
+		Name:    user.Email,
+		Members: []domain.Member{{UserID: user.UserID, Email: user.Email, Role: domain.RoleOwner}},
+	}); err != nil {
+		logger.Errorf("failed to add personal organization for user %s, error is: %s", user.UserID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to create personal organization",
+		})
+		return
+	}
+
 	expiresAt, err := user_validation.GetExpirationTime(user.Token)
 	if err != nil {
-		log.Errorf("failed to get token expiration time for user %s, error is: %s", user.UserID, err)
+		logger.Errorf("failed to get token expiration time for user %s, error is: %s", user.UserID, err)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
 			Message: "Failed to get token expiration time",
 		})
@@ -85,49 +127,93 @@ func SignUp(c *gin.Context) {
 // @Produce  json
 // @Success 200 {object} models.ResponseSuccessAuth
 // @Failure 400 {object} models.Response
+// @Failure 429 {object} models.Response
+// @Header 429 {integer} Retry-After "Seconds to wait before the next attempt"
 // @Failure 500 {object} models.Response
 // @Param request body models.User true "User's email and password"
 // @Router /login [post]
 func Login(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
 	userRepo, ok := c.MustGet("user_repo").(userrepo.UserRepository)
 	if !ok {
-		log.Errorf("failed to get user_repo from gin.Context")
+		logger.Errorf("failed to get user_repo from gin.Context")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
 			Message: "Failed to get user_repo",
 		})
 		return
 	}
+	limitRepo, ok := c.MustGet("limit_repo").(limitrepo.LimitRepository)
+	if !ok {
+		logger.Errorf("failed to get limit_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get limit_repo",
+		})
+		return
+	}
 
 	var req models.User
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Errorf("failed to parse request body, error is: %s", err)
+		logger.Errorf("failed to parse request body, error is: %s", err)
 		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
 			Message: "Failed to parse request body",
 		})
 		return
 	}
 
-	user, err := userRepo.GetUser(req.Email)
+	c.Set("audit_details", map[string]interface{}{"email": req.Email})
+
+	// Failures are counted per client, so nobody can lock the account out for its owner.
+	loginKey := fmt.Sprintf("login:%s:%s", c.ClientIP(), req.Email)
+
+	lockedFor, err := limitRepo.GetLock(c.Request.Context(), loginKey)
+	if err != nil {
+		logger.Errorf("failed to get lock for email %s, error is: %s", req.Email, err)
+	}
+	if lockedFor > 0 {
+		logger.Infof("login for email %s from %s is locked", req.Email, c.ClientIP())
+		middlewares.AbortWithRetryAfter(c, lockedFor, "Account is temporarily locked because of too many failed login attempts")
+		return
+	}
+
+	wait, err := limitRepo.Take(c.Request.Context(), loginKey, loginLimit)
+	if err != nil {
+		logger.Errorf("failed to take login token for email %s, error is: %s", req.Email, err)
+	}
+	if wait > 0 {
+		logger.Infof("login rate limit for email %s is exceeded", req.Email)
+		middlewares.AbortWithRetryAfter(c, wait, "Too many login attempts. Please, try again later")
+		return
+	}
+
+	user, err := userRepo.GetUser(c.Request.Context(), req.Email)
 	if err != nil {
-		log.Errorf("failed to get user with email %s, error is: %s", req.Email, err)
+		logger.Errorf("failed to get user with email %s, error is: %s", req.Email, err)
+		registerLoginFailure(c.Request.Context(), limitRepo, loginKey)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
 			Message: "User with given email doesn't exist",
 		})
 		return
 	}
+	c.Set("user_id", user.UserID)
 
 	if err = user_validation.VerifyPassword(user.Password, req.Password); err != nil {
-		log.Errorf("failed to verify password for user %s, error is: %s", user.UserID, err)
+		logger.Errorf("failed to verify password for user %s, error is: %s", user.UserID, err)
+		registerLoginFailure(c.Request.Context(), limitRepo, loginKey)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
 			Message: "Password is incorrect",
 		})
 		return
 	}
 
+	if err = limitRepo.ResetFailures(c.Request.Context(), loginKey); err != nil {
+		logger.Errorf("failed to reset login failures for user %s, error is: %s", user.UserID, err)
+	}
+
 	token, refreshToken, err := user_validation.GenerateTokens(user.Email, user.UserID)
 	if err != nil {
-		log.Errorf("failed to generate tokens for user %s, error is: %s", user.UserID, err)
+		logger.Errorf("failed to generate tokens for user %s, error is: %s", user.UserID, err)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
 			Message: "Failed to generate tokens",
 		})
@@ -136,8 +222,8 @@ func Login(c *gin.Context) {
 
 	user_validation.UpdateTokens(&user, token, refreshToken)
 
-	if err = userRepo.UpdateUser(user.UserID, user); err != nil {
-		log.Errorf("failed to update user %s, error is: %s", user.UserID, err)
+	if err = userRepo.UpdateUser(c.Request.Context(), user.UserID, user); err != nil {
+		logger.Errorf("failed to update user %s, error is: %s", user.UserID, err)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
 			Message: "Failed to update user data",
 		})
@@ -146,7 +232,7 @@ func Login(c *gin.Context) {
 
 	expiresAt, err := user_validation.GetExpirationTime(user.Token)
 	if err != nil {
-		log.Errorf("failed to get token expiration time for user %s, error is: %s", user.UserID, err)
+		logger.Errorf("failed to get token expiration time for user %s, error is: %s", user.UserID, err)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
 			Message: "Failed to get token expiration time",
 		})
@@ -160,3 +246,27 @@ func Login(c *gin.Context) {
 		ExpiresAt: expiresAt,
 	})
 }
+
+// registerLoginFailure locks login by the key once there are too many failures within the window.
+func registerLoginFailure(ctx context.Context, limitRepo limitrepo.LimitRepository, loginKey string) {
+	logger := logging.FromContext(ctx)
+
+	failures, err := limitRepo.AddFailure(ctx, loginKey, loginFailuresWindow)
+	if err != nil {
+		logger.Errorf("failed to register login failure for %s, error is: %s", loginKey, err)
+		return
+	}
+
+	if failures < maxLoginFailures {
+		return
+	}
+
+	logger.Warnf("locking %s for %s after %d failed attempts", loginKey, loginLockout, failures)
+	if err = limitRepo.Lock(ctx, loginKey, loginLockout); err != nil {
+		logger.Errorf("failed to lock %s, error is: %s", loginKey, err)
+		return
+	}
+	if err = limitRepo.ResetFailures(ctx, loginKey); err != nil {
+		logger.Errorf("failed to reset login failures for %s, error is: %s", loginKey, err)
+	}
+}
@@ -3,10 +3,13 @@ package controllers
 import (
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	limitrepo "github.com/hackfeed/remrratality/backend/internal/store/limit_repo"
+	orgrepo "github.com/hackfeed/remrratality/backend/internal/store/org_repo"
 	userrepo "github.com/hackfeed/remrratality/backend/internal/store/user_repo"
 	internalTesting "github.com/hackfeed/remrratality/backend/internal/utils/testing"
 	"github.com/stretchr/testify/assert"
@@ -38,6 +41,17 @@ func TestSignUp(t *testing.T) {
 		{
 			input: testInput{keys: map[string]interface{}{
 				"user_repo": &userrepo.UserRepositoryMock{},
+				"org_repo":  "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "{\"message\":\"Failed to get org_repo\"}",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"user_repo": &userrepo.UserRepositoryMock{},
+				"org_repo":  &orgrepo.OrgRepositoryMock{},
 			}},
 			want: testWant{
 				code:    http.StatusBadRequest,
@@ -48,6 +62,7 @@ func TestSignUp(t *testing.T) {
 			input: testInput{
 				keys: map[string]interface{}{
 					"user_repo": &userrepo.UserRepositoryMock{},
+					"org_repo":  &orgrepo.OrgRepositoryMock{},
 				},
 				body: models.User{
 					Email:    "takenEmail",
@@ -63,6 +78,7 @@ func TestSignUp(t *testing.T) {
 			input: testInput{
 				keys: map[string]interface{}{
 					"user_repo": &userrepo.UserRepositoryMock{},
+					"org_repo":  &orgrepo.OrgRepositoryMock{},
 				},
 				body: models.User{
 					Email:    "errorGetUser",
@@ -78,6 +94,23 @@ func TestSignUp(t *testing.T) {
 			input: testInput{
 				keys: map[string]interface{}{
 					"user_repo": &userrepo.UserRepositoryMock{},
+					"org_repo":  &orgrepo.OrgRepositoryMock{},
+				},
+				body: models.User{
+					Email:    "errorAddOrganization",
+					Password: "somePass",
+				},
+			},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "{\"message\":\"Failed to create personal organization\"}",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"user_repo": &userrepo.UserRepositoryMock{},
+					"org_repo":  &orgrepo.OrgRepositoryMock{},
 				},
 				body: models.User{
 					Email:    "someEmail",
@@ -124,7 +157,18 @@ func TestLogin(t *testing.T) {
 		},
 		{
 			input: testInput{keys: map[string]interface{}{
-				"user_repo": &userrepo.UserRepositoryMock{},
+				"user_repo":  &userrepo.UserRepositoryMock{},
+				"limit_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "{\"message\":\"Failed to get limit_repo\"}",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"user_repo":  &userrepo.UserRepositoryMock{},
+				"limit_repo": &limitrepo.LimitRepositoryMock{},
 			}},
 			want: testWant{
 				code:    http.StatusBadRequest,
@@ -134,7 +178,40 @@ func TestLogin(t *testing.T) {
 		{
 			input: testInput{
 				keys: map[string]interface{}{
-					"user_repo": &userrepo.UserRepositoryMock{},
+					"user_repo":  &userrepo.UserRepositoryMock{},
+					"limit_repo": &limitrepo.LimitRepositoryMock{},
+				},
+				body: models.User{
+					Email:    "lockedEmail",
+					Password: "somePass",
+				},
+			},
+			want: testWant{
+				code:    http.StatusTooManyRequests,
+				message: "{\"message\":\"Account is temporarily locked because of too many failed login attempts\"}",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"user_repo":  &userrepo.UserRepositoryMock{},
+					"limit_repo": &limitrepo.LimitRepositoryMock{},
+				},
+				body: models.User{
+					Email:    "limitedEmail",
+					Password: "somePass",
+				},
+			},
+			want: testWant{
+				code:    http.StatusTooManyRequests,
+				message: "{\"message\":\"Too many login attempts. Please, try again later\"}",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"user_repo":  &userrepo.UserRepositoryMock{},
+					"limit_repo": &limitrepo.LimitRepositoryMock{},
 				},
 				body: models.User{
 					Email:    "errorGetUser",
@@ -149,7 +226,8 @@ func TestLogin(t *testing.T) {
 		{
 			input: testInput{
 				keys: map[string]interface{}{
-					"user_repo": &userrepo.UserRepositoryMock{},
+					"user_repo":  &userrepo.UserRepositoryMock{},
+					"limit_repo": &limitrepo.LimitRepositoryMock{},
 				},
 				body: models.User{
 					Email:    "userWithWrongPass",
@@ -164,7 +242,8 @@ func TestLogin(t *testing.T) {
 		{
 			input: testInput{
 				keys: map[string]interface{}{
-					"user_repo": &userrepo.UserRepositoryMock{},
+					"user_repo":  &userrepo.UserRepositoryMock{},
+					"limit_repo": &limitrepo.LimitRepositoryMock{},
 				},
 				body: models.User{
 					Email:    "verifiedEmail",
@@ -186,3 +265,25 @@ func TestLogin(t *testing.T) {
 		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message))
 	}
 }
+
+func TestLoginLockoutByClient(t *testing.T) {
+	keys := map[string]interface{}{
+		"user_repo":  &userrepo.UserRepositoryMock{},
+		"limit_repo": limitrepo.NewMemoryRepo(),
+	}
+	login := func(ip, password string) *httptest.ResponseRecorder {
+		c, w := internalTesting.CreateGinContext(keys, models.User{Email: "victim", Password: password}, nil)
+		c.Request.RemoteAddr = ip + ":1234"
+		Login(c)
+		return w
+	}
+
+	for i := int64(0); i < maxLoginFailures; i++ {
+		assert.Equal(t, http.StatusInternalServerError, login("203.0.113.1", "fakePass").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, login("203.0.113.1", "somePass").Code)
+
+	w := login("198.51.100.1", "somePass")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, true, strings.Contains(w.Body.String(), "Login success"))
+}
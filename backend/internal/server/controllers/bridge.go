@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/calendar"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
+	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	datasetrepo "github.com/hackfeed/remrratality/backend/internal/store/dataset_repo"
+	storagerepo "github.com/hackfeed/remrratality/backend/internal/store/storage_repo"
+)
+
+// CreateBridge godoc
+// @Summary Create MRR bridge
+// @Description Creating MRR bridge from MRR of the first month of given period to MRR of its last month.
+// @Description Movements of every month after the first one are summed up into new, expansion, reactivation, contraction and churn,
+// @Description so starting MRR with movements equals ending MRR. With top parameter customers contributing the most
+// @Description to every movement are returned too.
+// @Tags analytics
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} models.ResponseSuccessBridge
+// @Failure 400 {object} models.Response
+// @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 404 {object} models.Response
+// @Failure 409 {object} models.Response
+// @Failure 429 {object} models.Response
+// @Header 429 {integer} Retry-After "Seconds to wait before the next request"
+// @Failure 500 {object} models.Response
+// @Security ApiKeyAuth
+// @Param request body models.Bridge true "Parameters for MRR bridge"
+// @Param org header string false "Organization ID, personal organization is used if omitted"
+// @Router /analytics/bridge [post]
+func CreateBridge(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	orgID, ok := c.MustGet("org_id").(string)
+	if !ok {
+		logger.Errorf("failed to get org_id from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Unable to determine organization",
+		})
+		return
+	}
+	datasetRepo, ok := c.MustGet("dataset_repo").(datasetrepo.DatasetRepository)
+	if !ok {
+		logger.Errorf("failed to get dataset_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get dataset_repo",
+		})
+		return
+	}
+	storageRepo, ok := c.MustGet("storage_repo").(storagerepo.StorageRepository)
+	if !ok {
+		logger.Errorf("failed to get storage_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get storage_repo",
+		})
+		return
+	}
+
+	var req models.Bridge
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Errorf("failed to parse request body, error is: %s", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Message: "Failed to parse request body",
+		})
+		return
+	}
+
+	c.Set("audit_details", map[string]interface{}{
+		"filename":     req.Filename,
+		"period_start": req.PeriodStart,
+		"period_end":   req.PeriodEnd,
+		"top":          req.Top,
+	})
+
+	if _, ok := readyDataset(c, datasetRepo, orgID, req.Filename); !ok {
+		return
+	}
+
+	bridge, top, err := createBridge(c.Request.Context(), storageRepo, orgID, req)
+	if err != nil {
+		logger.Errorf("failed to get MRR bridge, error is: %s", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Message: "Failed to get analytics. Please ensure that period start is earlier than period end and data exists in given period",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseSuccessBridge{
+		Message:      "Bridge is created",
+		Bridge:       bridge,
+		TopCustomers: top,
+	})
+}
+
+func createBridge(ctx context.Context, storageRepo storagerepo.StorageRepository, orgID string, req models.Bridge) (domain.MRRBridge, map[string][]domain.CustomerMovement, error) {
+	periods, err := analyzedPeriods(ctx, req.PeriodStart, req.PeriodEnd)
+	if err != nil {
+		return domain.MRRBridge{}, nil, err
+	}
+
+	mpp, err := formMPP(ctx, storageRepo, periods, orgID, req.Filename)
+	if err != nil {
+		return domain.MRRBridge{}, nil, fmt.Errorf("failed to form mpp, error is: %s", err)
+	}
+
+	bridge, top := calculateBridge(mpp, periods, req.Top)
+	return bridge, top, nil
+}
+
+// calculateBridge sums MRR movements of customers over periods after the first one. Customers
+// with the largest movements, top of them per bucket, are returned when top is positive.
+func calculateBridge(mpp []domain.MPP, periods []calendar.Period, top int) (domain.MRRBridge, map[string][]domain.CustomerMovement) {
+	last := len(periods) - 1
+	bridge := domain.MRRBridge{
+		StartMonth:  periods[0].Label,
+		EndMonth:    periods[last].Label,
+		StartPeriod: periods[0].ISO(),
+		EndPeriod:   periods[last].ISO(),
+	}
+	buckets := map[string][]domain.CustomerMovement{}
+
+	for _, mppEntry := range mpp {
+		bridge.StartMRR += mppEntry.Months[0]
+		bridge.EndMRR += mppEntry.Months[last]
+
+		var movements domain.MRR
+		clientMRR := calculateClientMRR(mppEntry)
+		for i := 1; i <= last; i++ {
+			movements.New += clientMRR[i].New
+			movements.Expansion += clientMRR[i].Expansion
+			movements.Reactivation += clientMRR[i].Reactivation
+			movements.Contraction += clientMRR[i].Contraction
+			movements.Churn += clientMRR[i].Churn
+		}
+		bridge.New += movements.New
+		bridge.Expansion += movements.Expansion
+		bridge.Reactivation += movements.Reactivation
+		bridge.Contraction += movements.Contraction
+		bridge.Churn += movements.Churn
+
+		if top <= 0 {
+			continue
+		}
+		for bucket, amount := range map[string]float32{
+			"new":          movements.New,
+			"expansion":    movements.Expansion,
+			"reactivation": movements.Reactivation,
+			"contraction":  movements.Contraction,
+			"churn":        movements.Churn,
+		} {
+			if amount != 0 {
+				buckets[bucket] = append(buckets[bucket], domain.CustomerMovement{CustomerID: mppEntry.CustomerID, Amount: amount})
+			}
+		}
+	}
+
+	if top <= 0 {
+		return bridge, nil
+	}
+	for bucket, movements := range buckets {
+		sort.Slice(movements, func(i, j int) bool {
+			left, right := math.Abs(float64(movements[i].Amount)), math.Abs(float64(movements[j].Amount))
+			if left != right {
+				return left > right
+			}
+			return movements[i].CustomerID < movements[j].CustomerID
+		})
+		if len(movements) > top {
+			buckets[bucket] = movements[:top]
+		}
+	}
+
+	return bridge, buckets
+}
@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/calendar"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	datasetrepo "github.com/hackfeed/remrratality/backend/internal/store/dataset_repo"
+	storagerepo "github.com/hackfeed/remrratality/backend/internal/store/storage_repo"
+	internalTesting "github.com/hackfeed/remrratality/backend/internal/utils/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateBridgeHandler(t *testing.T) {
+	type testInput struct {
+		keys map[string]interface{}
+		body interface{}
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	repos := func(orgID string) map[string]interface{} {
+		return map[string]interface{}{
+			"org_id":       orgID,
+			"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+			"storage_repo": &storagerepo.StorageRepositoryMock{},
+		}
+	}
+	period := models.Period{Filename: "file.csv", PeriodStart: "2021-10-01", PeriodEnd: "2021-11-01"}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id": 5,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine organization",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":       "org",
+				"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+				"storage_repo": "invalidType",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get storage_repo",
+			},
+		},
+		{
+			input: testInput{keys: repos("org"), body: models.Bridge{Period: period, Top: 101}},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Failed to parse request body",
+			},
+		},
+		{
+			input: testInput{keys: repos("org"), body: models.Bridge{Period: models.Period{Filename: "importingDataset", PeriodStart: "2021-10-01", PeriodEnd: "2021-11-01"}}},
+			want: testWant{
+				code:    http.StatusConflict,
+				message: "File is not imported yet",
+			},
+		},
+		{
+			input: testInput{keys: repos("errorGetInvoicesByPeriod"), body: models.Bridge{Period: period}},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Failed to get analytics",
+			},
+		},
+		{
+			input: testInput{keys: repos("org"), body: models.Bridge{Period: period}},
+			want: testWant{
+				code:    http.StatusOK,
+				message: `{"message":"Bridge is created","bridge":{"StartMonth":"10.2021","EndMonth":"11.2021","StartPeriod":"2021-10-01/2021-10-31","EndPeriod":"2021-11-01/2021-11-30","StartMRR":100,"New":0,"Expansion":0,"Reactivation":0,"Contraction":0,"Churn":-100,"EndMRR":0}}`,
+			},
+		},
+		{
+			input: testInput{keys: repos("org"), body: models.Bridge{Period: period, Top: 3}},
+			want: testWant{
+				code:    http.StatusOK,
+				message: `"top_customers":{"churn":[{"CustomerID":0,"Amount":-100}]}`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(test.input.keys, test.input.body, nil)
+		CreateBridge(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message), w.Body.String())
+	}
+}
+
+func TestCalculateBridge(t *testing.T) {
+	mpp := []domain.MPP{
+		{CustomerID: 1, Months: []float32{100, 150, 200}},
+		{CustomerID: 2, Months: []float32{100, 0, 0}},
+		{CustomerID: 3, Months: []float32{0, 50, 50}},
+		{CustomerID: 4, Months: []float32{50, 0, 30}},
+		{CustomerID: 5, Months: []float32{80, 60, 40}},
+		{CustomerID: 6, Months: []float32{0, 0, 70}},
+	}
+	periods, err := calendar.Between(calendar.Monthly{FiscalYearStart: time.January}, time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+
+	bridge, top := calculateBridge(mpp, periods, 0)
+	assert.Nil(t, top)
+	assert.Equal(t, domain.MRRBridge{
+		StartMonth:   "1.2021",
+		EndMonth:     "3.2021",
+		StartPeriod:  "2021-01-01/2021-01-31",
+		EndPeriod:    "2021-03-01/2021-03-31",
+		StartMRR:     330,
+		New:          120,
+		Expansion:    100,
+		Reactivation: 30,
+		Contraction:  -40,
+		Churn:        -150,
+		EndMRR:       390,
+	}, bridge)
+	assert.Equal(t, bridge.EndMRR, bridge.StartMRR+bridge.New+bridge.Expansion+bridge.Reactivation+bridge.Contraction+bridge.Churn)
+
+	_, top = calculateBridge(mpp, periods, 1)
+	assert.Equal(t, map[string][]domain.CustomerMovement{
+		"new":          {{CustomerID: 6, Amount: 70}},
+		"expansion":    {{CustomerID: 1, Amount: 100}},
+		"reactivation": {{CustomerID: 4, Amount: 30}},
+		"contraction":  {{CustomerID: 5, Amount: -40}},
+		"churn":        {{CustomerID: 2, Amount: -100}},
+	}, top)
+
+	bridge, _ = calculateBridge(mpp, periods[:1], 0)
+	assert.Equal(t, bridge.StartMRR, bridge.EndMRR)
+}
@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/calendar"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	cacherepo "github.com/hackfeed/remrratality/backend/internal/store/cache_repo"
+	storagerepo "github.com/hackfeed/remrratality/backend/internal/store/storage_repo"
+)
+
+const (
+	comparePrevious = "previous"
+	compareLastYear = "last_year"
+	compareCustom   = "custom"
+)
+
+// createComparison computes analytics of the period req is compared with and compares mrr of
+// req period with it. The period having no invoices is compared as the one with zero mrr.
+func createComparison(ctx context.Context, storageRepo storagerepo.StorageRepository, cacheRepo cacherepo.CacheRepository, orgID string, req models.Analytics, mrr domain.TotalMRR) (domain.MRRComparison, error) {
+	periodStart, periodEnd, err := comparisonPeriod(calendar.FromContext(ctx), req)
+	if err != nil {
+		return domain.MRRComparison{}, err
+	}
+
+	periods, comparisonMRR, err := createAnalytics(ctx, storageRepo, cacheRepo, orgID, req.Filename, periodStart, periodEnd)
+	if errors.Is(err, errNoData) {
+		comparisonMRR, err = convertRawMRR(make([]domain.MRR, len(periods))), nil
+	}
+	if err != nil {
+		return domain.MRRComparison{}, fmt.Errorf("failed to get analytics of comparison period, error is: %s", err)
+	}
+
+	comparison := compareMRR(mrr, comparisonMRR)
+	comparison.Months = calendar.Labels(periods)
+	comparison.Periods = calendar.ISOLabels(periods)
+	return comparison, nil
+}
+
+// comparisonPeriod returns dates of the period req is compared with. Previous and last year
+// periods start on the first day of reporting period of cal, as many periods as req period has.
+// Last year periods are the ones containing middles of req periods a year ago, so they stay
+// aligned when lengths of years differ.
+func comparisonPeriod(cal calendar.Calendar, req models.Analytics) (string, string, error) {
+	if req.Compare.Mode == compareCustom {
+		return req.Compare.PeriodStart, req.Compare.PeriodEnd, nil
+	}
+
+	periodStart, periodEnd, err := parsePeriod(req.PeriodStart, req.PeriodEnd)
+	if err != nil {
+		return "", "", err
+	}
+	periods, err := calendar.Between(cal, periodStart, periodEnd)
+	if err != nil {
+		return "", "", err
+	}
+	first, last := periods[0], periods[len(periods)-1]
+
+	if req.Compare.Mode == compareLastYear {
+		start, err := cal.Period(middle(first).AddDate(-1, 0, 0))
+		if err != nil {
+			return "", "", err
+		}
+		end, err := cal.Period(middle(last).AddDate(-1, 0, 0))
+		if err != nil {
+			return "", "", err
+		}
+		return start.Start.Format(layout), end.Start.Format(layout), nil
+	}
+	previous, err := calendar.Before(cal, first, len(periods))
+	if err != nil {
+		return "", "", err
+	}
+	return previous[0].Start.Format(layout), previous[len(previous)-1].Start.Format(layout), nil
+}
+
+// middle returns the middle day of period.
+func middle(period calendar.Period) time.Time {
+	days := int(period.End.Sub(period.Start).Hours() / 24)
+	return period.Start.AddDate(0, 0, days/2)
+}
+
+// compareMRR compares every series of mrr with the one of comparison month by month.
+func compareMRR(mrr, comparison domain.TotalMRR) domain.MRRComparison {
+	compared := domain.MRRComparison{
+		MRR:            comparison,
+		CMGR:           cmgr(mrr.Total),
+		ComparisonCMGR: cmgr(comparison.Total),
+	}
+
+	for _, series := range []struct {
+		primary, comparison []float32
+		delta, percent      *[]float32
+	}{
+		{mrr.New, comparison.New, &compared.Delta.New, &compared.DeltaPercent.New},
+		{mrr.Old, comparison.Old, &compared.Delta.Old, &compared.DeltaPercent.Old},
+		{mrr.Reactivation, comparison.Reactivation, &compared.Delta.Reactivation, &compared.DeltaPercent.Reactivation},
+		{mrr.Expansion, comparison.Expansion, &compared.Delta.Expansion, &compared.DeltaPercent.Expansion},
+		{mrr.Contraction, comparison.Contraction, &compared.Delta.Contraction, &compared.DeltaPercent.Contraction},
+		{mrr.Churn, comparison.Churn, &compared.Delta.Churn, &compared.DeltaPercent.Churn},
+		{mrr.Total, comparison.Total, &compared.Delta.Total, &compared.DeltaPercent.Total},
+	} {
+		monthsCount := len(series.primary)
+		if len(series.comparison) < monthsCount {
+			monthsCount = len(series.comparison)
+		}
+		*series.delta = make([]float32, monthsCount)
+		*series.percent = make([]float32, monthsCount)
+
+		for i := 0; i < monthsCount; i++ {
+			delta := series.primary[i] - series.comparison[i]
+			(*series.delta)[i] = delta
+			if series.comparison[i] != 0 {
+				// Magnitude keeps the sign of delta for negative series, like churn.
+				(*series.percent)[i] = delta / float32(math.Abs(float64(series.comparison[i]))) * 100
+			}
+		}
+	}
+
+	return compared
+}
+
+// cmgr is compound monthly growth rate of series from its first month to the last one in percents.
+func cmgr(series []float32) float32 {
+	if len(series) < 2 || series[0] <= 0 || series[len(series)-1] < 0 {
+		return 0
+	}
+
+	growth := float64(series[len(series)-1]) / float64(series[0])
+	return float32((math.Pow(growth, 1/float64(len(series)-1)) - 1) * 100)
+}
@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/calendar"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	cacherepo "github.com/hackfeed/remrratality/backend/internal/store/cache_repo"
+	datasetrepo "github.com/hackfeed/remrratality/backend/internal/store/dataset_repo"
+	storagerepo "github.com/hackfeed/remrratality/backend/internal/store/storage_repo"
+	internalTesting "github.com/hackfeed/remrratality/backend/internal/utils/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAnalyticsHandlerComparison(t *testing.T) {
+	type testInput struct {
+		body interface{}
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	period := models.Period{Filename: "file.csv", PeriodStart: "2021-10-01", PeriodEnd: "2021-11-01"}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{body: models.Analytics{Period: period, Compare: &models.Comparison{Mode: "quarter"}}},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Failed to parse request body",
+			},
+		},
+		{
+			input: testInput{body: models.Analytics{Period: period, Compare: &models.Comparison{Mode: "custom"}}},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Failed to parse request body",
+			},
+		},
+		{
+			input: testInput{body: models.Analytics{
+				Period:  period,
+				Compare: &models.Comparison{Mode: "custom", PeriodStart: "2021-11-01", PeriodEnd: "2021-10-01"},
+			}},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Failed to get analytics",
+			},
+		},
+		{
+			input: testInput{body: models.Analytics{Period: period, Compare: &models.Comparison{Mode: "previous"}}},
+			want: testWant{
+				code:    http.StatusOK,
+				message: `"comparison":{"Months":["8.2021","9.2021"],"Periods":["2021-08-01/2021-08-31","2021-09-01/2021-09-30"],"MRR":{"New":[0,0],"Old":[0,0],"Reactivation":[0,0],"Expansion":[0,0],"Contraction":[0,0],"Churn":[0,0],"Total":[0,0]},"Delta":{"New":[100,0],"Old":[0,0],"Reactivation":[0,0],"Expansion":[0,0],"Contraction":[0,0],"Churn":[0,-100],"Total":[100,-100]},"DeltaPercent":{"New":[0,0],"Old":[0,0],"Reactivation":[0,0],"Expansion":[0,0],"Contraction":[0,0],"Churn":[0,0],"Total":[0,0]},"CMGR":0,"ComparisonCMGR":0}`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(map[string]interface{}{
+			"org_id":       "org",
+			"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+			"storage_repo": &storagerepo.StorageRepositoryMock{},
+			"cache_repo":   &cacherepo.CacheRepositoryMock{},
+		}, test.input.body, nil)
+		CreateAnalytics(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message), w.Body.String())
+	}
+}
+
+func TestCreateComparison(t *testing.T) {
+	mrr := domain.TotalMRR{
+		New:          []float32{100, 0},
+		Old:          []float32{0, 100},
+		Reactivation: []float32{0, 0},
+		Expansion:    []float32{0, 0},
+		Contraction:  []float32{0, 0},
+		Churn:        []float32{0, 0},
+		Total:        []float32{100, 100},
+	}
+	req := models.Analytics{
+		Period:  models.Period{Filename: "file", PeriodStart: "2021-10-01", PeriodEnd: "2021-11-01"},
+		Compare: &models.Comparison{Mode: comparePrevious},
+	}
+
+	// Comparison period without invoices is compared as the one with zero mrr.
+	compared, err := createComparison(context.Background(), &storagerepo.StorageRepositoryMock{}, &cacherepo.CacheRepositoryMock{}, "emptyGetInvoicesByPeriod", req, mrr)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"8.2021", "9.2021"}, compared.Months)
+	assert.Equal(t, []float32{0, 0}, compared.MRR.Total)
+	assert.Equal(t, mrr.Total, compared.Delta.Total)
+	assert.Equal(t, []float32{0, 0}, compared.DeltaPercent.Total)
+
+	_, err = createComparison(context.Background(), &storagerepo.StorageRepositoryMock{}, &cacherepo.CacheRepositoryMock{}, "errorGetInvoicesByPeriod", req, mrr)
+	assert.Error(t, err)
+}
+
+func TestComparisonPeriod(t *testing.T) {
+	type testWant struct {
+		periodStart, periodEnd string
+		err                    error
+	}
+
+	tests := []struct {
+		input models.Analytics
+		want  testWant
+	}{
+		{
+			input: models.Analytics{
+				Period:  models.Period{PeriodStart: "2021-10-15", PeriodEnd: "2021-12-01"},
+				Compare: &models.Comparison{Mode: comparePrevious},
+			},
+			want: testWant{periodStart: "2021-07-01", periodEnd: "2021-09-01"},
+		},
+		{
+			input: models.Analytics{
+				Period:  models.Period{PeriodStart: "2021-01-01", PeriodEnd: "2021-01-01"},
+				Compare: &models.Comparison{Mode: comparePrevious},
+			},
+			want: testWant{periodStart: "2020-12-01", periodEnd: "2020-12-01"},
+		},
+		{
+			input: models.Analytics{
+				Period:  models.Period{PeriodStart: "2020-02-29", PeriodEnd: "2021-03-31"},
+				Compare: &models.Comparison{Mode: compareLastYear},
+			},
+			want: testWant{periodStart: "2019-02-01", periodEnd: "2020-03-01"},
+		},
+		{
+			input: models.Analytics{
+				Period:  models.Period{PeriodStart: "2021-01-01", PeriodEnd: "2021-03-01"},
+				Compare: &models.Comparison{Mode: compareCustom, PeriodStart: "2019-05-01", PeriodEnd: "2019-06-01"},
+			},
+			want: testWant{periodStart: "2019-05-01", periodEnd: "2019-06-01"},
+		},
+		{
+			input: models.Analytics{
+				Period:  models.Period{PeriodStart: "2021-03-01", PeriodEnd: "2021-01-01"},
+				Compare: &models.Comparison{Mode: compareLastYear},
+			},
+			want: testWant{err: errors.New("period start should be less than period end")},
+		},
+	}
+
+	for _, test := range tests {
+		periodStart, periodEnd, err := comparisonPeriod(calendar.Monthly{FiscalYearStart: time.January}, test.input)
+		assert.Equal(t, test.want.err, err)
+		assert.Equal(t, test.want.periodStart, periodStart)
+		assert.Equal(t, test.want.periodEnd, periodEnd)
+	}
+}
+
+func TestCompareMRR(t *testing.T) {
+	mrr := domain.TotalMRR{
+		New:          []float32{200, 100, 50},
+		Old:          []float32{0, 200, 300},
+		Reactivation: []float32{0, 0, 0},
+		Expansion:    []float32{0, 10, 0},
+		Contraction:  []float32{0, 0, 0},
+		Churn:        []float32{0, -10, -20},
+		Total:        []float32{200, 300, 330},
+	}
+	comparison := domain.TotalMRR{
+		New:          []float32{100, 100},
+		Old:          []float32{0, 100},
+		Reactivation: []float32{0, 0},
+		Expansion:    []float32{0, 0},
+		Contraction:  []float32{0, 0},
+		Churn:        []float32{0, -20},
+		Total:        []float32{100, 180},
+	}
+
+	compared := compareMRR(mrr, comparison)
+
+	assert.Equal(t, comparison, compared.MRR)
+	assert.Equal(t, []float32{100, 0}, compared.Delta.New)
+	assert.Equal(t, []float32{100, 0}, compared.DeltaPercent.New)
+	assert.Equal(t, []float32{0, 100}, compared.DeltaPercent.Old)
+	assert.Equal(t, []float32{0, 10}, compared.Delta.Churn)
+	assert.Equal(t, []float32{0, 50}, compared.DeltaPercent.Churn)
+	assert.Equal(t, []float32{0, 0}, compared.DeltaPercent.Expansion)
+	assert.InDeltaSlice(t, []float32{100, 66.667}, compared.DeltaPercent.Total, 0.001)
+	assert.InDelta(t, 28.452, compared.CMGR, 0.001)
+	assert.InDelta(t, 80, compared.ComparisonCMGR, 0.001)
+}
+
+func TestCMGR(t *testing.T) {
+	assert.InDelta(t, 10, cmgr([]float32{100, 110, 121}), 0.001)
+	assert.InDelta(t, -100, cmgr([]float32{100, 50, 0}), 0.001)
+	assert.Equal(t, float32(0), cmgr([]float32{100}))
+	assert.Equal(t, float32(0), cmgr([]float32{0, 100}))
+	assert.Equal(t, float32(0), cmgr([]float32{100, -50}))
+}
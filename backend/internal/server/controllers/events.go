@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// streamDeadlineMargin is how long before write deadline of the connection stream is ended, so
+// it's ended with the last event instead of being cut off.
+var streamDeadlineMargin = 5 * time.Second
+
+type shutdownKey struct{}
+
+// WithShutdown returns context requests are served with, their event streams end once shutdown
+// is closed, so open streams don't hold graceful shutdown for the whole grace period.
+func WithShutdown(ctx context.Context, shutdown <-chan struct{}) context.Context {
+	return context.WithValue(ctx, shutdownKey{}, shutdown)
+}
+
+// shuttingDown returns channel closed once server starts shutting down, it's nil outside of server.
+func shuttingDown(ctx context.Context) <-chan struct{} {
+	shutdown, _ := ctx.Value(shutdownKey{}).(<-chan struct{})
+	return shutdown
+}
+
+// streamDeadline returns channel receiving before write deadline of the connection, which is set
+// when request is read, expires. Channel is nil when server has no write timeout, stop releases it.
+func streamDeadline(ctx context.Context) (<-chan time.Time, func()) {
+	srv, ok := ctx.Value(http.ServerContextKey).(*http.Server)
+	if !ok || srv.WriteTimeout <= 0 {
+		return nil, func() {}
+	}
+
+	timeout := srv.WriteTimeout - streamDeadlineMargin
+	if timeout < srv.WriteTimeout/2 {
+		timeout = srv.WriteTimeout / 2
+	}
+	timer := time.NewTimer(timeout)
+
+	return timer.C, func() { timer.Stop() }
+}
+
+// startEventStream sends headers of Server-Sent Events stream. Proxy buffering is disabled,
+// so events reach the client as soon as they're written.
+func startEventStream(c *gin.Context) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+}
+
+// writeEvent sends event with data encoded as JSON, id is omitted when it's empty.
+func writeEvent(c *gin.Context, id, name string, data interface{}) {
+	c.Render(-1, sse.Event{Id: id, Event: name, Data: data})
+	c.Writer.Flush()
+}
+
+// writeKeepAlive sends comment, so proxies don't close idle stream.
+func writeKeepAlive(c *gin.Context) {
+	// nolint
+	c.Writer.WriteString(": keep-alive\n\n")
+	c.Writer.Flush()
+}
@@ -1,62 +1,98 @@
 package controllers
 
 import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
-	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gocarina/gocsv"
 	"github.com/google/uuid"
 	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/jobs"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
 	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	datasetrepo "github.com/hackfeed/remrratality/backend/internal/store/dataset_repo"
 	storagerepo "github.com/hackfeed/remrratality/backend/internal/store/storage_repo"
-	userrepo "github.com/hackfeed/remrratality/backend/internal/store/user_repo"
-	log "github.com/sirupsen/logrus"
+	"github.com/hackfeed/remrratality/backend/internal/webhooks"
 )
 
-type Invoice struct {
-	CustomerID  uint32  `csv:"customer_id"`
-	PeriodStart string  `csv:"period_start"`
-	PaidPlan    string  `csv:"paid_plan"`
-	PaidAmount  float32 `csv:"paid_amount"`
-	PeriodEnd   string  `csv:"period_end"`
-}
+var (
+	uploadLayout  = "02.01.2006"
+	invoiceHeader = []string{"customer_id", "period_start", "paid_plan", "paid_amount", "period_end"}
+	unsafeChars   = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
 
 // LoadFiles godoc
 // @Summary Loading user's invoices files list
-// @Description Loading invoices files' names, uploaded by user
+// @Description Loading datasets uploaded to organization, filtered by label, display name and upload date
 // @Tags files
 // @Accept  json
 // @Produce  json
 // @Success 200 {object} models.ResponseSuccessLoadFiles
+// @Failure 400 {object} models.Response
 // @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 404 {object} models.Response
 // @Failure 500 {object} models.Response
 // @Security ApiKeyAuth
+// @Param org header string false "Organization ID, personal organization is used if omitted"
+// @Param label query string false "Label datasets should have"
+// @Param name query string false "Case insensitive substring of display name"
+// @Param uploaded_from query string false "Earliest upload date" example(2021-10-01)
+// @Param uploaded_to query string false "Latest upload date, inclusive" example(2021-10-31)
+// @Param sort query string false "Sort field, prefixed with - for descending order" Enums(name, -name, uploaded_at, -uploaded_at)
 // @Router /files [get]
 func LoadFiles(c *gin.Context) {
-	email, ok := c.MustGet("email").(string)
+	logger := logging.FromContext(c.Request.Context())
+
+	orgID, ok := c.MustGet("org_id").(string)
 	if !ok {
-		log.Errorf("failed to get email from gin.Context")
+		logger.Errorf("failed to get org_id from gin.Context")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
-			Message: "Unable to determine logged in user",
+			Message: "Unable to determine organization",
 		})
 		return
 	}
-	userRepo, ok := c.MustGet("user_repo").(userrepo.UserRepository)
+	datasetRepo, ok := c.MustGet("dataset_repo").(datasetrepo.DatasetRepository)
 	if !ok {
-		log.Errorf("failed to get user_repo from gin.Context")
+		logger.Errorf("failed to get dataset_repo from gin.Context")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
-			Message: "Failed to get user_repo",
+			Message: "Failed to get dataset_repo",
+		})
+		return
+	}
+
+	var req models.DatasetQuery
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.Errorf("failed to parse query parameters, error is: %s", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Message: "Failed to parse query parameters",
 		})
 		return
 	}
 
-	files, err := loadFiles(userRepo, email)
+	query, err := parseDatasetQuery(req)
 	if err != nil {
-		log.Errorf("failed to load files for email %s, error is: %s", email, err)
+		logger.Errorf("failed to parse query parameters, error is: %s", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Message: "Failed to parse query parameters",
+		})
+		return
+	}
+
+	files, err := loadFiles(c.Request.Context(), datasetRepo, orgID, query)
+	if err != nil {
+		logger.Errorf("failed to load files for org_id %s, error is: %s", orgID, err)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
 			Message: "Failed to fetch user files",
 		})
@@ -69,261 +105,571 @@ func LoadFiles(c *gin.Context) {
 	})
 }
 
-// DeleteFileContent godoc
-// @Summary Deleting user's invoices file's content
-// @Description Deleting invoices linked to file from database
+// GetFile godoc
+// @Summary Loading invoices file's metadata
+// @Description Loading dataset with its row and customer counts, period start range, total amount and import status
 // @Tags files
 // @Accept  json
 // @Produce  json
-// @Success 200 {object} models.Response
-// @Failure 400 {object} models.Response
+// @Success 200 {object} models.ResponseSuccessGetFile
 // @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 404 {object} models.Response
 // @Failure 500 {object} models.Response
 // @Security ApiKeyAuth
-// @Param filename path string true "Invoice file to delete"
-// @Router /files/{filename} [delete]
-func DeleteFileContent(c *gin.Context) {
-	email, ok := c.MustGet("email").(string)
+// @Param id path string true "Invoice file ID"
+// @Param org header string false "Organization ID, personal organization is used if omitted"
+// @Router /files/{id} [get]
+func GetFile(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	orgID, ok := c.MustGet("org_id").(string)
 	if !ok {
-		log.Errorf("failed to get email from gin.Context")
+		logger.Errorf("failed to get org_id from gin.Context")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
-			Message: "Unable to determine logged in user",
+			Message: "Unable to determine organization",
 		})
 		return
 	}
-	userID, ok := c.MustGet("user_id").(string)
+	datasetRepo, ok := c.MustGet("dataset_repo").(datasetrepo.DatasetRepository)
 	if !ok {
-		log.Errorf("failed to get user_id from gin.Context")
+		logger.Errorf("failed to get dataset_repo from gin.Context")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
-			Message: "Unable to determine logged in user",
+			Message: "Failed to get dataset_repo",
 		})
 		return
 	}
-	userRepo, ok := c.MustGet("user_repo").(userrepo.UserRepository)
+
+	name := c.Param("id")
+
+	file, err := datasetRepo.GetDataset(c.Request.Context(), orgID, name)
+	if errors.Is(err, datasetrepo.ErrNotFound) {
+		logger.Infof("file %s of org_id %s doesn't exist", name, orgID)
+		c.AbortWithStatusJSON(http.StatusNotFound, models.Response{
+			Message: "File not found",
+		})
+		return
+	}
+	if err != nil {
+		logger.Errorf("failed to get file %s for org_id %s, error is: %s", name, orgID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to fetch file",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseSuccessGetFile{
+		Message: "File is loaded",
+		File:    file,
+	})
+}
+
+// UpdateFile godoc
+// @Summary Updating invoices file's metadata
+// @Description Renaming dataset, changing its description or replacing its labels, omitted fields are left untouched
+// @Tags files
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} models.ResponseSuccessGetFile
+// @Failure 400 {object} models.Response
+// @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 404 {object} models.Response
+// @Failure 500 {object} models.Response
+// @Security ApiKeyAuth
+// @Param id path string true "Invoice file ID"
+// @Param request body models.DatasetUpdate true "Dataset fields to change"
+// @Param org header string false "Organization ID, personal organization is used if omitted"
+// @Router /files/{id} [patch]
+func UpdateFile(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	orgID, ok := c.MustGet("org_id").(string)
 	if !ok {
-		log.Errorf("failed to get user_repo from gin.Context")
+		logger.Errorf("failed to get org_id from gin.Context")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
-			Message: "Failed to get user_repo",
+			Message: "Unable to determine organization",
 		})
 		return
 	}
-	storageRepo, ok := c.MustGet("storage_repo").(storagerepo.StorageRepository)
+	datasetRepo, ok := c.MustGet("dataset_repo").(datasetrepo.DatasetRepository)
 	if !ok {
-		log.Errorf("failed to get storage_repo from gin.Context")
+		logger.Errorf("failed to get dataset_repo from gin.Context")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
-			Message: "Failed to get storage_repo",
+			Message: "Failed to get dataset_repo",
 		})
 		return
 	}
 
-	filename := c.Param("filename")
+	var req models.DatasetUpdate
 
-	if err := deleteFileContent(userRepo, storageRepo, email, userID, filename); err != nil {
-		log.Errorf("failed to delete file %s for email %s, user_id %s, error is: %s", filename, email, userID, err)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Errorf("failed to parse request body, error is: %s", err)
 		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
-			Message: "Failed to delete file",
+			Message: "Failed to parse request body",
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, models.Response{
-		Message: "File deleted",
+	update, err := parseDatasetUpdate(req)
+	if err != nil {
+		logger.Errorf("failed to parse dataset update, error is: %s", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Message: "Nothing to update. Please provide non-empty display name, description or labels",
+		})
+		return
+	}
+
+	name := c.Param("id")
+
+	file, err := datasetRepo.UpdateDataset(c.Request.Context(), orgID, name, update)
+	if errors.Is(err, datasetrepo.ErrNotFound) {
+		logger.Infof("file %s of org_id %s doesn't exist", name, orgID)
+		c.AbortWithStatusJSON(http.StatusNotFound, models.Response{
+			Message: "File not found",
+		})
+		return
+	}
+	if err != nil {
+		logger.Errorf("failed to update file %s for org_id %s, error is: %s", name, orgID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to update file",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseSuccessGetFile{
+		Message: "File is updated",
+		File:    file,
 	})
 }
 
-// SaveFileContent godoc
-// @Summary Saving user's file's content
-// @Description Saving file locally, parsing its content to database and deleting it from the server
+// ExportFile godoc
+// @Summary Downloading invoices file's content
+// @Description Streaming stored invoices as CSV in the upload format, gzip-compressed if requested
 // @Tags files
-// @Accept  json
-// @Produce  json
-// @Success 200 {object} models.ResponseSuccessSaveFileContent
+// @Produce  text/csv
+// @Produce  application/gzip
+// @Success 200 {file} file
 // @Failure 400 {object} models.Response
 // @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 404 {object} models.Response
+// @Failure 409 {object} models.Response
 // @Failure 500 {object} models.Response
 // @Security ApiKeyAuth
-// @Param file formData file true "File to upload"
-// @Router /files [post]
-func SaveFileContent(c *gin.Context) {
-	email, ok := c.MustGet("email").(string)
+// @Param id path string true "Invoice file ID"
+// @Param gzip query bool false "Compress file with gzip"
+// @Param org header string false "Organization ID, personal organization is used if omitted"
+// @Router /files/{id}/export [get]
+func ExportFile(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	orgID, ok := c.MustGet("org_id").(string)
 	if !ok {
-		log.Errorf("failed to get email from gin.Context")
+		logger.Errorf("failed to get org_id from gin.Context")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
-			Message: "Unable to determine logged in user",
+			Message: "Unable to determine organization",
 		})
 		return
 	}
-	userID, ok := c.MustGet("user_id").(string)
+	datasetRepo, ok := c.MustGet("dataset_repo").(datasetrepo.DatasetRepository)
+	if !ok {
+		logger.Errorf("failed to get dataset_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get dataset_repo",
+		})
+		return
+	}
+	storageRepo, ok := c.MustGet("storage_repo").(storagerepo.StorageRepository)
+	if !ok {
+		logger.Errorf("failed to get storage_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get storage_repo",
+		})
+		return
+	}
+
+	compress := false
+	if value := c.Query("gzip"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			logger.Errorf("failed to parse gzip query parameter, error is: %s", err)
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+				Message: "Failed to parse query parameters",
+			})
+			return
+		}
+		compress = parsed
+	}
+
+	name := c.Param("id")
+
+	file, ok := readyDataset(c, datasetRepo, orgID, name)
+	if !ok {
+		return
+	}
+
+	filename := exportFilename(file)
+	contentType := "text/csv"
+	if compress {
+		filename += ".gz"
+		contentType = "application/gzip"
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Header("Content-Type", contentType)
+	c.Status(http.StatusOK)
+
+	if !compress {
+		if err := writeInvoices(c.Request.Context(), c.Writer, storageRepo, orgID, name); err != nil {
+			logger.Errorf("failed to export file %s for org_id %s, error is: %s", name, orgID, err)
+			c.Abort()
+		}
+		return
+	}
+
+	// Headers are already sent when streaming fails, so gzip stream is left unterminated
+	// to let client know the file is incomplete.
+	gz := gzip.NewWriter(c.Writer)
+	if err := writeInvoices(c.Request.Context(), gz, storageRepo, orgID, name); err != nil {
+		logger.Errorf("failed to export file %s for org_id %s, error is: %s", name, orgID, err)
+		c.Abort()
+		return
+	}
+	if err := gz.Close(); err != nil {
+		logger.Errorf("failed to finish gzip stream of file %s for org_id %s, error is: %s", name, orgID, err)
+	}
+}
+
+// DeleteFileContent godoc
+// @Summary Deleting user's invoices file's content
+// @Description Deleting invoices linked to file from database
+// @Tags files
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.Response
+// @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 404 {object} models.Response
+// @Failure 500 {object} models.Response
+// @Security ApiKeyAuth
+// @Param id path string true "Invoice file ID to delete"
+// @Param org header string false "Organization ID, personal organization is used if omitted"
+// @Router /files/{id} [delete]
+func DeleteFileContent(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	orgID, ok := c.MustGet("org_id").(string)
 	if !ok {
-		log.Errorf("failed to get user_id from gin.Context")
+		logger.Errorf("failed to get org_id from gin.Context")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
-			Message: "Unable to determine logged in user",
+			Message: "Unable to determine organization",
 		})
 		return
 	}
-	userRepo, ok := c.MustGet("user_repo").(userrepo.UserRepository)
+	datasetRepo, ok := c.MustGet("dataset_repo").(datasetrepo.DatasetRepository)
 	if !ok {
-		log.Errorf("failed to get user_repo from gin.Context")
+		logger.Errorf("failed to get dataset_repo from gin.Context")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
-			Message: "Failed to get user_repo",
+			Message: "Failed to get dataset_repo",
 		})
 		return
 	}
 	storageRepo, ok := c.MustGet("storage_repo").(storagerepo.StorageRepository)
 	if !ok {
-		log.Errorf("failed to get storage_repo from gin.Context")
+		logger.Errorf("failed to get storage_repo from gin.Context")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
 			Message: "Failed to get storage_repo",
 		})
 		return
 	}
-
-	file, err := c.FormFile("file")
-	if err != nil {
-		log.Errorf("failed to get file from formFile")
-		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
-			Message: "No file is received",
+	notifier, ok := c.MustGet("webhooks").(webhooks.Notifier)
+	if !ok {
+		logger.Errorf("failed to get webhooks from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get webhooks",
 		})
 		return
 	}
 
-	fext := filepath.Ext(file.Filename)
-	if fext != ".csv" {
-		log.Errorf("non csv files are not allowed, given %s", fext)
+	filename := c.Param("id")
+	c.Set("audit_details", map[string]interface{}{"filename": filename})
+
+	err := deleteFileContent(c.Request.Context(), datasetRepo, storageRepo, orgID, filename)
+	if errors.Is(err, datasetrepo.ErrNotFound) {
+		logger.Infof("file %s of org_id %s doesn't exist", filename, orgID)
+		c.AbortWithStatusJSON(http.StatusNotFound, models.Response{
+			Message: "File not found",
+		})
+		return
+	}
+	if err != nil {
+		logger.Errorf("failed to delete file %s for org_id %s, error is: %s", filename, orgID, err)
 		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
-			Message: "Wrong file format. Please provide CSV file",
+			Message: "Failed to delete file",
 		})
 		return
 	}
 
-	filename := fmt.Sprintf("%v%v", uuid.New(), fext)
-	if err = c.SaveUploadedFile(file, fmt.Sprintf("/tmp/%s", filename)); err != nil {
-		log.Errorf("unable to save file %s, error is: %s", filename, err)
+	if err := notifier.Notify(c.Request.Context(), orgID, domain.WebhookDatasetDeleted, domain.DatasetEvent{DatasetID: filename}); err != nil {
+		logger.Errorf("failed to notify webhooks about deleted file %s of org_id %s, error is: %s", filename, orgID, err)
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Message: "File deleted",
+	})
+}
+
+// SaveFileContent godoc
+// @Summary Saving user's file's content
+// @Description Saving file and queueing job parsing its content to database, job's progress is available at /jobs/{id}
+// @Tags files
+// @Accept  json
+// @Produce  json
+// @Success 202 {object} models.ResponseSuccessSaveFileContent
+// @Failure 400 {object} models.Response
+// @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 404 {object} models.Response
+// @Failure 429 {object} models.Response
+// @Header 429 {integer} Retry-After "Seconds to wait before the next request"
+// @Failure 500 {object} models.Response
+// @Security ApiKeyAuth
+// @Param file formData file true "File to upload"
+// @Param org header string false "Organization ID, personal organization is used if omitted"
+// @Router /files [post]
+func SaveFileContent(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	orgID, ok := c.MustGet("org_id").(string)
+	if !ok {
+		logger.Errorf("failed to get org_id from gin.Context")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
-			Message: "Unable to save the file",
+			Message: "Unable to determine organization",
 		})
 		return
 	}
-
-	csvFile, err := os.Open(fmt.Sprintf("/tmp/%s", filename))
-	if err != nil {
-		log.Errorf("unable to open file at /tmp/%s, error is: %s", filename, err)
+	queue, ok := c.MustGet("jobs").(jobs.Queue)
+	if !ok {
+		logger.Errorf("failed to get jobs from gin.Context")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
-			Message: "Failed to find data with given id",
+			Message: "Failed to get jobs",
 		})
 		return
 	}
-	defer csvFile.Close()
-
-	var invoices []*Invoice
 
-	if err = gocsv.UnmarshalFile(csvFile, &invoices); err != nil {
-		log.Errorf("unable to unmarshal %s, error is: %s", filename, err)
+	file, err := c.FormFile("file")
+	if err != nil {
+		logger.Errorf("failed to get file from formFile")
 		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
-			Message: "Failed to parse given CSV file",
+			Message: "No file is received",
 		})
 		return
 	}
 
-	if err = uploadFileContent(storageRepo, userID, filename, invoices); err != nil {
-		log.Errorf("unable to upload invoices for email %s, user_id %s, error is: %s", email, userID, err)
+	fext := filepath.Ext(file.Filename)
+	if fext != ".csv" {
+		logger.Errorf("non csv files are not allowed, given %s", fext)
 		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
-			Message: "Failed to upload data to database",
+			Message: "Wrong file format. Please provide CSV file",
 		})
 		return
 	}
 
-	if err := os.Remove(fmt.Sprintf("/tmp/%s", filename)); err != nil {
-		log.Errorf("failed to remove local file, error is: %s", err)
-		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
-			Message: "Failed to remove uploaded file after processing",
+	src, err := file.Open()
+	if err != nil {
+		logger.Errorf("unable to open uploaded file, error is: %s", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Unable to save the file",
 		})
 		return
 	}
+	defer src.Close()
 
-	if err = updateFiles(userRepo, email, userID, filename); err != nil {
-		log.Errorf("unable to update files for email %s, user_id %s, error is: %s", email, userID, err)
+	filename := fmt.Sprintf("%v%v", uuid.New(), fext)
+	job, err := queue.SubmitUpload(c.Request.Context(), domain.Job{
+		OrgID:            orgID,
+		UserID:           c.GetString("user_id"),
+		OriginalFilename: file.Filename,
+		DatasetID:        filename,
+	}, src)
+	if err != nil {
+		logger.Errorf("unable to queue upload of file %s for org_id %s, error is: %s", filename, orgID, err)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
-			Message: "Unable to update user in db",
+			Message: "Unable to save the file",
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, models.ResponseSuccessSaveFileContent{
-		Message:  "File is uploaded",
+	c.Set("audit_details", map[string]interface{}{
+		"filename":          filename,
+		"original_filename": file.Filename,
+		"job_id":            job.ID,
+	})
+
+	c.JSON(http.StatusAccepted, models.ResponseSuccessSaveFileContent{
+		Message:  "File is queued for processing",
 		Filename: filename,
+		Job:      job,
 	})
 }
 
-func loadFiles(userRepo userrepo.UserRepository, email string) ([]domain.File, error) {
-	user, err := userRepo.GetUser(email)
+func loadFiles(ctx context.Context, datasetRepo datasetrepo.DatasetRepository, orgID string, query domain.DatasetQuery) ([]domain.Dataset, error) {
+	files, err := datasetRepo.GetDatasets(ctx, orgID, query)
 	if err != nil {
-		return []domain.File{}, fmt.Errorf("failed to get user, error is: %s", err)
+		return []domain.Dataset{}, fmt.Errorf("failed to get datasets, error is: %s", err)
 	}
 
-	return user.Files, nil
+	return files, nil
 }
 
-func deleteFileContent(userRepo userrepo.UserRepository, storageRepo storagerepo.StorageRepository, email, userID, filename string) error {
-	user, err := userRepo.GetUser(email)
-	if err != nil {
-		return fmt.Errorf("failed to get user, error is: %s", err)
+func parseDatasetQuery(req models.DatasetQuery) (domain.DatasetQuery, error) {
+	query := domain.DatasetQuery{
+		Label:  strings.TrimSpace(req.Label),
+		Name:   strings.TrimSpace(req.Name),
+		SortBy: strings.TrimPrefix(req.Sort, "-"),
+		Desc:   strings.HasPrefix(req.Sort, "-"),
 	}
 
-	newFiles := make([]domain.File, 0)
-	for _, file := range user.Files {
-		if file.Name != filename {
-			newFiles = append(newFiles, file)
+	if req.UploadedFrom != "" {
+		uploadedFrom, err := time.Parse(layout, req.UploadedFrom)
+		if err != nil {
+			return query, fmt.Errorf("failed to parse uploaded_from, error is: %s", err)
 		}
+		query.UploadedFrom = uploadedFrom
+	}
+	if req.UploadedTo != "" {
+		uploadedTo, err := time.Parse(layout, req.UploadedTo)
+		if err != nil {
+			return query, fmt.Errorf("failed to parse uploaded_to, error is: %s", err)
+		}
+		query.UploadedTo = uploadedTo.AddDate(0, 0, 1)
 	}
 
-	user.Files = newFiles
-	if err = userRepo.UpdateUser(userID, user); err != nil {
-		return fmt.Errorf("failed to update user, error is: %s", err)
+	return query, nil
+}
+
+// parseDatasetUpdate trims given fields and removes empty and duplicate labels.
+func parseDatasetUpdate(req models.DatasetUpdate) (domain.DatasetUpdate, error) {
+	var update domain.DatasetUpdate
+
+	if req.DisplayName != nil {
+		displayName := strings.TrimSpace(*req.DisplayName)
+		if displayName == "" {
+			return update, errors.New("display name should not be empty")
+		}
+		update.DisplayName = &displayName
+	}
+	if req.Description != nil {
+		description := strings.TrimSpace(*req.Description)
+		update.Description = &description
+	}
+	if req.Labels != nil {
+		labels := make([]string, 0, len(*req.Labels))
+		seen := make(map[string]struct{})
+		for _, label := range *req.Labels {
+			label = strings.TrimSpace(label)
+			if _, ok := seen[label]; ok || label == "" {
+				continue
+			}
+			seen[label] = struct{}{}
+			labels = append(labels, label)
+		}
+		update.Labels = &labels
 	}
 
-	if err = storageRepo.DeleteInvoices(userID, filename); err != nil {
-		return fmt.Errorf("failed to delete ivoices from db, error is: %s", err)
+	if update.DisplayName == nil && update.Description == nil && update.Labels == nil {
+		return update, errors.New("no fields to update")
 	}
 
-	return nil
+	return update, nil
 }
 
-func updateFiles(userRepo userrepo.UserRepository, email, userID, filename string) error {
-	user, err := userRepo.GetUser(email)
+// readyDataset returns dataset of the org, request is aborted when dataset doesn't exist or it's
+// still being imported, as its invoices are only partially stored then.
+func readyDataset(c *gin.Context, datasetRepo datasetrepo.DatasetRepository, orgID, name string) (domain.Dataset, bool) {
+	logger := logging.FromContext(c.Request.Context())
+
+	file, err := datasetRepo.GetDataset(c.Request.Context(), orgID, name)
+	if errors.Is(err, datasetrepo.ErrNotFound) {
+		logger.Infof("file %s of org_id %s doesn't exist", name, orgID)
+		c.AbortWithStatusJSON(http.StatusNotFound, models.Response{
+			Message: "File not found",
+		})
+		return domain.Dataset{}, false
+	}
 	if err != nil {
-		return fmt.Errorf("failed to get user, error is: %s", err)
+		logger.Errorf("failed to get file %s for org_id %s, error is: %s", name, orgID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to fetch file",
+		})
+		return domain.Dataset{}, false
+	}
+	if file.Status != domain.DatasetReady {
+		logger.Infof("file %s of org_id %s is %s", name, orgID, file.Status)
+		c.AbortWithStatusJSON(http.StatusConflict, models.Response{
+			Message: "File is not imported yet",
+		})
+		return domain.Dataset{}, false
+	}
+
+	return file, true
+}
+
+// deleteFileContent deletes invoices before dataset, so the request may be retried if it fails halfway.
+func deleteFileContent(ctx context.Context, datasetRepo datasetrepo.DatasetRepository, storageRepo storagerepo.StorageRepository, orgID, filename string) error {
+	if _, err := datasetRepo.GetDataset(ctx, orgID, filename); err != nil {
+		return fmt.Errorf("failed to get dataset, error is: %w", err)
 	}
 
-	uploadedAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	user.Files = append(user.Files, domain.File{Name: filename, UploadedAt: uploadedAt})
+	if err := storageRepo.DeleteInvoices(ctx, orgID, filename); err != nil {
+		return fmt.Errorf("failed to delete ivoices from db, error is: %s", err)
+	}
 
-	if err = userRepo.UpdateUser(userID, user); err != nil {
-		return fmt.Errorf("failed to update user, error is: %s", err)
+	if err := datasetRepo.DeleteDataset(ctx, orgID, filename); err != nil {
+		return fmt.Errorf("failed to delete dataset, error is: %s", err)
 	}
 
 	return nil
 }
 
-func uploadFileContent(storageRepo storagerepo.StorageRepository, userID, fileID string, invoices []*Invoice) error {
-	mappedInvoices := make([]domain.Invoice, len(invoices))
-
-	for i, invoice := range invoices {
-		mappedInvoice := domain.Invoice{
-			UserID:      userID,
-			FileID:      fileID,
-			CustomerID:  invoice.CustomerID,
-			PeriodStart: invoice.PeriodStart,
-			PaidPlan:    invoice.PaidPlan,
-			PaidAmount:  invoice.PaidAmount,
-			PeriodEnd:   invoice.PeriodEnd,
-		}
-		mappedInvoices[i] = mappedInvoice
+// writeInvoices writes invoices of the file as CSV in the upload format.
+func writeInvoices(ctx context.Context, w io.Writer, storageRepo storagerepo.StorageRepository, orgID, fileID string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(invoiceHeader); err != nil {
+		return fmt.Errorf("failed to write header, error is: %s", err)
 	}
 
-	if _, err := storageRepo.AddInvoices(mappedInvoices); err != nil {
-		return fmt.Errorf("failed upload invoices to db, error is: %s", err)
+	err := storageRepo.StreamInvoices(ctx, orgID, fileID, func(invoice domain.Invoice) error {
+		periodStart, _ := time.Parse(layout, invoice.PeriodStart)
+		periodEnd, _ := time.Parse(layout, invoice.PeriodEnd)
+
+		return writer.Write([]string{
+			strconv.FormatUint(uint64(invoice.CustomerID), 10),
+			periodStart.Format(uploadLayout),
+			invoice.PaidPlan,
+			strconv.FormatFloat(float64(invoice.PaidAmount), 'f', -1, 32),
+			periodEnd.Format(uploadLayout),
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write invoices, error is: %s", err)
 	}
 
-	return nil
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// exportFilename builds file name from dataset's display name, keeping it safe for Content-Disposition header.
+func exportFilename(dataset domain.Dataset) string {
+	name := strings.TrimSuffix(dataset.DisplayName, filepath.Ext(dataset.DisplayName))
+	name = strings.Trim(unsafeChars.ReplaceAllString(name, "_"), "_")
+	if name == "" {
+		name = strings.TrimSuffix(dataset.Name, filepath.Ext(dataset.Name))
+	}
+
+	return name + ".csv"
 }
@@ -1,21 +1,29 @@
 package controllers
 
 import (
+	"compress/gzip"
+	"context"
 	"errors"
+	"io"
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	datasetrepo "github.com/hackfeed/remrratality/backend/internal/store/dataset_repo"
 	storagerepo "github.com/hackfeed/remrratality/backend/internal/store/storage_repo"
-	userrepo "github.com/hackfeed/remrratality/backend/internal/store/user_repo"
 	internalTesting "github.com/hackfeed/remrratality/backend/internal/utils/testing"
+	"github.com/hackfeed/remrratality/backend/internal/webhooks"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestLoadFilesHandler(t *testing.T) {
 	type testInput struct {
-		keys map[string]interface{}
+		keys  map[string]interface{}
+		query string
 	}
 	type testWant struct {
 		code    int
@@ -28,27 +36,53 @@ func TestLoadFilesHandler(t *testing.T) {
 	}{
 		{
 			input: testInput{keys: map[string]interface{}{
-				"email": 1,
+				"org_id": 1,
 			}},
 			want: testWant{
 				code:    http.StatusInternalServerError,
-				message: "Unable to determine logged in user",
+				message: "Unable to determine organization",
 			},
 		},
 		{
 			input: testInput{keys: map[string]interface{}{
-				"email":     "test@test.com",
-				"user_repo": "invalidRepo",
+				"org_id":       "org",
+				"dataset_repo": "invalidRepo",
 			}},
 			want: testWant{
 				code:    http.StatusInternalServerError,
-				message: "Failed to get user_repo",
+				message: "Failed to get dataset_repo",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+				},
+				query: "sort=size",
+			},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Failed to parse query parameters",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+				},
+				query: "uploaded_from=yesterday",
+			},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Failed to parse query parameters",
 			},
 		},
 		{
 			input: testInput{keys: map[string]interface{}{
-				"email":     "errorGetUser",
-				"user_repo": &userrepo.UserRepositoryMock{},
+				"org_id":       "errorGetDatasets",
+				"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
 			}},
 			want: testWant{
 				code:    http.StatusInternalServerError,
@@ -57,8 +91,8 @@ func TestLoadFilesHandler(t *testing.T) {
 		},
 		{
 			input: testInput{keys: map[string]interface{}{
-				"email":     "test@test.com",
-				"user_repo": &userrepo.UserRepositoryMock{},
+				"org_id":       "org",
+				"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
 			}},
 			want: testWant{
 				code:    http.StatusOK,
@@ -69,6 +103,7 @@ func TestLoadFilesHandler(t *testing.T) {
 
 	for _, test := range tests {
 		c, w := internalTesting.CreateGinContext(test.input.keys, nil, nil)
+		c.Request.URL.RawQuery = test.input.query
 		LoadFiles(c)
 		assert.Equal(t, test.want.code, w.Code)
 		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message))
@@ -77,7 +112,8 @@ func TestLoadFilesHandler(t *testing.T) {
 
 func TestDeleteFileContentHandler(t *testing.T) {
 	type testInput struct {
-		keys map[string]interface{}
+		keys   map[string]interface{}
+		params []gin.Param
 	}
 	type testWant struct {
 		code    int
@@ -90,65 +126,86 @@ func TestDeleteFileContentHandler(t *testing.T) {
 	}{
 		{
 			input: testInput{keys: map[string]interface{}{
-				"email": 1,
+				"org_id": 1,
 			}},
 			want: testWant{
 				code:    http.StatusInternalServerError,
-				message: "Unable to determine logged in user",
+				message: "Unable to determine organization",
 			},
 		},
 		{
 			input: testInput{keys: map[string]interface{}{
-				"email":   "test@test.com",
-				"user_id": 1,
+				"org_id":       "org",
+				"dataset_repo": "invalidRepo",
 			}},
 			want: testWant{
 				code:    http.StatusInternalServerError,
-				message: "Unable to determine logged in user",
+				message: "Failed to get dataset_repo",
 			},
 		},
 		{
 			input: testInput{keys: map[string]interface{}{
-				"email":     "test@test.com",
-				"user_id":   "id",
-				"user_repo": "invalidRepo",
+				"org_id":       "org",
+				"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+				"storage_repo": "invalidRepo",
 			}},
 			want: testWant{
 				code:    http.StatusInternalServerError,
-				message: "Failed to get user_repo",
+				message: "Failed to get storage_repo",
 			},
 		},
 		{
 			input: testInput{keys: map[string]interface{}{
-				"email":        "test@test.com",
-				"user_id":      "id",
-				"user_repo":    &userrepo.UserRepositoryMock{},
-				"storage_repo": "invalidRepo",
+				"org_id":       "org",
+				"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+				"storage_repo": &storagerepo.StorageRepositoryMock{},
+				"webhooks":     "invalidNotifier",
 			}},
 			want: testWant{
 				code:    http.StatusInternalServerError,
-				message: "Failed to get storage_repo",
+				message: "Failed to get webhooks",
 			},
 		},
 		{
-			input: testInput{keys: map[string]interface{}{
-				"email":        "errorGetUser",
-				"user_id":      "id",
-				"user_repo":    &userrepo.UserRepositoryMock{},
-				"storage_repo": &storagerepo.StorageRepositoryMock{},
-			}},
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+					"storage_repo": &storagerepo.StorageRepositoryMock{},
+					"webhooks":     &webhooks.NotifierMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "notFoundDataset"}},
+			},
+			want: testWant{
+				code:    http.StatusNotFound,
+				message: "File not found",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "errorDeleteInvoices",
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+					"storage_repo": &storagerepo.StorageRepositoryMock{},
+					"webhooks":     &webhooks.NotifierMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "file.csv"}},
+			},
 			want: testWant{
 				code:    http.StatusBadRequest,
 				message: "Failed to delete file",
 			},
 		},
 		{
-			input: testInput{keys: map[string]interface{}{
-				"email":        "user",
-				"user_id":      "user",
-				"user_repo":    &userrepo.UserRepositoryMock{},
-				"storage_repo": &storagerepo.StorageRepositoryMock{},
-			}},
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+					"storage_repo": &storagerepo.StorageRepositoryMock{},
+					"webhooks":     &webhooks.NotifierMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "file.csv"}},
+			},
 			want: testWant{
 				code:    http.StatusOK,
 				message: "File deleted",
@@ -157,19 +214,294 @@ func TestDeleteFileContentHandler(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		c, w := internalTesting.CreateGinContext(test.input.keys, nil, nil)
+		c, w := internalTesting.CreateGinContext(test.input.keys, nil, test.input.params)
 		DeleteFileContent(c)
 		assert.Equal(t, test.want.code, w.Code)
 		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message))
 	}
 }
 
+func TestGetFileHandler(t *testing.T) {
+	type testInput struct {
+		keys   map[string]interface{}
+		params []gin.Param
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id": 1,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine organization",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":       "org",
+				"dataset_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get dataset_repo",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "notFoundDataset"}},
+			},
+			want: testWant{
+				code:    http.StatusNotFound,
+				message: "File not found",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "errorGetDataset"}},
+			},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to fetch file",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "file.csv"}},
+			},
+			want: testWant{
+				code:    http.StatusOK,
+				message: "\"period_start_min\":\"2021-09-01\"",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(test.input.keys, nil, test.input.params)
+		GetFile(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message))
+	}
+}
+
+func TestUpdateFileHandler(t *testing.T) {
+	type testInput struct {
+		keys   map[string]interface{}
+		body   interface{}
+		params []gin.Param
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	displayName := "Invoices 2021"
+	emptyDisplayName := " "
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id": 1,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine organization",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":       "org",
+				"dataset_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get dataset_repo",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+				},
+				body: map[string]interface{}{"labels": []string{""}},
+			},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Failed to parse request body",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+				},
+				body: models.DatasetUpdate{DisplayName: &emptyDisplayName},
+			},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Nothing to update",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+				},
+				body:   models.DatasetUpdate{DisplayName: &displayName},
+				params: []gin.Param{{Key: "id", Value: "notFoundDataset"}},
+			},
+			want: testWant{
+				code:    http.StatusNotFound,
+				message: "File not found",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "errorUpdateDataset",
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+				},
+				body:   models.DatasetUpdate{DisplayName: &displayName},
+				params: []gin.Param{{Key: "id", Value: "file.csv"}},
+			},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to update file",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+				},
+				body:   models.DatasetUpdate{DisplayName: &displayName},
+				params: []gin.Param{{Key: "id", Value: "file.csv"}},
+			},
+			want: testWant{
+				code:    http.StatusOK,
+				message: "\"display_name\":\"Invoices 2021\"",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(test.input.keys, test.input.body, test.input.params)
+		UpdateFile(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message))
+	}
+}
+
+func TestParseDatasetQuery(t *testing.T) {
+	type testInput struct {
+		req models.DatasetQuery
+	}
+	type testWant struct {
+		query domain.DatasetQuery
+		err   error
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{
+				req: models.DatasetQuery{UploadedTo: "31.10.2021"},
+			},
+			want: testWant{
+				query: domain.DatasetQuery{},
+				err:   errors.New("failed to parse uploaded_to"),
+			},
+		},
+		{
+			input: testInput{
+				req: models.DatasetQuery{
+					Label:        " billing ",
+					Name:         "invoices",
+					UploadedFrom: "2021-10-01",
+					UploadedTo:   "2021-10-31",
+					Sort:         "-name",
+				},
+			},
+			want: testWant{
+				query: domain.DatasetQuery{
+					Label:        "billing",
+					Name:         "invoices",
+					UploadedFrom: time.Date(2021, time.October, 1, 0, 0, 0, 0, time.UTC),
+					UploadedTo:   time.Date(2021, time.November, 1, 0, 0, 0, 0, time.UTC),
+					SortBy:       "name",
+					Desc:         true,
+				},
+				err: nil,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		query, err := parseDatasetQuery(test.input.req)
+		if test.want.err != nil {
+			assert.Error(t, err)
+			assert.Equal(t, true, strings.HasPrefix(err.Error(), test.want.err.Error()))
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, test.want.query, query)
+	}
+}
+
+func TestParseDatasetUpdate(t *testing.T) {
+	displayName := " Invoices "
+	description := "Billing export"
+	labels := []string{"billing", " 2021 ", "billing", " "}
+
+	update, err := parseDatasetUpdate(models.DatasetUpdate{})
+	assert.Equal(t, errors.New("no fields to update"), err)
+	assert.Equal(t, domain.DatasetUpdate{}, update)
+
+	update, err = parseDatasetUpdate(models.DatasetUpdate{
+		DisplayName: &displayName,
+		Description: &description,
+		Labels:      &labels,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Invoices", *update.DisplayName)
+	assert.Equal(t, "Billing export", *update.Description)
+	assert.Equal(t, []string{"billing", "2021"}, *update.Labels)
+}
+
 func TestLoadFiles(t *testing.T) {
 	type testInput struct {
-		email string
+		orgID string
 	}
 	type testWant struct {
-		files []domain.File
+		files int
 		err   error
 	}
 
@@ -179,35 +511,36 @@ func TestLoadFiles(t *testing.T) {
 	}{
 		{
 			input: testInput{
-				email: "errorGetUser",
+				orgID: "errorGetDatasets",
 			},
 			want: testWant{
-				files: make([]domain.File, 0),
-				err:   errors.New("failed to get user, error is: user not exist"),
+				files: 0,
+				err:   errors.New("failed to get datasets, error is: error while getting datasets"),
 			},
 		},
 		{
 			input: testInput{
-				email: "user",
+				orgID: "org",
 			},
 			want: testWant{
-				files: make([]domain.File, 10),
+				files: 2,
 			},
 		},
 	}
 
-	userMock := &userrepo.UserRepositoryMock{}
+	datasetMock := &datasetrepo.DatasetRepositoryMock{}
 
 	for _, test := range tests {
-		files, err := loadFiles(userMock, test.input.email)
-		assert.Equal(t, test.want.files, files)
+		files, err := loadFiles(context.Background(), datasetMock, test.input.orgID, domain.DatasetQuery{})
+		assert.Equal(t, test.want.files, len(files))
 		assert.Equal(t, test.want.err, err)
 	}
 }
 
 func TestDeleteFileContent(t *testing.T) {
 	type testInput struct {
-		email, userID string
+		orgID    string
+		filename string
 	}
 	type testWant struct {
 		err error
@@ -219,34 +552,35 @@ func TestDeleteFileContent(t *testing.T) {
 	}{
 		{
 			input: testInput{
-				email: "errorGetUser",
+				orgID:    "org",
+				filename: "errorGetDataset",
 			},
 			want: testWant{
-				err: errors.New("failed to get user, error is: user not exist"),
+				err: errors.New("failed to get dataset, error is: error while getting dataset"),
 			},
 		},
 		{
 			input: testInput{
-				email:  "user",
-				userID: "errorUpdateUser",
+				orgID:    "errorDeleteInvoices",
+				filename: "someFile",
 			},
 			want: testWant{
-				err: errors.New("failed to update user, error is: error while updating user"),
+				err: errors.New("failed to delete ivoices from db, error is: error while deleting invoices"),
 			},
 		},
 		{
 			input: testInput{
-				email:  "user",
-				userID: "errorDeleteInvoices",
+				orgID:    "errorDeleteDataset",
+				filename: "someFile",
 			},
 			want: testWant{
-				err: errors.New("failed to delete ivoices from db, error is: error while deleting invoices"),
+				err: errors.New("failed to delete dataset, error is: error while deleting dataset"),
 			},
 		},
 		{
 			input: testInput{
-				email:  "user",
-				userID: "user",
+				orgID:    "org",
+				filename: "someFile",
 			},
 			want: testWant{
 				err: nil,
@@ -254,69 +588,168 @@ func TestDeleteFileContent(t *testing.T) {
 		},
 	}
 
-	userMock := &userrepo.UserRepositoryMock{}
+	datasetMock := &datasetrepo.DatasetRepositoryMock{}
 	storageMock := &storagerepo.StorageRepositoryMock{}
 
 	for _, test := range tests {
-		err := deleteFileContent(userMock, storageMock, test.input.email, test.input.userID, "someFile")
-		assert.Equal(t, test.want.err, err)
+		err := deleteFileContent(context.Background(), datasetMock, storageMock, test.input.orgID, test.input.filename)
+		if test.want.err == nil {
+			assert.NoError(t, err)
+			continue
+		}
+		assert.EqualError(t, err, test.want.err.Error())
 	}
 }
 
-func TestUpdateFiles(t *testing.T) {
+func TestExportFileHandler(t *testing.T) {
 	type testInput struct {
-		email, userID string
+		keys   map[string]interface{}
+		query  string
+		params []gin.Param
 	}
 	type testWant struct {
-		err error
+		code    int
+		message string
 	}
 
 	tests := []struct {
 		input testInput
 		want  testWant
 	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id": 1,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine organization",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":       "org",
+				"dataset_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get dataset_repo",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":       "org",
+				"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+				"storage_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get storage_repo",
+			},
+		},
 		{
 			input: testInput{
-				email: "errorGetUser",
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+					"storage_repo": &storagerepo.StorageRepositoryMock{},
+				},
+				query:  "gzip=maybe",
+				params: []gin.Param{{Key: "id", Value: "file.csv"}},
 			},
 			want: testWant{
-				err: errors.New("failed to get user, error is: user not exist"),
+				code:    http.StatusBadRequest,
+				message: "Failed to parse query parameters",
 			},
 		},
 		{
 			input: testInput{
-				email:  "user",
-				userID: "errorUpdateUser",
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+					"storage_repo": &storagerepo.StorageRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "notFoundDataset"}},
 			},
 			want: testWant{
-				err: errors.New("failed to update user, error is: error while updating user"),
+				code:    http.StatusNotFound,
+				message: "File not found",
 			},
 		},
 		{
 			input: testInput{
-				email:  "user",
-				userID: "user",
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+					"storage_repo": &storagerepo.StorageRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "importingDataset"}},
 			},
 			want: testWant{
-				err: nil,
+				code:    http.StatusConflict,
+				message: "File is not imported yet",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+					"storage_repo": &storagerepo.StorageRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "file.csv"}},
+			},
+			want: testWant{
+				code: http.StatusOK,
+				message: "customer_id,period_start,paid_plan,paid_amount,period_end\n" +
+					"1,01.10.2021,monthly,100.5,31.10.2021\n" +
+					"2,15.10.2021,annual,1200,14.10.2022\n",
 			},
 		},
 	}
 
-	userMock := &userrepo.UserRepositoryMock{}
-
 	for _, test := range tests {
-		err := updateFiles(userMock, test.input.email, test.input.userID, "someFile")
-		assert.Equal(t, test.want.err, err)
+		c, w := internalTesting.CreateGinContext(test.input.keys, nil, test.input.params)
+		c.Request.URL.RawQuery = test.input.query
+		ExportFile(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message))
 	}
 }
 
-func TestUploadFileContent(t *testing.T) {
+func TestExportFileHandlerGzip(t *testing.T) {
+	c, w := internalTesting.CreateGinContext(map[string]interface{}{
+		"org_id":       "org",
+		"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+		"storage_repo": &storagerepo.StorageRepositoryMock{},
+	}, nil, []gin.Param{{Key: "id", Value: "file.csv"}})
+	c.Request.URL.RawQuery = "gzip=true"
+	ExportFile(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/gzip", w.Header().Get("Content-Type"))
+	assert.Equal(t, "attachment; filename=\"invoices.csv.gz\"", w.Header().Get("Content-Disposition"))
+
+	gz, err := gzip.NewReader(w.Body)
+	assert.NoError(t, err)
+	content, err := io.ReadAll(gz)
+	assert.NoError(t, err)
+	assert.Equal(t, true, strings.HasPrefix(string(content), "customer_id,period_start,paid_plan,paid_amount,period_end\n"))
+}
+
+func TestWriteInvoices(t *testing.T) {
+	storageMock := &storagerepo.StorageRepositoryMock{}
+
+	var b strings.Builder
+	err := writeInvoices(context.Background(), &b, storageMock, "errorStreamInvoices", "file.csv")
+	assert.Equal(t, errors.New("failed to write invoices, error is: error while streaming invoices"), err)
+}
+
+func TestExportFilename(t *testing.T) {
 	type testInput struct {
-		invoices []*Invoice
+		dataset domain.Dataset
 	}
 	type testWant struct {
-		err error
+		filename string
 	}
 
 	tests := []struct {
@@ -325,26 +758,23 @@ func TestUploadFileContent(t *testing.T) {
 	}{
 		{
 			input: testInput{
-				invoices: make([]*Invoice, 0),
+				dataset: domain.Dataset{Name: "3f2a.csv", DisplayName: "Invoices 2021.csv"},
 			},
 			want: testWant{
-				err: errors.New("failed upload invoices to db, error is: error while adding invoices"),
+				filename: "Invoices_2021.csv",
 			},
 		},
 		{
 			input: testInput{
-				invoices: []*Invoice{{}},
+				dataset: domain.Dataset{Name: "3f2a.csv", DisplayName: "\";"},
 			},
 			want: testWant{
-				err: nil,
+				filename: "3f2a.csv",
 			},
 		},
 	}
 
-	storageMock := &storagerepo.StorageRepositoryMock{}
-
 	for _, test := range tests {
-		err := uploadFileContent(storageMock, "", "", test.input.invoices)
-		assert.Equal(t, test.want.err, err)
+		assert.Equal(t, test.want.filename, exportFilename(test.input.dataset))
 	}
 }
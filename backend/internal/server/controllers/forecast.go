@@ -0,0 +1,497 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/calendar"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
+	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	cacherepo "github.com/hackfeed/remrratality/backend/internal/store/cache_repo"
+	datasetrepo "github.com/hackfeed/remrratality/backend/internal/store/dataset_repo"
+	storagerepo "github.com/hackfeed/remrratality/backend/internal/store/storage_repo"
+)
+
+const (
+	forecastTrend  = "trend"
+	forecastCohort = "cohort"
+
+	defaultConfidence = 0.95
+	// seasonLength is the number of months in a season, seasonality is fit once two seasons are observed.
+	seasonLength = 12
+	// tailAges is the number of the oldest observed cohort ages rates of older cohorts are pooled from.
+	tailAges = 6
+)
+
+// confidenceLevels maps supported confidence of forecast intervals to z-score of normal distribution.
+var confidenceLevels = map[float64]float64{
+	0.8:  1.2816,
+	0.9:  1.6449,
+	0.95: 1.9600,
+	0.99: 2.5758,
+}
+
+// CreateForecast godoc
+// @Summary Create MRR analytics with forecast
+// @Description Creating MRR analytics data for given period and projecting Total and every component horizon months ahead.
+// @Description Trend method fits linear trend to each series, with monthly seasonality once two years are observed.
+// @Description Cohort method groups customers by their first paying month and projects them with observed month over month
+// @Description rates of cohorts of the same age, new MRR of future cohorts is projected with trend method.
+// @Description Every projected value has lower and upper bounds of interval with given confidence.
+// @Tags analytics
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} models.ResponseSuccessForecast
+// @Failure 400 {object} models.Response
+// @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 404 {object} models.Response
+// @Failure 409 {object} models.Response
+// @Failure 429 {object} models.Response
+// @Header 429 {integer} Retry-After "Seconds to wait before the next request"
+// @Failure 500 {object} models.Response
+// @Security ApiKeyAuth
+// @Param request body models.Forecast true "Parameters for MRR forecast"
+// @Param org header string false "Organization ID, personal organization is used if omitted"
+// @Router /analytics/forecast [post]
+func CreateForecast(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	orgID, ok := c.MustGet("org_id").(string)
+	if !ok {
+		logger.Errorf("failed to get org_id from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Unable to determine organization",
+		})
+		return
+	}
+	datasetRepo, ok := c.MustGet("dataset_repo").(datasetrepo.DatasetRepository)
+	if !ok {
+		logger.Errorf("failed to get dataset_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get dataset_repo",
+		})
+		return
+	}
+	storageRepo, ok := c.MustGet("storage_repo").(storagerepo.StorageRepository)
+	if !ok {
+		logger.Errorf("failed to get storage_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get storage_repo",
+		})
+		return
+	}
+	cacheRepo, ok := c.MustGet("cache_repo").(cacherepo.CacheRepository)
+	if !ok {
+		logger.Errorf("failed to get cache_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get cache_repo",
+		})
+		return
+	}
+
+	var req models.Forecast
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Errorf("failed to parse request body, error is: %s", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Message: "Failed to parse request body",
+		})
+		return
+	}
+	if req.Method == "" {
+		req.Method = forecastTrend
+	}
+	if req.Confidence == 0 {
+		req.Confidence = defaultConfidence
+	}
+	if _, ok := confidenceLevels[req.Confidence]; !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Message: "Unsupported confidence, supported values are 0.8, 0.9, 0.95 and 0.99",
+		})
+		return
+	}
+
+	c.Set("audit_details", map[string]interface{}{
+		"filename":     req.Filename,
+		"period_start": req.PeriodStart,
+		"period_end":   req.PeriodEnd,
+		"horizon":      req.Horizon,
+		"method":       req.Method,
+	})
+
+	if _, ok := readyDataset(c, datasetRepo, orgID, req.Filename); !ok {
+		return
+	}
+
+	periods, mrr, forecast, err := createForecast(c.Request.Context(), storageRepo, cacheRepo, orgID, req)
+	if err != nil {
+		logger.Errorf("failed to get MRR forecast, error is: %s", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Message: "Failed to get analytics. Please ensure that period start is earlier than period end and data exists in given period",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseSuccessForecast{
+		Message:  "Forecast is created",
+		Months:   calendar.Labels(periods),
+		Periods:  calendar.ISOLabels(periods),
+		MRR:      mrr,
+		Forecast: forecast,
+	})
+}
+
+func createForecast(ctx context.Context, storageRepo storagerepo.StorageRepository, cacheRepo cacherepo.CacheRepository, orgID string, req models.Forecast) ([]calendar.Period, domain.TotalMRR, domain.MRRForecast, error) {
+	var forecast domain.MRRForecast
+
+	periods, mrr, err := createAnalytics(ctx, storageRepo, cacheRepo, orgID, req.Filename, req.PeriodStart, req.PeriodEnd)
+	if err != nil {
+		return periods, mrr, forecast, err
+	}
+	z := confidenceLevels[req.Confidence]
+
+	if req.Method == forecastCohort {
+		mpp, err := formMPP(ctx, storageRepo, periods, orgID, req.Filename)
+		if err != nil {
+			return periods, mrr, forecast, fmt.Errorf("failed to form mpp, error is: %s", err)
+		}
+		forecast = cohortForecast(mpp, mrr, req.Horizon, z)
+	} else {
+		forecast = trendForecast(mrr, req.Horizon, z)
+	}
+
+	projected, err := calendar.After(calendar.FromContext(ctx), periods[len(periods)-1], req.Horizon)
+	if err != nil {
+		return periods, mrr, forecast, fmt.Errorf("failed to get projected periods, error is: %s", err)
+	}
+	forecast.Method = req.Method
+	forecast.Confidence = req.Confidence
+	forecast.Months = calendar.Labels(projected)
+	forecast.Periods = calendar.ISOLabels(projected)
+
+	return periods, mrr, forecast, nil
+}
+
+// trendForecast projects every series of mrr independently with projectTrend.
+func trendForecast(mrr domain.TotalMRR, horizon int, z float64) domain.MRRForecast {
+	forecast := domain.MRRForecast{
+		New:          projectTrend(mrr.New, horizon, z),
+		Old:          projectTrend(mrr.Old, horizon, z),
+		Reactivation: projectTrend(mrr.Reactivation, horizon, z),
+		Expansion:    projectTrend(mrr.Expansion, horizon, z),
+		Contraction:  projectTrend(mrr.Contraction, horizon, z),
+		Churn:        projectTrend(mrr.Churn, horizon, z),
+		Total:        projectTrend(mrr.Total, horizon, z),
+	}
+	clampForecast(&forecast)
+
+	return forecast
+}
+
+// projectTrend fits linear trend to series by least squares, adds monthly seasonality fit to its
+// residuals once two seasons are observed and projects series horizon months ahead. Bounds are
+// prediction interval of the regression, z is z-score of its confidence.
+func projectTrend(series []float32, horizon int, z float64) domain.ForecastSeries {
+	projected := newForecastSeries(horizon)
+	n := len(series)
+	if n == 0 {
+		return projected
+	}
+
+	tMean := float64(n-1) / 2
+	var yMean float64
+	for _, y := range series {
+		yMean += float64(y)
+	}
+	yMean /= float64(n)
+
+	var sxx, sxy float64
+	for t, y := range series {
+		sxx += (float64(t) - tMean) * (float64(t) - tMean)
+		sxy += (float64(t) - tMean) * (float64(y) - yMean)
+	}
+	var slope float64
+	if sxx > 0 {
+		slope = sxy / sxx
+	}
+	intercept := yMean - slope*tMean
+
+	params := 2
+	seasonal := make([]float64, seasonLength)
+	if n >= 2*seasonLength {
+		counts := make([]int, seasonLength)
+		for t, y := range series {
+			seasonal[t%seasonLength] += float64(y) - (intercept + slope*float64(t))
+			counts[t%seasonLength]++
+		}
+		var seasonalMean float64
+		for m := range seasonal {
+			seasonal[m] /= float64(counts[m])
+			seasonalMean += seasonal[m]
+		}
+		seasonalMean /= seasonLength
+		for m := range seasonal {
+			seasonal[m] -= seasonalMean
+		}
+		params += seasonLength - 1
+	}
+
+	var sse float64
+	for t, y := range series {
+		residual := float64(y) - (intercept + slope*float64(t) + seasonal[t%seasonLength])
+		sse += residual * residual
+	}
+	var sigma float64
+	if n > params {
+		sigma = math.Sqrt(sse / float64(n-params))
+	}
+
+	for h := 0; h < horizon; h++ {
+		t := float64(n + h)
+		value := intercept + slope*t + seasonal[(n+h)%seasonLength]
+		spread := 1 + 1/float64(n)
+		if sxx > 0 {
+			spread += (t - tMean) * (t - tMean) / sxx
+		}
+		margin := z * sigma * math.Sqrt(spread)
+
+		projected.Value[h] = float32(value)
+		projected.Lower[h] = float32(value - margin)
+		projected.Upper[h] = float32(value + margin)
+	}
+
+	return projected
+}
+
+// cohortRates are rates of MRR movements of cohort customers relative to MRR they had a month
+// before. Retention is the ratio of cohort MRR to its MRR a month before.
+type cohortRates struct {
+	old          float64
+	reactivation float64
+	expansion    float64
+	contraction  float64
+	churn        float64
+	retention    float64
+}
+
+// cohortMovements accumulates MRR movements of cohorts of the same age with MRR they're relative to.
+type cohortMovements struct {
+	base float64
+	mrr  float64
+	cohortRates
+}
+
+func (m cohortMovements) rates() cohortRates {
+	return cohortRates{
+		old:          m.old / m.base,
+		reactivation: m.reactivation / m.base,
+		expansion:    m.expansion / m.base,
+		contraction:  m.contraction / m.base,
+		churn:        m.churn / m.base,
+		retention:    m.mrr / m.base,
+	}
+}
+
+func (m *cohortMovements) add(other cohortMovements) {
+	m.base += other.base
+	m.mrr += other.mrr
+	m.old += other.old
+	m.reactivation += other.reactivation
+	m.expansion += other.expansion
+	m.contraction += other.contraction
+	m.churn += other.churn
+}
+
+// cohortForecast groups customers into cohorts by their first paying month and projects them
+// with rates observed for cohorts of the same age, cohorts older than any observed one follow
+// rates pooled over tailAges oldest observed ages. New MRR, and so cohorts joining in future,
+// is projected with projectTrend. Bounds of components widen with square root of months ahead
+// from their in-sample one month ahead error.
+func cohortForecast(mpp []domain.MPP, mrr domain.TotalMRR, horizon int, z float64) domain.MRRForecast {
+	n := len(mrr.Total)
+	forecast := domain.MRRForecast{
+		New:          projectTrend(mrr.New, horizon, z),
+		Old:          newForecastSeries(horizon),
+		Reactivation: newForecastSeries(horizon),
+		Expansion:    newForecastSeries(horizon),
+		Contraction:  newForecastSeries(horizon),
+		Churn:        newForecastSeries(horizon),
+		Total:        newForecastSeries(horizon),
+	}
+	if n == 0 {
+		return forecast
+	}
+	for i := range forecast.New.Value {
+		forecast.New.Value[i] = float32(math.Max(0, float64(forecast.New.Value[i])))
+	}
+
+	// cohorts keeps MRR of every cohort by month, keyed by month cohort has started in.
+	cohorts := make(map[int][]float64)
+	movements := make([]cohortMovements, n)
+	for _, entry := range mpp {
+		start := -1
+		for i, value := range entry.Months {
+			if value > 0 {
+				start = i
+				break
+			}
+		}
+		if start < 0 {
+			continue
+		}
+		if _, ok := cohorts[start]; !ok {
+			cohorts[start] = make([]float64, n)
+		}
+
+		clientMRR := calculateClientMRR(entry)
+		for i := start; i < n; i++ {
+			cohorts[start][i] += float64(entry.Months[i])
+			if i == start {
+				continue
+			}
+			age := i - start
+			movements[age].add(cohortMovements{
+				base: float64(entry.Months[i-1]),
+				mrr:  float64(entry.Months[i]),
+				cohortRates: cohortRates{
+					old:          float64(clientMRR[i].Old),
+					reactivation: float64(clientMRR[i].Reactivation),
+					expansion:    float64(clientMRR[i].Expansion),
+					contraction:  float64(clientMRR[i].Contraction),
+					churn:        float64(clientMRR[i].Churn),
+				},
+			})
+		}
+	}
+
+	tail := cohortMovements{}
+	for age := n - 1; age > 0 && age >= n-tailAges; age-- {
+		tail.add(movements[age])
+	}
+	tailRates := cohortRates{old: 1, retention: 1}
+	if tail.base > 0 {
+		tailRates = tail.rates()
+	}
+	ratesAt := func(age int) cohortRates {
+		if age < n && movements[age].base > 0 {
+			return movements[age].rates()
+		}
+		return tailRates
+	}
+
+	// In-sample one month ahead errors of components and Total, projected from actual cohort MRR.
+	var deviations [6]float64
+	for i := 1; i < n; i++ {
+		var predicted cohortRates
+		for start, cohortMRR := range cohorts {
+			if start >= i {
+				continue
+			}
+			rates := ratesAt(i - start)
+			predicted.old += cohortMRR[i-1] * rates.old
+			predicted.reactivation += cohortMRR[i-1] * rates.reactivation
+			predicted.expansion += cohortMRR[i-1] * rates.expansion
+			predicted.contraction += cohortMRR[i-1] * rates.contraction
+			predicted.churn += cohortMRR[i-1] * rates.churn
+		}
+		residuals := []float64{
+			float64(mrr.Old[i]) - predicted.old,
+			float64(mrr.Reactivation[i]) - predicted.reactivation,
+			float64(mrr.Expansion[i]) - predicted.expansion,
+			float64(mrr.Contraction[i]) - predicted.contraction,
+			float64(mrr.Churn[i]) - predicted.churn,
+		}
+		var total float64
+		for j, residual := range residuals {
+			deviations[j] += residual * residual
+			total += residual
+		}
+		deviations[5] += total * total
+	}
+	for j := range deviations {
+		if n > 1 {
+			deviations[j] = math.Sqrt(deviations[j] / float64(n-1))
+		}
+	}
+
+	type cohort struct {
+		age int
+		mrr float64
+	}
+	projected := make([]cohort, 0, len(cohorts)+horizon)
+	for start, cohortMRR := range cohorts {
+		projected = append(projected, cohort{age: n - 1 - start, mrr: cohortMRR[n-1]})
+	}
+
+	for h := 0; h < horizon; h++ {
+		var moved cohortRates
+		for i := range projected {
+			projected[i].age++
+			rates := ratesAt(projected[i].age)
+			moved.old += projected[i].mrr * rates.old
+			moved.reactivation += projected[i].mrr * rates.reactivation
+			moved.expansion += projected[i].mrr * rates.expansion
+			moved.contraction += projected[i].mrr * rates.contraction
+			moved.churn += projected[i].mrr * rates.churn
+			projected[i].mrr *= rates.retention
+		}
+		newMRR := float64(forecast.New.Value[h])
+		projected = append(projected, cohort{age: 0, mrr: newMRR})
+
+		spread := math.Sqrt(float64(h + 1))
+		setForecastValue(&forecast.Old, h, moved.old, z*deviations[0]*spread)
+		setForecastValue(&forecast.Reactivation, h, moved.reactivation, z*deviations[1]*spread)
+		setForecastValue(&forecast.Expansion, h, moved.expansion, z*deviations[2]*spread)
+		setForecastValue(&forecast.Contraction, h, moved.contraction, z*deviations[3]*spread)
+		setForecastValue(&forecast.Churn, h, moved.churn, z*deviations[4]*spread)
+
+		total := newMRR + moved.old + moved.reactivation + moved.expansion + moved.contraction + moved.churn
+		newMargin := float64(forecast.New.Upper[h]-forecast.New.Lower[h]) / 2
+		setForecastValue(&forecast.Total, h, total, math.Hypot(z*deviations[5]*spread, newMargin))
+	}
+	clampForecast(&forecast)
+
+	return forecast
+}
+
+func newForecastSeries(horizon int) domain.ForecastSeries {
+	return domain.ForecastSeries{
+		Value: make([]float32, horizon),
+		Lower: make([]float32, horizon),
+		Upper: make([]float32, horizon),
+	}
+}
+
+func setForecastValue(series *domain.ForecastSeries, month int, value, margin float64) {
+	series.Value[month] = float32(value)
+	series.Lower[month] = float32(value - margin)
+	series.Upper[month] = float32(value + margin)
+}
+
+// clampForecast keeps projected components within their signs, contraction and churn are never
+// positive and the rest are never negative. Total may be negative as analytics one.
+func clampForecast(forecast *domain.MRRForecast) {
+	for _, series := range []*domain.ForecastSeries{&forecast.New, &forecast.Old, &forecast.Reactivation, &forecast.Expansion} {
+		clampSeries(series, 0, math.MaxFloat32)
+	}
+	for _, series := range []*domain.ForecastSeries{&forecast.Contraction, &forecast.Churn} {
+		clampSeries(series, -math.MaxFloat32, 0)
+	}
+}
+
+func clampSeries(series *domain.ForecastSeries, min, max float32) {
+	for _, values := range [][]float32{series.Value, series.Lower, series.Upper} {
+		for i, value := range values {
+			if value < min {
+				values[i] = min
+			} else if value > max {
+				values[i] = max
+			}
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	cacherepo "github.com/hackfeed/remrratality/backend/internal/store/cache_repo"
+	datasetrepo "github.com/hackfeed/remrratality/backend/internal/store/dataset_repo"
+	storagerepo "github.com/hackfeed/remrratality/backend/internal/store/storage_repo"
+	internalTesting "github.com/hackfeed/remrratality/backend/internal/utils/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateForecastHandler(t *testing.T) {
+	type testInput struct {
+		keys map[string]interface{}
+		body interface{}
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	repos := func(orgID string) map[string]interface{} {
+		return map[string]interface{}{
+			"org_id":       orgID,
+			"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+			"storage_repo": &storagerepo.StorageRepositoryMock{},
+			"cache_repo":   &cacherepo.CacheRepositoryMock{},
+		}
+	}
+	period := models.Period{Filename: "file.csv", PeriodStart: "2021-10-01", PeriodEnd: "2021-11-01"}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id": 5,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine organization",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":       "org",
+				"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+				"storage_repo": "invalidType",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get storage_repo",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":       "org",
+				"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+				"storage_repo": &storagerepo.StorageRepositoryMock{},
+				"cache_repo":   "invalidType",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get cache_repo",
+			},
+		},
+		{
+			input: testInput{keys: repos("org"), body: models.Forecast{Period: period}},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Failed to parse request body",
+			},
+		},
+		{
+			input: testInput{keys: repos("org"), body: models.Forecast{Period: period, Horizon: 3, Method: "arima"}},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Failed to parse request body",
+			},
+		},
+		{
+			input: testInput{keys: repos("org"), body: models.Forecast{Period: period, Horizon: 3, Confidence: 0.5}},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Unsupported confidence",
+			},
+		},
+		{
+			input: testInput{keys: repos("org"), body: models.Forecast{Period: models.Period{Filename: "importingDataset", PeriodStart: "2021-10-01", PeriodEnd: "2021-11-01"}, Horizon: 2}},
+			want: testWant{
+				code:    http.StatusConflict,
+				message: "File is not imported yet",
+			},
+		},
+		{
+			input: testInput{keys: repos("errorGetInvoicesByPeriod"), body: models.Forecast{Period: period, Horizon: 3, Method: "cohort"}},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Failed to get analytics",
+			},
+		},
+		{
+			input: testInput{keys: repos("org"), body: models.Forecast{Period: period, Horizon: 2}},
+			want: testWant{
+				code:    http.StatusOK,
+				message: `"Method":"trend","Confidence":0.95,"Months":["12.2021","1.2022"]`,
+			},
+		},
+		{
+			input: testInput{keys: repos("org"), body: models.Forecast{Period: period, Horizon: 2, Method: "cohort", Confidence: 0.8}},
+			want: testWant{
+				code:    http.StatusOK,
+				message: `"Method":"cohort","Confidence":0.8,"Months":["12.2021","1.2022"]`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(test.input.keys, test.input.body, nil)
+		CreateForecast(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message), w.Body.String())
+	}
+}
+
+func TestProjectTrend(t *testing.T) {
+	linear := projectTrend([]float32{100, 110, 120, 130}, 2, 1.96)
+	assert.InDeltaSlice(t, []float32{140, 150}, linear.Value, 0.001)
+	assert.InDeltaSlice(t, linear.Value, linear.Lower, 0.001)
+	assert.InDeltaSlice(t, linear.Value, linear.Upper, 0.001)
+
+	noisy := projectTrend([]float32{100, 130, 110, 140, 120}, 3, 1.96)
+	for h := range noisy.Value {
+		assert.Less(t, noisy.Lower[h], noisy.Value[h])
+		assert.Greater(t, noisy.Upper[h], noisy.Value[h])
+	}
+	assert.Greater(t, noisy.Upper[2]-noisy.Lower[2], noisy.Upper[0]-noisy.Lower[0])
+
+	// Seasonal deviations are symmetric within a season, so they don't tilt the trend.
+	deviations := map[int]float32{0: 30, 11: 30, 5: -30, 6: -30}
+	series := make([]float32, 2*seasonLength)
+	for i := range series {
+		series[i] = float32(100+10*i) + deviations[i%seasonLength]
+	}
+	seasonal := projectTrend(series, seasonLength+1, 1.96)
+	assert.InDelta(t, 370, seasonal.Value[0], 0.01)
+	assert.InDelta(t, 350, seasonal.Value[1], 0.01)
+	assert.InDelta(t, 490, seasonal.Value[seasonLength], 0.01)
+	assert.InDelta(t, seasonal.Value[0], seasonal.Upper[0], 0.01)
+
+	assert.Equal(t, 2, len(projectTrend(nil, 2, 1.96).Value))
+}
+
+func TestTrendForecast(t *testing.T) {
+	forecast := trendForecast(domain.TotalMRR{
+		New:          []float32{300, 200, 100},
+		Old:          []float32{0, 300, 500},
+		Reactivation: []float32{0, 0, 0},
+		Expansion:    []float32{0, 0, 0},
+		Contraction:  []float32{0, -20, -40},
+		Churn:        []float32{0, 0, 0},
+		Total:        []float32{300, 480, 560},
+	}, 2, 1.96)
+
+	assert.InDeltaSlice(t, []float32{0, 0}, forecast.New.Value, 0.001)
+	assert.InDeltaSlice(t, []float32{0, 0}, forecast.New.Lower, 0.001)
+	assert.InDeltaSlice(t, []float32{-60, -80}, forecast.Contraction.Value, 0.001)
+	for h := range forecast.Old.Value {
+		assert.LessOrEqual(t, forecast.Contraction.Upper[h], float32(0))
+		assert.GreaterOrEqual(t, forecast.Old.Lower[h], float32(0))
+	}
+}
+
+func TestCohortForecast(t *testing.T) {
+	mpp := []domain.MPP{
+		{CustomerID: 1, Months: []float32{100, 100, 100, 100, 100, 100, 100, 100}},
+		{CustomerID: 2, Months: []float32{0, 100, 50, 50, 50, 50, 50, 50}},
+		{CustomerID: 3, Months: []float32{0, 0, 0, 0, 0, 0, 0, 0}},
+	}
+	mrr := convertRawMRR(calculateTotalMRR(mpp))
+
+	forecast := cohortForecast(mpp, mrr, 2, 1.96)
+
+	// New MRR trends down to zero, so only the existing cohorts are projected. Both of them are
+	// older than the age their customers contracted at, so they keep their MRR.
+	assert.InDeltaSlice(t, []float32{0, 0}, forecast.New.Value, 0.001)
+	assert.InDeltaSlice(t, []float32{150, 150}, forecast.Old.Value, 0.001)
+	assert.InDeltaSlice(t, []float32{0, 0}, forecast.Contraction.Value, 0.001)
+	assert.InDeltaSlice(t, []float32{0, 0}, forecast.Churn.Value, 0.001)
+	assert.InDeltaSlice(t, []float32{150, 150}, forecast.Total.Value, 0.001)
+	assert.Greater(t, forecast.Total.Upper[1]-forecast.Total.Lower[1], forecast.Total.Upper[0]-forecast.Total.Lower[0])
+	for h := range forecast.Total.Value {
+		assert.LessOrEqual(t, forecast.Total.Lower[h], forecast.Total.Value[h])
+		assert.GreaterOrEqual(t, forecast.Total.Upper[h], forecast.Total.Value[h])
+	}
+
+	empty := cohortForecast(nil, domain.TotalMRR{}, 2, 1.96)
+	assert.Equal(t, 2, len(empty.Total.Value))
+}
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/health"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
+	"github.com/hackfeed/remrratality/backend/internal/server/models"
+)
+
+var readinessTimeout = 2 * time.Second
+
+// Healthz reports that server is alive, it doesn't check dependencies, so failing database
+// doesn't get server restarted. It's served outside of API base path, hence not documented
+// in swagger.
+func Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, health.Report{Status: health.StatusOK})
+}
+
+// Readyz pings backing stores and reports status of each of them. Server is ready while
+// all critical stores are up, it responds with 503 otherwise.
+func Readyz(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	checks, ok := c.MustGet("health_checks").([]health.Check)
+	if !ok {
+		logger.Errorf("failed to get health_checks from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get health_checks",
+		})
+		return
+	}
+
+	report := health.Run(c.Request.Context(), checks, readinessTimeout)
+	for name, result := range report.Checks {
+		if result.Status == health.StatusDown {
+			logger.Warnf("readiness check %s failed, error is: %s", name, result.Error)
+		}
+	}
+
+	code := http.StatusOK
+	if report.Status == health.StatusUnavailable {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, report)
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/hackfeed/remrratality/backend/internal/health"
+	internalTesting "github.com/hackfeed/remrratality/backend/internal/utils/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+type pingerMock struct {
+	err error
+}
+
+func (pm pingerMock) Ping(_ context.Context) error {
+	return pm.err
+}
+
+func TestHealthzHandler(t *testing.T) {
+	c, w := internalTesting.CreateGinContext(nil, nil, nil)
+	Healthz(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "{\"status\":\"ok\"}", w.Body.String())
+}
+
+func TestReadyzHandler(t *testing.T) {
+	type testInput struct {
+		keys map[string]interface{}
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"health_checks": "invalidChecks",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "{\"message\":\"Failed to get health_checks\"}",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"health_checks": []health.Check{
+					{Name: "mongo", Pinger: pingerMock{}, Critical: true},
+					{Name: "redis", Pinger: pingerMock{}},
+				},
+			}},
+			want: testWant{
+				code:    http.StatusOK,
+				message: "{\"status\":\"ok\",\"checks\":{\"mongo\":{\"status\":\"up\",\"critical\":true,\"latency_ms\":0},\"redis\":{\"status\":\"up\",\"critical\":false,\"latency_ms\":0}}}",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"health_checks": []health.Check{
+					{Name: "mongo", Pinger: pingerMock{}, Critical: true},
+					{Name: "redis", Pinger: pingerMock{err: errors.New("connection refused")}},
+				},
+			}},
+			want: testWant{
+				code:    http.StatusOK,
+				message: "{\"status\":\"degraded\",\"checks\":{\"mongo\":{\"status\":\"up\",\"critical\":true,\"latency_ms\":0},\"redis\":{\"status\":\"down\",\"critical\":false,\"latency_ms\":0}}}",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"health_checks": []health.Check{
+					{Name: "postgres", Pinger: pingerMock{err: errors.New("connection refused")}, Critical: true},
+				},
+			}},
+			want: testWant{
+				code:    http.StatusServiceUnavailable,
+				message: "{\"status\":\"unavailable\",\"checks\":{\"postgres\":{\"status\":\"down\",\"critical\":true,\"latency_ms\":0}}}",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(test.input.keys, nil, nil)
+		Readyz(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, test.want.message, w.Body.String())
+	}
+}
@@ -0,0 +1,239 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
+	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	jobrepo "github.com/hackfeed/remrratality/backend/internal/store/job_repo"
+)
+
+var (
+	// jobEventsInterval is how often new events of the job are looked for.
+	jobEventsInterval = time.Second
+	// keepAliveInterval is how long stream may stay silent before keep-alive comment is sent.
+	keepAliveInterval = 15 * time.Second
+)
+
+// GetJob godoc
+// @Summary Loading background job's status
+// @Description Loading state and progress of the job, failed job lists what went wrong
+// @Tags jobs
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} models.ResponseSuccessGetJob
+// @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 404 {object} models.Response
+// @Failure 500 {object} models.Response
+// @Security ApiKeyAuth
+// @Param id path string true "Job ID"
+// @Param org header string false "Organization ID, personal organization is used if omitted"
+// @Router /jobs/{id} [get]
+func GetJob(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	orgID, ok := c.MustGet("org_id").(string)
+	if !ok {
+		logger.Errorf("failed to get org_id from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Unable to determine organization",
+		})
+		return
+	}
+	jobRepo, ok := c.MustGet("job_repo").(jobrepo.JobRepository)
+	if !ok {
+		logger.Errorf("failed to get job_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get job_repo",
+		})
+		return
+	}
+
+	jobID := c.Param("id")
+
+	job, err := jobRepo.GetJob(c.Request.Context(), jobID)
+	if err == nil && job.OrgID != orgID {
+		err = jobrepo.ErrNotFound
+	}
+	if errors.Is(err, jobrepo.ErrNotFound) {
+		logger.Infof("job %s of org_id %s doesn't exist", jobID, orgID)
+		c.AbortWithStatusJSON(http.StatusNotFound, models.Response{
+			Message: "Job not found",
+		})
+		return
+	}
+	if err != nil {
+		logger.Errorf("failed to get job %s for org_id %s, error is: %s", jobID, orgID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get job",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseSuccessGetJob{
+		Message: "Job is loaded",
+		Job:     job,
+	})
+}
+
+// GetJobEvents godoc
+// @Summary Streaming background job's progress
+// @Description Streaming events of the job as Server-Sent Events: progress events report stage and rows processed,
+// @Description warning events describe suspicious rows, stream ends with succeeded or failed event.
+// @Description Events are numbered, stream is resumed after the event given in Last-Event-ID header.
+// @Description Stream is also ended before write timeout of the server expires and when server shuts down, clients reconnect then.
+// @Tags jobs
+// @Produce  text/event-stream
+// @Success 200 {array} domain.JobEvent
+// @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 404 {object} models.Response
+// @Failure 500 {object} models.Response
+// @Security ApiKeyAuth
+// @Param id path string true "Job ID"
+// @Param Last-Event-ID header integer false "ID of the last received event"
+// @Param org header string false "Organization ID, personal organization is used if omitted"
+// @Router /jobs/{id}/events [get]
+func GetJobEvents(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	orgID, ok := c.MustGet("org_id").(string)
+	if !ok {
+		logger.Errorf("failed to get org_id from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Unable to determine organization",
+		})
+		return
+	}
+	jobRepo, ok := c.MustGet("job_repo").(jobrepo.JobRepository)
+	if !ok {
+		logger.Errorf("failed to get job_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get job_repo",
+		})
+		return
+	}
+
+	jobID := c.Param("id")
+
+	job, err := jobRepo.GetJob(c.Request.Context(), jobID)
+	if err == nil && job.OrgID != orgID {
+		err = jobrepo.ErrNotFound
+	}
+	if errors.Is(err, jobrepo.ErrNotFound) {
+		logger.Infof("job %s of org_id %s doesn't exist", jobID, orgID)
+		c.AbortWithStatusJSON(http.StatusNotFound, models.Response{
+			Message: "Job not found",
+		})
+		return
+	}
+	if err != nil {
+		logger.Errorf("failed to get job %s for org_id %s, error is: %s", jobID, orgID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get job",
+		})
+		return
+	}
+
+	after, err := strconv.Atoi(c.GetHeader("Last-Event-ID"))
+	if err != nil || after < 0 {
+		after = 0
+	}
+
+	startEventStream(c)
+
+	ticker := time.NewTicker(jobEventsInterval)
+	defer ticker.Stop()
+	deadline, stop := streamDeadline(c.Request.Context())
+	defer stop()
+	lastWrite := time.Now()
+
+	for {
+		events, final, err := nextJobEvents(c.Request.Context(), jobRepo, jobID, after)
+		if err != nil {
+			// Client reconnects and resumes from the last event it has received.
+			logger.Errorf("failed to stream events of job %s, error is: %s", jobID, err)
+			return
+		}
+		for _, event := range events {
+			writeEvent(c, strconv.Itoa(event.Seq), event.Type, event)
+			after = event.Seq
+			lastWrite = time.Now()
+		}
+		if final {
+			return
+		}
+		if time.Since(lastWrite) >= keepAliveInterval {
+			writeKeepAlive(c)
+			lastWrite = time.Now()
+		}
+
+		// Streams are ended cleanly, so the client reconnects and resumes from the last event.
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-deadline:
+			logger.Infof("stream of job %s events is about to exceed write timeout, ending it", jobID)
+			return
+		case <-shuttingDown(c.Request.Context()):
+			logger.Infof("server is shutting down, ending stream of job %s events", jobID)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// nextJobEvents returns events of the job following event after and whether the last of them
+// is the final one. Final event is made up from the job when it's finished, but its final event
+// is lost, so stream always ends.
+func nextJobEvents(ctx context.Context, jobRepo jobrepo.JobRepository, jobID string, after int) ([]domain.JobEvent, bool, error) {
+	events, err := jobRepo.GetJobEvents(ctx, jobID, after)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get events, error is: %s", err)
+	}
+	if len(events) != 0 {
+		return events, events[len(events)-1].Final(), nil
+	}
+
+	job, err := jobRepo.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get job, error is: %s", err)
+	}
+	if !job.Finished() {
+		return events, false, nil
+	}
+
+	// Job may have finished after events were read.
+	events, err = jobRepo.GetJobEvents(ctx, jobID, after)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get events, error is: %s", err)
+	}
+	if len(events) != 0 && events[len(events)-1].Final() {
+		return events, true, nil
+	}
+
+	seq := after + len(events) + 1
+	if job.Events >= seq {
+		seq = job.Events + 1
+	}
+	events = append(events, domain.JobEvent{
+		JobID:         job.ID,
+		Seq:           seq,
+		Type:          job.State,
+		Stage:         job.Stage,
+		RowsTotal:     job.RowsTotal,
+		RowsProcessed: job.RowsProcessed,
+		Errors:        job.Errors,
+		CreatedAt:     job.UpdatedAt,
+	})
+
+	return events, true, nil
+}
@@ -0,0 +1,271 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	jobrepo "github.com/hackfeed/remrratality/backend/internal/store/job_repo"
+	internalTesting "github.com/hackfeed/remrratality/backend/internal/utils/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetJobHandler(t *testing.T) {
+	type testInput struct {
+		keys   map[string]interface{}
+		params []gin.Param
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id": 1,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine organization",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":   "org",
+				"job_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get job_repo",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":   "org",
+					"job_repo": &jobrepo.JobRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "notFoundJob"}},
+			},
+			want: testWant{
+				code:    http.StatusNotFound,
+				message: "Job not found",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":   "org",
+					"job_repo": &jobrepo.JobRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "otherOrgJob"}},
+			},
+			want: testWant{
+				code:    http.StatusNotFound,
+				message: "Job not found",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":   "org",
+					"job_repo": &jobrepo.JobRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "errorGetJob"}},
+			},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get job",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":   "org",
+					"job_repo": &jobrepo.JobRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "job"}},
+			},
+			want: testWant{
+				code:    http.StatusOK,
+				message: "\"rows_processed\":1",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(test.input.keys, nil, test.input.params)
+		GetJob(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message))
+	}
+}
+
+func TestGetJobEventsHandler(t *testing.T) {
+	type testInput struct {
+		keys        map[string]interface{}
+		params      []gin.Param
+		lastEventID string
+	}
+	type testWant struct {
+		code int
+		body string
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":   "org",
+				"job_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code: http.StatusInternalServerError,
+				body: "{\"message\":\"Failed to get job_repo\"}",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":   "org",
+					"job_repo": &jobrepo.JobRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "otherOrgJob"}},
+			},
+			want: testWant{
+				code: http.StatusNotFound,
+				body: "{\"message\":\"Job not found\"}",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":   "org",
+					"job_repo": &jobrepo.JobRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "job"}},
+			},
+			want: testWant{
+				code: http.StatusOK,
+				body: "id:1\nevent:progress\ndata:{\"job_id\":\"job\",\"seq\":1,\"type\":\"progress\",\"stage\":\"inserting\",\"rows_total\":3,\"rows_processed\":1,\"created_at\":\"0001-01-01T00:00:00Z\"}\n\n" +
+					"id:2\nevent:succeeded\ndata:{\"job_id\":\"job\",\"seq\":2,\"type\":\"succeeded\",\"stage\":\"inserting\",\"rows_total\":3,\"rows_processed\":3,\"created_at\":\"0001-01-01T00:00:00Z\"}\n\n",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":   "org",
+					"job_repo": &jobrepo.JobRepositoryMock{},
+				},
+				params:      []gin.Param{{Key: "id", Value: "job"}},
+				lastEventID: "1",
+			},
+			want: testWant{
+				code: http.StatusOK,
+				body: "id:2\nevent:succeeded\ndata:{\"job_id\":\"job\",\"seq\":2,\"type\":\"succeeded\",\"stage\":\"inserting\",\"rows_total\":3,\"rows_processed\":3,\"created_at\":\"0001-01-01T00:00:00Z\"}\n\n",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(test.input.keys, nil, test.input.params)
+		c.Request.Header.Set("Last-Event-ID", test.input.lastEventID)
+		GetJobEvents(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, test.want.body, w.Body.String())
+	}
+}
+
+func TestGetJobEventsDeadline(t *testing.T) {
+	c, w := internalTesting.CreateGinContext(map[string]interface{}{
+		"org_id":   "org",
+		"job_repo": &jobrepo.JobRepositoryMock{},
+	}, nil, []gin.Param{{Key: "id", Value: "job"}})
+	// All events are received, so running job's stream stays open until write deadline is near.
+	c.Request.Header.Set("Last-Event-ID", "2")
+	srv := &http.Server{WriteTimeout: 200 * time.Millisecond}
+	c.Request = c.Request.WithContext(context.WithValue(context.Background(), http.ServerContextKey, srv))
+
+	started := time.Now()
+	GetJobEvents(c)
+
+	assert.Less(t, int64(time.Since(started)), int64(srv.WriteTimeout))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "", w.Body.String())
+}
+
+func TestNextJobEvents(t *testing.T) {
+	type testInput struct {
+		jobID string
+		after int
+	}
+	type testWant struct {
+		types []string
+		seqs  []int
+		final bool
+		err   error
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{jobID: "job", after: 0},
+			want: testWant{
+				types: []string{domain.JobEventProgress, domain.JobSucceeded},
+				seqs:  []int{1, 2},
+				final: true,
+			},
+		},
+		{
+			input: testInput{jobID: "job", after: 2},
+			want: testWant{
+				types: []string{},
+				seqs:  []int{},
+			},
+		},
+		{
+			input: testInput{jobID: "finishedJob", after: 0},
+			want: testWant{
+				types: []string{domain.JobSucceeded},
+				seqs:  []int{3},
+				final: true,
+			},
+		},
+		{
+			input: testInput{jobID: "errorGetJobEvents", after: 0},
+			want: testWant{
+				err: errors.New("failed to get events, error is: error while getting job events"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		events, final, err := nextJobEvents(context.Background(), &jobrepo.JobRepositoryMock{}, test.input.jobID, test.input.after)
+		assert.Equal(t, test.want.err, err)
+		if err != nil {
+			continue
+		}
+		types := make([]string, len(events))
+		seqs := make([]int, len(events))
+		for i, event := range events {
+			types[i] = event.Type
+			seqs[i] = event.Seq
+		}
+		assert.Equal(t, test.want.types, types)
+		assert.Equal(t, test.want.seqs, seqs)
+		assert.Equal(t, test.want.final, final)
+	}
+}
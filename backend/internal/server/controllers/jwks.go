@@ -0,0 +1,16 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/utils/user_validation"
+)
+
+// JWKS serves JSON Web Key Set with all keys accepted for tokens verification, so other
+// services can verify tokens without the signing key. It's served outside of API base
+// path, hence not documented in swagger.
+func JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, user_validation.GetJWKS())
+}
@@ -0,0 +1,16 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+
+	internalTesting "github.com/hackfeed/remrratality/backend/internal/utils/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWKSHandler(t *testing.T) {
+	c, w := internalTesting.CreateGinContext(nil, nil, nil)
+	JWKS(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "{\"keys\":[]}", w.Body.String())
+}
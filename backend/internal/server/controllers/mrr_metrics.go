@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hackfeed/remrratality/backend/internal/calendar"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	storagerepo "github.com/hackfeed/remrratality/backend/internal/store/storage_repo"
+)
+
+// createMetrics forms MPP of the period to calculate metrics of mrr, since cached analytics
+// keeps MRR components only.
+func createMetrics(ctx context.Context, storageRepo storagerepo.StorageRepository, orgID, fileID string, periods []calendar.Period, mrr domain.TotalMRR) (domain.MRRMetrics, error) {
+	mpp, err := formMPP(ctx, storageRepo, periods, orgID, fileID)
+	if err != nil {
+		return domain.MRRMetrics{}, fmt.Errorf("failed to form mpp, error is: %s", err)
+	}
+	reportProgress(ctx, "metrics", "Metrics are calculated for %d periods", len(periods))
+
+	return calculateMetrics(mpp, mrr), nil
+}
+
+func calculateMetrics(mpp []domain.MPP, mrr domain.TotalMRR) domain.MRRMetrics {
+	monthsCount := len(mrr.Total)
+	metrics := domain.MRRMetrics{
+		MRR:           make([]float32, monthsCount),
+		ARR:           make([]float32, monthsCount),
+		Accounts:      make([]int, monthsCount),
+		ARPA:          make([]float32, monthsCount),
+		CustomerChurn: make([]float32, monthsCount),
+		LTV:           make([]float32, monthsCount),
+		QuickRatio:    make([]float32, monthsCount),
+	}
+	churned := make([]int, monthsCount)
+
+	for _, mppEntry := range mpp {
+		for i := 0; i < monthsCount && i < len(mppEntry.Months); i++ {
+			if mppEntry.Months[i] > 0 {
+				metrics.MRR[i] += mppEntry.Months[i]
+				metrics.Accounts[i]++
+			} else if i > 0 && mppEntry.Months[i-1] > 0 {
+				churned[i]++
+			}
+		}
+	}
+
+	for i := 0; i < monthsCount; i++ {
+		metrics.ARR[i] = metrics.MRR[i] * 12
+		if metrics.Accounts[i] > 0 {
+			metrics.ARPA[i] = metrics.MRR[i] / float32(metrics.Accounts[i])
+		}
+		if i > 0 && metrics.Accounts[i-1] > 0 {
+			metrics.CustomerChurn[i] = float32(churned[i]) / float32(metrics.Accounts[i-1])
+		}
+		if metrics.CustomerChurn[i] > 0 {
+			metrics.LTV[i] = metrics.ARPA[i] / metrics.CustomerChurn[i]
+		}
+
+		gained := mrr.New[i] + mrr.Expansion[i] + mrr.Reactivation[i]
+		lost := -(mrr.Churn[i] + mrr.Contraction[i])
+		if lost > 0 {
+			metrics.QuickRatio[i] = gained / lost
+		}
+	}
+
+	return metrics
+}
@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateMetrics(t *testing.T) {
+	mpp := []domain.MPP{
+		{CustomerID: 1, Months: []float32{100, 150, 150}},
+		{CustomerID: 2, Months: []float32{100, 100, 0}},
+		{CustomerID: 3, Months: []float32{0, 50, 25}},
+		{CustomerID: 4, Months: []float32{0, 0, 0}},
+	}
+	mrr := convertRawMRR(calculateTotalMRR(mpp))
+
+	metrics := calculateMetrics(mpp, mrr)
+
+	assert.InDeltaSlice(t, []float32{200, 300, 175}, metrics.MRR, 0.001)
+	assert.InDeltaSlice(t, []float32{2400, 3600, 2100}, metrics.ARR, 0.001)
+	assert.Equal(t, []int{2, 3, 2}, metrics.Accounts)
+	assert.InDeltaSlice(t, []float32{100, 100, 87.5}, metrics.ARPA, 0.001)
+	assert.InDeltaSlice(t, []float32{0, 0, 1.0 / 3}, metrics.CustomerChurn, 0.001)
+	assert.InDeltaSlice(t, []float32{0, 0, 262.5}, metrics.LTV, 0.001)
+	// The second month gains new and expansion MRR without losing any, the third one loses
+	// churn of 100 and contraction of 25 without gaining.
+	assert.InDeltaSlice(t, []float32{0, 0, 0}, metrics.QuickRatio, 0.001)
+
+	mpp = append(mpp, domain.MPP{CustomerID: 5, Months: []float32{0, 0, 50}})
+	metrics = calculateMetrics(mpp, convertRawMRR(calculateTotalMRR(mpp)))
+	assert.InDeltaSlice(t, []float32{0, 0, 0.4}, metrics.QuickRatio, 0.001)
+}
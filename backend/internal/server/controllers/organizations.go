@@ -0,0 +1,343 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
+	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	orgrepo "github.com/hackfeed/remrratality/backend/internal/store/org_repo"
+	userrepo "github.com/hackfeed/remrratality/backend/internal/store/user_repo"
+)
+
+var errLastOwner = errors.New("organization should have at least one owner")
+
+// LoadOrganizations godoc
+// @Summary Loading user's organizations list
+// @Description Loading organizations user is a member of
+// @Tags organizations
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} models.ResponseSuccessOrganizations
+// @Failure 401 {object} models.Response
+// @Failure 500 {object} models.Response
+// @Security ApiKeyAuth
+// @Router /orgs [get]
+func LoadOrganizations(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	userID, ok := c.MustGet("user_id").(string)
+	if !ok {
+		logger.Errorf("failed to get user_id from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Unable to determine logged in user",
+		})
+		return
+	}
+	orgRepo, ok := c.MustGet("org_repo").(orgrepo.OrgRepository)
+	if !ok {
+		logger.Errorf("failed to get org_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get org_repo",
+		})
+		return
+	}
+
+	orgs, err := orgRepo.GetOrganizations(c.Request.Context(), userID)
+	if err != nil {
+		logger.Errorf("failed to load organizations for user_id %s, error is: %s", userID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to fetch organizations",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseSuccessOrganizations{
+		Message:       "Organizations are loaded",
+		Organizations: orgs,
+	})
+}
+
+// CreateOrganization godoc
+// @Summary Creating organization
+// @Description Creating organization with logged in user as its owner
+// @Tags organizations
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} models.ResponseSuccessOrganization
+// @Failure 400 {object} models.Response
+// @Failure 401 {object} models.Response
+// @Failure 500 {object} models.Response
+// @Security ApiKeyAuth
+// @Param request body models.Organization true "Organization's name"
+// @Router /orgs [post]
+func CreateOrganization(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	email, ok := c.MustGet("email").(string)
+	if !ok {
+		logger.Errorf("failed to get email from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Unable to determine logged in user",
+		})
+		return
+	}
+	userID, ok := c.MustGet("user_id").(string)
+	if !ok {
+		logger.Errorf("failed to get user_id from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Unable to determine logged in user",
+		})
+		return
+	}
+	orgRepo, ok := c.MustGet("org_repo").(orgrepo.OrgRepository)
+	if !ok {
+		logger.Errorf("failed to get org_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get org_repo",
+		})
+		return
+	}
+
+	var req models.Organization
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Errorf("failed to parse request body, error is: %s", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Message: "Failed to parse request body",
+		})
+		return
+	}
+
+	org, err := orgRepo.AddOrganization(c.Request.Context(), domain.Organization{
+		Name:    req.Name,
+		Members: []domain.Member{{UserID: userID, Email: email, Role: domain.RoleOwner}},
+	})
+	if err != nil {
+		logger.Errorf("failed to add organization for user_id %s, error is: %s", userID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to create organization",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseSuccessOrganization{
+		Message:      "Organization is created",
+		Organization: org,
+	})
+}
+
+// AddMember godoc
+// @Summary Adding member to organization
+// @Description Adding registered user to organization or changing role of existing member
+// @Tags organizations
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} models.ResponseSuccessOrganization
+// @Failure 400 {object} models.Response
+// @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 404 {object} models.Response
+// @Failure 500 {object} models.Response
+// @Security ApiKeyAuth
+// @Param org_id path string true "Organization ID"
+// @Param request body models.Member true "Member's email and role"
+// @Router /orgs/{org_id}/members [post]
+func AddMember(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	orgID, ok := c.MustGet("org_id").(string)
+	if !ok {
+		logger.Errorf("failed to get org_id from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Unable to determine organization",
+		})
+		return
+	}
+	orgRepo, ok := c.MustGet("org_repo").(orgrepo.OrgRepository)
+	if !ok {
+		logger.Errorf("failed to get org_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get org_repo",
+		})
+		return
+	}
+	userRepo, ok := c.MustGet("user_repo").(userrepo.UserRepository)
+	if !ok {
+		logger.Errorf("failed to get user_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get user_repo",
+		})
+		return
+	}
+
+	var req models.Member
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Errorf("failed to parse request body, error is: %s", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Message: "Failed to parse request body",
+		})
+		return
+	}
+
+	user, err := userRepo.GetUser(c.Request.Context(), req.Email)
+	if err != nil || user.UserID == "" {
+		logger.Errorf("failed to get user with email %s, error is: %v", req.Email, err)
+		c.AbortWithStatusJSON(http.StatusNotFound, models.Response{
+			Message: "User with given email doesn't exist",
+		})
+		return
+	}
+
+	org, err := addMember(c.Request.Context(), orgRepo, orgID, domain.Member{UserID: user.UserID, Email: user.Email, Role: req.Role})
+	if errors.Is(err, errLastOwner) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Message: "Organization should have at least one owner",
+		})
+		return
+	}
+	if err != nil {
+		logger.Errorf("failed to add member %s to org_id %s, error is: %s", user.UserID, orgID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to add member",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseSuccessOrganization{
+		Message:      "Member is added",
+		Organization: org,
+	})
+}
+
+// DeleteMember godoc
+// @Summary Removing member from organization
+// @Description Removing member from organization, the last owner can't be removed
+// @Tags organizations
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} models.ResponseSuccessOrganization
+// @Failure 400 {object} models.Response
+// @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 404 {object} models.Response
+// @Failure 500 {object} models.Response
+// @Security ApiKeyAuth
+// @Param org_id path string true "Organization ID"
+// @Param user_id path string true "Member's user ID"
+// @Router /orgs/{org_id}/members/{user_id} [delete]
+func DeleteMember(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	orgID, ok := c.MustGet("org_id").(string)
+	if !ok {
+		logger.Errorf("failed to get org_id from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Unable to determine organization",
+		})
+		return
+	}
+	orgRepo, ok := c.MustGet("org_repo").(orgrepo.OrgRepository)
+	if !ok {
+		logger.Errorf("failed to get org_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get org_repo",
+		})
+		return
+	}
+
+	memberID := c.Param("user_id")
+
+	org, err := deleteMember(c.Request.Context(), orgRepo, orgID, memberID)
+	if errors.Is(err, errLastOwner) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Message: "Organization should have at least one owner",
+		})
+		return
+	}
+	if err != nil {
+		logger.Errorf("failed to delete member %s from org_id %s, error is: %s", memberID, orgID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to delete member",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseSuccessOrganization{
+		Message:      "Member is deleted",
+		Organization: org,
+	})
+}
+
+func addMember(ctx context.Context, orgRepo orgrepo.OrgRepository, orgID string, member domain.Member) (domain.Organization, error) {
+	org, err := orgRepo.GetOrganization(ctx, orgID)
+	if err != nil {
+		return domain.Organization{}, fmt.Errorf("failed to get organization, error is: %s", err)
+	}
+
+	members := removeMember(org.Members, member.UserID)
+	members = append(members, member)
+	if countOwners(members) == 0 {
+		return domain.Organization{}, errLastOwner
+	}
+
+	org.Members = members
+	if err = orgRepo.UpdateOrganization(ctx, orgID, org); err != nil {
+		return domain.Organization{}, fmt.Errorf("failed to update organization, error is: %s", err)
+	}
+
+	return org, nil
+}
+
+func deleteMember(ctx context.Context, orgRepo orgrepo.OrgRepository, orgID, userID string) (domain.Organization, error) {
+	org, err := orgRepo.GetOrganization(ctx, orgID)
+	if err != nil {
+		return domain.Organization{}, fmt.Errorf("failed to get organization, error is: %s", err)
+	}
+
+	members := removeMember(org.Members, userID)
+	if countOwners(members) == 0 {
+		return domain.Organization{}, errLastOwner
+	}
+
+	org.Members = members
+	if err = orgRepo.UpdateOrganization(ctx, orgID, org); err != nil {
+		return domain.Organization{}, fmt.Errorf("failed to update organization, error is: %s", err)
+	}
+
+	return org, nil
+}
+
+func removeMember(members []domain.Member, userID string) []domain.Member {
+	newMembers := make([]domain.Member, 0)
+	for _, member := range members {
+		if member.UserID != userID {
+			newMembers = append(newMembers, member)
+		}
+	}
+	return newMembers
+}
+
+func countOwners(members []domain.Member) int {
+	count := 0
+	for _, member := range members {
+		if member.Role == domain.RoleOwner {
+			count++
+		}
+	}
+	return count
+}
+
+func isSoleOwner(org domain.Organization, userID string) bool {
+	for _, member := range org.Members {
+		if member.UserID == userID {
+			return member.Role == domain.RoleOwner && countOwners(org.Members) == 1
+		}
+	}
+	return false
+}
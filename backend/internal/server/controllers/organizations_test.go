@@ -0,0 +1,471 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	orgrepo "github.com/hackfeed/remrratality/backend/internal/store/org_repo"
+	userrepo "github.com/hackfeed/remrratality/backend/internal/store/user_repo"
+	internalTesting "github.com/hackfeed/remrratality/backend/internal/utils/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadOrganizationsHandler(t *testing.T) {
+	type testInput struct {
+		keys map[string]interface{}
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"user_id": 1,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine logged in user",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"user_id":  "user",
+				"org_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get org_repo",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"user_id":  "errorGetOrganizations",
+				"org_repo": &orgrepo.OrgRepositoryMock{},
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to fetch organizations",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"user_id":  "user",
+				"org_repo": &orgrepo.OrgRepositoryMock{},
+			}},
+			want: testWant{
+				code:    http.StatusOK,
+				message: "Organizations are loaded",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(test.input.keys, nil, nil)
+		LoadOrganizations(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message))
+	}
+}
+
+func TestCreateOrganizationHandler(t *testing.T) {
+	type testInput struct {
+		keys map[string]interface{}
+		body interface{}
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"email": 1,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine logged in user",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"email":   "test@test.com",
+				"user_id": 1,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine logged in user",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"email":    "test@test.com",
+				"user_id":  "user",
+				"org_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get org_repo",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"email":    "test@test.com",
+				"user_id":  "user",
+				"org_repo": &orgrepo.OrgRepositoryMock{},
+			}},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Failed to parse request body",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"email":    "test@test.com",
+					"user_id":  "user",
+					"org_repo": &orgrepo.OrgRepositoryMock{},
+				},
+				body: models.Organization{Name: "errorAddOrganization"},
+			},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to create organization",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"email":    "test@test.com",
+					"user_id":  "user",
+					"org_repo": &orgrepo.OrgRepositoryMock{},
+				},
+				body: models.Organization{Name: "Finance"},
+			},
+			want: testWant{
+				code:    http.StatusOK,
+				message: "{\"user_id\":\"user\",\"email\":\"test@test.com\",\"role\":\"owner\"}",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(test.input.keys, test.input.body, nil)
+		CreateOrganization(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message))
+	}
+}
+
+func TestAddMemberHandler(t *testing.T) {
+	type testInput struct {
+		keys map[string]interface{}
+		body interface{}
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id": 1,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine organization",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":   "org",
+				"org_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get org_repo",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":    "org",
+				"org_repo":  &orgrepo.OrgRepositoryMock{},
+				"user_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get user_repo",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":    "org",
+					"org_repo":  &orgrepo.OrgRepositoryMock{},
+					"user_repo": &userrepo.UserRepositoryMock{},
+				},
+				body: models.Member{Email: "test@test.com", Role: "admin"},
+			},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Failed to parse request body",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":    "org",
+					"org_repo":  &orgrepo.OrgRepositoryMock{},
+					"user_repo": &userrepo.UserRepositoryMock{},
+				},
+				body: models.Member{Email: "viewer@test.com", Role: domain.RoleViewer},
+			},
+			want: testWant{
+				code:    http.StatusOK,
+				message: "Member is added",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":    "errorUpdateOrganization",
+					"org_repo":  &orgrepo.OrgRepositoryMock{},
+					"user_repo": &userrepo.UserRepositoryMock{},
+				},
+				body: models.Member{Email: "test@test.com", Role: domain.RoleEditor},
+			},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to add member",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(test.input.keys, test.input.body, nil)
+		AddMember(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message))
+	}
+}
+
+func TestDeleteMemberHandler(t *testing.T) {
+	type testInput struct {
+		keys   map[string]interface{}
+		params []gin.Param
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id": 1,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine organization",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":   "org",
+				"org_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get org_repo",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":   "org",
+					"org_repo": &orgrepo.OrgRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "user_id", Value: "org"}},
+			},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Organization should have at least one owner",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":   "errorGetOrganization",
+					"org_repo": &orgrepo.OrgRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "user_id", Value: "viewer"}},
+			},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to delete member",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":   "org",
+					"org_repo": &orgrepo.OrgRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "user_id", Value: "viewer"}},
+			},
+			want: testWant{
+				code:    http.StatusOK,
+				message: "Member is deleted",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(test.input.keys, nil, test.input.params)
+		DeleteMember(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message))
+	}
+}
+
+func TestAddMember(t *testing.T) {
+	type testInput struct {
+		orgID  string
+		member domain.Member
+	}
+	type testWant struct {
+		members []domain.Member
+		err     error
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{
+				orgID:  "errorGetOrganization",
+				member: domain.Member{UserID: "new", Role: domain.RoleViewer},
+			},
+			want: testWant{
+				err: errors.New("failed to get organization, error is: error while getting organization"),
+			},
+		},
+		{
+			input: testInput{
+				orgID:  "org",
+				member: domain.Member{UserID: "org", Role: domain.RoleViewer},
+			},
+			want: testWant{
+				err: errLastOwner,
+			},
+		},
+		{
+			input: testInput{
+				orgID:  "errorUpdateOrganization",
+				member: domain.Member{UserID: "new", Role: domain.RoleViewer},
+			},
+			want: testWant{
+				err: errors.New("failed to update organization, error is: error while updating organization"),
+			},
+		},
+		{
+			input: testInput{
+				orgID:  "org",
+				member: domain.Member{UserID: "viewer", Email: "viewer@test.com", Role: domain.RoleEditor},
+			},
+			want: testWant{
+				members: []domain.Member{
+					{UserID: "org", Email: "owner@test.com", Role: domain.RoleOwner},
+					{UserID: "editor", Email: "editor@test.com", Role: domain.RoleEditor},
+					{UserID: "viewer", Email: "viewer@test.com", Role: domain.RoleEditor},
+				},
+				err: nil,
+			},
+		},
+	}
+
+	orgMock := &orgrepo.OrgRepositoryMock{}
+
+	for _, test := range tests {
+		org, err := addMember(context.Background(), orgMock, test.input.orgID, test.input.member)
+		assert.Equal(t, test.want.members, org.Members)
+		assert.Equal(t, test.want.err, err)
+	}
+}
+
+func TestDeleteMember(t *testing.T) {
+	type testInput struct {
+		orgID, userID string
+	}
+	type testWant struct {
+		members []domain.Member
+		err     error
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{
+				orgID:  "errorGetOrganization",
+				userID: "viewer",
+			},
+			want: testWant{
+				err: errors.New("failed to get organization, error is: error while getting organization"),
+			},
+		},
+		{
+			input: testInput{
+				orgID:  "org",
+				userID: "org",
+			},
+			want: testWant{
+				err: errLastOwner,
+			},
+		},
+		{
+			input: testInput{
+				orgID:  "org",
+				userID: "editor",
+			},
+			want: testWant{
+				members: []domain.Member{
+					{UserID: "org", Email: "owner@test.com", Role: domain.RoleOwner},
+					{UserID: "viewer", Email: "viewer@test.com", Role: domain.RoleViewer},
+				},
+				err: nil,
+			},
+		},
+	}
+
+	orgMock := &orgrepo.OrgRepositoryMock{}
+
+	for _, test := range tests {
+		org, err := deleteMember(context.Background(), orgMock, test.input.orgID, test.input.userID)
+		assert.Equal(t, test.want.members, org.Members)
+		assert.Equal(t, test.want.err, err)
+	}
+}
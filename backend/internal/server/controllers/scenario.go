@@ -0,0 +1,227 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/calendar"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
+	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	datasetrepo "github.com/hackfeed/remrratality/backend/internal/store/dataset_repo"
+	storagerepo "github.com/hackfeed/remrratality/backend/internal/store/storage_repo"
+)
+
+// CreateScenario godoc
+// @Summary Create MRR analytics of what-if scenario
+// @Description Creating MRR analytics data for given period as it is and as it would be with adjustments, side by side.
+// @Description Plan multipliers scale paid amounts of monthly and annually invoices, excluded customers are left out.
+// @Description Churn reduction is the share of churned customers who keep paying their last amount instead,
+// @Description churn rate is the share of paying customers who churn every month on top of observed churn,
+// @Description and expansion rate grows revenue of every paying customer every month.
+// @Description Customers are picked for synthetic churn in order of their IDs, so scenario is reproducible.
+// @Tags analytics
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} models.ResponseSuccessScenario
+// @Failure 400 {object} models.Response
+// @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 404 {object} models.Response
+// @Failure 409 {object} models.Response
+// @Failure 429 {object} models.Response
+// @Header 429 {integer} Retry-After "Seconds to wait before the next request"
+// @Failure 500 {object} models.Response
+// @Security ApiKeyAuth
+// @Param request body models.Scenario true "Parameters for MRR scenario"
+// @Param org header string false "Organization ID, personal organization is used if omitted"
+// @Router /analytics/scenario [post]
+func CreateScenario(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	orgID, ok := c.MustGet("org_id").(string)
+	if !ok {
+		logger.Errorf("failed to get org_id from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Unable to determine organization",
+		})
+		return
+	}
+	datasetRepo, ok := c.MustGet("dataset_repo").(datasetrepo.DatasetRepository)
+	if !ok {
+		logger.Errorf("failed to get dataset_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get dataset_repo",
+		})
+		return
+	}
+	storageRepo, ok := c.MustGet("storage_repo").(storagerepo.StorageRepository)
+	if !ok {
+		logger.Errorf("failed to get storage_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get storage_repo",
+		})
+		return
+	}
+
+	var req models.Scenario
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Errorf("failed to parse request body, error is: %s", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Message: "Failed to parse request body",
+		})
+		return
+	}
+
+	c.Set("audit_details", map[string]interface{}{
+		"filename":     req.Filename,
+		"period_start": req.PeriodStart,
+		"period_end":   req.PeriodEnd,
+		"adjustments":  req.Adjustments,
+	})
+
+	if _, ok := readyDataset(c, datasetRepo, orgID, req.Filename); !ok {
+		return
+	}
+
+	periods, baseline, scenario, err := createScenario(c.Request.Context(), storageRepo, orgID, req)
+	if err != nil {
+		logger.Errorf("failed to get MRR scenario, error is: %s", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Message: "Failed to get analytics. Please ensure that period start is earlier than period end and data exists in given period",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseSuccessScenario{
+		Message:  "Scenario is created",
+		Months:   calendar.Labels(periods),
+		Periods:  calendar.ISOLabels(periods),
+		Baseline: baseline,
+		Scenario: scenario,
+	})
+}
+
+func createScenario(ctx context.Context, storageRepo storagerepo.StorageRepository, orgID string, req models.Scenario) ([]calendar.Period, domain.TotalMRR, domain.TotalMRR, error) {
+	var baseline, scenario domain.TotalMRR
+
+	periods, err := analyzedPeriods(ctx, req.PeriodStart, req.PeriodEnd)
+	if err != nil {
+		return nil, baseline, scenario, err
+	}
+
+	invoices, err := loadInvoices(ctx, storageRepo, orgID, req.Filename, periods)
+	if err != nil {
+		return periods, baseline, scenario, fmt.Errorf("failed to form mpp, error is: %s", err)
+	}
+
+	baselineMPP := spreadInvoices(ctx, invoices, periods)
+	scenarioMPP := spreadInvoices(ctx, scaleInvoices(invoices, req.Adjustments.PlanMultipliers), periods)
+	scenarioMPP = adjustMPP(scenarioMPP, req.Adjustments)
+
+	return periods, scenarioMRR(baselineMPP, len(periods)), scenarioMRR(scenarioMPP, len(periods)), nil
+}
+
+// scaleInvoices returns copies of invoices with paid amounts scaled by multiplier of their plan,
+// invoices of plans other than annually are scaled as monthly ones.
+func scaleInvoices(invoices []domain.Invoice, multipliers map[string]float64) []domain.Invoice {
+	scaled := make([]domain.Invoice, len(invoices))
+	for i, invoice := range invoices {
+		plan := "monthly"
+		if invoice.PaidPlan == "annually" {
+			plan = "annually"
+		}
+		if multiplier, ok := multipliers[plan]; ok {
+			invoice.PaidAmount *= float32(multiplier)
+		}
+		scaled[i] = invoice
+	}
+
+	return scaled
+}
+
+// adjustMPP leaves excluded customers out and applies churn and expansion adjustments to revenue
+// of the rest. Customers are ordered by their IDs, churn adjustments pick every customer whose
+// accumulated share of eligible customers reaches one, so results are the same on every run.
+func adjustMPP(mpp []domain.MPP, adjustments models.ScenarioAdjustments) []domain.MPP {
+	excluded := make(map[uint32]bool, len(adjustments.ExcludedCustomers))
+	for _, customerID := range adjustments.ExcludedCustomers {
+		excluded[customerID] = true
+	}
+
+	adjusted := make([]domain.MPP, 0, len(mpp))
+	for _, entry := range mpp {
+		if excluded[entry.CustomerID] {
+			continue
+		}
+		months := make([]float32, len(entry.Months))
+		copy(months, entry.Months)
+		adjusted = append(adjusted, domain.MPP{CustomerID: entry.CustomerID, Months: months})
+	}
+	sort.Slice(adjusted, func(i, j int) bool {
+		return adjusted[i].CustomerID < adjusted[j].CustomerID
+	})
+
+	if len(adjusted) == 0 {
+		return adjusted
+	}
+	var retained, churned float64
+	for i := 1; i < len(adjusted[0].Months); i++ {
+		for _, entry := range adjusted {
+			previous, current := entry.Months[i-1], entry.Months[i]
+			if previous > 0 && current == 0 {
+				if pickCustomer(&retained, adjustments.ChurnReduction) {
+					for j := i; j < len(entry.Months) && entry.Months[j] == 0; j++ {
+						entry.Months[j] = previous
+					}
+				}
+			} else if previous > 0 && current > 0 {
+				if pickCustomer(&churned, adjustments.ChurnRate) {
+					for j := i; j < len(entry.Months); j++ {
+						entry.Months[j] = 0
+					}
+				}
+			}
+		}
+	}
+
+	if adjustments.ExpansionRate > 0 {
+		for _, entry := range adjusted {
+			growth := float32(1)
+			for i := range entry.Months {
+				if entry.Months[i] == 0 {
+					continue
+				}
+				entry.Months[i] *= growth
+				growth *= float32(1 + adjustments.ExpansionRate)
+			}
+		}
+	}
+
+	return adjusted
+}
+
+// pickCustomer adds share of the next eligible customer to accumulated one and tells whether
+// the customer is picked, which happens once accumulated share reaches one.
+func pickCustomer(accumulated *float64, share float64) bool {
+	*accumulated += share
+	// Tolerance keeps shares like 0.1 picking every tenth customer despite rounding.
+	if *accumulated < 1-1e-9 {
+		return false
+	}
+	*accumulated--
+	return true
+}
+
+// scenarioMRR calculates MRR of mpp, which is empty when every customer is excluded.
+func scenarioMRR(mpp []domain.MPP, monthsCount int) domain.TotalMRR {
+	if len(mpp) == 0 {
+		return convertRawMRR(make([]domain.MRR, monthsCount))
+	}
+
+	return convertRawMRR(calculateTotalMRR(mpp))
+}
@@ -0,0 +1,231 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	datasetrepo "github.com/hackfeed/remrratality/backend/internal/store/dataset_repo"
+	storagerepo "github.com/hackfeed/remrratality/backend/internal/store/storage_repo"
+	internalTesting "github.com/hackfeed/remrratality/backend/internal/utils/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateScenarioHandler(t *testing.T) {
+	type testInput struct {
+		keys map[string]interface{}
+		body interface{}
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	repos := func(orgID string) map[string]interface{} {
+		return map[string]interface{}{
+			"org_id":       orgID,
+			"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+			"storage_repo": &storagerepo.StorageRepositoryMock{},
+		}
+	}
+	period := models.Period{Filename: "file.csv", PeriodStart: "2021-10-01", PeriodEnd: "2021-11-01"}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id": 5,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine organization",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":       "org",
+				"dataset_repo": &datasetrepo.DatasetRepositoryMock{},
+				"storage_repo": "invalidType",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get storage_repo",
+			},
+		},
+		{
+			input: testInput{keys: repos("org")},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Failed to parse request body",
+			},
+		},
+		{
+			input: testInput{
+				keys: repos("org"),
+				body: models.Scenario{Period: period, Adjustments: models.ScenarioAdjustments{PlanMultipliers: map[string]float64{"weekly": 2}}},
+			},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Failed to parse request body",
+			},
+		},
+		{
+			input: testInput{
+				keys: repos("org"),
+				body: models.Scenario{Period: period, Adjustments: models.ScenarioAdjustments{ChurnRate: 1.5}},
+			},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Failed to parse request body",
+			},
+		},
+		{
+			input: testInput{
+				keys: repos("org"),
+				body: models.Scenario{Period: models.Period{Filename: "file.csv", PeriodStart: "2021-11-01", PeriodEnd: "2021-10-01"}},
+			},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Failed to get analytics",
+			},
+		},
+		{
+			input: testInput{keys: repos("org"), body: models.Scenario{Period: models.Period{Filename: "importingDataset", PeriodStart: "2021-10-01", PeriodEnd: "2021-11-01"}}},
+			want: testWant{
+				code:    http.StatusConflict,
+				message: "File is not imported yet",
+			},
+		},
+		{
+			input: testInput{keys: repos("emptyGetInvoicesByPeriod"), body: models.Scenario{Period: period}},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Failed to get analytics",
+			},
+		},
+		{
+			input: testInput{
+				keys: repos("org"),
+				body: models.Scenario{Period: period, Adjustments: models.ScenarioAdjustments{PlanMultipliers: map[string]float64{"monthly": 1.5}}},
+			},
+			want: testWant{
+				code:    http.StatusOK,
+				message: `"baseline":{"New":[100,0],"Old":[0,0],"Reactivation":[0,0],"Expansion":[0,0],"Contraction":[0,0],"Churn":[0,-100],"Total":[100,-100]},"scenario":{"New":[150,0],"Old":[0,0],"Reactivation":[0,0],"Expansion":[0,0],"Contraction":[0,0],"Churn":[0,-150],"Total":[150,-150]}`,
+			},
+		},
+		{
+			input: testInput{
+				keys: repos("org"),
+				body: models.Scenario{Period: period, Adjustments: models.ScenarioAdjustments{ExcludedCustomers: []uint32{0}}},
+			},
+			want: testWant{
+				code:    http.StatusOK,
+				message: `"scenario":{"New":[0,0],"Old":[0,0],"Reactivation":[0,0],"Expansion":[0,0],"Contraction":[0,0],"Churn":[0,0],"Total":[0,0]}`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(test.input.keys, test.input.body, nil)
+		CreateScenario(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message), w.Body.String())
+	}
+}
+
+func TestScaleInvoices(t *testing.T) {
+	invoices := []domain.Invoice{
+		{CustomerID: 1, PaidPlan: "monthly", PaidAmount: 100},
+		{CustomerID: 2, PaidPlan: "annually", PaidAmount: 1200},
+		{CustomerID: 3, PaidPlan: "weekly", PaidAmount: 10},
+	}
+
+	scaled := scaleInvoices(invoices, map[string]float64{"annually": 1.1, "monthly": 2})
+
+	assert.InDelta(t, 200, scaled[0].PaidAmount, 0.001)
+	assert.InDelta(t, 1320, scaled[1].PaidAmount, 0.001)
+	assert.InDelta(t, 20, scaled[2].PaidAmount, 0.001)
+	assert.InDelta(t, 100, invoices[0].PaidAmount, 0.001)
+}
+
+func TestAdjustMPP(t *testing.T) {
+	mpp := []domain.MPP{
+		{CustomerID: 3, Months: []float32{100, 100, 100}},
+		{CustomerID: 1, Months: []float32{100, 0, 0}},
+		{CustomerID: 2, Months: []float32{50, 50, 0}},
+		{CustomerID: 4, Months: []float32{10, 10, 10}},
+	}
+
+	tests := []struct {
+		adjustments models.ScenarioAdjustments
+		want        []domain.MPP
+	}{
+		{
+			adjustments: models.ScenarioAdjustments{ExcludedCustomers: []uint32{4}},
+			want: []domain.MPP{
+				{CustomerID: 1, Months: []float32{100, 0, 0}},
+				{CustomerID: 2, Months: []float32{50, 50, 0}},
+				{CustomerID: 3, Months: []float32{100, 100, 100}},
+			},
+		},
+		{
+			adjustments: models.ScenarioAdjustments{ExcludedCustomers: []uint32{4}, ChurnReduction: 0.5},
+			want: []domain.MPP{
+				{CustomerID: 1, Months: []float32{100, 0, 0}},
+				{CustomerID: 2, Months: []float32{50, 50, 50}},
+				{CustomerID: 3, Months: []float32{100, 100, 100}},
+			},
+		},
+		{
+			adjustments: models.ScenarioAdjustments{ExcludedCustomers: []uint32{4}, ChurnReduction: 1},
+			want: []domain.MPP{
+				{CustomerID: 1, Months: []float32{100, 100, 100}},
+				{CustomerID: 2, Months: []float32{50, 50, 50}},
+				{CustomerID: 3, Months: []float32{100, 100, 100}},
+			},
+		},
+		{
+			adjustments: models.ScenarioAdjustments{ExcludedCustomers: []uint32{4}, ChurnRate: 0.5},
+			want: []domain.MPP{
+				{CustomerID: 1, Months: []float32{100, 0, 0}},
+				{CustomerID: 2, Months: []float32{50, 50, 0}},
+				{CustomerID: 3, Months: []float32{100, 0, 0}},
+			},
+		},
+		{
+			adjustments: models.ScenarioAdjustments{ExcludedCustomers: []uint32{1, 2, 4}, ExpansionRate: 0.1},
+			want: []domain.MPP{
+				{CustomerID: 3, Months: []float32{100, 110, 121}},
+			},
+		},
+		{
+			adjustments: models.ScenarioAdjustments{ExcludedCustomers: []uint32{1, 2, 3, 4}},
+			want:        []domain.MPP{},
+		},
+	}
+
+	for _, test := range tests {
+		adjusted := adjustMPP(mpp, test.adjustments)
+		assert.Equal(t, len(test.want), len(adjusted))
+		for i := range test.want {
+			assert.Equal(t, test.want[i].CustomerID, adjusted[i].CustomerID)
+			assert.InDeltaSlice(t, test.want[i].Months, adjusted[i].Months, 0.001)
+		}
+	}
+	assert.Equal(t, []float32{100, 0, 0}, mpp[1].Months)
+}
+
+func TestPickCustomer(t *testing.T) {
+	var accumulated float64
+	picked := 0
+	for i := 0; i < 100; i++ {
+		if pickCustomer(&accumulated, 0.1) {
+			picked++
+		}
+	}
+	assert.Equal(t, 10, picked)
+}
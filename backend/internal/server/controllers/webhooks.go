@@ -0,0 +1,409 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
+	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	webhookrepo "github.com/hackfeed/remrratality/backend/internal/store/webhook_repo"
+	"github.com/hackfeed/remrratality/backend/internal/webhooks"
+)
+
+// LoadWebhooks godoc
+// @Summary Loading organization's webhooks
+// @Description Loading webhooks of organization, secrets aren't included
+// @Tags webhooks
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} models.ResponseSuccessWebhooks
+// @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 404 {object} models.Response
+// @Failure 500 {object} models.Response
+// @Security ApiKeyAuth
+// @Param org header string false "Organization ID, personal organization is used if omitted"
+// @Router /webhooks [get]
+func LoadWebhooks(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	orgID, ok := c.MustGet("org_id").(string)
+	if !ok {
+		logger.Errorf("failed to get org_id from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Unable to determine organization",
+		})
+		return
+	}
+	webhookRepo, ok := c.MustGet("webhook_repo").(webhookrepo.WebhookRepository)
+	if !ok {
+		logger.Errorf("failed to get webhook_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get webhook_repo",
+		})
+		return
+	}
+
+	hooks, err := webhookRepo.GetWebhooks(c.Request.Context(), orgID)
+	if err != nil {
+		logger.Errorf("failed to load webhooks for org_id %s, error is: %s", orgID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to fetch webhooks",
+		})
+		return
+	}
+	for i := range hooks {
+		hooks[i].Secret = ""
+	}
+
+	c.JSON(http.StatusOK, models.ResponseSuccessWebhooks{
+		Message:  "Webhooks are loaded",
+		Webhooks: hooks,
+	})
+}
+
+// CreateWebhook godoc
+// @Summary Creating webhook
+// @Description Creating webhook receiving given events of organization. Payloads are signed with the secret returned in response, it isn't shown again.
+// @Description Webhook URL should point to a public address, loopback, private, link-local and multicast ones are refused
+// @Tags webhooks
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} models.ResponseSuccessWebhook
+// @Failure 400 {object} models.Response
+// @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 404 {object} models.Response
+// @Failure 500 {object} models.Response
+// @Security ApiKeyAuth
+// @Param request body models.Webhook true "Webhook's URL and events"
+// @Param org header string false "Organization ID, personal organization is used if omitted"
+// @Router /webhooks [post]
+func CreateWebhook(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	orgID, ok := c.MustGet("org_id").(string)
+	if !ok {
+		logger.Errorf("failed to get org_id from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Unable to determine organization",
+		})
+		return
+	}
+	webhookRepo, ok := c.MustGet("webhook_repo").(webhookrepo.WebhookRepository)
+	if !ok {
+		logger.Errorf("failed to get webhook_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get webhook_repo",
+		})
+		return
+	}
+
+	var req models.Webhook
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Errorf("failed to parse request body, error is: %s", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Message: "Failed to parse request body",
+		})
+		return
+	}
+	if err := webhooks.ValidateURL(c.Request.Context(), req.URL); err != nil {
+		logger.Errorf("failed to validate webhook URL for org_id %s, error is: %s", orgID, err)
+		message := "Webhook URL should be http or https"
+		if errors.Is(err, webhooks.ErrForbiddenAddress) {
+			message = "Webhook URL should point to a public address"
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Message: message,
+		})
+		return
+	}
+	c.Set("audit_details", map[string]interface{}{"url": req.URL, "events": req.Events})
+
+	secret, err := webhooks.NewSecret()
+	if err != nil {
+		logger.Errorf("failed to create webhook for org_id %s, error is: %s", orgID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to create webhook",
+		})
+		return
+	}
+
+	webhook, err := webhookRepo.AddWebhook(c.Request.Context(), domain.Webhook{
+		OrgID:  orgID,
+		URL:    req.URL,
+		Secret: secret,
+		Events: uniqueStrings(req.Events),
+	})
+	if err != nil {
+		logger.Errorf("failed to add webhook for org_id %s, error is: %s", orgID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to create webhook",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseSuccessWebhook{
+		Message: "Webhook is created",
+		Webhook: webhook,
+	})
+}
+
+// DeleteWebhook godoc
+// @Summary Deleting webhook
+// @Description Deleting webhook with its deliveries, pending deliveries aren't sent
+// @Tags webhooks
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} models.Response
+// @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 404 {object} models.Response
+// @Failure 500 {object} models.Response
+// @Security ApiKeyAuth
+// @Param id path string true "Webhook ID"
+// @Param org header string false "Organization ID, personal organization is used if omitted"
+// @Router /webhooks/{id} [delete]
+func DeleteWebhook(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	orgID, ok := c.MustGet("org_id").(string)
+	if !ok {
+		logger.Errorf("failed to get org_id from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Unable to determine organization",
+		})
+		return
+	}
+	webhookRepo, ok := c.MustGet("webhook_repo").(webhookrepo.WebhookRepository)
+	if !ok {
+		logger.Errorf("failed to get webhook_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get webhook_repo",
+		})
+		return
+	}
+
+	webhookID := c.Param("id")
+	c.Set("audit_details", map[string]interface{}{"webhook_id": webhookID})
+
+	_, err := getWebhook(c.Request.Context(), webhookRepo, orgID, webhookID)
+	if err == nil {
+		err = webhookRepo.DeleteWebhook(c.Request.Context(), webhookID)
+	}
+	if errors.Is(err, webhookrepo.ErrNotFound) {
+		logger.Infof("webhook %s of org_id %s doesn't exist", webhookID, orgID)
+		c.AbortWithStatusJSON(http.StatusNotFound, models.Response{
+			Message: "Webhook not found",
+		})
+		return
+	}
+	if err != nil {
+		logger.Errorf("failed to delete webhook %s for org_id %s, error is: %s", webhookID, orgID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to delete webhook",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Message: "Webhook is deleted",
+	})
+}
+
+// LoadWebhookDeliveries godoc
+// @Summary Loading webhook's deliveries
+// @Description Loading the latest deliveries of webhook with all their attempts, newest first
+// @Tags webhooks
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} models.ResponseSuccessWebhookDeliveries
+// @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 404 {object} models.Response
+// @Failure 500 {object} models.Response
+// @Security ApiKeyAuth
+// @Param id path string true "Webhook ID"
+// @Param org header string false "Organization ID, personal organization is used if omitted"
+// @Router /webhooks/{id}/deliveries [get]
+func LoadWebhookDeliveries(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	orgID, ok := c.MustGet("org_id").(string)
+	if !ok {
+		logger.Errorf("failed to get org_id from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Unable to determine organization",
+		})
+		return
+	}
+	webhookRepo, ok := c.MustGet("webhook_repo").(webhookrepo.WebhookRepository)
+	if !ok {
+		logger.Errorf("failed to get webhook_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get webhook_repo",
+		})
+		return
+	}
+
+	webhookID := c.Param("id")
+
+	_, err := getWebhook(c.Request.Context(), webhookRepo, orgID, webhookID)
+	if errors.Is(err, webhookrepo.ErrNotFound) {
+		logger.Infof("webhook %s of org_id %s doesn't exist", webhookID, orgID)
+		c.AbortWithStatusJSON(http.StatusNotFound, models.Response{
+			Message: "Webhook not found",
+		})
+		return
+	}
+	if err != nil {
+		logger.Errorf("failed to get webhook %s for org_id %s, error is: %s", webhookID, orgID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get webhook",
+		})
+		return
+	}
+
+	deliveries, err := webhookRepo.GetDeliveries(c.Request.Context(), webhookID)
+	if err != nil {
+		logger.Errorf("failed to load deliveries of webhook %s, error is: %s", webhookID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to fetch deliveries",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseSuccessWebhookDeliveries{
+		Message:    "Deliveries are loaded",
+		Deliveries: deliveries,
+	})
+}
+
+// SendWebhookTest godoc
+// @Summary Sending sample event to webhook
+// @Description Sending sample event of the given type to webhook once, the delivery is recorded, but not retried. Payload of the sample has "test": true
+// @Tags webhooks
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} models.ResponseSuccessWebhookDelivery
+// @Failure 400 {object} models.Response
+// @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
+// @Failure 404 {object} models.Response
+// @Failure 500 {object} models.Response
+// @Security ApiKeyAuth
+// @Param id path string true "Webhook ID"
+// @Param event query string false "Event type, dataset.imported if omitted" Enums(dataset.imported, dataset.deleted, dataset.import_failed, alert.triggered)
+// @Param org header string false "Organization ID, personal organization is used if omitted"
+// @Router /webhooks/{id}/test [post]
+func SendWebhookTest(c *gin.Context) {
+	logger := logging.FromContext(c.Request.Context())
+
+	orgID, ok := c.MustGet("org_id").(string)
+	if !ok {
+		logger.Errorf("failed to get org_id from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Unable to determine organization",
+		})
+		return
+	}
+	webhookRepo, ok := c.MustGet("webhook_repo").(webhookrepo.WebhookRepository)
+	if !ok {
+		logger.Errorf("failed to get webhook_repo from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get webhook_repo",
+		})
+		return
+	}
+	notifier, ok := c.MustGet("webhooks").(webhooks.Notifier)
+	if !ok {
+		logger.Errorf("failed to get webhooks from gin.Context")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get webhooks",
+		})
+		return
+	}
+
+	event := c.DefaultQuery("event", domain.WebhookDatasetImported)
+	if !knownEvent(event) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+			Message: "Unknown event, supported events are dataset.imported, dataset.deleted, dataset.import_failed and alert.triggered",
+		})
+		return
+	}
+
+	webhookID := c.Param("id")
+
+	webhook, err := getWebhook(c.Request.Context(), webhookRepo, orgID, webhookID)
+	if errors.Is(err, webhookrepo.ErrNotFound) {
+		logger.Infof("webhook %s of org_id %s doesn't exist", webhookID, orgID)
+		c.AbortWithStatusJSON(http.StatusNotFound, models.Response{
+			Message: "Webhook not found",
+		})
+		return
+	}
+	if err != nil {
+		logger.Errorf("failed to get webhook %s for org_id %s, error is: %s", webhookID, orgID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to get webhook",
+		})
+		return
+	}
+
+	delivery, err := notifier.Test(c.Request.Context(), webhook, event)
+	if err != nil {
+		logger.Errorf("failed to send test event to webhook %s, error is: %s", webhookID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+			Message: "Failed to send test event",
+		})
+		return
+	}
+
+	message := "Test event is delivered"
+	if delivery.State != domain.DeliverySucceeded {
+		message = "Test event delivery failed"
+	}
+
+	c.JSON(http.StatusOK, models.ResponseSuccessWebhookDelivery{
+		Message:  message,
+		Delivery: delivery,
+	})
+}
+
+// getWebhook returns webhook of the organization, webhooks of other organizations aren't found.
+func getWebhook(ctx context.Context, webhookRepo webhookrepo.WebhookRepository, orgID, webhookID string) (domain.Webhook, error) {
+	webhook, err := webhookRepo.GetWebhook(ctx, webhookID)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	if webhook.OrgID != orgID {
+		return domain.Webhook{}, webhookrepo.ErrNotFound
+	}
+
+	return webhook, nil
+}
+
+func knownEvent(event string) bool {
+	for _, known := range domain.WebhookEvents {
+		if event == known {
+			return true
+		}
+	}
+	return false
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool)
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
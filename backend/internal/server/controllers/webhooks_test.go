@@ -0,0 +1,506 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	webhookrepo "github.com/hackfeed/remrratality/backend/internal/store/webhook_repo"
+	internalTesting "github.com/hackfeed/remrratality/backend/internal/utils/testing"
+	"github.com/hackfeed/remrratality/backend/internal/webhooks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadWebhooksHandler(t *testing.T) {
+	type testInput struct {
+		keys map[string]interface{}
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id": 1,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine organization",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":       "org",
+				"webhook_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get webhook_repo",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":       "errorGetWebhooks",
+				"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to fetch webhooks",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":       "org",
+				"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+			}},
+			want: testWant{
+				code:    http.StatusOK,
+				message: "Webhooks are loaded",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(test.input.keys, nil, nil)
+		LoadWebhooks(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message))
+		assert.Equal(t, false, strings.Contains(w.Body.String(), "secret"))
+	}
+}
+
+func TestCreateWebhookHandler(t *testing.T) {
+	type testInput struct {
+		keys map[string]interface{}
+		body interface{}
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id": 1,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine organization",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":       "org",
+				"webhook_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get webhook_repo",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+				},
+				body: models.Webhook{URL: "https://example.com/webhook", Events: []string{"dataset.created"}},
+			},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Failed to parse request body",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+				},
+				body: models.Webhook{URL: "ftp://example.com/webhook", Events: []string{"dataset.imported"}},
+			},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Webhook URL should be http or https",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+				},
+				body: models.Webhook{URL: "http://127.0.0.1:8080/webhook", Events: []string{"dataset.imported"}},
+			},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Webhook URL should point to a public address",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+				},
+				body: models.Webhook{URL: "http://169.254.169.254/latest/meta-data", Events: []string{"dataset.imported"}},
+			},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Webhook URL should point to a public address",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "errorAddWebhook",
+					"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+				},
+				body: models.Webhook{URL: "https://example.com/webhook", Events: []string{"dataset.imported"}},
+			},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to create webhook",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+				},
+				body: models.Webhook{URL: "https://example.com/webhook", Events: []string{"dataset.imported", "dataset.imported"}},
+			},
+			want: testWant{
+				code:    http.StatusOK,
+				message: `"events":["dataset.imported"]`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(test.input.keys, test.input.body, nil)
+		CreateWebhook(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message))
+		if w.Code == http.StatusOK {
+			assert.Equal(t, true, strings.Contains(w.Body.String(), `"secret":"`))
+		}
+	}
+}
+
+func TestDeleteWebhookHandler(t *testing.T) {
+	type testInput struct {
+		keys   map[string]interface{}
+		params []gin.Param
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id": 1,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine organization",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":       "org",
+				"webhook_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get webhook_repo",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "otherOrgWebhook"}},
+			},
+			want: testWant{
+				code:    http.StatusNotFound,
+				message: "Webhook not found",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "errorDeleteWebhook"}},
+			},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to delete webhook",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "webhook"}},
+			},
+			want: testWant{
+				code:    http.StatusOK,
+				message: "Webhook is deleted",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(test.input.keys, nil, test.input.params)
+		DeleteWebhook(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message))
+	}
+}
+
+func TestLoadWebhookDeliveriesHandler(t *testing.T) {
+	type testInput struct {
+		keys   map[string]interface{}
+		params []gin.Param
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id": 1,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine organization",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":       "org",
+				"webhook_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get webhook_repo",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "notFoundWebhook"}},
+			},
+			want: testWant{
+				code:    http.StatusNotFound,
+				message: "Webhook not found",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "errorGetWebhook"}},
+			},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get webhook",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "errorGetDeliveries"}},
+			},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to fetch deliveries",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "webhook"}},
+			},
+			want: testWant{
+				code:    http.StatusOK,
+				message: "Deliveries are loaded",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(test.input.keys, nil, test.input.params)
+		LoadWebhookDeliveries(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message))
+	}
+}
+
+func TestSendWebhookTestHandler(t *testing.T) {
+	type testInput struct {
+		keys   map[string]interface{}
+		params []gin.Param
+		query  string
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id": 1,
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Unable to determine organization",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":       "org",
+				"webhook_repo": "invalidRepo",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get webhook_repo",
+			},
+		},
+		{
+			input: testInput{keys: map[string]interface{}{
+				"org_id":       "org",
+				"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+				"webhooks":     "invalidNotifier",
+			}},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to get webhooks",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+					"webhooks":     &webhooks.NotifierMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "webhook"}},
+				query:  "event=dataset.created",
+			},
+			want: testWant{
+				code:    http.StatusBadRequest,
+				message: "Unknown event",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+					"webhooks":     &webhooks.NotifierMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "otherOrgWebhook"}},
+			},
+			want: testWant{
+				code:    http.StatusNotFound,
+				message: "Webhook not found",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+					"webhooks":     &webhooks.NotifierMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "errorTestWebhook"}},
+			},
+			want: testWant{
+				code:    http.StatusInternalServerError,
+				message: "Failed to send test event",
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+					"webhooks":     &webhooks.NotifierMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "webhook"}},
+				query:  "event=dataset.deleted",
+			},
+			want: testWant{
+				code:    http.StatusOK,
+				message: `"event":"dataset.deleted"`,
+			},
+		},
+		{
+			input: testInput{
+				keys: map[string]interface{}{
+					"org_id":       "org",
+					"webhook_repo": &webhookrepo.WebhookRepositoryMock{},
+					"webhooks":     &webhooks.NotifierMock{},
+				},
+				params: []gin.Param{{Key: "id", Value: "webhook"}},
+			},
+			want: testWant{
+				code:    http.StatusOK,
+				message: "Test event is delivered",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, w := internalTesting.CreateGinContext(test.input.keys, nil, test.input.params)
+		c.Request.URL.RawQuery = test.input.query
+		SendWebhookTest(c)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message))
+	}
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/config"
+	"github.com/hackfeed/remrratality/backend/internal/server/controllers"
+	log "github.com/sirupsen/logrus"
+)
+
+// NewHTTPServer creates server with timeouts from cfg, so slow clients can't hold connections forever.
+func NewHTTPServer(cfg config.Server, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+}
+
+// Serve serves requests on listener until ctx is done, then stops accepting new connections and
+// waits for in-flight requests to complete for grace period at most. Event streams, which never
+// complete by themselves, are told to end when shutdown starts.
+func Serve(ctx context.Context, srv *http.Server, listener net.Listener, grace time.Duration) error {
+	shutdown := make(chan struct{})
+	srv.BaseContext = func(net.Listener) context.Context {
+		return controllers.WithShutdown(context.Background(), shutdown)
+	}
+	srv.RegisterOnShutdown(func() {
+		close(shutdown)
+	})
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.Serve(listener)
+	}()
+
+	select {
+	case err := <-errs:
+		return fmt.Errorf("failed to serve requests, error is: %s", err)
+	case <-ctx.Done():
+	}
+
+	log.Infof("shutting down server, in-flight requests are given %s to complete", grace)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to drain in-flight requests, error is: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/config"
+	"github.com/hackfeed/remrratality/backend/internal/server/controllers"
+	jobrepo "github.com/hackfeed/remrratality/backend/internal/store/job_repo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHTTPServer(t *testing.T) {
+	srv := NewHTTPServer(config.Default().Server, http.NotFoundHandler())
+
+	assert.Equal(t, ":8080", srv.Addr)
+	assert.Equal(t, 10*time.Second, srv.ReadHeaderTimeout)
+	assert.Equal(t, 5*time.Minute, srv.ReadTimeout)
+	assert.Equal(t, 5*time.Minute, srv.WriteTimeout)
+	assert.Equal(t, 2*time.Minute, srv.IdleTimeout)
+}
+
+func TestServe(t *testing.T) {
+	type testInput struct {
+		handlerDelay time.Duration
+		grace        time.Duration
+	}
+	type testWant struct {
+		body      string
+		serveErr  error
+		requestOK bool
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{
+				handlerDelay: 100 * time.Millisecond,
+				grace:        5 * time.Second,
+			},
+			want: testWant{
+				body:      "done",
+				serveErr:  nil,
+				requestOK: true,
+			},
+		},
+		{
+			input: testInput{
+				handlerDelay: 5 * time.Second,
+				grace:        50 * time.Millisecond,
+			},
+			want: testWant{
+				serveErr:  context.DeadlineExceeded,
+				requestOK: false,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		started := make(chan struct{})
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			time.Sleep(test.input.handlerDelay)
+			_, _ = io.WriteString(w, "done")
+		})
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		served := make(chan error, 1)
+		go func() {
+			served <- Serve(ctx, &http.Server{Handler: handler}, listener, test.input.grace)
+		}()
+
+		type response struct {
+			body string
+			err  error
+		}
+		responses := make(chan response, 1)
+		go func() {
+			resp, err := http.Get("http://" + listener.Addr().String())
+			if err != nil {
+				responses <- response{err: err}
+				return
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			responses <- response{body: string(body), err: err}
+		}()
+
+		<-started
+		cancel()
+
+		err = <-served
+		if test.want.serveErr != nil {
+			assert.Equal(t, true, errors.Is(err, test.want.serveErr))
+		} else {
+			assert.NoError(t, err)
+		}
+
+		if test.want.requestOK {
+			resp := <-responses
+			assert.NoError(t, resp.err)
+			assert.Equal(t, test.want.body, resp.body)
+		}
+
+		_, err = http.Get("http://" + listener.Addr().String())
+		assert.Error(t, err)
+	}
+}
+
+func TestServeEventStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := gin.New()
+	handler.GET("/jobs/:id/events", func(c *gin.Context) {
+		c.Set("org_id", "org")
+		c.Set("job_repo", &jobrepo.JobRepositoryMock{})
+	}, controllers.GetJobEvents)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(ctx, &http.Server{Handler: handler}, listener, time.Second)
+	}()
+
+	// All events are received, so running job's stream stays open.
+	req, err := http.NewRequest(http.MethodGet, "http://"+listener.Addr().String()+"/jobs/job/events", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "2")
+	// Stream is cut off by timeout, if it isn't ended on shutdown.
+	client := &http.Client{Timeout: 3 * time.Second}
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	started := time.Now()
+	cancel()
+
+	assert.NoError(t, <-served)
+	assert.Less(t, int64(time.Since(started)), int64(500*time.Millisecond))
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "", string(body))
+}
+
+func TestServeListenerError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	assert.NoError(t, listener.Close())
+
+	err = Serve(context.Background(), &http.Server{}, listener, time.Second)
+	assert.Error(t, err)
+}
+
+func TestDependenciesClose(t *testing.T) {
+	var closed []string
+	closeWith := func(name string, err error) closer {
+		return closer{name: name, close: func(context.Context) error {
+			closed = append(closed, name)
+			return err
+		}}
+	}
+
+	deps := Dependencies{
+		closers: []closer{
+			closeWith("mongo", nil),
+			closeWith("postgres", errors.New("pool is busy")),
+			closeWith("redis", nil),
+		},
+	}
+
+	err := deps.Close(context.Background())
+	assert.EqualError(t, err, "failed to close clients: postgres")
+	assert.Equal(t, []string{"mongo", "postgres", "redis"}, closed)
+
+	assert.NoError(t, Dependencies{}.Close(context.Background()))
+}
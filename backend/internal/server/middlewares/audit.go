@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
+	auditrepo "github.com/hackfeed/remrratality/backend/internal/store/audit_repo"
+)
+
+// Audit records the action once the request is handled. Handlers may set "user_id"
+// for unauthenticated routes and "audit_details" to extend the stored event.
+func Audit(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger := logging.FromContext(c.Request.Context())
+
+		c.Next()
+
+		auditRepo, ok := c.MustGet("audit_repo").(auditrepo.AuditRepository)
+		if !ok {
+			logger.Errorf("failed to get audit_repo from gin.Context, %s event is not recorded", action)
+			return
+		}
+
+		event := domain.AuditEvent{
+			UserID:    c.GetString("user_id"),
+			OrgID:     c.GetString("org_id"),
+			Action:    action,
+			Success:   c.Writer.Status() < http.StatusBadRequest,
+			Status:    c.Writer.Status(),
+			IP:        c.ClientIP(),
+			RequestID: c.GetString("request_id"),
+			CreatedAt: time.Now().UTC(),
+		}
+		if details, ok := c.Get("audit_details"); ok {
+			event.Details, _ = details.(map[string]interface{})
+		}
+
+		if err := auditRepo.AddEvent(c.Request.Context(), event); err != nil {
+			logger.Errorf("failed to record %s event for user_id %s, error is: %s", action, event.UserID, err)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/calendar"
+)
+
+// Calendar makes analytics computed while handling request bucketed by reporting periods of cal,
+// calendar months are used when it's nil.
+func Calendar(cal calendar.Calendar) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(calendar.WithContext(c.Request.Context(), cal))
+		c.Next()
+	}
+}
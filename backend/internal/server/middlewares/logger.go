@@ -0,0 +1,52 @@
+package middlewares
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID keeps request ID sent by client or generates a new one. The ID is returned in
+// response header, set as "request_id" and carried by request context for logging.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(requestIDHeader, requestID)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), requestID))
+
+		c.Next()
+	}
+}
+
+// Logger writes an access log entry for every request. Query is left out of the path
+// as it may carry sensitive parameters.
+func Logger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		logging.FromContext(c.Request.Context()).WithFields(log.Fields{
+			"method":     c.Request.Method,
+			"route":      c.FullPath(),
+			"path":       c.Request.URL.Path,
+			"status":     c.Writer.Status(),
+			"latency_ms": time.Since(start).Milliseconds(),
+			"ip":         c.ClientIP(),
+			"user_id":    c.GetString("user_id"),
+		}).Info("request is handled")
+	}
+}
@@ -0,0 +1,29 @@
+package middlewares
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/metrics"
+)
+
+// Metrics records latency of every request. Route pattern is used instead of path to keep
+// number of series bounded, requests not matching any route are recorded as unmatched.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.RequestDuration.WithLabelValues(
+			c.Request.Method,
+			route,
+			strconv.Itoa(c.Writer.Status()),
+		).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,193 @@
+package middlewares
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
+	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	datasetrepo "github.com/hackfeed/remrratality/backend/internal/store/dataset_repo"
+	orgrepo "github.com/hackfeed/remrratality/backend/internal/store/org_repo"
+	storagerepo "github.com/hackfeed/remrratality/backend/internal/store/storage_repo"
+	userrepo "github.com/hackfeed/remrratality/backend/internal/store/user_repo"
+	"github.com/hackfeed/remrratality/backend/internal/utils/dataset_summary"
+)
+
+var roleRanks = map[string]int{
+	domain.RoleViewer: 1,
+	domain.RoleEditor: 2,
+	domain.RoleOwner:  3,
+}
+
+// Org resolves organization from the org_id path parameter or the org header, falling back
+// to the personal organization of logged in user, and checks that user's role is at least the given one.
+func Org(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger := logging.FromContext(c.Request.Context())
+
+		userID, ok := c.MustGet("user_id").(string)
+		if !ok {
+			logger.Errorf("failed to get user_id from gin.Context")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+				Message: "Unable to determine logged in user",
+			})
+			return
+		}
+		orgRepo, ok := c.MustGet("org_repo").(orgrepo.OrgRepository)
+		if !ok {
+			logger.Errorf("failed to get org_repo from gin.Context")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+				Message: "Failed to get org_repo",
+			})
+			return
+		}
+
+		orgID := c.Param("org_id")
+		if orgID == "" {
+			orgID = c.Request.Header.Get("org")
+		}
+		if orgID == "" {
+			orgID = userID
+		}
+
+		org, err := orgRepo.GetOrganization(c.Request.Context(), orgID)
+		if errors.Is(err, orgrepo.ErrNotFound) && orgID == userID {
+			org, err = createPersonalOrganization(c, orgRepo, userID)
+		}
+		if errors.Is(err, orgrepo.ErrNotFound) {
+			logger.Infof("organization %s requested by user %s doesn't exist", orgID, userID)
+			c.AbortWithStatusJSON(http.StatusNotFound, models.Response{
+				Message: "Organization not found",
+			})
+			return
+		}
+		if err != nil {
+			logger.Errorf("failed to get organization %s, error is: %s", orgID, err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+				Message: "Failed to fetch organization",
+			})
+			return
+		}
+
+		var member *domain.Member
+		for i := range org.Members {
+			if org.Members[i].UserID == userID {
+				member = &org.Members[i]
+				break
+			}
+		}
+		if member == nil {
+			logger.Infof("user %s is not a member of organization %s", userID, orgID)
+			c.AbortWithStatusJSON(http.StatusNotFound, models.Response{
+				Message: "Organization not found",
+			})
+			return
+		}
+		if roleRanks[member.Role] < roleRanks[role] {
+			logger.Infof("user %s with role %s is not allowed to act as %s in organization %s", userID, member.Role, role, orgID)
+			c.AbortWithStatusJSON(http.StatusForbidden, models.Response{
+				Message: "Not enough permissions in organization",
+			})
+			return
+		}
+
+		if len(org.Files) != 0 {
+			if err = migrateFiles(c, orgRepo, org); err != nil {
+				logger.Errorf("failed to move files of organization %s to datasets, error is: %s", orgID, err)
+			}
+		}
+
+		c.Set("org_id", org.OrgID)
+		c.Set("role", member.Role)
+
+		c.Next()
+	}
+}
+
+// createPersonalOrganization creates personal organization for users registered before organizations
+// existed and moves their files to it.
+func createPersonalOrganization(c *gin.Context, orgRepo orgrepo.OrgRepository, userID string) (domain.Organization, error) {
+	email, ok := c.MustGet("email").(string)
+	if !ok {
+		return domain.Organization{}, errors.New("failed to get email from gin.Context")
+	}
+	userRepo, ok := c.MustGet("user_repo").(userrepo.UserRepository)
+	if !ok {
+		return domain.Organization{}, errors.New("failed to get user_repo from gin.Context")
+	}
+
+	user, err := userRepo.GetUser(c.Request.Context(), email)
+	if err != nil {
+		return domain.Organization{}, fmt.Errorf("failed to get user, error is: %s", err)
+	}
+
+	org, err := orgRepo.AddOrganization(c.Request.Context(), domain.Organization{
+		OrgID:   userID,
+		Name:    email,
+		Members: []domain.Member{{UserID: userID, Email: email, Role: domain.RoleOwner}},
+		Files:   user.Files,
+	})
+	if err != nil {
+		return domain.Organization{}, fmt.Errorf("failed to add organization, error is: %s", err)
+	}
+
+	user.Files = make([]domain.File, 0)
+	if err = userRepo.UpdateUser(c.Request.Context(), userID, user); err != nil {
+		logging.FromContext(c.Request.Context()).Errorf("failed to clear files of user %s moved to personal organization, error is: %s", userID, err)
+	}
+
+	return org, nil
+}
+
+// migrateFiles moves files embedded into organization before datasets existed to the datasets,
+// describing them by their stored invoices. Files already moved are skipped, so failed migration
+// is retried on the next request.
+func migrateFiles(c *gin.Context, orgRepo orgrepo.OrgRepository, org domain.Organization) error {
+	datasetRepo, ok := c.MustGet("dataset_repo").(datasetrepo.DatasetRepository)
+	if !ok {
+		return errors.New("failed to get dataset_repo from gin.Context")
+	}
+	storageRepo, ok := c.MustGet("storage_repo").(storagerepo.StorageRepository)
+	if !ok {
+		return errors.New("failed to get storage_repo from gin.Context")
+	}
+
+	for _, file := range org.Files {
+		_, err := datasetRepo.GetDataset(c.Request.Context(), org.OrgID, file.Name)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, datasetrepo.ErrNotFound) {
+			return fmt.Errorf("failed to get dataset, error is: %s", err)
+		}
+
+		invoices, err := storageRepo.GetInvoicesByPeriod(c.Request.Context(), org.OrgID, file.Name, time.Time{}, time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			return fmt.Errorf("failed to get invoices of file %s, error is: %s", file.Name, err)
+		}
+
+		dataset := domain.Dataset{
+			Name:        file.Name,
+			OrgID:       org.OrgID,
+			DisplayName: file.Name,
+			Status:      domain.DatasetReady,
+			UploadedAt:  file.UploadedAt,
+		}
+		dataset_summary.Summarize(&dataset, invoices, "2006-01-02")
+
+		if _, err = datasetRepo.AddDataset(c.Request.Context(), dataset); err != nil {
+			return fmt.Errorf("failed to add dataset, error is: %s", err)
+		}
+	}
+
+	org.Files = make([]domain.File, 0)
+	if err := orgRepo.UpdateOrganization(c.Request.Context(), org.OrgID, org); err != nil {
+		return fmt.Errorf("failed to update organization, error is: %s", err)
+	}
+
+	return nil
+}
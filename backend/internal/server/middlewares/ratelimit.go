@@ -0,0 +1,66 @@
+package middlewares
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
+	"github.com/hackfeed/remrratality/backend/internal/server/models"
+	limitrepo "github.com/hackfeed/remrratality/backend/internal/store/limit_repo"
+)
+
+// ByIP keys rate limit by client's IP address.
+func ByIP(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// ByUser keys rate limit by logged in user, so it should be used after Auth.
+func ByUser(c *gin.Context) string {
+	return c.GetString("user_id")
+}
+
+// RateLimit rejects requests with 429 once the bucket of given scope and key is empty.
+// Requests are let through if the limiter itself fails.
+func RateLimit(scope string, limit domain.Limit, key func(*gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger := logging.FromContext(c.Request.Context())
+
+		limitRepo, ok := c.MustGet("limit_repo").(limitrepo.LimitRepository)
+		if !ok {
+			logger.Errorf("failed to get limit_repo from gin.Context")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+				Message: "Failed to get limit_repo",
+			})
+			return
+		}
+
+		bucket := fmt.Sprintf("%s:%s", scope, key(c))
+
+		wait, err := limitRepo.Take(c.Request.Context(), bucket, limit)
+		if err != nil {
+			logger.Errorf("failed to take token from bucket %s, error is: %s", bucket, err)
+			c.Next()
+			return
+		}
+		if wait > 0 {
+			logger.Infof("rate limit for bucket %s is exceeded", bucket)
+			AbortWithRetryAfter(c, wait, "Too many requests. Please, try again later")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// AbortWithRetryAfter rejects request with 429 telling the client how long to wait.
+func AbortWithRetryAfter(c *gin.Context, wait time.Duration, message string) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, models.Response{
+		Message: message,
+	})
+}
@@ -2,9 +2,19 @@ package middlewares
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/health"
+	"github.com/hackfeed/remrratality/backend/internal/jobs"
+	alertrepo "github.com/hackfeed/remrratality/backend/internal/store/alert_repo"
+	auditrepo "github.com/hackfeed/remrratality/backend/internal/store/audit_repo"
 	cacherepo "github.com/hackfeed/remrratality/backend/internal/store/cache_repo"
+	datasetrepo "github.com/hackfeed/remrratality/backend/internal/store/dataset_repo"
+	jobrepo "github.com/hackfeed/remrratality/backend/internal/store/job_repo"
+	limitrepo "github.com/hackfeed/remrratality/backend/internal/store/limit_repo"
+	orgrepo "github.com/hackfeed/remrratality/backend/internal/store/org_repo"
 	storagerepo "github.com/hackfeed/remrratality/backend/internal/store/storage_repo"
 	userrepo "github.com/hackfeed/remrratality/backend/internal/store/user_repo"
+	webhookrepo "github.com/hackfeed/remrratality/backend/internal/store/webhook_repo"
+	"github.com/hackfeed/remrratality/backend/internal/webhooks"
 )
 
 func UserRepo(userRepo userrepo.UserRepository) gin.HandlerFunc {
@@ -27,3 +37,73 @@ func CacheRepo(cacheRepo cacherepo.CacheRepository) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+func OrgRepo(orgRepo orgrepo.OrgRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("org_repo", orgRepo)
+		c.Next()
+	}
+}
+
+func LimitRepo(limitRepo limitrepo.LimitRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("limit_repo", limitRepo)
+		c.Next()
+	}
+}
+
+func AuditRepo(auditRepo auditrepo.AuditRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("audit_repo", auditRepo)
+		c.Next()
+	}
+}
+
+func DatasetRepo(datasetRepo datasetrepo.DatasetRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("dataset_repo", datasetRepo)
+		c.Next()
+	}
+}
+
+func JobRepo(jobRepo jobrepo.JobRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("job_repo", jobRepo)
+		c.Next()
+	}
+}
+
+func Jobs(queue jobs.Queue) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("jobs", queue)
+		c.Next()
+	}
+}
+
+func WebhookRepo(webhookRepo webhookrepo.WebhookRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("webhook_repo", webhookRepo)
+		c.Next()
+	}
+}
+
+func Webhooks(notifier webhooks.Notifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("webhooks", notifier)
+		c.Next()
+	}
+}
+
+func AlertRepo(alertRepo alertrepo.AlertRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("alert_repo", alertRepo)
+		c.Next()
+	}
+}
+
+func HealthChecks(checks []health.Check) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("health_checks", checks)
+		c.Next()
+	}
+}
@@ -0,0 +1,29 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// untracedPaths are polled by orchestrator and Prometheus, their spans would only be noise.
+var untracedPaths = map[string]struct{}{
+	"/healthz": {},
+	"/readyz":  {},
+	"/metrics": {},
+}
+
+// Tracing starts server span of every request, continuing trace passed in traceparent header.
+// Span is carried by request context, so spans of queries made while handling the request
+// become its children.
+func Tracing(service string) gin.HandlerFunc {
+	trace := otelgin.Middleware(service)
+
+	return func(c *gin.Context) {
+		if _, ok := untracedPaths[c.Request.URL.Path]; ok {
+			c.Next()
+			return
+		}
+
+		trace(c)
+	}
+}
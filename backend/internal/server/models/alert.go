@@ -0,0 +1,12 @@
+package models
+
+type AlertRule struct {
+	Name            string   `json:"name" binding:"required,max=100" example:"Churn spike"`
+	DatasetID       string   `json:"dataset_id" example:"filename.csv"`
+	Metric          string   `json:"metric" binding:"required,oneof=new old reactivation expansion contraction churn total net_new growth_rate churn_rate" example:"churn"`
+	Condition       string   `json:"condition" binding:"required,oneof=above below" example:"above"`
+	Threshold       *float64 `json:"threshold" binding:"required" example:"5"`
+	OfPreviousTotal bool     `json:"of_previous_total" example:"true"`
+	Channels        []string `json:"channels" binding:"required,min=1,dive,oneof=webhook email log" example:"webhook"`
+	Recipients      []string `json:"recipients" binding:"omitempty,dive,email" example:"owner@example.com"`
+}
@@ -0,0 +1,15 @@
+package models
+
+type DatasetUpdate struct {
+	DisplayName *string   `json:"display_name" binding:"omitempty,min=1,max=256" example:"Invoices 2021"`
+	Description *string   `json:"description" binding:"omitempty,max=1024" example:"Invoices exported from billing"`
+	Labels      *[]string `json:"labels" binding:"omitempty,max=20,dive,min=1,max=64" example:"billing,2021"`
+}
+
+type DatasetQuery struct {
+	Label        string `form:"label"`
+	Name         string `form:"name"`
+	UploadedFrom string `form:"uploaded_from"`
+	UploadedTo   string `form:"uploaded_to"`
+	Sort         string `form:"sort" binding:"omitempty,oneof=name -name uploaded_at -uploaded_at"`
+}
@@ -0,0 +1,10 @@
+package models
+
+type Organization struct {
+	Name string `json:"name" binding:"required" example:"Finance"`
+}
+
+type Member struct {
+	Email string `json:"email" binding:"required,email" example:"test@test.com"`
+	Role  string `json:"role" binding:"required,oneof=owner editor viewer" example:"viewer"`
+}
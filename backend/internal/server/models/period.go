@@ -5,3 +5,52 @@ type Period struct {
 	PeriodStart string `json:"period_start" binding:"required" example:"2019-01-01"`
 	PeriodEnd   string `json:"period_end" binding:"required" example:"2021-01-01"`
 }
+
+// Forecast asks for analytics of the period projected Horizon months ahead with Method, which
+// is trend (default) or cohort. Confidence of the intervals is 0.8, 0.9, 0.95 (default) or 0.99.
+type Forecast struct {
+	Period
+	Horizon    int     `json:"horizon" binding:"required,min=1,max=36" example:"6"`
+	Method     string  `json:"method" binding:"omitempty,oneof=trend cohort" example:"trend"`
+	Confidence float64 `json:"confidence" example:"0.95"`
+}
+
+// Scenario asks for analytics of the period as it is and as it would be with Adjustments.
+type Scenario struct {
+	Period
+	Adjustments ScenarioAdjustments `json:"adjustments"`
+}
+
+// ScenarioAdjustments change revenue of customers before MRR is calculated. PlanMultipliers
+// scale paid amounts of monthly and annually invoices. ChurnReduction is the share of churned
+// customers who keep paying instead, ChurnRate is the share of paying customers who churn every
+// month on top of observed churn and ExpansionRate grows revenue of every paying customer monthly.
+type ScenarioAdjustments struct {
+	PlanMultipliers   map[string]float64 `json:"plan_multipliers" binding:"omitempty,dive,keys,oneof=monthly annually,endkeys,gte=0,lte=100" example:"annually:1.1"`
+	ExcludedCustomers []uint32           `json:"excluded_customers" example:"42"`
+	ChurnReduction    float64            `json:"churn_reduction" binding:"gte=0,lte=1" example:"0.2"`
+	ChurnRate         float64            `json:"churn_rate" binding:"gte=0,lte=1" example:"0"`
+	ExpansionRate     float64            `json:"expansion_rate" binding:"gte=0,lte=1" example:"0.01"`
+}
+
+// Bridge asks for MRR bridge between the first and the last months of the period, with Top
+// customers contributing the most to every movement bucket if it's set.
+type Bridge struct {
+	Period
+	Top int `json:"top" binding:"omitempty,min=1,max=100" example:"5"`
+}
+
+// Analytics asks for analytics of the period, compared with analytics of another period when
+// Compare is set.
+type Analytics struct {
+	Period
+	Compare *Comparison `json:"compare"`
+}
+
+// Comparison sets the period analytics is compared with: the previous period of the same
+// length, the same period a year before or a custom one.
+type Comparison struct {
+	Mode        string `json:"mode" binding:"required,oneof=previous last_year custom" example:"last_year"`
+	PeriodStart string `json:"period_start" binding:"required_if=Mode custom" example:"2018-01-01"`
+	PeriodEnd   string `json:"period_end" binding:"required_if=Mode custom" example:"2020-01-01"`
+}
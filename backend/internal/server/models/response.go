@@ -1,15 +1,30 @@
 package models
 
-import "github.com/hackfeed/remrratality/backend/internal/domain"
+import (
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
 
 type ResponseSuccessLoadFiles struct {
-	Message string        `json:"message" example:"Files are loaded"`
-	Files   []domain.File `json:"files"`
+	Message string           `json:"message" example:"Files are loaded"`
+	Files   []domain.Dataset `json:"files"`
+}
+
+type ResponseSuccessGetFile struct {
+	Message string         `json:"message" example:"File is loaded"`
+	File    domain.Dataset `json:"file"`
 }
 
 type ResponseSuccessSaveFileContent struct {
-	Message  string `json:"message" example:"File is uploaded"`
-	Filename string `json:"filename" example:"filename.csv"`
+	Message  string     `json:"message" example:"File is queued for processing"`
+	Filename string     `json:"filename" example:"filename.csv"`
+	Job      domain.Job `json:"job"`
+}
+
+type ResponseSuccessGetJob struct {
+	Message string     `json:"message" example:"Job is loaded"`
+	Job     domain.Job `json:"job"`
 }
 
 type ResponseSuccessAuth struct {
@@ -20,9 +35,103 @@ type ResponseSuccessAuth struct {
 }
 
 type ResponseSuccessAnalytics struct {
-	Message string          `json:"message" example:"Analytics is loaded"`
-	Months  []string        `json:"months"`
-	MRR     domain.TotalMRR `json:"mrr"`
+	Message    string                `json:"message" example:"Analytics is loaded"`
+	Months     []string              `json:"months"`
+	Periods    []string              `json:"periods" example:"2021-10-01/2021-10-31"`
+	MRR        domain.TotalMRR       `json:"mrr"`
+	Metrics    *domain.MRRMetrics    `json:"metrics,omitempty"`
+	Comparison *domain.MRRComparison `json:"comparison,omitempty"`
+}
+
+type ResponseSuccessForecast struct {
+	Message  string             `json:"message" example:"Forecast is created"`
+	Months   []string           `json:"months"`
+	Periods  []string           `json:"periods" example:"2021-10-01/2021-10-31"`
+	MRR      domain.TotalMRR    `json:"mrr"`
+	Forecast domain.MRRForecast `json:"forecast"`
+}
+
+type ResponseSuccessScenario struct {
+	Message  string          `json:"message" example:"Scenario is created"`
+	Months   []string        `json:"months"`
+	Periods  []string        `json:"periods" example:"2021-10-01/2021-10-31"`
+	Baseline domain.TotalMRR `json:"baseline"`
+	Scenario domain.TotalMRR `json:"scenario"`
+}
+
+type ResponseSuccessBridge struct {
+	Message      string                               `json:"message" example:"Bridge is created"`
+	Bridge       domain.MRRBridge                     `json:"bridge"`
+	TopCustomers map[string][]domain.CustomerMovement `json:"top_customers,omitempty"`
+}
+
+// AnalyticsProgress is streamed while analytics is computed, stage is one of cache, invoices, mpp and mrr.
+type AnalyticsProgress struct {
+	Stage   string `json:"stage" example:"invoices"`
+	Message string `json:"message" example:"120 invoices are loaded"`
+}
+
+type ResponseSuccessDeleteAccount struct {
+	Message       string `json:"message" example:"Account is deleted"`
+	Organizations int    `json:"organizations" example:"1"`
+	Files         int    `json:"files" example:"2"`
+	Invoices      int64  `json:"invoices" example:"1500"`
+	CacheKeys     int64  `json:"cache_keys" example:"4"`
+	Jobs          int64  `json:"jobs" example:"3"`
+	Webhooks      int64  `json:"webhooks" example:"1"`
+	AlertRules    int64  `json:"alert_rules" example:"2"`
+}
+
+type ResponseSuccessOrganization struct {
+	Message      string              `json:"message" example:"Organization is created"`
+	Organization domain.Organization `json:"organization"`
+}
+
+type ResponseSuccessOrganizations struct {
+	Message       string                `json:"message" example:"Organizations are loaded"`
+	Organizations []domain.Organization `json:"organizations"`
+}
+
+type ResponseSuccessAuditEvents struct {
+	Message string              `json:"message" example:"Audit events are loaded"`
+	From    time.Time           `json:"from"`
+	To      time.Time           `json:"to"`
+	Events  []domain.AuditEvent `json:"events"`
+}
+
+type ResponseSuccessWebhook struct {
+	Message string         `json:"message" example:"Webhook is created"`
+	Webhook domain.Webhook `json:"webhook"`
+}
+
+type ResponseSuccessWebhooks struct {
+	Message  string           `json:"message" example:"Webhooks are loaded"`
+	Webhooks []domain.Webhook `json:"webhooks"`
+}
+
+type ResponseSuccessWebhookDeliveries struct {
+	Message    string                   `json:"message" example:"Deliveries are loaded"`
+	Deliveries []domain.WebhookDelivery `json:"deliveries"`
+}
+
+type ResponseSuccessWebhookDelivery struct {
+	Message  string                 `json:"message" example:"Test event is delivered"`
+	Delivery domain.WebhookDelivery `json:"delivery"`
+}
+
+type ResponseSuccessAlertRule struct {
+	Message string           `json:"message" example:"Alert rule is created"`
+	Rule    domain.AlertRule `json:"rule"`
+}
+
+type ResponseSuccessAlertRules struct {
+	Message string             `json:"message" example:"Alert rules are loaded"`
+	Rules   []domain.AlertRule `json:"rules"`
+}
+
+type ResponseSuccessAlerts struct {
+	Message string         `json:"message" example:"Alerts are loaded"`
+	Alerts  []domain.Alert `json:"alerts"`
 }
 
 type Response struct {
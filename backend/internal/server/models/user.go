@@ -4,3 +4,7 @@ type User struct {
 	Email    string `json:"email" validate:"email,required" binding:"required" example:"test@test.com"`
 	Password string `json:"password" validate:"required,min=6" binding:"required" example:"password123"`
 }
+
+type PasswordConfirmation struct {
+	Password string `json:"password" binding:"required" example:"password123"`
+}
@@ -0,0 +1,6 @@
+package models
+
+type Webhook struct {
+	URL    string   `json:"url" binding:"required,url" example:"https://example.com/webhook"`
+	Events []string `json:"events" binding:"required,min=1,dive,oneof=dataset.imported dataset.deleted dataset.import_failed alert.triggered" example:"dataset.imported"`
+}
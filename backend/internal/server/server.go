@@ -2,98 +2,334 @@ package server
 
 import (
 	"context"
-	"os"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	_ "github.com/hackfeed/remrratality/backend/docs"
+	"github.com/hackfeed/remrratality/backend/internal/alerts"
+	"github.com/hackfeed/remrratality/backend/internal/calendar"
+	"github.com/hackfeed/remrratality/backend/internal/config"
 	"github.com/hackfeed/remrratality/backend/internal/db/cache"
 	"github.com/hackfeed/remrratality/backend/internal/db/storage"
 	"github.com/hackfeed/remrratality/backend/internal/db/user"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/health"
+	"github.com/hackfeed/remrratality/backend/internal/jobs"
+	"github.com/hackfeed/remrratality/backend/internal/metrics"
 	"github.com/hackfeed/remrratality/backend/internal/server/controllers"
 	"github.com/hackfeed/remrratality/backend/internal/server/middlewares"
+	alertrepo "github.com/hackfeed/remrratality/backend/internal/store/alert_repo"
+	auditrepo "github.com/hackfeed/remrratality/backend/internal/store/audit_repo"
 	cacherepo "github.com/hackfeed/remrratality/backend/internal/store/cache_repo"
+	datasetrepo "github.com/hackfeed/remrratality/backend/internal/store/dataset_repo"
+	jobrepo "github.com/hackfeed/remrratality/backend/internal/store/job_repo"
+	limitrepo "github.com/hackfeed/remrratality/backend/internal/store/limit_repo"
+	orgrepo "github.com/hackfeed/remrratality/backend/internal/store/org_repo"
 	storagerepo "github.com/hackfeed/remrratality/backend/internal/store/storage_repo"
 	userrepo "github.com/hackfeed/remrratality/backend/internal/store/user_repo"
+	webhookrepo "github.com/hackfeed/remrratality/backend/internal/store/webhook_repo"
+	"github.com/hackfeed/remrratality/backend/internal/webhooks"
 	log "github.com/sirupsen/logrus"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
+// serverName is reported in http.server_name attribute of request spans.
+const serverName = "remrratality-backend"
+
 var (
-	userRepo    userrepo.UserRepository
-	storageRepo storagerepo.StorageRepository
-	cacheRepo   cacherepo.CacheRepository
+	authLimit      = domain.Limit{Rate: 10.0 / 60, Burst: 10}
+	analyticsLimit = domain.Limit{Rate: 1, Burst: 30}
+	uploadLimit    = domain.Limit{Rate: 20.0 / 3600, Burst: 5}
 )
 
-func init() {
-	ctx := context.Background()
+// Dependencies are repositories handlers work with, queue of background jobs, notifier of
+// webhooks, checks readiness probe runs and reporting calendar analytics is bucketed by. Client
+// addresses are taken from X-Forwarded-For of TrustedProxies only. Jobs and Webhooks are set by
+// the caller, as runner and dispatcher behind them need repositories.
+type Dependencies struct {
+	UserRepo       userrepo.UserRepository
+	OrgRepo        orgrepo.OrgRepository
+	DatasetRepo    datasetrepo.DatasetRepository
+	StorageRepo    storagerepo.StorageRepository
+	CacheRepo      cacherepo.CacheRepository
+	LimitRepo      limitrepo.LimitRepository
+	AuditRepo      auditrepo.AuditRepository
+	JobRepo        jobrepo.JobRepository
+	Jobs           jobs.Queue
+	WebhookRepo    webhookrepo.WebhookRepository
+	Webhooks       webhooks.Notifier
+	AlertRepo      alertrepo.AlertRepository
+	HealthChecks   []health.Check
+	Calendar       calendar.Calendar
+	TrustedProxies []string
+
+	closers []closer
+}
+
+type closer struct {
+	name  string
+	close func(context.Context) error
+}
+
+// Close closes clients of backing stores in the order they were created, failure to close
+// one of them doesn't keep the rest open. It should be called once server has stopped
+// serving requests.
+func (d Dependencies) Close(ctx context.Context) error {
+	var failed []string
+
+	for _, c := range d.closers {
+		if err := c.close(ctx); err != nil {
+			log.Errorf("failed to close %s client, error is: %s", c.name, err)
+			failed = append(failed, c.name)
+		}
+	}
+
+	if len(failed) != 0 {
+		return fmt.Errorf("failed to close clients: %s", strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// Connect creates clients of backing stores described by cfg and repositories on top of them.
+// Stores aren't required to be reachable, server starts in degraded mode if any of them is down.
+func Connect(ctx context.Context, cfg config.Config) (Dependencies, error) {
+	cal, err := calendar.New(cfg.Calendar)
+	if err != nil {
+		return Dependencies{}, fmt.Errorf("failed to create reporting calendar, error is: %s", err)
+	}
 
 	userClient, err := user.NewMongoClient(ctx, &user.Options{
-		Host:     os.Getenv("MONGO_HOST"),
-		Port:     os.Getenv("MONGO_PORT"),
-		User:     os.Getenv("MONGO_USER"),
-		Password: os.Getenv("MONGO_PASS"),
-		DB:       os.Getenv("MONGO_DB"),
+		Host:     cfg.Mongo.Host,
+		Port:     cfg.Mongo.Port,
+		User:     cfg.Mongo.User,
+		Password: cfg.Mongo.Password,
+		DB:       cfg.Mongo.DB,
 	})
 	if err != nil {
-		log.Fatalf("failed to create mongo client, error is: %s", err)
+		return Dependencies{}, fmt.Errorf("failed to create mongo client, error is: %s", err)
 	}
-	userRepo = userrepo.NewMongoRepo(*userClient)
 
 	storageClient, err := storage.NewPostgresClient(ctx, &storage.Options{
-		Host:     os.Getenv("POSTGRES_HOST"),
-		Port:     os.Getenv("POSTGRES_PORT"),
-		User:     os.Getenv("POSTGRES_USER"),
-		Password: os.Getenv("POSTGRES_PASS"),
-		DB:       os.Getenv("POSTGRES_DB"),
+		Host:     cfg.Postgres.Host,
+		Port:     cfg.Postgres.Port,
+		User:     cfg.Postgres.User,
+		Password: cfg.Postgres.Password,
+		DB:       cfg.Postgres.DB,
 	})
 	if err != nil {
-		log.Fatalf("failed to create postgres client, error is: %s", err)
+		return Dependencies{}, fmt.Errorf("failed to create postgres client, error is: %s", err)
+	}
+	if err := metrics.Register(metrics.NewPoolCollector(storageClient.Client)); err != nil {
+		return Dependencies{}, fmt.Errorf("failed to register postgres pool metrics, error is: %s", err)
 	}
-	storageRepo = storagerepo.NewPostgresRepo(*storageClient)
 
 	cacheClient, err := cache.NewRedisClient(ctx, &cache.Options{
-		Host:     os.Getenv("REDIS_HOST"),
-		Port:     os.Getenv("REDIS_PORT"),
-		Password: os.Getenv("REDIS_PASS"),
-		DB:       os.Getenv("REDIS_DB"),
+		Host:     cfg.Redis.Host,
+		Port:     cfg.Redis.Port,
+		Password: cfg.Redis.Password,
+		DB:       strconv.Itoa(cfg.Redis.DB),
 	})
 	if err != nil {
-		log.Fatalf("failed to create redis client, error is: %s", err)
+		return Dependencies{}, fmt.Errorf("failed to create redis client, error is: %s", err)
+	}
+
+	deps := Dependencies{
+		UserRepo:    userrepo.NewMongoRepo(*userClient),
+		OrgRepo:     orgrepo.NewMongoRepo(*userClient),
+		DatasetRepo: datasetrepo.NewMongoRepo(*userClient),
+		StorageRepo: storagerepo.NewPostgresRepo(*storageClient),
+		CacheRepo:   cacherepo.NewRedisRepo(*cacheClient, cfg.Cache.TTL),
+		LimitRepo:   limitrepo.NewFallbackRepo(limitrepo.NewRedisRepo(*cacheClient), limitrepo.NewMemoryRepo()),
+		AuditRepo:   auditrepo.NewPostgresRepo(*storageClient),
+		JobRepo:     jobrepo.NewMongoRepo(*userClient),
+		WebhookRepo: webhookrepo.NewMongoRepo(*userClient),
+		AlertRepo:   alertrepo.NewMongoRepo(*userClient),
+		HealthChecks: []health.Check{
+			{Name: "mongo", Pinger: userClient, Critical: true},
+			{Name: "postgres", Pinger: storageClient, Critical: true},
+			{Name: "redis", Pinger: cacheClient},
+		},
+		Calendar:       cal,
+		TrustedProxies: cfg.Server.TrustedProxies,
+		closers: []closer{
+			{name: "mongo", close: userClient.Close},
+			{name: "postgres", close: func(context.Context) error {
+				storageClient.Close()
+				return nil
+			}},
+			{name: "redis", close: func(context.Context) error {
+				return cacheClient.Close()
+			}},
+		},
+	}
+
+	report := health.Run(ctx, deps.HealthChecks, 5*time.Second)
+	for name, result := range report.Checks {
+		if result.Status == health.StatusDown {
+			log.Warnf("%s is unreachable, server starts in %s mode, error is: %s", name, report.Status, result.Error)
+		}
+	}
+
+	return deps, nil
+}
+
+// Analyzer computes MRR analytics of datasets for alert rules, the same way analytics endpoint does.
+func (d Dependencies) Analyzer() alerts.Analyzer {
+	analyze := controllers.MRRAnalyzer(d.StorageRepo, d.CacheRepo)
+
+	return func(ctx context.Context, orgID, fileID, periodStart, periodEnd string) ([]string, domain.TotalMRR, error) {
+		return analyze(calendar.WithContext(ctx, d.Calendar), orgID, fileID, periodStart, periodEnd)
 	}
-	cacheRepo = cacherepo.NewRedisRepo(*cacheClient, 1*time.Hour)
 }
 
-func SetupServer() *gin.Engine {
-	r := gin.Default()
+// New creates server with all routes, handlers use repositories from deps only.
+func New(deps Dependencies) *gin.Engine {
+	r := gin.New()
+	// Proxies are validated with config, so the error is only possible for hand-made deps.
+	if err := r.SetTrustedProxies(deps.TrustedProxies); err != nil {
+		log.Errorf("failed to set trusted proxies, error is: %s", err)
+	}
+	r.Use(middlewares.Tracing(serverName))
+	r.Use(middlewares.RequestID())
+	r.Use(middlewares.Logger())
+	r.Use(gin.Recovery())
+	r.Use(middlewares.Metrics())
 
 	config := cors.DefaultConfig()
 	config.AllowAllOrigins = true
-	config.AllowHeaders = []string{"token", "Origin", "X-Requested-With", "Content-Type", "Accept"}
-	config.AllowMethods = []string{"GET", "POST", "DELETE"}
+	config.AllowHeaders = []string{"token", "org", "X-Request-ID", "traceparent", "tracestate", "Origin", "X-Requested-With", "Content-Type", "Accept"}
+	config.AllowMethods = []string{"GET", "POST", "PATCH", "DELETE"}
+	config.ExposeHeaders = []string{"Retry-After", "X-Request-ID"}
 	r.Use(cors.New(config))
 
-	r.Use(middlewares.UserRepo(userRepo))
-	r.Use(middlewares.StorageRepo(storageRepo))
-	r.Use(middlewares.CacheRepo(cacheRepo))
+	r.Use(middlewares.UserRepo(deps.UserRepo))
+	r.Use(middlewares.OrgRepo(deps.OrgRepo))
+	r.Use(middlewares.DatasetRepo(deps.DatasetRepo))
+	r.Use(middlewares.StorageRepo(deps.StorageRepo))
+	r.Use(middlewares.CacheRepo(deps.CacheRepo))
+	r.Use(middlewares.LimitRepo(deps.LimitRepo))
+	r.Use(middlewares.AuditRepo(deps.AuditRepo))
+	r.Use(middlewares.JobRepo(deps.JobRepo))
+	r.Use(middlewares.Jobs(deps.Jobs))
+	r.Use(middlewares.WebhookRepo(deps.WebhookRepo))
+	r.Use(middlewares.Webhooks(deps.Webhooks))
+	r.Use(middlewares.AlertRepo(deps.AlertRepo))
+	r.Use(middlewares.Calendar(deps.Calendar))
+
+	r.GET("/.well-known/jwks.json", controllers.JWKS)
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+	r.GET("/healthz", controllers.Healthz)
+	r.GET("/readyz", middlewares.HealthChecks(deps.HealthChecks), controllers.Readyz)
 
 	v1 := r.Group("/api/v1")
 	{
-		v1.POST("/signup", controllers.SignUp)
-		v1.POST("/login", controllers.Login)
+		v1.POST(
+			"/signup",
+			middlewares.Audit(domain.AuditSignUp),
+			middlewares.RateLimit("signup", authLimit, middlewares.ByIP),
+			controllers.SignUp,
+		)
+		v1.POST(
+			"/login",
+			middlewares.Audit(domain.AuditLogin),
+			middlewares.RateLimit("login", authLimit, middlewares.ByIP),
+			controllers.Login,
+		)
+
+		account := v1.Group("/account", middlewares.Auth())
+		{
+			account.DELETE("", middlewares.RateLimit("account", authLimit, middlewares.ByUser), controllers.DeleteAccount)
+		}
+
+		v1.GET("/audit", middlewares.Auth(), controllers.LoadAuditEvents)
+
+		orgs := v1.Group("/orgs", middlewares.Auth())
+		{
+			orgs.GET("", controllers.LoadOrganizations)
+			orgs.POST("", controllers.CreateOrganization)
+			orgs.POST(":org_id/members", middlewares.Org(domain.RoleOwner), controllers.AddMember)
+			orgs.DELETE(":org_id/members/:user_id", middlewares.Org(domain.RoleOwner), controllers.DeleteMember)
+		}
 
 		files := v1.Group("/files", middlewares.Auth())
 		{
-			files.GET("", controllers.LoadFiles)
-			files.POST("", controllers.SaveFileContent)
-			files.DELETE(":filename", controllers.DeleteFileContent)
+			files.GET("", middlewares.Org(domain.RoleViewer), controllers.LoadFiles)
+			files.GET(":id", middlewares.Org(domain.RoleViewer), controllers.GetFile)
+			files.PATCH(":id", middlewares.Org(domain.RoleEditor), controllers.UpdateFile)
+			files.GET(":id/export", middlewares.Org(domain.RoleViewer), controllers.ExportFile)
+			files.POST(
+				"",
+				middlewares.Audit(domain.AuditFileUpload),
+				middlewares.Org(domain.RoleEditor),
+				middlewares.RateLimit("upload", uploadLimit, middlewares.ByUser),
+				controllers.SaveFileContent,
+			)
+			files.DELETE(
+				":id",
+				middlewares.Audit(domain.AuditFileDelete),
+				middlewares.Org(domain.RoleEditor),
+				controllers.DeleteFileContent,
+			)
+		}
+
+		v1.GET("/jobs/:id", middlewares.Auth(), middlewares.Org(domain.RoleViewer), controllers.GetJob)
+		v1.GET("/jobs/:id/events", middlewares.Auth(), middlewares.Org(domain.RoleViewer), controllers.GetJobEvents)
+
+		hooks := v1.Group("/webhooks", middlewares.Auth())
+		{
+			hooks.GET("", middlewares.Org(domain.RoleOwner), controllers.LoadWebhooks)
+			hooks.POST(
+				"",
+				middlewares.Audit(domain.AuditWebhookCreate),
+				middlewares.Org(domain.RoleOwner),
+				controllers.CreateWebhook,
+			)
+			hooks.DELETE(
+				":id",
+				middlewares.Audit(domain.AuditWebhookDelete),
+				middlewares.Org(domain.RoleOwner),
+				controllers.DeleteWebhook,
+			)
+			hooks.GET(":id/deliveries", middlewares.Org(domain.RoleOwner), controllers.LoadWebhookDeliveries)
+			hooks.POST(":id/test", middlewares.Org(domain.RoleOwner), controllers.SendWebhookTest)
+		}
+
+		alerting := v1.Group("/alerts", middlewares.Auth())
+		{
+			alerting.GET("", middlewares.Org(domain.RoleViewer), controllers.LoadAlerts)
+			alerting.GET("/rules", middlewares.Org(domain.RoleViewer), controllers.LoadAlertRules)
+			alerting.POST(
+				"/rules",
+				middlewares.Audit(domain.AuditAlertRuleCreate),
+				middlewares.Org(domain.RoleEditor),
+				controllers.CreateAlertRule,
+			)
+			alerting.DELETE(
+				"/rules/:id",
+				middlewares.Audit(domain.AuditAlertRuleDelete),
+				middlewares.Org(domain.RoleEditor),
+				controllers.DeleteAlertRule,
+			)
 		}
 
-		analytics := v1.Group("/analytics", middlewares.Auth())
+		analytics := v1.Group(
+			"/analytics",
+			middlewares.Auth(),
+			middlewares.Audit(domain.AuditAnalytics),
+			middlewares.Org(domain.RoleViewer),
+			middlewares.RateLimit("analytics", analyticsLimit, middlewares.ByUser),
+		)
 		{
 			analytics.POST("/mrr", controllers.CreateAnalytics)
+			analytics.POST("/forecast", controllers.CreateForecast)
+			analytics.POST("/scenario", controllers.CreateScenario)
+			analytics.POST("/bridge", controllers.CreateBridge)
 		}
 
 		v1.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
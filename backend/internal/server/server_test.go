@@ -0,0 +1,236 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hackfeed/remrratality/backend/internal/calendar"
+	"github.com/hackfeed/remrratality/backend/internal/health"
+	"github.com/hackfeed/remrratality/backend/internal/jobs"
+	alertrepo "github.com/hackfeed/remrratality/backend/internal/store/alert_repo"
+	auditrepo "github.com/hackfeed/remrratality/backend/internal/store/audit_repo"
+	cacherepo "github.com/hackfeed/remrratality/backend/internal/store/cache_repo"
+	datasetrepo "github.com/hackfeed/remrratality/backend/internal/store/dataset_repo"
+	jobrepo "github.com/hackfeed/remrratality/backend/internal/store/job_repo"
+	limitrepo "github.com/hackfeed/remrratality/backend/internal/store/limit_repo"
+	orgrepo "github.com/hackfeed/remrratality/backend/internal/store/org_repo"
+	storagerepo "github.com/hackfeed/remrratality/backend/internal/store/storage_repo"
+	userrepo "github.com/hackfeed/remrratality/backend/internal/store/user_repo"
+	webhookrepo "github.com/hackfeed/remrratality/backend/internal/store/webhook_repo"
+	"github.com/hackfeed/remrratality/backend/internal/utils/user_validation"
+	"github.com/hackfeed/remrratality/backend/internal/webhooks"
+	"github.com/stretchr/testify/assert"
+)
+
+type pingerMock struct{}
+
+func (pm pingerMock) Ping(_ context.Context) error {
+	return nil
+}
+
+func newTestServer() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	return New(testDependencies())
+}
+
+func testDependencies() Dependencies {
+	return Dependencies{
+		UserRepo:    &userrepo.UserRepositoryMock{},
+		OrgRepo:     &orgrepo.OrgRepositoryMock{},
+		DatasetRepo: &datasetrepo.DatasetRepositoryMock{},
+		StorageRepo: &storagerepo.StorageRepositoryMock{},
+		CacheRepo:   &cacherepo.CacheRepositoryMock{},
+		LimitRepo:   &limitrepo.LimitRepositoryMock{},
+		AuditRepo:   &auditrepo.AuditRepositoryMock{},
+		JobRepo:     &jobrepo.JobRepositoryMock{},
+		Jobs:        &jobs.QueueMock{},
+		WebhookRepo: &webhookrepo.WebhookRepositoryMock{},
+		Webhooks:    &webhooks.NotifierMock{},
+		AlertRepo:   &alertrepo.AlertRepositoryMock{},
+		HealthChecks: []health.Check{
+			{Name: "mongo", Pinger: pingerMock{}, Critical: true},
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	user_validation.SetSecretKey("secret")
+	defer user_validation.SetSecretKey("")
+
+	token, _, err := user_validation.GenerateTokens("user@example.com", "user")
+	assert.NoError(t, err)
+
+	type testInput struct {
+		method, path, token string
+	}
+	type testWant struct {
+		code    int
+		message string
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{
+				method: http.MethodGet,
+				path:   "/healthz",
+			},
+			want: testWant{
+				code:    http.StatusOK,
+				message: "{\"status\":\"ok\"}",
+			},
+		},
+		{
+			input: testInput{
+				method: http.MethodGet,
+				path:   "/readyz",
+			},
+			want: testWant{
+				code:    http.StatusOK,
+				message: "\"mongo\":{\"status\":\"up\"",
+			},
+		},
+		{
+			input: testInput{
+				method: http.MethodGet,
+				path:   "/api/v1/files",
+			},
+			want: testWant{
+				code:    http.StatusUnauthorized,
+				message: "No Authorization header provided",
+			},
+		},
+		{
+			input: testInput{
+				method: http.MethodGet,
+				path:   "/api/v1/audit",
+				token:  token,
+			},
+			want: testWant{
+				code:    http.StatusOK,
+				message: "Audit events are loaded",
+			},
+		},
+	}
+
+	r := newTestServer()
+
+	for _, test := range tests {
+		req := httptest.NewRequest(test.input.method, test.input.path, nil)
+		if test.input.token != "" {
+			req.Header.Set("token", test.input.token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, test.want.code, w.Code)
+		assert.Equal(t, true, strings.Contains(w.Body.String(), test.want.message))
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	type testInput struct {
+		requestID string
+	}
+	type testWant struct {
+		kept bool
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{
+				requestID: "client-request.1",
+			},
+			want: testWant{
+				kept: true,
+			},
+		},
+		{
+			input: testInput{
+				requestID: "",
+			},
+			want: testWant{
+				kept: false,
+			},
+		},
+		{
+			input: testInput{
+				requestID: "bad request id\n",
+			},
+			want: testWant{
+				kept: false,
+			},
+		},
+	}
+
+	r := newTestServer()
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+		if test.input.requestID != "" {
+			req.Header.Set("X-Request-ID", test.input.requestID)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		requestID := w.Header().Get("X-Request-ID")
+		assert.NotEmpty(t, requestID)
+		assert.Equal(t, test.want.kept, requestID == test.input.requestID)
+	}
+}
+
+func TestAnalyzer(t *testing.T) {
+	deps := Dependencies{
+		StorageRepo: &storagerepo.StorageRepositoryMock{},
+		CacheRepo:   &cacherepo.CacheRepositoryMock{},
+		Calendar:    calendar.Monthly{FiscalYearStart: time.February},
+	}
+
+	months, mrr, err := deps.Analyzer()(context.Background(), "user", "file", "2021-10-01", "2021-10-31")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"P9.FY2022"}, months)
+	assert.Equal(t, []float32{100}, mrr.Total)
+}
+
+func TestRateLimitForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	deps := testDependencies()
+	deps.LimitRepo = limitrepo.NewMemoryRepo()
+	r := New(deps)
+
+	// Spoofed X-Forwarded-For is ignored, so every request is limited as the one of RemoteAddr.
+	codes := make([]int, 0, 11)
+	for i := 0; i < 11; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/login", strings.NewReader("{}"))
+		req.RemoteAddr = "203.0.113.7:4321"
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%d", i))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		codes = append(codes, w.Code)
+	}
+	assert.NotEqual(t, http.StatusTooManyRequests, codes[9])
+	assert.Equal(t, http.StatusTooManyRequests, codes[10])
+
+	// X-Forwarded-For of trusted proxy is used.
+	deps.LimitRepo = limitrepo.NewMemoryRepo()
+	deps.TrustedProxies = []string{"203.0.113.0/24"}
+	r = New(deps)
+	for i := 0; i < 11; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/login", strings.NewReader("{}"))
+		req.RemoteAddr = "203.0.113.7:4321"
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%d", i))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.NotEqual(t, http.StatusTooManyRequests, w.Code)
+	}
+}
@@ -0,0 +1,105 @@
+package alertrepo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
+
+type AlertRepositoryMock struct{}
+
+func (arm *AlertRepositoryMock) AddRule(_ context.Context, rule domain.AlertRule) (domain.AlertRule, error) {
+	if rule.OrgID == "errorAddRule" {
+		return domain.AlertRule{}, errors.New("error while adding alert rule")
+	}
+	rule.ID = "rule"
+	return rule, nil
+}
+
+func (arm *AlertRepositoryMock) GetRule(_ context.Context, ruleID string) (domain.AlertRule, error) {
+	if ruleID == "errorGetRule" {
+		return domain.AlertRule{}, errors.New("error while getting alert rule")
+	}
+	if ruleID == "notFoundRule" {
+		return domain.AlertRule{}, ErrNotFound
+	}
+	orgID := "org"
+	if ruleID == "otherOrgRule" {
+		orgID = "otherOrg"
+	}
+	return mockRule(ruleID, orgID), nil
+}
+
+func (arm *AlertRepositoryMock) GetRules(_ context.Context, orgID string) ([]domain.AlertRule, error) {
+	if orgID == "errorGetRules" {
+		return nil, errors.New("error while getting alert rules")
+	}
+	return []domain.AlertRule{mockRule("rule", orgID)}, nil
+}
+
+func (arm *AlertRepositoryMock) GetAllRules(_ context.Context) ([]domain.AlertRule, error) {
+	return []domain.AlertRule{mockRule("rule", "org")}, nil
+}
+
+func (arm *AlertRepositoryMock) DeleteRule(_ context.Context, ruleID string) error {
+	if ruleID == "errorDeleteRule" {
+		return errors.New("error while deleting alert rule")
+	}
+	return nil
+}
+
+func (arm *AlertRepositoryMock) DeleteRules(_ context.Context, orgID string) (int64, error) {
+	if orgID == "errorDeleteRules" {
+		return 0, errors.New("error while deleting alert rules")
+	}
+	return 2, nil
+}
+
+func (arm *AlertRepositoryMock) AddAlert(_ context.Context, alert domain.Alert) (domain.Alert, error) {
+	if alert.OrgID == "errorAddAlert" {
+		return domain.Alert{}, errors.New("error while adding alert")
+	}
+	if alert.OrgID == "existingAlert" {
+		return domain.Alert{}, ErrExists
+	}
+	alert.ID = "alert"
+	return alert, nil
+}
+
+func (arm *AlertRepositoryMock) GetAlerts(_ context.Context, orgID, ruleID string) ([]domain.Alert, error) {
+	if orgID == "errorGetAlerts" {
+		return nil, errors.New("error while getting alerts")
+	}
+	if ruleID == "" {
+		ruleID = "rule"
+	}
+	return []domain.Alert{
+		{
+			ID:        "alert",
+			RuleID:    ruleID,
+			OrgID:     orgID,
+			DatasetID: "file.csv",
+			Month:     "10.2021",
+			Metric:    domain.AlertMetricChurn,
+			Condition: domain.AlertAbove,
+			Threshold: 5,
+			Value:     50,
+			Message:   "Churn MRR of 10.2021 is 50.00% of the previous month's total, above the threshold of 5.00%",
+		},
+	}, nil
+}
+
+func mockRule(ruleID, orgID string) domain.AlertRule {
+	return domain.AlertRule{
+		ID:              ruleID,
+		OrgID:           orgID,
+		Name:            "Churn spike",
+		Metric:          domain.AlertMetricChurn,
+		Condition:       domain.AlertAbove,
+		Threshold:       5,
+		OfPreviousTotal: true,
+		Channels:        []string{domain.AlertChannelLog},
+		Recipients:      make([]string, 0),
+	}
+}
@@ -0,0 +1,223 @@
+package alertrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/db/user"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// alertsLimit is the number of the latest alerts returned for organization.
+const alertsLimit = 100
+
+type mongoRepo struct {
+	UserClient user.MongoClient
+}
+
+func NewMongoRepo(userClient user.MongoClient) AlertRepository {
+	return &mongoRepo{
+		UserClient: userClient,
+	}
+}
+
+func (mr *mongoRepo) AddRule(ctx context.Context, rule domain.AlertRule) (domain.AlertRule, error) {
+	createdAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	if rule.ID == "" {
+		rule.ID = primitive.NewObjectID().Hex()
+	}
+	if rule.Channels == nil {
+		rule.Channels = make([]string, 0)
+	}
+	if rule.Recipients == nil {
+		rule.Recipients = make([]string, 0)
+	}
+	rule.CreatedAt = createdAt
+
+	if _, err := mr.UserClient.CreateAlertRule(ctx, convertRuleToUser(rule)); err != nil {
+		return domain.AlertRule{}, fmt.Errorf("failed to insert alert rule %s, error is: %s", rule.ID, err)
+	}
+
+	return rule, nil
+}
+
+func (mr *mongoRepo) GetRule(ctx context.Context, ruleID string) (domain.AlertRule, error) {
+	rule, err := mr.UserClient.ReadAlertRule(ctx, bson.M{"rule_id": ruleID})
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.AlertRule{}, ErrNotFound
+	}
+	if err != nil {
+		return domain.AlertRule{}, fmt.Errorf("failed to get alert rule %s, error is: %s", ruleID, err)
+	}
+
+	return convertRuleToDomain(rule), nil
+}
+
+// GetRules returns alert rules of organization in order they were created.
+func (mr *mongoRepo) GetRules(ctx context.Context, orgID string) ([]domain.AlertRule, error) {
+	rules, err := mr.UserClient.ReadAlertRules(ctx, bson.M{"org_id": orgID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alert rules of org_id %s, error is: %s", orgID, err)
+	}
+
+	return convertRulesToDomain(rules), nil
+}
+
+// GetAllRules returns alert rules of every organization, they're evaluated monthly.
+func (mr *mongoRepo) GetAllRules(ctx context.Context) ([]domain.AlertRule, error) {
+	rules, err := mr.UserClient.ReadAlertRules(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alert rules, error is: %s", err)
+	}
+
+	return convertRulesToDomain(rules), nil
+}
+
+// DeleteRule deletes alert rule with all alerts it has triggered.
+func (mr *mongoRepo) DeleteRule(ctx context.Context, ruleID string) error {
+	err := mr.UserClient.DeleteAlertRule(ctx, bson.M{"rule_id": ruleID})
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete alert rule %s, error is: %s", ruleID, err)
+	}
+
+	return nil
+}
+
+// DeleteRules deletes alert rules of organization with all alerts they have triggered.
+func (mr *mongoRepo) DeleteRules(ctx context.Context, orgID string) (int64, error) {
+	deleted, err := mr.UserClient.DeleteAlertRules(ctx, bson.M{"org_id": orgID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete alert rules of organization %s, error is: %s", orgID, err)
+	}
+
+	return deleted, nil
+}
+
+// AddAlert records alert triggered by the rule, ErrExists is returned when the rule has already
+// triggered for the same month of the dataset.
+func (mr *mongoRepo) AddAlert(ctx context.Context, alert domain.Alert) (domain.Alert, error) {
+	createdAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	if alert.ID == "" {
+		alert.ID = primitive.NewObjectID().Hex()
+	}
+	alert.CreatedAt = createdAt
+
+	_, err := mr.UserClient.CreateAlert(ctx, convertAlertToUser(alert))
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.Alert{}, ErrExists
+	}
+	if err != nil {
+		return domain.Alert{}, fmt.Errorf("failed to insert alert %s, error is: %s", alert.ID, err)
+	}
+
+	return alert, nil
+}
+
+// GetAlerts returns the latest alerts of organization, newest first. Alerts of every rule are
+// returned if ruleID is empty.
+func (mr *mongoRepo) GetAlerts(ctx context.Context, orgID, ruleID string) ([]domain.Alert, error) {
+	filter := bson.M{"org_id": orgID}
+	if ruleID != "" {
+		filter["rule_id"] = ruleID
+	}
+
+	alerts, err := mr.UserClient.ReadAlerts(ctx, filter, alertsLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alerts of org_id %s, error is: %s", orgID, err)
+	}
+
+	mappedAlerts := make([]domain.Alert, len(alerts))
+	for i, alert := range alerts {
+		mappedAlerts[i] = domain.Alert{
+			ID:        alert.AlertID,
+			RuleID:    alert.RuleID,
+			OrgID:     alert.OrgID,
+			DatasetID: alert.DatasetID,
+			Month:     alert.Month,
+			Metric:    alert.Metric,
+			Condition: alert.Condition,
+			Threshold: alert.Threshold,
+			Value:     alert.Value,
+			Message:   alert.Message,
+			CreatedAt: alert.CreatedAt,
+		}
+	}
+
+	return mappedAlerts, nil
+}
+
+func convertRulesToDomain(rules []user.AlertRule) []domain.AlertRule {
+	mappedRules := make([]domain.AlertRule, len(rules))
+	for i, rule := range rules {
+		mappedRules[i] = convertRuleToDomain(rule)
+	}
+	return mappedRules
+}
+
+func convertRuleToDomain(rule user.AlertRule) domain.AlertRule {
+	channels := rule.Channels
+	if channels == nil {
+		channels = make([]string, 0)
+	}
+	recipients := rule.Recipients
+	if recipients == nil {
+		recipients = make([]string, 0)
+	}
+
+	return domain.AlertRule{
+		ID:              rule.RuleID,
+		OrgID:           rule.OrgID,
+		Name:            rule.Name,
+		DatasetID:       rule.DatasetID,
+		Metric:          rule.Metric,
+		Condition:       rule.Condition,
+		Threshold:       rule.Threshold,
+		OfPreviousTotal: rule.OfPreviousTotal,
+		Channels:        channels,
+		Recipients:      recipients,
+		CreatedAt:       rule.CreatedAt,
+	}
+}
+
+func convertRuleToUser(rule domain.AlertRule) user.AlertRule {
+	return user.AlertRule{
+		RuleID:          rule.ID,
+		OrgID:           rule.OrgID,
+		Name:            rule.Name,
+		DatasetID:       rule.DatasetID,
+		Metric:          rule.Metric,
+		Condition:       rule.Condition,
+		Threshold:       rule.Threshold,
+		OfPreviousTotal: rule.OfPreviousTotal,
+		Channels:        rule.Channels,
+		Recipients:      rule.Recipients,
+		CreatedAt:       rule.CreatedAt,
+	}
+}
+
+func convertAlertToUser(alert domain.Alert) user.Alert {
+	return user.Alert{
+		Key:       fmt.Sprintf("%s/%s/%s", alert.RuleID, alert.DatasetID, alert.Month),
+		AlertID:   alert.ID,
+		RuleID:    alert.RuleID,
+		OrgID:     alert.OrgID,
+		DatasetID: alert.DatasetID,
+		Month:     alert.Month,
+		Metric:    alert.Metric,
+		Condition: alert.Condition,
+		Threshold: alert.Threshold,
+		Value:     alert.Value,
+		Message:   alert.Message,
+		CreatedAt: alert.CreatedAt,
+	}
+}
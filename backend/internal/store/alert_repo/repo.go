@@ -0,0 +1,24 @@
+package alertrepo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
+
+var (
+	ErrNotFound = errors.New("alert rule not found")
+	ErrExists   = errors.New("alert already exists")
+)
+
+type AlertRepository interface {
+	AddRule(context.Context, domain.AlertRule) (domain.AlertRule, error)
+	GetRule(context.Context, string) (domain.AlertRule, error)
+	GetRules(context.Context, string) ([]domain.AlertRule, error)
+	GetAllRules(context.Context) ([]domain.AlertRule, error)
+	DeleteRule(context.Context, string) error
+	DeleteRules(context.Context, string) (int64, error)
+	AddAlert(context.Context, domain.Alert) (domain.Alert, error)
+	GetAlerts(context.Context, string, string) ([]domain.Alert, error)
+}
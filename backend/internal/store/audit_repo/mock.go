@@ -0,0 +1,40 @@
+package auditrepo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
+
+type AuditRepositoryMock struct{}
+
+func (arm *AuditRepositoryMock) AddEvent(_ context.Context, event domain.AuditEvent) error {
+	if event.UserID == "errorAddEvent" {
+		return errors.New("error while adding audit event")
+	}
+	return nil
+}
+
+func (arm *AuditRepositoryMock) GetEvents(_ context.Context, userID string, from, _ time.Time) ([]domain.AuditEvent, error) {
+	if userID == "errorGetEvents" {
+		return nil, errors.New("error while getting audit events")
+	}
+	if userID == "emptyGetEvents" {
+		return make([]domain.AuditEvent, 0), nil
+	}
+	return []domain.AuditEvent{
+		{
+			UserID:    userID,
+			OrgID:     userID,
+			Action:    domain.AuditLogin,
+			Success:   true,
+			Status:    200,
+			IP:        "127.0.0.1",
+			RequestID: "request",
+			Details:   map[string]interface{}{},
+			CreatedAt: from,
+		},
+	}, nil
+}
@@ -0,0 +1,72 @@
+package auditrepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/db/storage"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
+
+type postgresRepo struct {
+	StorageClient storage.PostgresClient
+}
+
+func NewPostgresRepo(storageClient storage.PostgresClient) AuditRepository {
+	return &postgresRepo{
+		StorageClient: storageClient,
+	}
+}
+
+func (pr *postgresRepo) AddEvent(ctx context.Context, event domain.AuditEvent) error {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+
+	err := pr.StorageClient.CreateAuditEvent(ctx, "audit_log", storage.AuditEvent{
+		UserID:    event.UserID,
+		OrgID:     event.OrgID,
+		Action:    event.Action,
+		Success:   event.Success,
+		Status:    event.Status,
+		IP:        event.IP,
+		RequestID: event.RequestID,
+		Details:   event.Details,
+		CreatedAt: event.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to insert %s audit event for user_id %s, error is: %s", event.Action, event.UserID, err)
+	}
+
+	return nil
+}
+
+func (pr *postgresRepo) GetEvents(ctx context.Context, userID string, from, to time.Time) ([]domain.AuditEvent, error) {
+	events, err := pr.StorageClient.ReadAuditEvents(ctx, "audit_log", userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to read audit events from %v to %v with user_id %s, error is: %s",
+			from, to, userID, err,
+		)
+	}
+
+	mappedEvents := make([]domain.AuditEvent, 0)
+
+	for _, event := range events {
+		mappedEvent := domain.AuditEvent{
+			UserID:    event.UserID,
+			OrgID:     event.OrgID,
+			Action:    event.Action,
+			Success:   event.Success,
+			Status:    event.Status,
+			IP:        event.IP,
+			RequestID: event.RequestID,
+			Details:   event.Details,
+			CreatedAt: event.CreatedAt,
+		}
+		mappedEvents = append(mappedEvents, mappedEvent)
+	}
+
+	return mappedEvents, nil
+}
@@ -0,0 +1,13 @@
+package auditrepo
+
+import (
+	"context"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
+
+type AuditRepository interface {
+	AddEvent(context.Context, domain.AuditEvent) error
+	GetEvents(context.Context, string, time.Time, time.Time) ([]domain.AuditEvent, error)
+}
@@ -1,6 +1,7 @@
 package cacherepo
 
 import (
+	"context"
 	"errors"
 
 	"github.com/hackfeed/remrratality/backend/internal/domain"
@@ -8,7 +9,7 @@ import (
 
 type CacheRepositoryMock struct{}
 
-func (crm *CacheRepositoryMock) GetMRR(key string) (domain.TotalMRR, error) {
+func (crm *CacheRepositoryMock) GetMRR(_ context.Context, key string) (domain.TotalMRR, error) {
 	if key == "user.file-2021-01-02-2021-02-02" {
 		return domain.TotalMRR{}, errors.New("error while fetching mrr from cache")
 	}
@@ -18,9 +19,16 @@ func (crm *CacheRepositoryMock) GetMRR(key string) (domain.TotalMRR, error) {
 	return domain.TotalMRR{}, nil
 }
 
-func (crm *CacheRepositoryMock) SetMRR(key string, mrr domain.TotalMRR) (domain.TotalMRR, error) {
+func (crm *CacheRepositoryMock) SetMRR(_ context.Context, key string, mrr domain.TotalMRR) (domain.TotalMRR, error) {
 	if key == "errorSetMRR.file-2021-10-01-2021-10-31" {
 		return domain.TotalMRR{}, errors.New("error while setting mrr to cache")
 	}
 	return mrr, nil
 }
+
+func (crm *CacheRepositoryMock) DeleteMRR(_ context.Context, prefix string) (int64, error) {
+	if prefix == "errorDeleteMRR" {
+		return 0, errors.New("error while deleting mrr from cache")
+	}
+	return 2, nil
+}
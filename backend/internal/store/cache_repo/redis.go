@@ -9,6 +9,7 @@ import (
 	"github.com/go-redis/redis/v8"
 	"github.com/hackfeed/remrratality/backend/internal/db/cache"
 	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/metrics"
 )
 
 type RedisRepo struct {
@@ -23,33 +24,57 @@ func NewRedisRepo(cacheClient cache.RedisClient, ttl time.Duration) CacheReposit
 	}
 }
 
-func (rr *RedisRepo) GetMRR(key string) (domain.TotalMRR, error) {
-	bytes, err := rr.CacheClient.Get(context.Background(), key)
+func (rr *RedisRepo) GetMRR(ctx context.Context, key string) (domain.TotalMRR, error) {
+	bytes, err := rr.CacheClient.Get(ctx, key)
 	if err == redis.Nil {
+		metrics.CacheRequests.WithLabelValues("miss").Inc()
 		return domain.TotalMRR{}, nil
 	}
 	if err != redis.Nil && err != nil {
+		metrics.CacheRequests.WithLabelValues("error").Inc()
 		return domain.TotalMRR{}, fmt.Errorf("failed to get mrr from cache by key %s, error is: %s", key, err)
 	}
 
 	var mrr domain.TotalMRR
 
 	if err := json.Unmarshal(bytes, &mrr); err != nil {
+		metrics.CacheRequests.WithLabelValues("error").Inc()
 		return domain.TotalMRR{}, fmt.Errorf("failed to unmarshal by key %s, error is: %s", key, err)
 	}
+	metrics.CacheRequests.WithLabelValues("hit").Inc()
 
 	return mrr, nil
 }
 
-func (rr *RedisRepo) SetMRR(key string, mrr domain.TotalMRR) (domain.TotalMRR, error) {
+func (rr *RedisRepo) SetMRR(ctx context.Context, key string, mrr domain.TotalMRR) (domain.TotalMRR, error) {
 	bytes, err := json.Marshal(mrr)
 	if err != nil {
 		return domain.TotalMRR{}, fmt.Errorf("failed to marshal by key %s, error is: %s", key, err)
 	}
 
-	if err := rr.CacheClient.Set(context.Background(), key, bytes, rr.TTL); err != nil {
+	if err := rr.CacheClient.Set(ctx, key, bytes, rr.TTL); err != nil {
 		return domain.TotalMRR{}, fmt.Errorf("failed to set mrr to cache by key %s, error is: %s", key, err)
 	}
 
 	return mrr, nil
 }
+
+func (rr *RedisRepo) DeleteMRR(ctx context.Context, prefix string) (int64, error) {
+	pattern := fmt.Sprintf("%s.*", prefix)
+
+	keys, err := rr.CacheClient.Keys(ctx, pattern)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get keys from cache by pattern %s, error is: %s", pattern, err)
+	}
+
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	deleted, err := rr.CacheClient.Del(ctx, keys...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete keys from cache by pattern %s, error is: %s", pattern, err)
+	}
+
+	return deleted, nil
+}
@@ -1,6 +1,7 @@
 package cacherepo
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"testing"
@@ -9,6 +10,8 @@ import (
 	"github.com/go-redis/redismock/v8"
 	"github.com/hackfeed/remrratality/backend/internal/db/cache"
 	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -35,8 +38,9 @@ func TestGetMRR(t *testing.T) {
 		key string
 	}
 	type testWant struct {
-		mrr domain.TotalMRR
-		err error
+		mrr    domain.TotalMRR
+		err    error
+		result string
 	}
 
 	tests := []struct {
@@ -48,8 +52,9 @@ func TestGetMRR(t *testing.T) {
 				key: "notExistingKey",
 			},
 			want: testWant{
-				mrr: domain.TotalMRR{},
-				err: nil,
+				mrr:    domain.TotalMRR{},
+				err:    nil,
+				result: "miss",
 			},
 		},
 		{
@@ -57,8 +62,9 @@ func TestGetMRR(t *testing.T) {
 				key: "existingKeyWithRedisErr",
 			},
 			want: testWant{
-				mrr: domain.TotalMRR{},
-				err: errors.New("failed to get mrr from cache by key existingKeyWithRedisErr, error is: redis err"),
+				mrr:    domain.TotalMRR{},
+				err:    errors.New("failed to get mrr from cache by key existingKeyWithRedisErr, error is: redis err"),
+				result: "error",
 			},
 		},
 		{
@@ -66,8 +72,9 @@ func TestGetMRR(t *testing.T) {
 				key: "existingKeyWithMarshalErr",
 			},
 			want: testWant{
-				mrr: domain.TotalMRR{},
-				err: errors.New("failed to unmarshal by key existingKeyWithMarshalErr, error is: invalid character 'b' looking for beginning of value"),
+				mrr:    domain.TotalMRR{},
+				err:    errors.New("failed to unmarshal by key existingKeyWithMarshalErr, error is: invalid character 'b' looking for beginning of value"),
+				result: "error",
 			},
 		},
 		{
@@ -84,15 +91,18 @@ func TestGetMRR(t *testing.T) {
 					Churn:        []float32{0},
 					Total:        []float32{0},
 				},
-				err: nil,
+				err:    nil,
+				result: "hit",
 			},
 		},
 	}
 
 	for _, test := range tests {
+		requests := testutil.ToFloat64(metrics.CacheRequests.WithLabelValues(test.want.result))
+
 		if test.input.key == "notExistingKey" {
 			mock.ExpectGet(test.input.key).RedisNil()
-			mrr, err := repo.GetMRR(test.input.key)
+			mrr, err := repo.GetMRR(context.Background(), test.input.key)
 			assert.Equal(t, test.want.mrr, mrr)
 			assert.Equal(t, test.want.err, err)
 			if err = mock.ExpectationsWereMet(); err != nil {
@@ -103,7 +113,7 @@ func TestGetMRR(t *testing.T) {
 		if test.input.key == "existingKeyWithRedisErr" {
 			redisErr := errors.New("redis err")
 			mock.ExpectGet(test.input.key).SetErr(redisErr)
-			mrr, err := repo.GetMRR(test.input.key)
+			mrr, err := repo.GetMRR(context.Background(), test.input.key)
 			assert.Equal(t, test.want.mrr, mrr)
 			assert.Equal(t, test.want.err, err)
 			if err = mock.ExpectationsWereMet(); err != nil {
@@ -113,7 +123,7 @@ func TestGetMRR(t *testing.T) {
 		}
 		if test.input.key == "existingKeyWithMarshalErr" {
 			mock.ExpectGet(test.input.key).SetVal("brokenJSON")
-			mrr, err := repo.GetMRR(test.input.key)
+			mrr, err := repo.GetMRR(context.Background(), test.input.key)
 			assert.Equal(t, test.want.mrr, mrr)
 			assert.Equal(t, test.want.err, err)
 			if err = mock.ExpectationsWereMet(); err != nil {
@@ -123,7 +133,7 @@ func TestGetMRR(t *testing.T) {
 		}
 		if test.input.key == "existingKey" {
 			mock.ExpectGet(test.input.key).SetVal("{\"New\":[0],\"Old\":[0],\"Reactivation\":[0],\"Expansion\":[0],\"Contraction\":[0],\"Churn\":[0],\"Total\":[0]}")
-			mrr, err := repo.GetMRR(test.input.key)
+			mrr, err := repo.GetMRR(context.Background(), test.input.key)
 			assert.Equal(t, test.want.mrr, mrr)
 			assert.Equal(t, test.want.err, err)
 			if err = mock.ExpectationsWereMet(); err != nil {
@@ -131,6 +141,8 @@ func TestGetMRR(t *testing.T) {
 			}
 			mock.ClearExpect()
 		}
+
+		assert.Equal(t, requests+1, testutil.ToFloat64(metrics.CacheRequests.WithLabelValues(test.want.result)))
 	}
 }
 
@@ -198,7 +210,7 @@ func TestSetMRR(t *testing.T) {
 			redisErr := errors.New("redis err")
 			bytes, _ := json.Marshal(test.input.mrr)
 			mock.ExpectSet(test.input.key, bytes, testTTL).SetErr(redisErr)
-			mrr, err := repo.SetMRR(test.input.key, test.input.mrr)
+			mrr, err := repo.SetMRR(context.Background(), test.input.key, test.input.mrr)
 			assert.Equal(t, test.want.mrr, mrr)
 			assert.Equal(t, test.want.err, err)
 			if err = mock.ExpectationsWereMet(); err != nil {
@@ -209,7 +221,7 @@ func TestSetMRR(t *testing.T) {
 		if test.input.key == "key" {
 			bytes, _ := json.Marshal(test.input.mrr)
 			mock.ExpectSet(test.input.key, bytes, testTTL).SetVal("")
-			mrr, err := repo.SetMRR(test.input.key, test.input.mrr)
+			mrr, err := repo.SetMRR(context.Background(), test.input.key, test.input.mrr)
 			assert.Equal(t, test.want.mrr, mrr)
 			assert.Equal(t, test.want.err, err)
 			if err = mock.ExpectationsWereMet(); err != nil {
@@ -219,3 +231,86 @@ func TestSetMRR(t *testing.T) {
 		}
 	}
 }
+
+func TestDeleteMRR(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+
+	redisTestClient := cache.RedisClient{Client: db}
+	testTTL := 1 * time.Minute
+
+	repo := NewRedisRepo(redisTestClient, testTTL)
+
+	type testInput struct {
+		prefix string
+	}
+	type testWant struct {
+		deleted int64
+		err     error
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{
+				prefix: "userWithScanErr",
+			},
+			want: testWant{
+				deleted: 0,
+				err:     errors.New("failed to get keys from cache by pattern userWithScanErr.*, error is: redis err"),
+			},
+		},
+		{
+			input: testInput{
+				prefix: "userWithoutKeys",
+			},
+			want: testWant{
+				deleted: 0,
+				err:     nil,
+			},
+		},
+		{
+			input: testInput{
+				prefix: "userWithDelErr",
+			},
+			want: testWant{
+				deleted: 0,
+				err:     errors.New("failed to delete keys from cache by pattern userWithDelErr.*, error is: redis err"),
+			},
+		},
+		{
+			input: testInput{
+				prefix: "user",
+			},
+			want: testWant{
+				deleted: 2,
+				err:     nil,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		pattern := test.input.prefix + ".*"
+		keys := []string{test.input.prefix + ".first", test.input.prefix + ".second"}
+		switch test.input.prefix {
+		case "userWithScanErr":
+			mock.ExpectScan(0, pattern, 100).SetErr(errors.New("redis err"))
+		case "userWithoutKeys":
+			mock.ExpectScan(0, pattern, 100).SetVal([]string{}, 0)
+		case "userWithDelErr":
+			mock.ExpectScan(0, pattern, 100).SetVal(keys, 0)
+			mock.ExpectDel(keys...).SetErr(errors.New("redis err"))
+		case "user":
+			mock.ExpectScan(0, pattern, 100).SetVal(keys, 0)
+			mock.ExpectDel(keys...).SetVal(2)
+		}
+		deleted, err := repo.DeleteMRR(context.Background(), test.input.prefix)
+		assert.Equal(t, test.want.deleted, deleted)
+		assert.Equal(t, test.want.err, err)
+		if err = mock.ExpectationsWereMet(); err != nil {
+			assert.Error(t, err)
+		}
+		mock.ClearExpect()
+	}
+}
@@ -1,8 +1,13 @@
 package cacherepo
 
-import "github.com/hackfeed/remrratality/backend/internal/domain"
+import (
+	"context"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
 
 type CacheRepository interface {
-	GetMRR(string) (domain.TotalMRR, error)
-	SetMRR(string, domain.TotalMRR) (domain.TotalMRR, error)
+	GetMRR(context.Context, string) (domain.TotalMRR, error)
+	SetMRR(context.Context, string, domain.TotalMRR) (domain.TotalMRR, error)
+	DeleteMRR(context.Context, string) (int64, error)
 }
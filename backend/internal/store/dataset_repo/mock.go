@@ -0,0 +1,94 @@
+package datasetrepo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
+
+type DatasetRepositoryMock struct{}
+
+func (drm *DatasetRepositoryMock) AddDataset(_ context.Context, dataset domain.Dataset) (domain.Dataset, error) {
+	if dataset.OrgID == "errorAddDataset" {
+		return domain.Dataset{}, errors.New("error while adding dataset")
+	}
+	return dataset, nil
+}
+
+func (drm *DatasetRepositoryMock) GetDataset(_ context.Context, orgID, name string) (domain.Dataset, error) {
+	if name == "errorGetDataset" {
+		return domain.Dataset{}, errors.New("error while getting dataset")
+	}
+	if name == "notFoundDataset" {
+		return domain.Dataset{}, ErrNotFound
+	}
+	status := domain.DatasetReady
+	if name == "importingDataset" {
+		status = domain.DatasetImporting
+	}
+	return domain.Dataset{
+		Name:             name,
+		OrgID:            orgID,
+		DisplayName:      "invoices.csv",
+		Labels:           []string{"2021"},
+		OriginalFilename: "invoices.csv",
+		RowCount:         3,
+		CustomerCount:    2,
+		PeriodStartMin:   "2021-09-01",
+		PeriodStartMax:   "2021-10-01",
+		TotalAmount:      300,
+		Status:           status,
+	}, nil
+}
+
+func (drm *DatasetRepositoryMock) GetDatasets(_ context.Context, orgID string, _ domain.DatasetQuery) ([]domain.Dataset, error) {
+	if orgID == "errorGetDatasets" {
+		return nil, errors.New("error while getting datasets")
+	}
+	return []domain.Dataset{
+		{Name: "first.csv", OrgID: orgID, Status: domain.DatasetReady},
+		{Name: "second.csv", OrgID: orgID, Status: domain.DatasetImporting},
+	}, nil
+}
+
+func (drm *DatasetRepositoryMock) UpdateDataset(ctx context.Context, orgID, name string, update domain.DatasetUpdate) (domain.Dataset, error) {
+	if orgID == "errorUpdateDataset" {
+		return domain.Dataset{}, errors.New("error while updating dataset")
+	}
+	dataset, err := drm.GetDataset(ctx, orgID, name)
+	if err != nil {
+		return domain.Dataset{}, err
+	}
+	if update.DisplayName != nil {
+		dataset.DisplayName = *update.DisplayName
+	}
+	if update.Description != nil {
+		dataset.Description = *update.Description
+	}
+	if update.Labels != nil {
+		dataset.Labels = *update.Labels
+	}
+	return dataset, nil
+}
+
+func (drm *DatasetRepositoryMock) UpdateDatasetStatus(_ context.Context, orgID, _, _ string) error {
+	if orgID == "errorUpdateDatasetStatus" {
+		return errors.New("error while updating dataset status")
+	}
+	return nil
+}
+
+func (drm *DatasetRepositoryMock) DeleteDataset(_ context.Context, orgID, _ string) error {
+	if orgID == "errorDeleteDataset" {
+		return errors.New("error while deleting dataset")
+	}
+	return nil
+}
+
+func (drm *DatasetRepositoryMock) DeleteDatasets(_ context.Context, orgID string) (int64, error) {
+	if orgID == "errorDeleteDatasets" {
+		return 0, errors.New("error while deleting datasets")
+	}
+	return 2, nil
+}
@@ -0,0 +1,213 @@
+package datasetrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/db/user"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var sortFields = map[string]string{
+	"name":        "display_name",
+	"uploaded_at": "uploaded_at",
+}
+
+type mongoRepo struct {
+	UserClient user.MongoClient
+}
+
+func NewMongoRepo(userClient user.MongoClient) DatasetRepository {
+	return &mongoRepo{
+		UserClient: userClient,
+	}
+}
+
+func (mr *mongoRepo) AddDataset(ctx context.Context, dataset domain.Dataset) (domain.Dataset, error) {
+	updatedAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	if dataset.Labels == nil {
+		dataset.Labels = make([]string, 0)
+	}
+	if dataset.UploadedAt.IsZero() {
+		dataset.UploadedAt = updatedAt
+	}
+	dataset.UpdatedAt = updatedAt
+
+	if _, err := mr.UserClient.CreateDataset(ctx, convertDatasetToUser(dataset)); err != nil {
+		return domain.Dataset{}, fmt.Errorf("failed to insert dataset %s, error is: %s", dataset.Name, err)
+	}
+
+	return dataset, nil
+}
+
+func (mr *mongoRepo) GetDataset(ctx context.Context, orgID, name string) (domain.Dataset, error) {
+	dataset, err := mr.UserClient.ReadDataset(ctx, bson.M{"org_id": orgID, "name": name})
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.Dataset{}, ErrNotFound
+	}
+	if err != nil {
+		return domain.Dataset{}, fmt.Errorf("failed to get dataset %s of organization %s, error is: %s", name, orgID, err)
+	}
+
+	return convertDatasetToDomain(dataset), nil
+}
+
+func (mr *mongoRepo) GetDatasets(ctx context.Context, orgID string, query domain.DatasetQuery) ([]domain.Dataset, error) {
+	filter := bson.M{"org_id": orgID}
+	if query.Label != "" {
+		filter["labels"] = query.Label
+	}
+	if query.Name != "" {
+		filter["display_name"] = primitive.Regex{Pattern: regexp.QuoteMeta(query.Name), Options: "i"}
+	}
+	uploadedAt := bson.M{}
+	if !query.UploadedFrom.IsZero() {
+		uploadedAt["$gte"] = query.UploadedFrom
+	}
+	if !query.UploadedTo.IsZero() {
+		uploadedAt["$lt"] = query.UploadedTo
+	}
+	if len(uploadedAt) != 0 {
+		filter["uploaded_at"] = uploadedAt
+	}
+
+	sortField, ok := sortFields[query.SortBy]
+	if !ok {
+		sortField = sortFields["uploaded_at"]
+	}
+	order := 1
+	if query.Desc {
+		order = -1
+	}
+	opts := options.Find().SetSort(bson.D{{Key: sortField, Value: order}, {Key: "name", Value: order}})
+
+	datasets, err := mr.UserClient.ReadDatasets(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get datasets of organization %s, error is: %s", orgID, err)
+	}
+
+	mappedDatasets := make([]domain.Dataset, len(datasets))
+	for i, dataset := range datasets {
+		mappedDatasets[i] = convertDatasetToDomain(dataset)
+	}
+
+	return mappedDatasets, nil
+}
+
+func (mr *mongoRepo) UpdateDataset(ctx context.Context, orgID, name string, update domain.DatasetUpdate) (domain.Dataset, error) {
+	updatedAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	updatedDataset := primitive.D{}
+	if update.DisplayName != nil {
+		updatedDataset = append(updatedDataset, bson.E{Key: "display_name", Value: *update.DisplayName})
+	}
+	if update.Description != nil {
+		updatedDataset = append(updatedDataset, bson.E{Key: "description", Value: *update.Description})
+	}
+	if update.Labels != nil {
+		updatedDataset = append(updatedDataset, bson.E{Key: "labels", Value: *update.Labels})
+	}
+	updatedDataset = append(updatedDataset, bson.E{Key: "updated_at", Value: updatedAt})
+
+	matched, err := mr.UserClient.UpdateDataset(ctx, updatedDataset, bson.M{"org_id": orgID, "name": name})
+	if err != nil {
+		return domain.Dataset{}, fmt.Errorf("failed to update dataset %s, error is: %s", name, err)
+	}
+	if matched == 0 {
+		return domain.Dataset{}, ErrNotFound
+	}
+
+	return mr.GetDataset(ctx, orgID, name)
+}
+
+func (mr *mongoRepo) UpdateDatasetStatus(ctx context.Context, orgID, name, status string) error {
+	updatedAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	updatedDataset := primitive.D{
+		bson.E{Key: "status", Value: status},
+		bson.E{Key: "updated_at", Value: updatedAt},
+	}
+	matched, err := mr.UserClient.UpdateDataset(ctx, updatedDataset, bson.M{"org_id": orgID, "name": name})
+	if err != nil {
+		return fmt.Errorf("failed to update status of dataset %s, error is: %s", name, err)
+	}
+	if matched == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (mr *mongoRepo) DeleteDataset(ctx context.Context, orgID, name string) error {
+	deleted, err := mr.UserClient.DeleteDatasets(ctx, bson.M{"org_id": orgID, "name": name})
+	if err != nil {
+		return fmt.Errorf("failed to delete dataset %s, error is: %s", name, err)
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (mr *mongoRepo) DeleteDatasets(ctx context.Context, orgID string) (int64, error) {
+	deleted, err := mr.UserClient.DeleteDatasets(ctx, bson.M{"org_id": orgID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete datasets of organization %s, error is: %s", orgID, err)
+	}
+
+	return deleted, nil
+}
+
+func convertDatasetToDomain(dataset user.Dataset) domain.Dataset {
+	labels := dataset.Labels
+	if labels == nil {
+		labels = make([]string, 0)
+	}
+
+	return domain.Dataset{
+		Name:             dataset.Name,
+		OrgID:            dataset.OrgID,
+		DisplayName:      dataset.DisplayName,
+		Description:      dataset.Description,
+		Labels:           labels,
+		OriginalFilename: dataset.OriginalFilename,
+		RowCount:         dataset.RowCount,
+		CustomerCount:    dataset.CustomerCount,
+		PeriodStartMin:   dataset.PeriodStartMin,
+		PeriodStartMax:   dataset.PeriodStartMax,
+		TotalAmount:      dataset.TotalAmount,
+		ContentHash:      dataset.ContentHash,
+		Status:           dataset.Status,
+		UploadedAt:       dataset.UploadedAt,
+		UpdatedAt:        dataset.UpdatedAt,
+	}
+}
+
+func convertDatasetToUser(dataset domain.Dataset) user.Dataset {
+	return user.Dataset{
+		Name:             dataset.Name,
+		OrgID:            dataset.OrgID,
+		DisplayName:      dataset.DisplayName,
+		Description:      dataset.Description,
+		Labels:           dataset.Labels,
+		OriginalFilename: dataset.OriginalFilename,
+		RowCount:         dataset.RowCount,
+		CustomerCount:    dataset.CustomerCount,
+		PeriodStartMin:   dataset.PeriodStartMin,
+		PeriodStartMax:   dataset.PeriodStartMax,
+		TotalAmount:      dataset.TotalAmount,
+		ContentHash:      dataset.ContentHash,
+		Status:           dataset.Status,
+		UploadedAt:       dataset.UploadedAt,
+		UpdatedAt:        dataset.UpdatedAt,
+	}
+}
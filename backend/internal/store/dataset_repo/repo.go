@@ -0,0 +1,20 @@
+package datasetrepo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
+
+var ErrNotFound = errors.New("dataset not found")
+
+type DatasetRepository interface {
+	AddDataset(context.Context, domain.Dataset) (domain.Dataset, error)
+	GetDataset(context.Context, string, string) (domain.Dataset, error)
+	GetDatasets(context.Context, string, domain.DatasetQuery) ([]domain.Dataset, error)
+	UpdateDataset(context.Context, string, string, domain.DatasetUpdate) (domain.Dataset, error)
+	UpdateDatasetStatus(context.Context, string, string, string) error
+	DeleteDataset(context.Context, string, string) error
+	DeleteDatasets(context.Context, string) (int64, error)
+}
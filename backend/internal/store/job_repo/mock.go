@@ -0,0 +1,107 @@
+package jobrepo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
+
+type JobRepositoryMock struct{}
+
+func (jrm *JobRepositoryMock) AddJob(_ context.Context, job domain.Job) (domain.Job, error) {
+	if job.OrgID == "errorAddJob" {
+		return domain.Job{}, errors.New("error while adding job")
+	}
+	if job.Errors == nil {
+		job.Errors = make([]string, 0)
+	}
+	if job.Warnings == nil {
+		job.Warnings = make([]string, 0)
+	}
+	return job, nil
+}
+
+func (jrm *JobRepositoryMock) GetJob(_ context.Context, jobID string) (domain.Job, error) {
+	if jobID == "errorGetJob" {
+		return domain.Job{}, errors.New("error while getting job")
+	}
+	if jobID == "notFoundJob" {
+		return domain.Job{}, ErrNotFound
+	}
+	orgID := "org"
+	if jobID == "otherOrgJob" {
+		orgID = "otherOrg"
+	}
+	state := domain.JobRunning
+	if jobID == "finishedJob" {
+		state = domain.JobSucceeded
+	}
+	return domain.Job{
+		ID:               jobID,
+		Type:             domain.JobUpload,
+		OrgID:            orgID,
+		UserID:           "user",
+		State:            state,
+		OriginalFilename: "invoices.csv",
+		DatasetID:        "file.csv",
+		RowsTotal:        3,
+		RowsProcessed:    1,
+		Stage:            domain.StageInserting,
+		Errors:           make([]string, 0),
+		Warnings:         make([]string, 0),
+		Events:           2,
+	}, nil
+}
+
+func (jrm *JobRepositoryMock) GetJobs(_ context.Context, node, _ string) ([]domain.Job, error) {
+	if node == "errorGetJobs" {
+		return nil, errors.New("error while getting jobs")
+	}
+	return make([]domain.Job, 0), nil
+}
+
+func (jrm *JobRepositoryMock) ClaimJob(_ context.Context, node string) (domain.Job, error) {
+	if node == "errorClaimJob" {
+		return domain.Job{}, errors.New("error while claiming job")
+	}
+	return domain.Job{}, ErrNotFound
+}
+
+func (jrm *JobRepositoryMock) UpdateJob(_ context.Context, job domain.Job) error {
+	if job.ID == "errorUpdateJob" {
+		return errors.New("error while updating job")
+	}
+	return nil
+}
+
+func (jrm *JobRepositoryMock) AddJobEvent(_ context.Context, event domain.JobEvent) error {
+	if event.JobID == "errorAddJobEvent" {
+		return errors.New("error while adding job event")
+	}
+	return nil
+}
+
+func (jrm *JobRepositoryMock) GetJobEvents(_ context.Context, jobID string, after int) ([]domain.JobEvent, error) {
+	if jobID == "errorGetJobEvents" {
+		return nil, errors.New("error while getting job events")
+	}
+	if jobID == "finishedJob" {
+		return make([]domain.JobEvent, 0), nil
+	}
+	events := []domain.JobEvent{
+		{JobID: jobID, Seq: 1, Type: domain.JobEventProgress, Stage: domain.StageInserting, RowsTotal: 3, RowsProcessed: 1},
+		{JobID: jobID, Seq: 2, Type: domain.JobSucceeded, Stage: domain.StageInserting, RowsTotal: 3, RowsProcessed: 3},
+	}
+	if after >= len(events) {
+		return make([]domain.JobEvent, 0), nil
+	}
+	return events[after:], nil
+}
+
+func (jrm *JobRepositoryMock) DeleteJobs(_ context.Context, orgID string) (int64, error) {
+	if orgID == "errorDeleteJobs" {
+		return 0, errors.New("error while deleting jobs")
+	}
+	return 3, nil
+}
@@ -0,0 +1,235 @@
+package jobrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/db/user"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoRepo struct {
+	UserClient user.MongoClient
+}
+
+func NewMongoRepo(userClient user.MongoClient) JobRepository {
+	return &mongoRepo{
+		UserClient: userClient,
+	}
+}
+
+func (mr *mongoRepo) AddJob(ctx context.Context, job domain.Job) (domain.Job, error) {
+	createdAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	job.Errors = nonNil(job.Errors)
+	job.Warnings = nonNil(job.Warnings)
+	job.CreatedAt = createdAt
+	job.UpdatedAt = createdAt
+
+	if _, err := mr.UserClient.CreateJob(ctx, convertJobToUser(job)); err != nil {
+		return domain.Job{}, fmt.Errorf("failed to insert job %s, error is: %s", job.ID, err)
+	}
+
+	return job, nil
+}
+
+func (mr *mongoRepo) GetJob(ctx context.Context, jobID string) (domain.Job, error) {
+	job, err := mr.UserClient.ReadJob(ctx, bson.M{"job_id": jobID})
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.Job{}, ErrNotFound
+	}
+	if err != nil {
+		return domain.Job{}, fmt.Errorf("failed to get job %s, error is: %s", jobID, err)
+	}
+
+	return convertJobToDomain(job), nil
+}
+
+// GetJobs returns jobs of node in the given state.
+func (mr *mongoRepo) GetJobs(ctx context.Context, node, state string) ([]domain.Job, error) {
+	jobs, err := mr.UserClient.ReadJobs(ctx, bson.M{"node": node, "state": state})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s jobs of node %s, error is: %s", state, node, err)
+	}
+
+	mappedJobs := make([]domain.Job, len(jobs))
+	for i, job := range jobs {
+		mappedJobs[i] = convertJobToDomain(job)
+	}
+
+	return mappedJobs, nil
+}
+
+// ClaimJob marks the oldest queued job of node as running and returns it, ErrNotFound is
+// returned when there are no queued jobs.
+func (mr *mongoRepo) ClaimJob(ctx context.Context, node string) (domain.Job, error) {
+	startedAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	job, err := mr.UserClient.ClaimJob(
+		ctx,
+		bson.M{"node": node, "state": domain.JobQueued},
+		primitive.D{
+			bson.E{Key: "state", Value: domain.JobRunning},
+			bson.E{Key: "started_at", Value: startedAt},
+			bson.E{Key: "updated_at", Value: startedAt},
+		},
+	)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.Job{}, ErrNotFound
+	}
+	if err != nil {
+		return domain.Job{}, fmt.Errorf("failed to claim job of node %s, error is: %s", node, err)
+	}
+
+	return convertJobToDomain(job), nil
+}
+
+// UpdateJob saves state, progress and result of the job.
+func (mr *mongoRepo) UpdateJob(ctx context.Context, job domain.Job) error {
+	updatedAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	updatedJob := primitive.D{
+		bson.E{Key: "state", Value: job.State},
+		bson.E{Key: "dataset_id", Value: job.DatasetID},
+		bson.E{Key: "rows_total", Value: job.RowsTotal},
+		bson.E{Key: "rows_processed", Value: job.RowsProcessed},
+		bson.E{Key: "stage", Value: job.Stage},
+		bson.E{Key: "errors", Value: nonNil(job.Errors)},
+		bson.E{Key: "warnings", Value: nonNil(job.Warnings)},
+		bson.E{Key: "events", Value: job.Events},
+		bson.E{Key: "started_at", Value: job.StartedAt},
+		bson.E{Key: "finished_at", Value: job.FinishedAt},
+		bson.E{Key: "updated_at", Value: updatedAt},
+	}
+	matched, err := mr.UserClient.UpdateJob(ctx, updatedJob, bson.M{"job_id": job.ID})
+	if err != nil {
+		return fmt.Errorf("failed to update job %s, error is: %s", job.ID, err)
+	}
+	if matched == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// AddJobEvent stores event of the job, event with the same sequence number is replaced.
+func (mr *mongoRepo) AddJobEvent(ctx context.Context, event domain.JobEvent) error {
+	if err := mr.UserClient.CreateJobEvent(ctx, convertJobEventToUser(event)); err != nil {
+		return fmt.Errorf("failed to insert event %d of job %s, error is: %s", event.Seq, event.JobID, err)
+	}
+
+	return nil
+}
+
+// GetJobEvents returns events of the job following event with sequence number after.
+func (mr *mongoRepo) GetJobEvents(ctx context.Context, jobID string, after int) ([]domain.JobEvent, error) {
+	events, err := mr.UserClient.ReadJobEvents(ctx, bson.M{"job_id": jobID, "seq": bson.M{"$gt": after}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events of job %s, error is: %s", jobID, err)
+	}
+
+	mappedEvents := make([]domain.JobEvent, len(events))
+	for i, event := range events {
+		mappedEvents[i] = convertJobEventToDomain(event)
+	}
+
+	return mappedEvents, nil
+}
+
+// DeleteJobs deletes jobs of organization with all their events.
+func (mr *mongoRepo) DeleteJobs(ctx context.Context, orgID string) (int64, error) {
+	deleted, err := mr.UserClient.DeleteJobs(ctx, bson.M{"org_id": orgID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete jobs of organization %s, error is: %s", orgID, err)
+	}
+
+	return deleted, nil
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return make([]string, 0)
+	}
+	return values
+}
+
+func convertJobToDomain(job user.Job) domain.Job {
+	return domain.Job{
+		ID:               job.JobID,
+		Type:             job.Type,
+		OrgID:            job.OrgID,
+		UserID:           job.UserID,
+		State:            job.State,
+		Node:             job.Node,
+		File:             job.File,
+		OriginalFilename: job.OriginalFilename,
+		DatasetID:        job.DatasetID,
+		RowsTotal:        job.RowsTotal,
+		RowsProcessed:    job.RowsProcessed,
+		Stage:            job.Stage,
+		Errors:           nonNil(job.Errors),
+		Warnings:         nonNil(job.Warnings),
+		Events:           job.Events,
+		CreatedAt:        job.CreatedAt,
+		UpdatedAt:        job.UpdatedAt,
+		StartedAt:        job.StartedAt,
+		FinishedAt:       job.FinishedAt,
+	}
+}
+
+func convertJobToUser(job domain.Job) user.Job {
+	return user.Job{
+		JobID:            job.ID,
+		Type:             job.Type,
+		OrgID:            job.OrgID,
+		UserID:           job.UserID,
+		State:            job.State,
+		Node:             job.Node,
+		File:             job.File,
+		OriginalFilename: job.OriginalFilename,
+		DatasetID:        job.DatasetID,
+		RowsTotal:        job.RowsTotal,
+		RowsProcessed:    job.RowsProcessed,
+		Stage:            job.Stage,
+		Errors:           job.Errors,
+		Warnings:         job.Warnings,
+		Events:           job.Events,
+		CreatedAt:        job.CreatedAt,
+		UpdatedAt:        job.UpdatedAt,
+		StartedAt:        job.StartedAt,
+		FinishedAt:       job.FinishedAt,
+	}
+}
+
+func convertJobEventToDomain(event user.JobEvent) domain.JobEvent {
+	return domain.JobEvent{
+		JobID:         event.JobID,
+		Seq:           event.Seq,
+		Type:          event.Type,
+		Stage:         event.Stage,
+		RowsTotal:     event.RowsTotal,
+		RowsProcessed: event.RowsProcessed,
+		Message:       event.Message,
+		Errors:        event.Errors,
+		CreatedAt:     event.CreatedAt,
+	}
+}
+
+func convertJobEventToUser(event domain.JobEvent) user.JobEvent {
+	return user.JobEvent{
+		JobID:         event.JobID,
+		Seq:           event.Seq,
+		Type:          event.Type,
+		Stage:         event.Stage,
+		RowsTotal:     event.RowsTotal,
+		RowsProcessed: event.RowsProcessed,
+		Message:       event.Message,
+		Errors:        event.Errors,
+		CreatedAt:     event.CreatedAt,
+	}
+}
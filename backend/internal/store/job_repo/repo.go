@@ -0,0 +1,21 @@
+package jobrepo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
+
+var ErrNotFound = errors.New("job not found")
+
+type JobRepository interface {
+	AddJob(context.Context, domain.Job) (domain.Job, error)
+	GetJob(context.Context, string) (domain.Job, error)
+	GetJobs(context.Context, string, string) ([]domain.Job, error)
+	ClaimJob(context.Context, string) (domain.Job, error)
+	UpdateJob(context.Context, domain.Job) error
+	AddJobEvent(context.Context, domain.JobEvent) error
+	GetJobEvents(context.Context, string, int) ([]domain.JobEvent, error)
+	DeleteJobs(context.Context, string) (int64, error)
+}
@@ -0,0 +1,67 @@
+package limitrepo
+
+import (
+	"context"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
+)
+
+type fallbackRepo struct {
+	Primary  LimitRepository
+	Fallback LimitRepository
+}
+
+// NewFallbackRepo creates repository, which uses fallback repository whenever primary one fails.
+func NewFallbackRepo(primary, fallback LimitRepository) LimitRepository {
+	return &fallbackRepo{
+		Primary:  primary,
+		Fallback: fallback,
+	}
+}
+
+func (fr *fallbackRepo) Take(ctx context.Context, key string, limit domain.Limit) (time.Duration, error) {
+	wait, err := fr.Primary.Take(ctx, key, limit)
+	if err != nil {
+		logging.FromContext(ctx).Warnf("falling back to in-memory rate limiter, error is: %s", err)
+		return fr.Fallback.Take(ctx, key, limit)
+	}
+	return wait, nil
+}
+
+func (fr *fallbackRepo) AddFailure(ctx context.Context, key string, window time.Duration) (int64, error) {
+	failures, err := fr.Primary.AddFailure(ctx, key, window)
+	if err != nil {
+		logging.FromContext(ctx).Warnf("falling back to in-memory rate limiter, error is: %s", err)
+		return fr.Fallback.AddFailure(ctx, key, window)
+	}
+	return failures, nil
+}
+
+func (fr *fallbackRepo) ResetFailures(ctx context.Context, key string) error {
+	if err := fr.Primary.ResetFailures(ctx, key); err != nil {
+		logging.FromContext(ctx).Warnf("falling back to in-memory rate limiter, error is: %s", err)
+	}
+	return fr.Fallback.ResetFailures(ctx, key)
+}
+
+func (fr *fallbackRepo) Lock(ctx context.Context, key string, ttl time.Duration) error {
+	if err := fr.Primary.Lock(ctx, key, ttl); err != nil {
+		logging.FromContext(ctx).Warnf("falling back to in-memory rate limiter, error is: %s", err)
+		return fr.Fallback.Lock(ctx, key, ttl)
+	}
+	return nil
+}
+
+func (fr *fallbackRepo) GetLock(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := fr.Primary.GetLock(ctx, key)
+	if err != nil {
+		logging.FromContext(ctx).Warnf("falling back to in-memory rate limiter, error is: %s", err)
+		return fr.Fallback.GetLock(ctx, key)
+	}
+	if ttl > 0 {
+		return ttl, nil
+	}
+	return fr.Fallback.GetLock(ctx, key)
+}
@@ -0,0 +1,152 @@
+package limitrepo
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
+
+const (
+	// sweepInterval is how often expired entries are dropped, so maps don't grow with every seen
+	// client and scanning them doesn't cost every request.
+	sweepInterval = time.Minute
+	// bucketIdleTTL is how long bucket is kept after it was last taken from, it's full by then.
+	bucketIdleTTL = time.Hour
+)
+
+type bucket struct {
+	tokens float64
+	ts     time.Time
+}
+
+type counter struct {
+	value     int64
+	expiresAt time.Time
+}
+
+type memoryRepo struct {
+	mu       sync.Mutex
+	now      func() time.Time
+	buckets  map[string]*bucket
+	failures map[string]*counter
+	locks    map[string]time.Time
+	swept    time.Time
+}
+
+// NewMemoryRepo creates process local repository, which is used when redis is unavailable.
+func NewMemoryRepo() LimitRepository {
+	return &memoryRepo{
+		now:      time.Now,
+		buckets:  make(map[string]*bucket),
+		failures: make(map[string]*counter),
+		locks:    make(map[string]time.Time),
+	}
+}
+
+func (mr *memoryRepo) Take(_ context.Context, key string, limit domain.Limit) (time.Duration, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	now := mr.now()
+	mr.sweep(now)
+
+	b, ok := mr.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), ts: now}
+		mr.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.ts).Seconds()*limit.Rate)
+	b.ts = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, nil
+	}
+
+	wait := math.Ceil((1 - b.tokens) / limit.Rate * 1000)
+	return time.Duration(wait) * time.Millisecond, nil
+}
+
+func (mr *memoryRepo) AddFailure(_ context.Context, key string, window time.Duration) (int64, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	now := mr.now()
+	mr.sweep(now)
+
+	c, ok := mr.failures[key]
+	if !ok || !now.Before(c.expiresAt) {
+		c = &counter{expiresAt: now.Add(window)}
+		mr.failures[key] = c
+	}
+	c.value++
+
+	return c.value, nil
+}
+
+func (mr *memoryRepo) ResetFailures(_ context.Context, key string) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	delete(mr.failures, key)
+
+	return nil
+}
+
+func (mr *memoryRepo) Lock(_ context.Context, key string, ttl time.Duration) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	now := mr.now()
+	mr.sweep(now)
+
+	mr.locks[key] = now.Add(ttl)
+
+	return nil
+}
+
+func (mr *memoryRepo) GetLock(_ context.Context, key string) (time.Duration, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	expiresAt, ok := mr.locks[key]
+	if !ok {
+		return 0, nil
+	}
+
+	ttl := expiresAt.Sub(mr.now())
+	if ttl <= 0 {
+		delete(mr.locks, key)
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+// sweep drops idle buckets, expired failure counters and expired locks once per sweepInterval.
+func (mr *memoryRepo) sweep(now time.Time) {
+	if now.Sub(mr.swept) < sweepInterval {
+		return
+	}
+	mr.swept = now
+
+	for key, b := range mr.buckets {
+		if now.Sub(b.ts) > bucketIdleTTL {
+			delete(mr.buckets, key)
+		}
+	}
+	for key, c := range mr.failures {
+		if !now.Before(c.expiresAt) {
+			delete(mr.failures, key)
+		}
+	}
+	for key, expiresAt := range mr.locks {
+		if !now.Before(expiresAt) {
+			delete(mr.locks, key)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package limitrepo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryTake(t *testing.T) {
+	now := time.Date(2021, time.October, 1, 0, 0, 0, 0, time.UTC)
+	repo := NewMemoryRepo().(*memoryRepo)
+	repo.now = func() time.Time { return now }
+
+	limit := domain.Limit{Rate: 0.5, Burst: 2}
+
+	for i := 0; i < limit.Burst; i++ {
+		wait, err := repo.Take(context.Background(), "key", limit)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Duration(0), wait)
+	}
+
+	wait, err := repo.Take(context.Background(), "key", limit)
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Second, wait)
+
+	wait, err = repo.Take(context.Background(), "otherKey", limit)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), wait)
+
+	now = now.Add(2 * time.Second)
+
+	wait, err = repo.Take(context.Background(), "key", limit)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), wait)
+}
+
+func TestMemoryFailuresAndLock(t *testing.T) {
+	now := time.Date(2021, time.October, 1, 0, 0, 0, 0, time.UTC)
+	repo := NewMemoryRepo().(*memoryRepo)
+	repo.now = func() time.Time { return now }
+
+	failures, _ := repo.AddFailure(context.Background(), "key", time.Minute)
+	assert.Equal(t, int64(1), failures)
+	failures, _ = repo.AddFailure(context.Background(), "key", time.Minute)
+	assert.Equal(t, int64(2), failures)
+
+	now = now.Add(time.Minute)
+
+	failures, _ = repo.AddFailure(context.Background(), "key", time.Minute)
+	assert.Equal(t, int64(1), failures)
+
+	assert.NoError(t, repo.ResetFailures(context.Background(), "key"))
+	failures, _ = repo.AddFailure(context.Background(), "key", time.Minute)
+	assert.Equal(t, int64(1), failures)
+
+	ttl, _ := repo.GetLock(context.Background(), "key")
+	assert.Equal(t, time.Duration(0), ttl)
+
+	assert.NoError(t, repo.Lock(context.Background(), "key", time.Minute))
+	now = now.Add(20 * time.Second)
+	ttl, _ = repo.GetLock(context.Background(), "key")
+	assert.Equal(t, 40*time.Second, ttl)
+
+	now = now.Add(40 * time.Second)
+	ttl, _ = repo.GetLock(context.Background(), "key")
+	assert.Equal(t, time.Duration(0), ttl)
+}
+
+func TestMemorySweep(t *testing.T) {
+	now := time.Date(2021, time.October, 1, 0, 0, 0, 0, time.UTC)
+	repo := NewMemoryRepo().(*memoryRepo)
+	repo.now = func() time.Time { return now }
+
+	limit := domain.Limit{Rate: 1, Burst: 1}
+
+	_, _ = repo.Take(context.Background(), "bucket", limit)
+	_, _ = repo.AddFailure(context.Background(), "failure", time.Minute)
+	assert.NoError(t, repo.Lock(context.Background(), "lock", time.Minute))
+
+	// Entries aren't looked through on every request.
+	now = now.Add(sweepInterval / 2)
+	_, _ = repo.AddFailure(context.Background(), "otherFailure", time.Hour)
+	assert.Len(t, repo.failures, 2)
+
+	now = now.Add(sweepInterval)
+	_, _ = repo.Take(context.Background(), "otherBucket", limit)
+	assert.Len(t, repo.buckets, 2)
+	assert.Equal(t, map[string]*counter{"otherFailure": repo.failures["otherFailure"]}, repo.failures)
+	assert.Len(t, repo.locks, 0)
+
+	now = now.Add(bucketIdleTTL + time.Second)
+	assert.NoError(t, repo.Lock(context.Background(), "lock", time.Minute))
+	assert.Len(t, repo.buckets, 0)
+	assert.Len(t, repo.failures, 0)
+	assert.Len(t, repo.locks, 1)
+}
@@ -0,0 +1,44 @@
+package limitrepo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
+
+type LimitRepositoryMock struct{}
+
+func (lrm *LimitRepositoryMock) Take(_ context.Context, key string, _ domain.Limit) (time.Duration, error) {
+	if strings.HasSuffix(key, ":limitedEmail") {
+		return 1500 * time.Millisecond, nil
+	}
+	if strings.HasSuffix(key, ":errorTake") {
+		return 0, errors.New("error while taking token")
+	}
+	return 0, nil
+}
+
+func (lrm *LimitRepositoryMock) AddFailure(_ context.Context, key string, _ time.Duration) (int64, error) {
+	if strings.HasSuffix(key, ":userWithWrongPass") {
+		return 5, nil
+	}
+	return 1, nil
+}
+
+func (lrm *LimitRepositoryMock) ResetFailures(_ context.Context, _ string) error {
+	return nil
+}
+
+func (lrm *LimitRepositoryMock) Lock(_ context.Context, _ string, _ time.Duration) error {
+	return nil
+}
+
+func (lrm *LimitRepositoryMock) GetLock(_ context.Context, key string) (time.Duration, error) {
+	if strings.HasSuffix(key, ":lockedEmail") {
+		return 10 * time.Minute, nil
+	}
+	return 0, nil
+}
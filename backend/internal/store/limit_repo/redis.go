@@ -0,0 +1,122 @@
+package limitrepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/db/cache"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
+
+// takeScript refills the bucket according to the time passed since the last take and returns
+// the number of milliseconds to wait before the next token is available, 0 if it is taken.
+const takeScript = `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) / 1000 * rate)
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+else
+	wait = math.ceil((1 - tokens) / rate * 1000)
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst / rate * 1000))
+return wait
+`
+
+type redisRepo struct {
+	CacheClient cache.RedisClient
+}
+
+func NewRedisRepo(cacheClient cache.RedisClient) LimitRepository {
+	return &redisRepo{
+		CacheClient: cacheClient,
+	}
+}
+
+func (rr *redisRepo) Take(ctx context.Context, key string, limit domain.Limit) (time.Duration, error) {
+	res, err := rr.CacheClient.Eval(
+		ctx,
+		takeScript,
+		[]string{bucketKey(key)},
+		limit.Rate,
+		limit.Burst,
+		time.Now().UnixNano()/int64(time.Millisecond),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to take token by key %s, error is: %s", key, err)
+	}
+
+	wait, ok := res.(int64)
+	if !ok {
+		return 0, fmt.Errorf("failed to take token by key %s, unexpected reply %v", key, res)
+	}
+
+	return time.Duration(wait) * time.Millisecond, nil
+}
+
+func (rr *redisRepo) AddFailure(ctx context.Context, key string, window time.Duration) (int64, error) {
+	failures, err := rr.CacheClient.Incr(ctx, failuresKey(key))
+	if err != nil {
+		return 0, fmt.Errorf("failed to add failure by key %s, error is: %s", key, err)
+	}
+
+	if failures == 1 {
+		if err = rr.CacheClient.Expire(ctx, failuresKey(key), window); err != nil {
+			return 0, fmt.Errorf("failed to set failures window by key %s, error is: %s", key, err)
+		}
+	}
+
+	return failures, nil
+}
+
+func (rr *redisRepo) ResetFailures(ctx context.Context, key string) error {
+	if _, err := rr.CacheClient.Del(ctx, failuresKey(key)); err != nil {
+		return fmt.Errorf("failed to reset failures by key %s, error is: %s", key, err)
+	}
+
+	return nil
+}
+
+func (rr *redisRepo) Lock(ctx context.Context, key string, ttl time.Duration) error {
+	if err := rr.CacheClient.Set(ctx, lockKey(key), 1, ttl); err != nil {
+		return fmt.Errorf("failed to lock by key %s, error is: %s", key, err)
+	}
+
+	return nil
+}
+
+func (rr *redisRepo) GetLock(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := rr.CacheClient.TTL(ctx, lockKey(key))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get lock by key %s, error is: %s", key, err)
+	}
+
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+func bucketKey(key string) string {
+	return fmt.Sprintf("ratelimit:bucket:%s", key)
+}
+
+func failuresKey(key string) string {
+	return fmt.Sprintf("ratelimit:failures:%s", key)
+}
+
+func lockKey(key string) string {
+	return fmt.Sprintf("ratelimit:lock:%s", key)
+}
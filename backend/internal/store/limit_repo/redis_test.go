@@ -0,0 +1,153 @@
+package limitrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v8"
+	"github.com/hackfeed/remrratality/backend/internal/db/cache"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// ignoreNow matches commands without comparing the last argument, which is the current time.
+func ignoreNow(expected, actual []interface{}) error {
+	if len(expected) != len(actual) || !reflect.DeepEqual(expected[:len(expected)-1], actual[:len(actual)-1]) {
+		return fmt.Errorf("expected %v, actual %v", expected, actual)
+	}
+	return nil
+}
+
+func TestTake(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+
+	repo := NewRedisRepo(cache.RedisClient{Client: db})
+	limit := domain.Limit{Rate: 1, Burst: 5}
+
+	type testInput struct {
+		key string
+	}
+	type testWant struct {
+		wait time.Duration
+		err  error
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{
+				key: "keyWithRedisErr",
+			},
+			want: testWant{
+				wait: 0,
+				err:  errors.New("failed to take token by key keyWithRedisErr, error is: redis err"),
+			},
+		},
+		{
+			input: testInput{
+				key: "keyWithUnexpectedReply",
+			},
+			want: testWant{
+				wait: 0,
+				err:  errors.New("failed to take token by key keyWithUnexpectedReply, unexpected reply reply"),
+			},
+		},
+		{
+			input: testInput{
+				key: "limitedKey",
+			},
+			want: testWant{
+				wait: 1500 * time.Millisecond,
+				err:  nil,
+			},
+		},
+		{
+			input: testInput{
+				key: "key",
+			},
+			want: testWant{
+				wait: 0,
+				err:  nil,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		expect := mock.CustomMatch(ignoreNow).ExpectEval(takeScript, []string{bucketKey(test.input.key)}, limit.Rate, limit.Burst, 0)
+		switch test.input.key {
+		case "keyWithRedisErr":
+			expect.SetErr(errors.New("redis err"))
+		case "keyWithUnexpectedReply":
+			expect.SetVal("reply")
+		case "limitedKey":
+			expect.SetVal(int64(1500))
+		case "key":
+			expect.SetVal(int64(0))
+		}
+		wait, err := repo.Take(context.Background(), test.input.key, limit)
+		assert.Equal(t, test.want.wait, wait)
+		assert.Equal(t, test.want.err, err)
+		if err = mock.ExpectationsWereMet(); err != nil {
+			assert.Error(t, err)
+		}
+		mock.ClearExpect()
+	}
+}
+
+func TestAddFailure(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+
+	repo := NewRedisRepo(cache.RedisClient{Client: db})
+	window := 15 * time.Minute
+
+	mock.ExpectIncr(failuresKey("keyWithRedisErr")).SetErr(errors.New("redis err"))
+	_, err := repo.AddFailure(context.Background(), "keyWithRedisErr", window)
+	assert.Equal(t, errors.New("failed to add failure by key keyWithRedisErr, error is: redis err"), err)
+
+	mock.ExpectIncr(failuresKey("key")).SetVal(1)
+	mock.ExpectExpire(failuresKey("key"), window).SetVal(true)
+	failures, err := repo.AddFailure(context.Background(), "key", window)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), failures)
+
+	mock.ExpectIncr(failuresKey("key")).SetVal(2)
+	failures, err = repo.AddFailure(context.Background(), "key", window)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), failures)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		assert.Error(t, err)
+	}
+}
+
+func TestLock(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+
+	repo := NewRedisRepo(cache.RedisClient{Client: db})
+
+	mock.ExpectSet(lockKey("key"), 1, time.Minute).SetVal("OK")
+	assert.NoError(t, repo.Lock(context.Background(), "key", time.Minute))
+
+	mock.ExpectPTTL(lockKey("key")).SetVal(30 * time.Second)
+	ttl, err := repo.GetLock(context.Background(), "key")
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, ttl)
+
+	mock.ExpectPTTL(lockKey("notLockedKey")).SetVal(-2)
+	ttl, err = repo.GetLock(context.Background(), "notLockedKey")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), ttl)
+
+	mock.ExpectDel(failuresKey("key")).SetVal(1)
+	assert.NoError(t, repo.ResetFailures(context.Background(), "key"))
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,16 @@
+package limitrepo
+
+import (
+	"context"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
+
+type LimitRepository interface {
+	Take(context.Context, string, domain.Limit) (time.Duration, error)
+	AddFailure(context.Context, string, time.Duration) (int64, error)
+	ResetFailures(context.Context, string) error
+	Lock(context.Context, string, time.Duration) error
+	GetLock(context.Context, string) (time.Duration, error)
+}
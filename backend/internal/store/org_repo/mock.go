@@ -0,0 +1,86 @@
+package orgrepo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
+
+type OrgRepositoryMock struct{}
+
+func (orm *OrgRepositoryMock) AddOrganization(_ context.Context, org domain.Organization) (domain.Organization, error) {
+	if org.Name == "errorAddOrganization" {
+		return domain.Organization{}, errors.New("error while adding organization")
+	}
+	if org.OrgID == "" {
+		org.OrgID = "org"
+	}
+	return org, nil
+}
+
+func (orm *OrgRepositoryMock) GetOrganization(_ context.Context, orgID string) (domain.Organization, error) {
+	if orgID == "errorGetOrganization" {
+		return domain.Organization{}, errors.New("error while getting organization")
+	}
+	if orgID == "notFoundOrganization" {
+		return domain.Organization{}, ErrNotFound
+	}
+	return domain.Organization{
+		OrgID: orgID,
+		Name:  orgID,
+		Members: []domain.Member{
+			{UserID: orgID, Email: "owner@test.com", Role: domain.RoleOwner},
+			{UserID: "editor", Email: "editor@test.com", Role: domain.RoleEditor},
+			{UserID: "viewer", Email: "viewer@test.com", Role: domain.RoleViewer},
+		},
+		Files: make([]domain.File, 10),
+	}, nil
+}
+
+func (orm *OrgRepositoryMock) GetOrganizations(_ context.Context, userID string) ([]domain.Organization, error) {
+	if userID == "errorGetOrganizations" {
+		return nil, errors.New("error while getting organizations")
+	}
+	if userID == "soleOwner" {
+		return []domain.Organization{
+			{
+				OrgID: "team",
+				Members: []domain.Member{
+					{UserID: userID, Role: domain.RoleOwner},
+					{UserID: "viewer", Role: domain.RoleViewer},
+				},
+				Files: make([]domain.File, 5),
+			},
+		}, nil
+	}
+	return []domain.Organization{
+		{
+			OrgID:   userID,
+			Members: []domain.Member{{UserID: userID, Role: domain.RoleOwner}},
+			Files:   make([]domain.File, 2),
+		},
+		{
+			OrgID: "shared",
+			Members: []domain.Member{
+				{UserID: userID, Role: domain.RoleViewer},
+				{UserID: "owner", Role: domain.RoleOwner},
+			},
+			Files: make([]domain.File, 5),
+		},
+	}, nil
+}
+
+func (orm *OrgRepositoryMock) UpdateOrganization(_ context.Context, orgID string, _ domain.Organization) error {
+	if orgID == "errorUpdateOrganization" {
+		return errors.New("error while updating organization")
+	}
+	return nil
+}
+
+func (orm *OrgRepositoryMock) DeleteOrganization(_ context.Context, orgID string) error {
+	if orgID == "errorDeleteOrganization" {
+		return errors.New("error while deleting organization")
+	}
+	return nil
+}
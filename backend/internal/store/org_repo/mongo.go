@@ -0,0 +1,137 @@
+package orgrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/db/user"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoRepo struct {
+	UserClient user.MongoClient
+}
+
+func NewMongoRepo(userClient user.MongoClient) OrgRepository {
+	return &mongoRepo{
+		UserClient: userClient,
+	}
+}
+
+func (mr *mongoRepo) AddOrganization(ctx context.Context, org domain.Organization) (domain.Organization, error) {
+	createdAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	if org.OrgID == "" {
+		org.OrgID = primitive.NewObjectID().Hex()
+	}
+	if org.Members == nil {
+		org.Members = make([]domain.Member, 0)
+	}
+	if org.Files == nil {
+		org.Files = make([]domain.File, 0)
+	}
+	org.CreatedAt = createdAt
+	org.UpdatedAt = createdAt
+
+	if _, err := mr.UserClient.CreateOrganization(ctx, convertOrganizationToUser(org)); err != nil {
+		return domain.Organization{}, fmt.Errorf("failed to insert organization %s, error is: %s", org.OrgID, err)
+	}
+
+	return org, nil
+}
+
+func (mr *mongoRepo) GetOrganization(ctx context.Context, orgID string) (domain.Organization, error) {
+	org, err := mr.UserClient.ReadOrganization(ctx, "org_id", orgID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.Organization{}, ErrNotFound
+	}
+	if err != nil {
+		return domain.Organization{}, fmt.Errorf("failed to get organization %s, error is: %s", orgID, err)
+	}
+
+	return convertOrganizationToDomain(org), nil
+}
+
+func (mr *mongoRepo) GetOrganizations(ctx context.Context, userID string) ([]domain.Organization, error) {
+	orgs, err := mr.UserClient.ReadOrganizations(ctx, "members.user_id", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organizations of user %s, error is: %s", userID, err)
+	}
+
+	mappedOrgs := make([]domain.Organization, len(orgs))
+	for i, org := range orgs {
+		mappedOrgs[i] = convertOrganizationToDomain(org)
+	}
+
+	return mappedOrgs, nil
+}
+
+func (mr *mongoRepo) UpdateOrganization(ctx context.Context, orgID string, org domain.Organization) error {
+	updatedAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	mappedOrg := convertOrganizationToUser(org)
+	updatedOrg := primitive.D{
+		bson.E{Key: "name", Value: mappedOrg.Name},
+		bson.E{Key: "members", Value: mappedOrg.Members},
+		bson.E{Key: "files", Value: mappedOrg.Files},
+		bson.E{Key: "updated_at", Value: updatedAt},
+	}
+	return mr.UserClient.UpdateOrganization(ctx, updatedOrg, "org_id", orgID)
+}
+
+func (mr *mongoRepo) DeleteOrganization(ctx context.Context, orgID string) error {
+	deleted, err := mr.UserClient.DeleteOrganization(ctx, "org_id", orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete organization %s, error is: %s", orgID, err)
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func convertOrganizationToDomain(org user.Organization) domain.Organization {
+	members := make([]domain.Member, len(org.Members))
+	for i, member := range org.Members {
+		members[i] = domain.Member{UserID: member.UserID, Email: member.Email, Role: member.Role}
+	}
+	files := make([]domain.File, len(org.Files))
+	for i, file := range org.Files {
+		files[i] = domain.File{Name: file.Name, UploadedAt: file.UploadedAt}
+	}
+
+	return domain.Organization{
+		OrgID:     org.OrgID,
+		Name:      org.Name,
+		Members:   members,
+		Files:     files,
+		CreatedAt: org.CreatedAt,
+		UpdatedAt: org.UpdatedAt,
+	}
+}
+
+func convertOrganizationToUser(org domain.Organization) user.Organization {
+	members := make([]user.Member, len(org.Members))
+	for i, member := range org.Members {
+		members[i] = user.Member{UserID: member.UserID, Email: member.Email, Role: member.Role}
+	}
+	files := make([]user.File, len(org.Files))
+	for i, file := range org.Files {
+		files[i] = user.File{Name: file.Name, UploadedAt: file.UploadedAt}
+	}
+
+	return user.Organization{
+		OrgID:     org.OrgID,
+		Name:      org.Name,
+		Members:   members,
+		Files:     files,
+		CreatedAt: org.CreatedAt,
+		UpdatedAt: org.UpdatedAt,
+	}
+}
@@ -0,0 +1,18 @@
+package orgrepo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
+
+var ErrNotFound = errors.New("organization not found")
+
+type OrgRepository interface {
+	AddOrganization(context.Context, domain.Organization) (domain.Organization, error)
+	GetOrganization(context.Context, string) (domain.Organization, error)
+	GetOrganizations(context.Context, string) ([]domain.Organization, error)
+	UpdateOrganization(context.Context, string, domain.Organization) error
+	DeleteOrganization(context.Context, string) error
+}
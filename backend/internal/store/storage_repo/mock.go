@@ -1,6 +1,7 @@
 package storagerepo
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -9,20 +10,28 @@ import (
 
 type StorageRepositoryMock struct{}
 
-func (prm *StorageRepositoryMock) AddInvoices(invoices []domain.Invoice) ([]domain.Invoice, error) {
+func (prm *StorageRepositoryMock) AddInvoices(_ context.Context, invoices []domain.Invoice) ([]domain.Invoice, error) {
 	if len(invoices) == 0 {
 		return invoices, errors.New("error while adding invoices")
 	}
 	return invoices, nil
 }
 
-func (prm *StorageRepositoryMock) GetInvoicesByPeriod(userID, _ string, _, _ time.Time) ([]domain.Invoice, error) {
+func (prm *StorageRepositoryMock) GetInvoicesByPeriod(_ context.Context, userID, _ string, _, _ time.Time) ([]domain.Invoice, error) {
 	if userID == "errorGetInvoicesByPeriod" {
 		return nil, errors.New("error while getting invoices by period")
 	}
 	if userID == "emptyGetInvoicesByPeriod" {
 		return make([]domain.Invoice, 0), nil
 	}
+	if userID == "fiscal" {
+		// Invoices fall into the first three periods of FY2022 of 4-4-5 calendar starting on Monday of February.
+		return []domain.Invoice{
+			{CustomerID: 1, PeriodStart: "2021-02-15", PaidPlan: "monthly", PaidAmount: 100.0, PeriodEnd: "2021-03-14"},
+			{CustomerID: 2, PeriodStart: "2021-03-01", PaidPlan: "monthly", PaidAmount: 200.0, PeriodEnd: "2021-03-31"},
+			{CustomerID: 3, PeriodStart: "2021-03-29", PaidPlan: "monthly", PaidAmount: 300.0, PeriodEnd: "2021-04-28"},
+		}, nil
+	}
 	return []domain.Invoice{
 		{
 			UserID:      "",
@@ -36,9 +45,48 @@ func (prm *StorageRepositoryMock) GetInvoicesByPeriod(userID, _ string, _, _ tim
 	}, nil
 }
 
-func (prm *StorageRepositoryMock) DeleteInvoices(userID, _ string) error {
+func (prm *StorageRepositoryMock) StreamInvoices(_ context.Context, userID, fileID string, fn func(domain.Invoice) error) error {
+	if userID == "errorStreamInvoices" {
+		return errors.New("error while streaming invoices")
+	}
+	invoices := []domain.Invoice{
+		{
+			UserID:      userID,
+			FileID:      fileID,
+			CustomerID:  1,
+			PeriodStart: "2021-10-01",
+			PaidPlan:    "monthly",
+			PaidAmount:  100.5,
+			PeriodEnd:   "2021-10-31",
+		},
+		{
+			UserID:      userID,
+			FileID:      fileID,
+			CustomerID:  2,
+			PeriodStart: "2021-10-15",
+			PaidPlan:    "annual",
+			PaidAmount:  1200,
+			PeriodEnd:   "2022-10-14",
+		},
+	}
+	for _, invoice := range invoices {
+		if err := fn(invoice); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (prm *StorageRepositoryMock) DeleteInvoices(_ context.Context, userID, _ string) error {
 	if userID == "errorDeleteInvoices" {
 		return errors.New("error while deleting invoices")
 	}
 	return nil
 }
+
+func (prm *StorageRepositoryMock) DeleteUserInvoices(_ context.Context, userID string) (int64, error) {
+	if userID == "errorDeleteUserInvoices" {
+		return 0, errors.New("error while deleting user invoices")
+	}
+	return 3, nil
+}
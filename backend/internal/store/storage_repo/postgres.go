@@ -19,7 +19,7 @@ func NewPostgresRepo(storageClient storage.PostgresClient) StorageRepository {
 	}
 }
 
-func (pr *postgresRepo) AddInvoices(invoices []domain.Invoice) ([]domain.Invoice, error) {
+func (pr *postgresRepo) AddInvoices(ctx context.Context, invoices []domain.Invoice) ([]domain.Invoice, error) {
 	mappedInvoices := make([]storage.Invoice, 0)
 
 	for _, invoice := range invoices {
@@ -35,7 +35,7 @@ func (pr *postgresRepo) AddInvoices(invoices []domain.Invoice) ([]domain.Invoice
 		mappedInvoices = append(mappedInvoices, mappedInvoice)
 	}
 
-	err := pr.StorageClient.Create(context.Background(), "invoices", storage.AllFields, mappedInvoices)
+	err := pr.StorageClient.Create(ctx, "invoices", storage.AllFields, mappedInvoices)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert invoices, error is: %s", err)
 	}
@@ -43,9 +43,9 @@ func (pr *postgresRepo) AddInvoices(invoices []domain.Invoice) ([]domain.Invoice
 	return invoices, nil
 }
 
-func (pr *postgresRepo) GetInvoicesByPeriod(userID, fileID string, periodStart, periodEnd time.Time) ([]domain.Invoice, error) {
+func (pr *postgresRepo) GetInvoicesByPeriod(ctx context.Context, userID, fileID string, periodStart, periodEnd time.Time) ([]domain.Invoice, error) {
 	invoices, err := pr.StorageClient.ReadByPeriod(
-		context.Background(),
+		ctx,
 		"invoices",
 		storage.AllFields,
 		userID,
@@ -78,8 +78,38 @@ func (pr *postgresRepo) GetInvoicesByPeriod(userID, fileID string, periodStart,
 	return mappedInvoices, nil
 }
 
-func (pr *postgresRepo) DeleteInvoices(userID, fileID string) error {
-	return pr.StorageClient.Delete(context.Background(), "invoices", userID, fileID)
+func (pr *postgresRepo) StreamInvoices(ctx context.Context, userID, fileID string, fn func(domain.Invoice) error) error {
+	err := pr.StorageClient.ReadByFile(
+		ctx,
+		"invoices",
+		storage.AllFields,
+		userID,
+		fileID,
+		func(invoice storage.Invoice) error {
+			return fn(domain.Invoice{
+				UserID:      invoice.UserID,
+				FileID:      invoice.FileID,
+				CustomerID:  invoice.CustomerID,
+				PeriodStart: invoice.PeriodStart.Format("2006-01-02"),
+				PaidPlan:    invoice.PaidPlan,
+				PaidAmount:  invoice.PaidAmount,
+				PeriodEnd:   invoice.PeriodEnd.Format("2006-01-02"),
+			})
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to stream invoices with user_id %s, file_id %s, error is: %s", userID, fileID, err)
+	}
+
+	return nil
+}
+
+func (pr *postgresRepo) DeleteInvoices(ctx context.Context, userID, fileID string) error {
+	return pr.StorageClient.Delete(ctx, "invoices", userID, fileID)
+}
+
+func (pr *postgresRepo) DeleteUserInvoices(ctx context.Context, userID string) (int64, error) {
+	return pr.StorageClient.DeleteByUser(ctx, "invoices", userID)
 }
 
 func mapDate(date string) time.Time {
@@ -1,13 +1,16 @@
 package storagerepo
 
 import (
+	"context"
 	"time"
 
 	"github.com/hackfeed/remrratality/backend/internal/domain"
 )
 
 type StorageRepository interface {
-	AddInvoices([]domain.Invoice) ([]domain.Invoice, error)
-	GetInvoicesByPeriod(string, string, time.Time, time.Time) ([]domain.Invoice, error)
-	DeleteInvoices(string, string) error
+	AddInvoices(context.Context, []domain.Invoice) ([]domain.Invoice, error)
+	GetInvoicesByPeriod(context.Context, string, string, time.Time, time.Time) ([]domain.Invoice, error)
+	StreamInvoices(context.Context, string, string, func(domain.Invoice) error) error
+	DeleteInvoices(context.Context, string, string) error
+	DeleteUserInvoices(context.Context, string) (int64, error)
 }
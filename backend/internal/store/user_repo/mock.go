@@ -1,6 +1,7 @@
 package userrepo
 
 import (
+	"context"
 	"errors"
 
 	"github.com/hackfeed/remrratality/backend/internal/utils/user_validation"
@@ -10,7 +11,7 @@ import (
 
 type UserRepositoryMock struct{}
 
-func (urm *UserRepositoryMock) AddUser(email, password string) (domain.User, error) {
+func (urm *UserRepositoryMock) AddUser(_ context.Context, email, password string) (domain.User, error) {
 	if email == "errorGetUser" {
 		return domain.User{}, errors.New("error while adding user")
 	}
@@ -33,17 +34,18 @@ func (urm *UserRepositoryMock) AddUser(email, password string) (domain.User, err
 	}, nil
 }
 
-func (urm *UserRepositoryMock) GetUser(email string) (domain.User, error) {
+func (urm *UserRepositoryMock) GetUser(_ context.Context, email string) (domain.User, error) {
 	if email == "errorGetUser" {
 		return domain.User{}, errors.New("user not exist")
 	}
-	if email == "errorToken" || email == "someEmail" {
+	if email == "errorToken" || email == "someEmail" || email == "errorAddOrganization" {
 		return domain.User{}, nil
 	}
 	id := "id"
 	token, refreshToken, _ := user_validation.GenerateTokens(email, id)
 	hashedPassword, _ := user_validation.HashPassword("somePass")
 	return domain.User{
+		UserID:       id,
 		Email:        email,
 		Password:     hashedPassword,
 		Token:        token,
@@ -52,9 +54,16 @@ func (urm *UserRepositoryMock) GetUser(email string) (domain.User, error) {
 	}, nil
 }
 
-func (urm *UserRepositoryMock) UpdateUser(userID string, _ domain.User) error {
+func (urm *UserRepositoryMock) UpdateUser(_ context.Context, userID string, _ domain.User) error {
 	if userID == "errorUpdateUser" {
 		return errors.New("error while updating user")
 	}
 	return nil
 }
+
+func (urm *UserRepositoryMock) DeleteUser(_ context.Context, userID string) error {
+	if userID == "errorDeleteUser" {
+		return errors.New("error while deleting user")
+	}
+	return nil
+}
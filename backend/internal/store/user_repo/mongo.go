@@ -1,6 +1,7 @@
 package userrepo
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -22,7 +23,7 @@ func NewMongoRepo(userClient user.MongoClient) UserRepository {
 	}
 }
 
-func (mr *mongoRepo) AddUser(email, password string) (domain.User, error) {
+func (mr *mongoRepo) AddUser(ctx context.Context, email, password string) (domain.User, error) {
 	hashedPassword, err := user_validation.HashPassword(password)
 	if err != nil {
 		return domain.User{}, fmt.Errorf("failed to hash password for email %s, error is: %s", email, err)
@@ -48,7 +49,7 @@ func (mr *mongoRepo) AddUser(email, password string) (domain.User, error) {
 	mappedUser.Token = token
 	mappedUser.RefreshToken = refreshToken
 
-	_, err = mr.UserClient.Create(mappedUser)
+	_, err = mr.UserClient.Create(ctx, mappedUser)
 	if err != nil {
 		return domain.User{}, fmt.Errorf("failed to insert user with email %s, error is: %s", email, err)
 	}
@@ -67,8 +68,8 @@ func (mr *mongoRepo) AddUser(email, password string) (domain.User, error) {
 	return internalUser, nil
 }
 
-func (mr *mongoRepo) GetUser(email string) (domain.User, error) {
-	user, err := mr.UserClient.Read("email", email)
+func (mr *mongoRepo) GetUser(ctx context.Context, email string) (domain.User, error) {
+	user, err := mr.UserClient.Read(ctx, "email", email)
 	if err != nil {
 		return domain.User{}, fmt.Errorf("failed to get user with email %s, error is: %s", email, err)
 	}
@@ -89,7 +90,7 @@ func (mr *mongoRepo) GetUser(email string) (domain.User, error) {
 	return mappedUser, nil
 }
 
-func (mr *mongoRepo) UpdateUser(userID string, user domain.User) error {
+func (mr *mongoRepo) UpdateUser(ctx context.Context, userID string, user domain.User) error {
 	updatedUser := primitive.D{
 		bson.E{Key: "user_id", Value: user.UserID},
 		bson.E{Key: "email", Value: user.Email},
@@ -100,7 +101,19 @@ func (mr *mongoRepo) UpdateUser(userID string, user domain.User) error {
 		bson.E{Key: "updated_at", Value: user.UpdatedAt},
 		bson.E{Key: "files", Value: convertFilesToUser(user.Files)},
 	}
-	return mr.UserClient.Update(updatedUser, "user_id", userID)
+	return mr.UserClient.Update(ctx, updatedUser, "user_id", userID)
+}
+
+func (mr *mongoRepo) DeleteUser(ctx context.Context, userID string) error {
+	deleted, err := mr.UserClient.Delete(ctx, "user_id", userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user with user_id %s, error is: %s", userID, err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("user with user_id %s doesn't exist", userID)
+	}
+
+	return nil
 }
 
 func convertFilesToDomain(userFiles []user.File) []domain.File {
@@ -1,9 +1,14 @@
 package userrepo
 
-import "github.com/hackfeed/remrratality/backend/internal/domain"
+import (
+	"context"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
 
 type UserRepository interface {
-	AddUser(string, string) (domain.User, error)
-	GetUser(string) (domain.User, error)
-	UpdateUser(string, domain.User) error
+	AddUser(context.Context, string, string) (domain.User, error)
+	GetUser(context.Context, string) (domain.User, error)
+	UpdateUser(context.Context, string, domain.User) error
+	DeleteUser(context.Context, string) error
 }
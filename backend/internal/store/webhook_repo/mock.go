@@ -0,0 +1,102 @@
+package webhookrepo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
+
+type WebhookRepositoryMock struct{}
+
+func (wrm *WebhookRepositoryMock) AddWebhook(_ context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+	if webhook.OrgID == "errorAddWebhook" {
+		return domain.Webhook{}, errors.New("error while adding webhook")
+	}
+	webhook.ID = "webhook"
+	return webhook, nil
+}
+
+func (wrm *WebhookRepositoryMock) GetWebhook(_ context.Context, webhookID string) (domain.Webhook, error) {
+	if webhookID == "errorGetWebhook" {
+		return domain.Webhook{}, errors.New("error while getting webhook")
+	}
+	if webhookID == "notFoundWebhook" {
+		return domain.Webhook{}, ErrNotFound
+	}
+	orgID := "org"
+	if webhookID == "otherOrgWebhook" {
+		orgID = "otherOrg"
+	}
+	return mockWebhook(webhookID, orgID), nil
+}
+
+func (wrm *WebhookRepositoryMock) GetWebhooks(_ context.Context, orgID string) ([]domain.Webhook, error) {
+	if orgID == "errorGetWebhooks" {
+		return nil, errors.New("error while getting webhooks")
+	}
+	return []domain.Webhook{mockWebhook("webhook", orgID)}, nil
+}
+
+func (wrm *WebhookRepositoryMock) DeleteWebhook(_ context.Context, webhookID string) error {
+	if webhookID == "errorDeleteWebhook" {
+		return errors.New("error while deleting webhook")
+	}
+	return nil
+}
+
+func (wrm *WebhookRepositoryMock) DeleteWebhooks(_ context.Context, orgID string) (int64, error) {
+	if orgID == "errorDeleteWebhooks" {
+		return 0, errors.New("error while deleting webhooks")
+	}
+	return 1, nil
+}
+
+func (wrm *WebhookRepositoryMock) AddDelivery(_ context.Context, delivery domain.WebhookDelivery) (domain.WebhookDelivery, error) {
+	if delivery.OrgID == "errorAddDelivery" {
+		return domain.WebhookDelivery{}, errors.New("error while adding delivery")
+	}
+	if delivery.Attempts == nil {
+		delivery.Attempts = make([]domain.WebhookAttempt, 0)
+	}
+	return delivery, nil
+}
+
+func (wrm *WebhookRepositoryMock) GetDeliveries(_ context.Context, webhookID string) ([]domain.WebhookDelivery, error) {
+	if webhookID == "errorGetDeliveries" {
+		return nil, errors.New("error while getting deliveries")
+	}
+	return []domain.WebhookDelivery{
+		{
+			ID:        "delivery",
+			WebhookID: webhookID,
+			OrgID:     "org",
+			Event:     domain.WebhookDatasetImported,
+			Payload:   `{"id":"delivery","event":"dataset.imported"}`,
+			State:     domain.DeliverySucceeded,
+			Attempts:  []domain.WebhookAttempt{{StatusCode: 200, DurationMs: 12}},
+		},
+	}, nil
+}
+
+func (wrm *WebhookRepositoryMock) ClaimDelivery(_ context.Context, _ time.Duration) (domain.WebhookDelivery, error) {
+	return domain.WebhookDelivery{}, ErrNotFound
+}
+
+func (wrm *WebhookRepositoryMock) UpdateDelivery(_ context.Context, delivery domain.WebhookDelivery) error {
+	if delivery.OrgID == "errorUpdateDelivery" {
+		return errors.New("error while updating delivery")
+	}
+	return nil
+}
+
+func mockWebhook(webhookID, orgID string) domain.Webhook {
+	return domain.Webhook{
+		ID:     webhookID,
+		OrgID:  orgID,
+		URL:    "https://example.com/webhook",
+		Secret: "secret",
+		Events: []string{domain.WebhookDatasetImported, domain.WebhookDatasetDeleted},
+	}
+}
@@ -0,0 +1,251 @@
+package webhookrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/db/user"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// deliveriesLimit is the number of the latest deliveries returned for the webhook.
+const deliveriesLimit = 50
+
+type mongoRepo struct {
+	UserClient user.MongoClient
+}
+
+func NewMongoRepo(userClient user.MongoClient) WebhookRepository {
+	return &mongoRepo{
+		UserClient: userClient,
+	}
+}
+
+func (mr *mongoRepo) AddWebhook(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+	createdAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	if webhook.ID == "" {
+		webhook.ID = primitive.NewObjectID().Hex()
+	}
+	if webhook.Events == nil {
+		webhook.Events = make([]string, 0)
+	}
+	webhook.CreatedAt = createdAt
+
+	if _, err := mr.UserClient.CreateWebhook(ctx, convertWebhookToUser(webhook)); err != nil {
+		return domain.Webhook{}, fmt.Errorf("failed to insert webhook %s, error is: %s", webhook.ID, err)
+	}
+
+	return webhook, nil
+}
+
+func (mr *mongoRepo) GetWebhook(ctx context.Context, webhookID string) (domain.Webhook, error) {
+	webhook, err := mr.UserClient.ReadWebhook(ctx, bson.M{"webhook_id": webhookID})
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.Webhook{}, ErrNotFound
+	}
+	if err != nil {
+		return domain.Webhook{}, fmt.Errorf("failed to get webhook %s, error is: %s", webhookID, err)
+	}
+
+	return convertWebhookToDomain(webhook), nil
+}
+
+// GetWebhooks returns webhooks of organization in order they were created.
+func (mr *mongoRepo) GetWebhooks(ctx context.Context, orgID string) ([]domain.Webhook, error) {
+	webhooks, err := mr.UserClient.ReadWebhooks(ctx, bson.M{"org_id": orgID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks of org_id %s, error is: %s", orgID, err)
+	}
+
+	mappedWebhooks := make([]domain.Webhook, len(webhooks))
+	for i, webhook := range webhooks {
+		mappedWebhooks[i] = convertWebhookToDomain(webhook)
+	}
+
+	return mappedWebhooks, nil
+}
+
+// DeleteWebhook deletes webhook with all its deliveries, pending ones are never sent.
+func (mr *mongoRepo) DeleteWebhook(ctx context.Context, webhookID string) error {
+	err := mr.UserClient.DeleteWebhook(ctx, bson.M{"webhook_id": webhookID})
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook %s, error is: %s", webhookID, err)
+	}
+
+	return nil
+}
+
+// DeleteWebhooks deletes webhooks of organization with all their deliveries.
+func (mr *mongoRepo) DeleteWebhooks(ctx context.Context, orgID string) (int64, error) {
+	deleted, err := mr.UserClient.DeleteWebhooks(ctx, bson.M{"org_id": orgID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete webhooks of organization %s, error is: %s", orgID, err)
+	}
+
+	return deleted, nil
+}
+
+func (mr *mongoRepo) AddDelivery(ctx context.Context, delivery domain.WebhookDelivery) (domain.WebhookDelivery, error) {
+	createdAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	if delivery.Attempts == nil {
+		delivery.Attempts = make([]domain.WebhookAttempt, 0)
+	}
+	delivery.CreatedAt = createdAt
+	delivery.UpdatedAt = createdAt
+
+	if _, err := mr.UserClient.CreateWebhookDelivery(ctx, convertDeliveryToUser(delivery)); err != nil {
+		return domain.WebhookDelivery{}, fmt.Errorf("failed to insert delivery %s, error is: %s", delivery.ID, err)
+	}
+
+	return delivery, nil
+}
+
+// GetDeliveries returns the latest deliveries of the webhook, newest first.
+func (mr *mongoRepo) GetDeliveries(ctx context.Context, webhookID string) ([]domain.WebhookDelivery, error) {
+	deliveries, err := mr.UserClient.ReadWebhookDeliveries(ctx, bson.M{"webhook_id": webhookID}, deliveriesLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deliveries of webhook %s, error is: %s", webhookID, err)
+	}
+
+	mappedDeliveries := make([]domain.WebhookDelivery, len(deliveries))
+	for i, delivery := range deliveries {
+		mappedDeliveries[i] = convertDeliveryToDomain(delivery)
+	}
+
+	return mappedDeliveries, nil
+}
+
+// ClaimDelivery returns pending delivery which is due and postpones its next attempt by lease,
+// so the delivery is attempted again if the instance sending it stops. ErrNotFound is returned
+// when no delivery is due.
+func (mr *mongoRepo) ClaimDelivery(ctx context.Context, lease time.Duration) (domain.WebhookDelivery, error) {
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	delivery, err := mr.UserClient.ClaimWebhookDelivery(
+		ctx,
+		bson.M{"state": domain.DeliveryPending, "next_attempt_at": bson.M{"$lte": now}},
+		primitive.D{
+			bson.E{Key: "next_attempt_at", Value: now.Add(lease)},
+			bson.E{Key: "updated_at", Value: now},
+		},
+	)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.WebhookDelivery{}, ErrNotFound
+	}
+	if err != nil {
+		return domain.WebhookDelivery{}, fmt.Errorf("failed to claim delivery, error is: %s", err)
+	}
+
+	return convertDeliveryToDomain(delivery), nil
+}
+
+// UpdateDelivery saves state, attempts and time of the next attempt of the delivery.
+func (mr *mongoRepo) UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	updatedAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	updatedDelivery := primitive.D{
+		bson.E{Key: "state", Value: delivery.State},
+		bson.E{Key: "attempts", Value: convertAttemptsToUser(delivery.Attempts)},
+		bson.E{Key: "next_attempt_at", Value: delivery.NextAttemptAt},
+		bson.E{Key: "updated_at", Value: updatedAt},
+	}
+	matched, err := mr.UserClient.UpdateWebhookDelivery(ctx, updatedDelivery, bson.M{"delivery_id": delivery.ID})
+	if err != nil {
+		return fmt.Errorf("failed to update delivery %s, error is: %s", delivery.ID, err)
+	}
+	if matched == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func convertWebhookToDomain(webhook user.Webhook) domain.Webhook {
+	events := webhook.Events
+	if events == nil {
+		events = make([]string, 0)
+	}
+
+	return domain.Webhook{
+		ID:        webhook.WebhookID,
+		OrgID:     webhook.OrgID,
+		URL:       webhook.URL,
+		Secret:    webhook.Secret,
+		Events:    events,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+func convertWebhookToUser(webhook domain.Webhook) user.Webhook {
+	return user.Webhook{
+		WebhookID: webhook.ID,
+		OrgID:     webhook.OrgID,
+		URL:       webhook.URL,
+		Secret:    webhook.Secret,
+		Events:    webhook.Events,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+func convertDeliveryToDomain(delivery user.WebhookDelivery) domain.WebhookDelivery {
+	attempts := make([]domain.WebhookAttempt, len(delivery.Attempts))
+	for i, attempt := range delivery.Attempts {
+		attempts[i] = domain.WebhookAttempt{
+			At:         attempt.At,
+			StatusCode: attempt.StatusCode,
+			Error:      attempt.Error,
+			DurationMs: attempt.DurationMs,
+		}
+	}
+
+	return domain.WebhookDelivery{
+		ID:            delivery.DeliveryID,
+		WebhookID:     delivery.WebhookID,
+		OrgID:         delivery.OrgID,
+		Event:         delivery.Event,
+		Payload:       delivery.Payload,
+		State:         delivery.State,
+		Attempts:      attempts,
+		NextAttemptAt: delivery.NextAttemptAt,
+		CreatedAt:     delivery.CreatedAt,
+		UpdatedAt:     delivery.UpdatedAt,
+	}
+}
+
+func convertDeliveryToUser(delivery domain.WebhookDelivery) user.WebhookDelivery {
+	return user.WebhookDelivery{
+		DeliveryID:    delivery.ID,
+		WebhookID:     delivery.WebhookID,
+		OrgID:         delivery.OrgID,
+		Event:         delivery.Event,
+		Payload:       delivery.Payload,
+		State:         delivery.State,
+		Attempts:      convertAttemptsToUser(delivery.Attempts),
+		NextAttemptAt: delivery.NextAttemptAt,
+		CreatedAt:     delivery.CreatedAt,
+		UpdatedAt:     delivery.UpdatedAt,
+	}
+}
+
+func convertAttemptsToUser(attempts []domain.WebhookAttempt) []user.WebhookAttempt {
+	mappedAttempts := make([]user.WebhookAttempt, len(attempts))
+	for i, attempt := range attempts {
+		mappedAttempts[i] = user.WebhookAttempt{
+			At:         attempt.At,
+			StatusCode: attempt.StatusCode,
+			Error:      attempt.Error,
+			DurationMs: attempt.DurationMs,
+		}
+	}
+	return mappedAttempts
+}
@@ -0,0 +1,23 @@
+package webhookrepo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
+
+var ErrNotFound = errors.New("webhook not found")
+
+type WebhookRepository interface {
+	AddWebhook(context.Context, domain.Webhook) (domain.Webhook, error)
+	GetWebhook(context.Context, string) (domain.Webhook, error)
+	GetWebhooks(context.Context, string) ([]domain.Webhook, error)
+	DeleteWebhook(context.Context, string) error
+	DeleteWebhooks(context.Context, string) (int64, error)
+	AddDelivery(context.Context, domain.WebhookDelivery) (domain.WebhookDelivery, error)
+	GetDeliveries(context.Context, string) ([]domain.WebhookDelivery, error)
+	ClaimDelivery(context.Context, time.Duration) (domain.WebhookDelivery, error)
+	UpdateDelivery(context.Context, domain.WebhookDelivery) error
+}
@@ -0,0 +1,58 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hackfeed/remrratality/backend/internal/config"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetup(t *testing.T) {
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	path := filepath.Join(t.TempDir(), "spans.json")
+	shutdown, err := Setup(context.Background(), config.Tracing{
+		Exporter:    "file",
+		File:        path,
+		ServiceName: "test-service",
+		SampleRatio: 1,
+	})
+	assert.NoError(t, err)
+
+	ctx, span := Start(context.Background(), "operation")
+	traceID := TraceID(ctx)
+	span.End()
+	assert.Len(t, traceID, 32)
+
+	assert.NoError(t, shutdown(context.Background()))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, true, strings.Contains(string(data), "\"Name\":\"operation\""))
+	assert.Equal(t, true, strings.Contains(string(data), traceID))
+	assert.Equal(t, true, strings.Contains(string(data), "test-service"))
+}
+
+func TestSetupNone(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.Tracing{Exporter: "none"})
+	assert.NoError(t, err)
+
+	ctx, span := Start(context.Background(), "operation")
+	span.End()
+	assert.Equal(t, "", TraceID(ctx))
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestSetupErrors(t *testing.T) {
+	_, err := Setup(context.Background(), config.Tracing{Exporter: "file", File: t.TempDir()})
+	assert.Error(t, err)
+
+	_, err = Setup(context.Background(), config.Tracing{Exporter: "jaeger"})
+	assert.EqualError(t, err, "unknown tracing exporter jaeger")
+}
@@ -0,0 +1,141 @@
+package analytics_export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	monthLayout = "1.2006"
+	rowLayout   = "2006-01"
+	sheetName   = "MRR"
+)
+
+// Header is the list of table columns, rows are written in the same order.
+var Header = []string{"month", "new", "old", "reactivation", "expansion", "contraction", "churn", "total"}
+
+// Row is MRR analytics of a single month.
+type Row struct {
+	Month        string  `json:"month"`
+	New          float32 `json:"new"`
+	Old          float32 `json:"old"`
+	Reactivation float32 `json:"reactivation"`
+	Expansion    float32 `json:"expansion"`
+	Contraction  float32 `json:"contraction"`
+	Churn        float32 `json:"churn"`
+	Total        float32 `json:"total"`
+}
+
+// Rows turns parallel arrays of MRR analytics into one row per month. Months are converted
+// to "2006-01" so spreadsheets don't take them for numbers, components missing for a month
+// are zero.
+func Rows(months []string, mrr domain.TotalMRR) []Row {
+	rows := make([]Row, len(months))
+
+	for i, month := range months {
+		if date, err := time.Parse(monthLayout, month); err == nil {
+			month = date.Format(rowLayout)
+		}
+		rows[i] = Row{
+			Month:        month,
+			New:          valueAt(mrr.New, i),
+			Old:          valueAt(mrr.Old, i),
+			Reactivation: valueAt(mrr.Reactivation, i),
+			Expansion:    valueAt(mrr.Expansion, i),
+			Contraction:  valueAt(mrr.Contraction, i),
+			Churn:        valueAt(mrr.Churn, i),
+			Total:        valueAt(mrr.Total, i),
+		}
+	}
+
+	return rows
+}
+
+func (r Row) values() []float32 {
+	return []float32{r.New, r.Old, r.Reactivation, r.Expansion, r.Contraction, r.Churn, r.Total}
+}
+
+// WriteCSV writes rows as CSV table with header.
+func WriteCSV(w io.Writer, rows []Row) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(Header); err != nil {
+		return fmt.Errorf("failed to write header, error is: %s", err)
+	}
+	for _, row := range rows {
+		record := []string{row.Month}
+		for _, value := range row.values() {
+			record = append(record, strconv.FormatFloat(float64(value), 'f', -1, 32))
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write row, error is: %s", err)
+		}
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// WriteNDJSON writes rows as JSON objects separated by newlines.
+func WriteNDJSON(w io.Writer, rows []Row) error {
+	encoder := json.NewEncoder(w)
+
+	for _, row := range rows {
+		if err := encoder.Encode(row); err != nil {
+			return fmt.Errorf("failed to write row, error is: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// WriteXLSX writes rows as workbook with single sheet with header.
+func WriteXLSX(w io.Writer, rows []Row) error {
+	f := excelize.NewFile()
+	f.SetSheetName(f.GetSheetName(0), sheetName)
+
+	header := make([]interface{}, len(Header))
+	for i, column := range Header {
+		header[i] = column
+	}
+	if err := f.SetSheetRow(sheetName, "A1", &header); err != nil {
+		return fmt.Errorf("failed to write header, error is: %s", err)
+	}
+
+	for i, row := range rows {
+		record := []interface{}{row.Month}
+		for _, value := range row.values() {
+			record = append(record, toFloat64(value))
+		}
+		if err := f.SetSheetRow(sheetName, fmt.Sprintf("A%d", i+2), &record); err != nil {
+			return fmt.Errorf("failed to write row, error is: %s", err)
+		}
+	}
+
+	if err := f.Write(w); err != nil {
+		return fmt.Errorf("failed to write workbook, error is: %s", err)
+	}
+
+	return nil
+}
+
+func valueAt(values []float32, i int) float32 {
+	if i < len(values) {
+		return values[i]
+	}
+	return 0
+}
+
+// toFloat64 keeps the shortest decimal representation of value, so 100.1 isn't
+// written as 100.09999847412109.
+func toFloat64(value float32) float64 {
+	converted, _ := strconv.ParseFloat(strconv.FormatFloat(float64(value), 'f', -1, 32), 64)
+	return converted
+}
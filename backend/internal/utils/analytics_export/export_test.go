@@ -0,0 +1,106 @@
+package analytics_export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+var testRows = []Row{
+	{Month: "2021-10", New: 100.1, Total: 100.1},
+	{Month: "2021-11", Old: 100.1, Churn: -50, Total: 50.1},
+}
+
+func TestRows(t *testing.T) {
+	type testInput struct {
+		months []string
+		mrr    domain.TotalMRR
+	}
+	type testWant struct {
+		rows []Row
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{
+				months: []string{"10.2021", "11.2021"},
+				mrr: domain.TotalMRR{
+					New:          []float32{100.1, 0},
+					Old:          []float32{0, 100.1},
+					Reactivation: []float32{0, 0},
+					Expansion:    []float32{0, 0},
+					Contraction:  []float32{0, 0},
+					Churn:        []float32{0, -50},
+					Total:        []float32{100.1, 50.1},
+				},
+			},
+			want: testWant{
+				rows: testRows,
+			},
+		},
+		{
+			input: testInput{
+				months: []string{"someMonth"},
+				mrr:    domain.TotalMRR{},
+			},
+			want: testWant{
+				rows: []Row{{Month: "someMonth"}},
+			},
+		},
+		{
+			input: testInput{
+				months: nil,
+				mrr:    domain.TotalMRR{},
+			},
+			want: testWant{
+				rows: []Row{},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want.rows, Rows(test.input.months, test.input.mrr))
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := WriteCSV(&buf, testRows)
+	assert.NoError(t, err)
+	assert.Equal(t, "month,new,old,reactivation,expansion,contraction,churn,total\n"+
+		"2021-10,100.1,0,0,0,0,0,100.1\n"+
+		"2021-11,0,100.1,0,0,0,-50,50.1\n", buf.String())
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := WriteNDJSON(&buf, testRows)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"month\":\"2021-10\",\"new\":100.1,\"old\":0,\"reactivation\":0,\"expansion\":0,\"contraction\":0,\"churn\":0,\"total\":100.1}\n"+
+		"{\"month\":\"2021-11\",\"new\":0,\"old\":100.1,\"reactivation\":0,\"expansion\":0,\"contraction\":0,\"churn\":-50,\"total\":50.1}\n", buf.String())
+}
+
+func TestWriteXLSX(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := WriteXLSX(&buf, testRows)
+	assert.NoError(t, err)
+
+	f, err := excelize.OpenReader(&buf)
+	assert.NoError(t, err)
+	rows, err := f.GetRows(sheetName)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		Header,
+		{"2021-10", "100.1", "0", "0", "0", "0", "0", "100.1"},
+		{"2021-11", "0", "100.1", "0", "0", "0", "-50", "50.1"},
+	}, rows)
+}
@@ -0,0 +1,44 @@
+package dataset_summary
+
+import (
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
+
+const datasetLayout = "2006-01-02"
+
+// Summarize fills row count, customer count, period start range and total amount of the dataset
+// from its invoices. Invoices dates are parsed with layout, the ones failed to parse are skipped
+// when period start range is computed.
+func Summarize(dataset *domain.Dataset, invoices []domain.Invoice, layout string) {
+	customers := make(map[uint32]struct{})
+	var periodStartMin, periodStartMax time.Time
+
+	dataset.RowCount = len(invoices)
+	dataset.TotalAmount = 0
+
+	for _, invoice := range invoices {
+		customers[invoice.CustomerID] = struct{}{}
+		dataset.TotalAmount += float64(invoice.PaidAmount)
+
+		periodStart, err := time.Parse(layout, invoice.PeriodStart)
+		if err != nil {
+			continue
+		}
+		if periodStartMin.IsZero() || periodStart.Before(periodStartMin) {
+			periodStartMin = periodStart
+		}
+		if periodStartMax.IsZero() || periodStart.After(periodStartMax) {
+			periodStartMax = periodStart
+		}
+	}
+
+	dataset.CustomerCount = len(customers)
+	dataset.PeriodStartMin = ""
+	dataset.PeriodStartMax = ""
+	if !periodStartMin.IsZero() {
+		dataset.PeriodStartMin = periodStartMin.Format(datasetLayout)
+		dataset.PeriodStartMax = periodStartMax.Format(datasetLayout)
+	}
+}
@@ -0,0 +1,78 @@
+package dataset_summary
+
+import (
+	"testing"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	type testInput struct {
+		invoices []domain.Invoice
+		layout   string
+	}
+	type testWant struct {
+		dataset domain.Dataset
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{
+				invoices: make([]domain.Invoice, 0),
+				layout:   "02.01.2006",
+			},
+			want: testWant{
+				dataset: domain.Dataset{Name: "file.csv"},
+			},
+		},
+		{
+			input: testInput{
+				invoices: []domain.Invoice{
+					{CustomerID: 1, PeriodStart: "01.10.2021", PaidAmount: 100},
+					{CustomerID: 2, PeriodStart: "15.09.2021", PaidAmount: 50.5},
+					{CustomerID: 1, PeriodStart: "01.11.2021", PaidAmount: 100},
+					{CustomerID: 3, PeriodStart: "wrongDate", PaidAmount: 10},
+				},
+				layout: "02.01.2006",
+			},
+			want: testWant{
+				dataset: domain.Dataset{
+					Name:           "file.csv",
+					RowCount:       4,
+					CustomerCount:  3,
+					PeriodStartMin: "2021-09-15",
+					PeriodStartMax: "2021-11-01",
+					TotalAmount:    260.5,
+				},
+			},
+		},
+		{
+			input: testInput{
+				invoices: []domain.Invoice{
+					{CustomerID: 1, PeriodStart: "2021-10-01", PaidAmount: 100},
+				},
+				layout: "2006-01-02",
+			},
+			want: testWant{
+				dataset: domain.Dataset{
+					Name:           "file.csv",
+					RowCount:       1,
+					CustomerCount:  1,
+					PeriodStartMin: "2021-10-01",
+					PeriodStartMax: "2021-10-01",
+					TotalAmount:    100,
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		dataset := domain.Dataset{Name: "file.csv"}
+		Summarize(&dataset, test.input.invoices, test.input.layout)
+		assert.Equal(t, test.want.dataset, dataset)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,7 +16,9 @@ func CreateGinContext(keys map[string]interface{}, body interface{}, params []gi
 	c, _ := gin.CreateTestContext(w)
 	jsonBytes, _ := json.Marshal(body)
 	req := &http.Request{
-		Body: io.NopCloser(bytes.NewBuffer(jsonBytes)),
+		Body:   io.NopCloser(bytes.NewBuffer(jsonBytes)),
+		URL:    &url.URL{},
+		Header: make(http.Header),
 	}
 
 	for k, v := range keys {
@@ -0,0 +1,232 @@
+package user_validation
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// KeySet holds the key tokens are signed with and all keys tokens are verified with.
+// Keys are identified by kid, which is the name of PEM file without extension, so
+// rotating keys means adding a new private key, switching signing kid to it and
+// removing the old one once all tokens it has signed are expired.
+type KeySet struct {
+	signingKID   string
+	signingKey   crypto.PrivateKey
+	verification map[string]verificationKey
+}
+
+type verificationKey struct {
+	method jwt.SigningMethod
+	key    crypto.PublicKey
+}
+
+// JWK is a public key in JSON Web Key format, only RSA and Ed25519 keys are supported.
+type JWK struct {
+	KID string `json:"kid"`
+	KTY string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+var keys *KeySet
+
+// LoadKeys reads all *.pem files from dir and makes them used by token functions. Private
+// keys may be used for signing, public keys are used for verification only. If signingKID
+// is empty, dir should contain exactly one private key.
+func LoadKeys(dir, signingKID string) error {
+	keySet, err := NewKeySet(dir, signingKID)
+	if err != nil {
+		return err
+	}
+	keys = keySet
+
+	return nil
+}
+
+func NewKeySet(dir, signingKID string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list keys in %s, error is: %s", dir, err)
+	}
+	sort.Strings(paths)
+
+	keySet := &KeySet{
+		verification: make(map[string]verificationKey),
+	}
+	privateKeys := make(map[string]crypto.PrivateKey)
+
+	for _, path := range paths {
+		kid := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key %s, error is: %s", path, err)
+		}
+
+		privateKey, publicKey, err := parseKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse key %s, error is: %s", path, err)
+		}
+
+		method, err := signingMethod(publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to use key %s, error is: %s", path, err)
+		}
+
+		keySet.verification[kid] = verificationKey{method: method, key: publicKey}
+		if privateKey != nil {
+			privateKeys[kid] = privateKey
+		}
+	}
+
+	if signingKID == "" {
+		if len(privateKeys) != 1 {
+			return nil, fmt.Errorf("failed to choose signing key, %d private keys found in %s", len(privateKeys), dir)
+		}
+		for kid := range privateKeys {
+			signingKID = kid
+		}
+	}
+
+	signingKey, ok := privateKeys[signingKID]
+	if !ok {
+		return nil, fmt.Errorf("failed to find private key %s in %s", signingKID, dir)
+	}
+	keySet.signingKID = signingKID
+	keySet.signingKey = signingKey
+
+	return keySet, nil
+}
+
+func (ks *KeySet) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.verification[ks.signingKID].method, claims)
+	token.Header["kid"] = ks.signingKID
+
+	return token.SignedString(ks.signingKey)
+}
+
+func (ks *KeySet) keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return nil, errors.New("token has no kid header")
+	}
+
+	key, ok := ks.verification[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %s", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s for key %s", token.Method.Alg(), kid)
+	}
+
+	return key.key, nil
+}
+
+// JWKS returns public keys of the set sorted by kid.
+func (ks *KeySet) JWKS() JWKS {
+	kids := make([]string, 0, len(ks.verification))
+	for kid := range ks.verification {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	jwks := JWKS{Keys: make([]JWK, 0, len(kids))}
+	for _, kid := range kids {
+		key := ks.verification[kid]
+		jwk := JWK{
+			KID: kid,
+			Alg: key.method.Alg(),
+			Use: "sig",
+		}
+
+		switch publicKey := key.key.(type) {
+		case *rsa.PublicKey:
+			jwk.KTY = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KTY = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks
+}
+
+// GetJWKS returns public keys tokens are verified with, the list is empty when
+// tokens are signed with shared secret.
+func GetJWKS() JWKS {
+	if keys == nil {
+		return JWKS{Keys: make([]JWK, 0)}
+	}
+
+	return keys.JWKS()
+}
+
+func parseKey(data []byte) (crypto.PrivateKey, crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, errors.New("no PEM data found")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		signer, ok := privateKey.(crypto.Signer)
+		if !ok {
+			return nil, nil, errors.New("private key can't be used for signing")
+		}
+		return privateKey, signer.Public(), nil
+	case "RSA PRIVATE KEY":
+		privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return privateKey, privateKey.Public(), nil
+	case "PUBLIC KEY":
+		publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, publicKey, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported PEM block %s", block.Type)
+	}
+}
+
+func signingMethod(publicKey crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", publicKey)
+	}
+}
@@ -0,0 +1,150 @@
+package user_validation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func writePrivateKey(t *testing.T, dir, kid string, key interface{}) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0600))
+}
+
+func writePublicKey(t *testing.T, dir, kid string, key interface{}) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	assert.NoError(t, err)
+	data := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0600))
+}
+
+func TestNewKeySet(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	writePrivateKey(t, dir, "rsa", rsaKey)
+	writePrivateKey(t, dir, "ed", edKey)
+
+	_, err = NewKeySet(dir, "")
+	assert.Error(t, err)
+
+	_, err = NewKeySet(dir, "unknown")
+	assert.Error(t, err)
+
+	_, err = NewKeySet(t.TempDir(), "")
+	assert.Error(t, err)
+
+	keySet, err := NewKeySet(dir, "ed")
+	assert.NoError(t, err)
+
+	jwks := keySet.JWKS()
+	assert.Equal(t, 2, len(jwks.Keys))
+	assert.Equal(t, JWK{
+		KID: "ed",
+		KTY: "OKP",
+		Alg: "EdDSA",
+		Use: "sig",
+		Crv: "Ed25519",
+		X:   jwks.Keys[0].X,
+	}, jwks.Keys[0])
+	assert.Equal(t, "rsa", jwks.Keys[1].KID)
+	assert.Equal(t, "RSA", jwks.Keys[1].KTY)
+	assert.Equal(t, "RS256", jwks.Keys[1].Alg)
+	assert.Equal(t, "AQAB", jwks.Keys[1].E)
+}
+
+func TestKeyRotation(t *testing.T) {
+	defer func() { keys = nil }()
+
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	_, newKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	writePrivateKey(t, dir, "old", oldKey)
+
+	assert.NoError(t, LoadKeys(dir, ""))
+	oldToken, _, err := GenerateTokens("test@test.com", "1")
+	assert.NoError(t, err)
+
+	writePrivateKey(t, dir, "new", newKey)
+	assert.NoError(t, LoadKeys(dir, "new"))
+	newToken, _, err := GenerateTokens("test@test.com", "1")
+	assert.NoError(t, err)
+
+	for _, token := range []string{oldToken, newToken} {
+		claims, err := ValidateToken(token)
+		assert.NoError(t, err)
+		assert.Equal(t, "1", claims.UserID)
+	}
+
+	parsed, _, err := new(jwt.Parser).ParseUnverified(newToken, &SignedDetails{})
+	assert.NoError(t, err)
+	assert.Equal(t, "new", parsed.Header["kid"])
+	assert.Equal(t, "EdDSA", parsed.Method.Alg())
+
+	rotatedDir := t.TempDir()
+	writePrivateKey(t, rotatedDir, "new", newKey)
+	writePublicKey(t, rotatedDir, "old", oldKey.Public())
+	assert.NoError(t, LoadKeys(rotatedDir, ""))
+
+	_, err = ValidateToken(oldToken)
+	assert.NoError(t, err)
+
+	assert.NoError(t, LoadKeys(dir, "new"))
+	assert.NoError(t, os.Remove(filepath.Join(dir, "old.pem")))
+	assert.NoError(t, LoadKeys(dir, ""))
+
+	_, err = ValidateToken(oldToken)
+	assert.Error(t, err)
+}
+
+func TestKeyfunc(t *testing.T) {
+	defer func() { keys = nil }()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	writePrivateKey(t, dir, "rsa", rsaKey)
+	assert.NoError(t, LoadKeys(dir, ""))
+
+	expiresAt := time.Now().Add(time.Hour).Unix()
+
+	hmacToken := jwt.NewWithClaims(jwt.SigningMethodHS256, &SignedDetails{
+		StandardClaims: jwt.StandardClaims{ExpiresAt: expiresAt},
+	})
+	hmacToken.Header["kid"] = "rsa"
+	signed, err := hmacToken.SignedString([]byte("secret"))
+	assert.NoError(t, err)
+	_, err = ValidateToken(signed)
+	assert.Error(t, err)
+
+	legacyToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &SignedDetails{
+		StandardClaims: jwt.StandardClaims{ExpiresAt: expiresAt},
+	}).SignedString([]byte("secret"))
+	assert.NoError(t, err)
+
+	_, err = ValidateToken(legacyToken)
+	assert.Error(t, err)
+
+	SetSecretKey("secret")
+	defer SetSecretKey("")
+	_, err = ValidateToken(legacyToken)
+	assert.NoError(t, err)
+}
@@ -3,16 +3,19 @@ package user_validation
 import (
 	"errors"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/hackfeed/remrratality/backend/internal/domain"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/crypto/bcrypt"
 )
 
-type signedDetails struct {
+// secretKey is shared secret for HS256 tokens, it's used when no keys are loaded.
+var secretKey string
+
+// SignedDetails are claims of the access token, refresh token carries expiration time only.
+type SignedDetails struct {
 	Email  string
 	UserID string
 	jwt.StandardClaims
@@ -21,7 +24,7 @@ type signedDetails struct {
 func GenerateTokens(email, id string) (string, string, error) {
 	var token, refreshToken string
 
-	claims := &signedDetails{
+	claims := &SignedDetails{
 		Email:  email,
 		UserID: id,
 		StandardClaims: jwt.StandardClaims{
@@ -29,17 +32,17 @@ func GenerateTokens(email, id string) (string, string, error) {
 		},
 	}
 
-	refreshClaims := &signedDetails{
+	refreshClaims := &SignedDetails{
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: time.Now().Local().Add(time.Hour * time.Duration(4)).Unix(),
 		},
 	}
 
-	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(os.Getenv("SECRET_KEY")))
+	token, err := signToken(claims)
 	if err != nil {
 		return token, refreshToken, fmt.Errorf("failed create new token, error is: %s", err)
 	}
-	refreshToken, err = jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims).SignedString([]byte(os.Getenv("SECRET_KEY")))
+	refreshToken, err = signToken(refreshClaims)
 	if err != nil {
 		return token, refreshToken, fmt.Errorf("failed create new refresh token, error is: %s", err)
 	}
@@ -56,22 +59,73 @@ func UpdateTokens(user *domain.User, signedToken, signedRefreshToken string) {
 }
 
 func GetExpirationTime(token string) (int64, error) {
-	tk, err := jwt.ParseWithClaims(
-		token,
-		&signedDetails{},
-		func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("SECRET_KEY")), nil
-		},
-	)
+	claims, err := parseToken(token)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get token, error is: %s", err)
 	}
-	claims, ok := tk.Claims.(*signedDetails)
+
+	return claims.ExpiresAt, nil
+}
+
+// ValidateToken verifies token's signature and expiration time and returns its claims.
+func ValidateToken(signedToken string) (*SignedDetails, error) {
+	claims, err := parseToken(signedToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain token, error is: %s", err)
+	}
+
+	if claims.ExpiresAt < time.Now().Local().Unix() {
+		return nil, errors.New("token is expired")
+	}
+
+	return claims, nil
+}
+
+func parseToken(signedToken string) (*SignedDetails, error) {
+	tk, err := jwt.ParseWithClaims(signedToken, &SignedDetails{}, keyfunc)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := tk.Claims.(*SignedDetails)
 	if !ok {
-		return 0, errors.New("token is invalid")
+		return nil, errors.New("token is invalid")
 	}
 
-	return claims.ExpiresAt, nil
+	return claims, nil
+}
+
+// SetSecretKey sets shared secret HS256 tokens are signed and verified with.
+func SetSecretKey(key string) {
+	secretKey = key
+}
+
+// signToken signs claims with the key loaded by LoadKeys, falling back to HS256 with
+// shared secret if no keys are loaded.
+func signToken(claims jwt.Claims) (string, error) {
+	if keys != nil {
+		return keys.sign(claims)
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secretKey))
+}
+
+// keyfunc looks tokens with kid header up in the loaded keys. Tokens without kid are
+// verified with shared secret, so HS256 tokens issued before keys were loaded stay valid
+// until the secret is unset.
+func keyfunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Header["kid"]; ok && keys != nil {
+		return keys.keyfunc(token)
+	}
+
+	if token.Method != jwt.SigningMethodHS256 {
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+	if keys != nil && secretKey == "" {
+		return nil, errors.New("tokens signed with shared secret are not accepted")
+	}
+
+	return []byte(secretKey), nil
 }
 
 func HashPassword(password string) (string, error) {
@@ -0,0 +1,128 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned for webhook hosts which resolve to addresses of internal
+// networks, deliveries are never sent there so webhooks can't be used to probe them.
+var ErrForbiddenAddress = errors.New("webhook address is not public")
+
+// reservedNetworks aren't covered by net.IP methods, but are internal as well.
+var reservedNetworks = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),
+	mustParseCIDR("100.64.0.0/10"),
+	mustParseCIDR("192.0.0.0/24"),
+	mustParseCIDR("198.18.0.0/15"),
+	mustParseCIDR("240.0.0.0/4"),
+	mustParseCIDR("64:ff9b::/96"),
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// PublicIP reports whether ip may be a webhook's address. Loopback, private, link-local,
+// including cloud metadata endpoint 169.254.169.254, unspecified and multicast addresses
+// aren't.
+func PublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, network := range reservedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ValidateURL checks that webhook's URL is http or https one with public host. Host is resolved,
+// but failure to resolve it isn't an error, as addresses are checked again on every connection.
+func ValidateURL(ctx context.Context, rawURL string) error {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse webhook URL, error is: %s", err)
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return errors.New("webhook URL should be http or https")
+	}
+
+	host := target.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if !PublicIP(ip) {
+			return ErrForbiddenAddress
+		}
+		return nil
+	}
+	if host == "" || host == "localhost" {
+		return ErrForbiddenAddress
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if !PublicIP(addr.IP) {
+			return ErrForbiddenAddress
+		}
+	}
+
+	return nil
+}
+
+// publicOnly is Control of dialer, it's called with resolved address right before connecting,
+// so hosts resolving to internal addresses after webhook is created are refused too.
+func publicOnly(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !PublicIP(ip) {
+		return ErrForbiddenAddress
+	}
+
+	return nil
+}
+
+// newClient returns client deliveries are sent with, connections are checked with control.
+// Proxies aren't used, as they would connect to webhooks instead of the checked dialer.
+func newClient(timeout time.Duration, control func(network, address string, c syscall.RawConn) error) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   control,
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		},
+		// Redirect is reported as failed attempt, so webhook's URL is the only one payloads are sent to.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,358 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hackfeed/remrratality/backend/internal/config"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	"github.com/hackfeed/remrratality/backend/internal/logging"
+	webhookrepo "github.com/hackfeed/remrratality/backend/internal/store/webhook_repo"
+	"github.com/hackfeed/remrratality/backend/internal/tracing"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Headers sent with every delivery, signature is computed by Sign.
+const (
+	HeaderEvent     = "X-Remrratality-Event"
+	HeaderDelivery  = "X-Remrratality-Delivery"
+	HeaderTimestamp = "X-Remrratality-Timestamp"
+	HeaderSignature = "X-Remrratality-Signature"
+)
+
+const (
+	// leaseMargin is added to attempt timeout while delivery is claimed, so its result is saved
+	// before another worker may claim it again.
+	leaseMargin = 30 * time.Second
+	// maxResponseSize is the part of webhook's response which is read before connection is reused.
+	maxResponseSize = 64 << 10
+)
+
+// Notifier sends events of organization to its webhooks.
+type Notifier interface {
+	// Notify queues delivery of the event to every webhook of organization orgID subscribed to it.
+	Notify(ctx context.Context, orgID, event string, data interface{}) error
+	// Test sends sample event to the webhook once and returns the recorded delivery.
+	Test(ctx context.Context, webhook domain.Webhook, event string) (domain.WebhookDelivery, error)
+}
+
+// Dispatcher delivers queued events with a pool of workers. Deliveries are claimed from the
+// webhook repository, so any instance may send them and pending ones survive restarts.
+type Dispatcher struct {
+	cfg    config.Webhooks
+	repo   webhookrepo.WebhookRepository
+	client *http.Client
+
+	wake chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewDispatcher(cfg config.Webhooks, repo webhookrepo.WebhookRepository) *Dispatcher {
+	return &Dispatcher{
+		cfg:    cfg,
+		repo:   repo,
+		client: newClient(cfg.Timeout, publicOnly),
+		wake:   make(chan struct{}, cfg.Workers),
+	}
+}
+
+func (d *Dispatcher) Notify(ctx context.Context, orgID, event string, data interface{}) error {
+	webhooks, err := d.repo.GetWebhooks(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to get webhooks, error is: %s", err)
+	}
+
+	queued := false
+	for _, webhook := range webhooks {
+		if !webhook.Subscribed(event) {
+			continue
+		}
+
+		delivery, err := newDelivery(webhook, event, data, false)
+		if err != nil {
+			return err
+		}
+		nextAttemptAt := now()
+		delivery.NextAttemptAt = &nextAttemptAt
+
+		if _, err := d.repo.AddDelivery(ctx, delivery); err != nil {
+			return fmt.Errorf("failed to queue delivery to webhook %s, error is: %s", webhook.ID, err)
+		}
+		queued = true
+	}
+
+	if queued {
+		select {
+		case d.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Test sends sample event to the webhook right away, test deliveries aren't retried.
+func (d *Dispatcher) Test(ctx context.Context, webhook domain.Webhook, event string) (domain.WebhookDelivery, error) {
+	delivery, err := newDelivery(webhook, event, sampleData(event), true)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	attempt := d.attempt(ctx, webhook, delivery)
+	delivery.Attempts = []domain.WebhookAttempt{attempt}
+	delivery.State = domain.DeliverySucceeded
+	if attempt.Error != "" {
+		delivery.State = domain.DeliveryFailed
+	}
+
+	delivery, err = d.repo.AddDelivery(ctx, delivery)
+	if err != nil {
+		return domain.WebhookDelivery{}, fmt.Errorf("failed to save test delivery, error is: %s", err)
+	}
+
+	return delivery, nil
+}
+
+// Start starts workers, which stop once ctx is done.
+func (d *Dispatcher) Start(ctx context.Context) {
+	for i := 0; i < d.cfg.Workers; i++ {
+		d.wg.Add(1)
+		go d.work(ctx)
+	}
+}
+
+// Wait blocks until workers finish attempts they've started.
+func (d *Dispatcher) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to wait for webhook workers to stop, error is: %w", ctx.Err())
+	}
+}
+
+func (d *Dispatcher) work(stop context.Context) {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for stop.Err() == nil {
+		delivery, err := d.repo.ClaimDelivery(context.Background(), d.cfg.Timeout+leaseMargin)
+		if err == nil {
+			d.deliver(context.Background(), delivery)
+			continue
+		}
+		if !errors.Is(err, webhookrepo.ErrNotFound) {
+			log.Errorf("failed to claim webhook delivery, error is: %s", err)
+		}
+
+		select {
+		case <-stop.Done():
+		case <-d.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliver attempts the delivery and schedules the next attempt if it fails. Delivery fails once
+// it's attempted MaxAttempts times or its webhook is deleted. Updated delivery is returned.
+func (d *Dispatcher) deliver(ctx context.Context, delivery domain.WebhookDelivery) domain.WebhookDelivery {
+	ctx, span := tracing.Start(ctx, "webhook delivery", trace.WithAttributes(
+		attribute.String("webhook.id", delivery.WebhookID),
+		attribute.String("webhook.event", delivery.Event),
+		attribute.Int("webhook.attempt", len(delivery.Attempts)+1),
+	))
+	defer span.End()
+	logger := logging.FromContext(ctx).WithField("delivery_id", delivery.ID)
+
+	webhook, err := d.repo.GetWebhook(ctx, delivery.WebhookID)
+	if err != nil && !errors.Is(err, webhookrepo.ErrNotFound) {
+		// Delivery is attempted again once its lease expires.
+		logger.Errorf("failed to get webhook %s, error is: %s", delivery.WebhookID, err)
+		return delivery
+	}
+
+	delivery.State = domain.DeliveryFailed
+	delivery.NextAttemptAt = nil
+	if err == nil {
+		attempt := d.attempt(ctx, webhook, delivery)
+		delivery.Attempts = append(delivery.Attempts, attempt)
+
+		switch {
+		case attempt.Error == "":
+			delivery.State = domain.DeliverySucceeded
+		case len(delivery.Attempts) < d.cfg.MaxAttempts:
+			nextAttemptAt := now().Add(backoff(d.cfg, len(delivery.Attempts)))
+			delivery.State = domain.DeliveryPending
+			delivery.NextAttemptAt = &nextAttemptAt
+			logger.Warnf("attempt %d to deliver %s event failed, retrying at %s, error is: %s",
+				len(delivery.Attempts), delivery.Event, nextAttemptAt.Format(time.RFC3339), attempt.Error)
+		default:
+			logger.Errorf("delivery of %s event failed after %d attempts, error is: %s", delivery.Event, len(delivery.Attempts), attempt.Error)
+		}
+	}
+
+	if err := d.repo.UpdateDelivery(ctx, delivery); err != nil {
+		logger.Errorf("failed to save result of webhook delivery, error is: %s", err)
+	}
+
+	return delivery
+}
+
+// attempt sends delivery to the webhook once, non-2xx response is a failure.
+func (d *Dispatcher) attempt(ctx context.Context, webhook domain.Webhook, delivery domain.WebhookDelivery) domain.WebhookAttempt {
+	attempt := domain.WebhookAttempt{At: now()}
+	started := time.Now()
+
+	statusCode, err := d.send(ctx, webhook, delivery)
+	attempt.StatusCode = statusCode
+	attempt.DurationMs = time.Since(started).Milliseconds()
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+
+	return attempt
+}
+
+func (d *Dispatcher) send(ctx context.Context, webhook domain.Webhook, delivery domain.WebhookDelivery) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request, error is: %s", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "remrratality-webhooks")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, []byte(delivery.Payload)))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request, error is: %s", err)
+	}
+	defer resp.Body.Close()
+	// nolint
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize))
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Sign returns signature of the body sent at timestamp: HMAC-SHA256 of timestamp and body joined
+// with a dot, keyed with webhook's secret. Receivers should compare it in constant time and
+// reject old timestamps, so captured deliveries can't be replayed.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewSecret returns random secret to sign payloads of the new webhook with.
+func NewSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret, error is: %s", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// backoff returns delay before the attempt which follows the given number of failed ones.
+func backoff(cfg config.Webhooks, attempts int) time.Duration {
+	delay := cfg.Backoff
+	for i := 1; i < attempts && delay < cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > cfg.MaxBackoff {
+		delay = cfg.MaxBackoff
+	}
+	return delay
+}
+
+func newDelivery(webhook domain.Webhook, event string, data interface{}, test bool) (domain.WebhookDelivery, error) {
+	id := uuid.NewString()
+
+	payload, err := json.Marshal(domain.WebhookPayload{
+		ID:        id,
+		Event:     event,
+		OrgID:     webhook.OrgID,
+		Test:      test,
+		CreatedAt: now(),
+		Data:      data,
+	})
+	if err != nil {
+		return domain.WebhookDelivery{}, fmt.Errorf("failed to marshal payload of %s event, error is: %s", event, err)
+	}
+
+	return domain.WebhookDelivery{
+		ID:        id,
+		WebhookID: webhook.ID,
+		OrgID:     webhook.OrgID,
+		Event:     event,
+		Payload:   string(payload),
+		State:     domain.DeliveryPending,
+	}, nil
+}
+
+// sampleData returns data of the event as it would be sent for an uploaded invoices.csv file.
+func sampleData(event string) interface{} {
+	if event == domain.WebhookAlertTriggered {
+		return domain.Alert{
+			ID:        "sample",
+			RuleID:    "sample",
+			DatasetID: "sample.csv",
+			Month:     "10.2021",
+			Metric:    domain.AlertMetricChurn,
+			Condition: domain.AlertAbove,
+			Threshold: 5,
+			Value:     7.5,
+			Message:   "Churn MRR of 10.2021 is 7.50% of the previous month's total, above the threshold of 5.00%",
+			CreatedAt: now(),
+		}
+	}
+
+	data := domain.DatasetEvent{DatasetID: "sample.csv", OriginalFilename: "invoices.csv"}
+
+	switch event {
+	case domain.WebhookDatasetImported:
+		data.JobID = "sample"
+		data.Rows = 3
+	case domain.WebhookDatasetImportFailed:
+		data.JobID = "sample"
+		data.Errors = []string{`Row 2: period_start "2021-01-01" should be a date formatted as DD.MM.YYYY`}
+	}
+
+	return data
+}
+
+// now returns current time in UTC with precision of stored timestamps.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
@@ -0,0 +1,306 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hackfeed/remrratality/backend/internal/config"
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+	webhookrepo "github.com/hackfeed/remrratality/backend/internal/store/webhook_repo"
+	"github.com/stretchr/testify/assert"
+)
+
+var testConfig = config.Webhooks{
+	Workers:      1,
+	PollInterval: time.Second,
+	Timeout:      time.Second,
+	MaxAttempts:  3,
+	Backoff:      30 * time.Second,
+	MaxBackoff:   5 * time.Minute,
+}
+
+// recordingRepo points webhooks of the mock to the test server and keeps added deliveries.
+type recordingRepo struct {
+	webhookrepo.WebhookRepositoryMock
+	url        string
+	mu         sync.Mutex
+	deliveries []domain.WebhookDelivery
+}
+
+func (r *recordingRepo) GetWebhook(ctx context.Context, webhookID string) (domain.Webhook, error) {
+	webhook, err := r.WebhookRepositoryMock.GetWebhook(ctx, webhookID)
+	webhook.URL = r.url
+	return webhook, err
+}
+
+func (r *recordingRepo) GetWebhooks(ctx context.Context, orgID string) ([]domain.Webhook, error) {
+	webhooks, err := r.WebhookRepositoryMock.GetWebhooks(ctx, orgID)
+	for i := range webhooks {
+		webhooks[i].URL = r.url
+	}
+	return webhooks, err
+}
+
+func (r *recordingRepo) AddDelivery(ctx context.Context, delivery domain.WebhookDelivery) (domain.WebhookDelivery, error) {
+	delivery, err := r.WebhookRepositoryMock.AddDelivery(ctx, delivery)
+	if err == nil {
+		r.mu.Lock()
+		r.deliveries = append(r.deliveries, delivery)
+		r.mu.Unlock()
+	}
+	return delivery, err
+}
+
+// newTestReceiver starts server responding with the given status to deliveries signed with
+// the mock webhook's secret, deliveries with invalid signature are rejected with 401. Responses
+// point to another location, so redirects may be tested.
+func newTestReceiver(t *testing.T, status int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signature := Sign("secret", r.Header.Get(HeaderTimestamp), body)
+		if r.Header.Get(HeaderSignature) != signature || r.Header.Get(HeaderDelivery) == "" || r.Header.Get(HeaderEvent) == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Location", "/elsewhere")
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// newTestDispatcher returns dispatcher allowed to deliver to test receivers on loopback.
+func newTestDispatcher(repo webhookrepo.WebhookRepository) *Dispatcher {
+	dispatcher := NewDispatcher(testConfig, repo)
+	dispatcher.client = newClient(testConfig.Timeout, nil)
+	return dispatcher
+}
+
+func TestSign(t *testing.T) {
+	assert.Equal(
+		t,
+		"sha256=c69d8d1e25361664a02f2c2afb23286a80deab9ebf75533bbdc61c4b436cd377",
+		Sign("secret", "1634000000", []byte(`{"event":"dataset.imported"}`)),
+	)
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 30 * time.Second},
+		{attempts: 2, want: time.Minute},
+		{attempts: 4, want: 4 * time.Minute},
+		{attempts: 5, want: 5 * time.Minute},
+		{attempts: 50, want: 5 * time.Minute},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, backoff(testConfig, test.attempts))
+	}
+}
+
+func TestNotify(t *testing.T) {
+	repo := &recordingRepo{}
+	dispatcher := newTestDispatcher(repo)
+	data := domain.DatasetEvent{DatasetID: "file.csv", JobID: "job", Rows: 3}
+
+	assert.NoError(t, dispatcher.Notify(context.Background(), "org", domain.WebhookDatasetImported, data))
+	assert.NoError(t, dispatcher.Notify(context.Background(), "org", domain.WebhookDatasetImportFailed, data))
+	assert.Equal(t, 1, len(repo.deliveries))
+	assert.Equal(t, 1, len(dispatcher.wake))
+
+	delivery := repo.deliveries[0]
+	assert.Equal(t, "webhook", delivery.WebhookID)
+	assert.Equal(t, domain.DeliveryPending, delivery.State)
+	assert.NotNil(t, delivery.NextAttemptAt)
+
+	var payload struct {
+		ID    string              `json:"id"`
+		Event string              `json:"event"`
+		OrgID string              `json:"org_id"`
+		Test  bool                `json:"test"`
+		Data  domain.DatasetEvent `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(delivery.Payload), &payload))
+	assert.Equal(t, delivery.ID, payload.ID)
+	assert.Equal(t, domain.WebhookDatasetImported, payload.Event)
+	assert.Equal(t, "org", payload.OrgID)
+	assert.Equal(t, false, payload.Test)
+	assert.Equal(t, data, payload.Data)
+
+	assert.EqualError(
+		t,
+		dispatcher.Notify(context.Background(), "errorGetWebhooks", domain.WebhookDatasetImported, data),
+		"failed to get webhooks, error is: error while getting webhooks",
+	)
+	assert.EqualError(
+		t,
+		dispatcher.Notify(context.Background(), "errorAddDelivery", domain.WebhookDatasetImported, data),
+		"failed to queue delivery to webhook webhook, error is: error while adding delivery",
+	)
+}
+
+func TestDeliver(t *testing.T) {
+	type testInput struct {
+		status    int
+		webhookID string
+		attempts  int
+	}
+	type testWant struct {
+		state         string
+		attempts      int
+		statusCode    int
+		nextAttemptAt bool
+	}
+
+	tests := []struct {
+		input testInput
+		want  testWant
+	}{
+		{
+			input: testInput{status: http.StatusNoContent, webhookID: "webhook"},
+			want:  testWant{state: domain.DeliverySucceeded, attempts: 1, statusCode: http.StatusNoContent},
+		},
+		{
+			input: testInput{status: http.StatusInternalServerError, webhookID: "webhook"},
+			want:  testWant{state: domain.DeliveryPending, attempts: 1, statusCode: http.StatusInternalServerError, nextAttemptAt: true},
+		},
+		{
+			input: testInput{status: http.StatusInternalServerError, webhookID: "webhook", attempts: 2},
+			want:  testWant{state: domain.DeliveryFailed, attempts: 3, statusCode: http.StatusInternalServerError},
+		},
+		{
+			input: testInput{status: http.StatusMovedPermanently, webhookID: "webhook"},
+			want:  testWant{state: domain.DeliveryPending, attempts: 1, statusCode: http.StatusMovedPermanently, nextAttemptAt: true},
+		},
+		{
+			input: testInput{status: http.StatusOK, webhookID: "notFoundWebhook"},
+			want:  testWant{state: domain.DeliveryFailed},
+		},
+		{
+			input: testInput{status: http.StatusOK, webhookID: "errorGetWebhook"},
+			want:  testWant{state: domain.DeliveryPending, nextAttemptAt: true},
+		},
+	}
+
+	for _, test := range tests {
+		server := newTestReceiver(t, test.input.status)
+		dispatcher := newTestDispatcher(&recordingRepo{url: server.URL})
+
+		delivery, err := newDelivery(domain.Webhook{ID: test.input.webhookID, OrgID: "org"}, domain.WebhookDatasetDeleted, domain.DatasetEvent{DatasetID: "file.csv"}, false)
+		assert.NoError(t, err)
+		claimedUntil := now().Add(time.Minute)
+		delivery.NextAttemptAt = &claimedUntil
+		delivery.Attempts = make([]domain.WebhookAttempt, test.input.attempts)
+
+		delivery = dispatcher.deliver(context.Background(), delivery)
+		assert.Equal(t, test.want.state, delivery.State)
+		assert.Equal(t, test.want.attempts, len(delivery.Attempts))
+		assert.Equal(t, test.want.nextAttemptAt, delivery.NextAttemptAt != nil)
+		if test.want.attempts != 0 {
+			assert.Equal(t, test.want.statusCode, delivery.Attempts[len(delivery.Attempts)-1].StatusCode)
+		}
+		if test.want.state == domain.DeliveryPending && test.want.attempts == 1 {
+			assert.Equal(t, true, delivery.NextAttemptAt.After(now().Add(29*time.Second)))
+		}
+	}
+}
+
+func TestTest(t *testing.T) {
+	server := newTestReceiver(t, http.StatusOK)
+	repo := &recordingRepo{url: server.URL}
+	dispatcher := newTestDispatcher(repo)
+
+	webhook, _ := repo.GetWebhook(context.Background(), "webhook")
+	delivery, err := dispatcher.Test(context.Background(), webhook, domain.WebhookDatasetImportFailed)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DeliverySucceeded, delivery.State)
+	assert.Equal(t, 1, len(delivery.Attempts))
+	assert.Equal(t, http.StatusOK, delivery.Attempts[0].StatusCode)
+	assert.Nil(t, delivery.NextAttemptAt)
+	assert.Equal(t, []domain.WebhookDelivery{delivery}, repo.deliveries)
+
+	var payload domain.WebhookPayload
+	assert.NoError(t, json.Unmarshal([]byte(delivery.Payload), &payload))
+	assert.Equal(t, true, payload.Test)
+	assert.Equal(t, domain.WebhookDatasetImportFailed, payload.Event)
+
+	delivery, err = dispatcher.Test(context.Background(), webhook, domain.WebhookAlertTriggered)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DeliverySucceeded, delivery.State)
+	var alertPayload struct {
+		Data domain.Alert `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(delivery.Payload), &alertPayload))
+	assert.Equal(t, "10.2021", alertPayload.Data.Month)
+
+	webhook.Secret = "wrong"
+	delivery, err = dispatcher.Test(context.Background(), webhook, domain.WebhookDatasetImported)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DeliveryFailed, delivery.State)
+	assert.Equal(t, http.StatusUnauthorized, delivery.Attempts[0].StatusCode)
+	assert.Equal(t, "webhook responded with status 401", delivery.Attempts[0].Error)
+
+	webhook.OrgID = "errorAddDelivery"
+	_, err = dispatcher.Test(context.Background(), webhook, domain.WebhookDatasetImported)
+	assert.EqualError(t, err, "failed to save test delivery, error is: error while adding delivery")
+}
+
+func TestTestInternalAddress(t *testing.T) {
+	received := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = true
+	}))
+	t.Cleanup(server.Close)
+	repo := &recordingRepo{url: server.URL}
+	dispatcher := NewDispatcher(testConfig, repo)
+
+	webhook, _ := repo.GetWebhook(context.Background(), "webhook")
+	delivery, err := dispatcher.Test(context.Background(), webhook, domain.WebhookDatasetImported)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DeliveryFailed, delivery.State)
+	assert.Equal(t, 0, delivery.Attempts[0].StatusCode)
+	assert.Equal(t, true, strings.Contains(delivery.Attempts[0].Error, ErrForbiddenAddress.Error()), delivery.Attempts[0].Error)
+	assert.Equal(t, false, received)
+}
+
+func TestPublicIP(t *testing.T) {
+	for address, public := range map[string]bool{
+		"93.184.216.34":     true,
+		"2606:2800:220:1::": true,
+		"127.0.0.1":         false,
+		"::1":               false,
+		"10.1.2.3":          false,
+		"172.16.0.1":        false,
+		"192.168.1.1":       false,
+		"169.254.169.254":   false,
+		"fe80::1":           false,
+		"fd00::1":           false,
+		"0.0.0.0":           false,
+		"::":                false,
+		"224.0.0.1":         false,
+		"100.64.0.1":        false,
+	} {
+		assert.Equal(t, public, PublicIP(net.ParseIP(address)), address)
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	assert.NoError(t, ValidateURL(context.Background(), "https://93.184.216.34/webhook"))
+	assert.Equal(t, ErrForbiddenAddress, ValidateURL(context.Background(), "http://127.0.0.1:8080/webhook"))
+	assert.Equal(t, ErrForbiddenAddress, ValidateURL(context.Background(), "http://169.254.169.254/latest/meta-data"))
+	assert.Equal(t, ErrForbiddenAddress, ValidateURL(context.Background(), "http://[::1]/webhook"))
+	assert.Equal(t, ErrForbiddenAddress, ValidateURL(context.Background(), "http://localhost/webhook"))
+	assert.EqualError(t, ValidateURL(context.Background(), "ftp://example.com/webhook"), "webhook URL should be http or https")
+}
@@ -0,0 +1,31 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hackfeed/remrratality/backend/internal/domain"
+)
+
+type NotifierMock struct{}
+
+func (nm *NotifierMock) Notify(_ context.Context, orgID, _ string, _ interface{}) error {
+	if orgID == "errorNotify" {
+		return errors.New("error while notifying webhooks")
+	}
+	return nil
+}
+
+func (nm *NotifierMock) Test(_ context.Context, webhook domain.Webhook, event string) (domain.WebhookDelivery, error) {
+	if webhook.ID == "errorTestWebhook" {
+		return domain.WebhookDelivery{}, errors.New("error while testing webhook")
+	}
+	return domain.WebhookDelivery{
+		ID:        "delivery",
+		WebhookID: webhook.ID,
+		OrgID:     webhook.OrgID,
+		Event:     event,
+		State:     domain.DeliverySucceeded,
+		Attempts:  []domain.WebhookAttempt{{StatusCode: 200}},
+	}, nil
+}
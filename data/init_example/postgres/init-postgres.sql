@@ -6,4 +6,22 @@ CREATE TABLE invoices(
     paid_plan VARCHAR(10) NOT NULL,
     paid_amount REAL NOT NULL,
     period_end DATE NOT NULL
-);
\ No newline at end of file
+);
+
+CREATE TABLE audit_log(
+    event_id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(256) NOT NULL,
+    org_id VARCHAR(256) NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    success BOOLEAN NOT NULL,
+    status INT NOT NULL,
+    ip VARCHAR(64) NOT NULL,
+    request_id VARCHAR(256) NOT NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX audit_log_user_id_created_at_idx ON audit_log(user_id, created_at);
+
+CREATE RULE audit_log_no_update AS ON UPDATE TO audit_log DO INSTEAD NOTHING;
+CREATE RULE audit_log_no_delete AS ON DELETE TO audit_log DO INSTEAD NOTHING;
@@ -0,0 +1,21 @@
+export interface ServerEvent {
+  id: string;
+  event: string;
+  data: string;
+}
+
+export interface JobEvent {
+  seq: number;
+  type: string;
+  stage?: string;
+  rows_total: number;
+  rows_processed: number;
+  message?: string;
+  errors?: string[];
+}
+
+export interface AnalyticsResult {
+  message: string;
+  months: string[];
+  mrr: Record<string, any>;
+}
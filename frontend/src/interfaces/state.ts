@@ -12,6 +12,7 @@ export interface AnalyticsState {
   grid: Grid;
   data: BarData;
   dataOptions: BarOptions;
+  progress: string | null;
 }
 
 export interface AuthState {
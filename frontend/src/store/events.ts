@@ -0,0 +1,61 @@
+import { ServerEvent } from "@/interfaces/event";
+
+function parseEvent(block: string): ServerEvent | null {
+  const event: ServerEvent = { id: "", event: "message", data: "" };
+  const data: string[] = [];
+
+  for (const line of block.split("\n")) {
+    if (line === "" || line.startsWith(":")) {
+      continue;
+    }
+    const separator = line.indexOf(":");
+    const field = separator === -1 ? line : line.slice(0, separator);
+    let value = separator === -1 ? "" : line.slice(separator + 1);
+    if (value.startsWith(" ")) {
+      value = value.slice(1);
+    }
+
+    if (field === "id") {
+      event.id = value;
+    } else if (field === "event") {
+      event.event = value;
+    } else if (field === "data") {
+      data.push(value);
+    }
+  }
+
+  if (data.length === 0) {
+    return null;
+  }
+  event.data = data.join("\n");
+
+  return event;
+}
+
+// readEvents passes Server-Sent Events of the response to onEvent until the stream ends.
+export async function readEvents(
+  response: Response,
+  onEvent: (event: ServerEvent) => void
+): Promise<void> {
+  const reader = response.body!.getReader();
+  const decoder = new TextDecoder();
+  let buffer = "";
+
+  for (;;) {
+    const { done, value } = await reader.read();
+    if (done) {
+      break;
+    }
+    buffer += decoder.decode(value, { stream: true }).replace(/\r\n?/g, "\n");
+
+    let end = buffer.indexOf("\n\n");
+    while (end !== -1) {
+      const event = parseEvent(buffer.slice(0, end));
+      buffer = buffer.slice(end + 2);
+      if (event) {
+        onEvent(event);
+      }
+      end = buffer.indexOf("\n\n");
+    }
+  }
+}
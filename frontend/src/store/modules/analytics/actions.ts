@@ -1,7 +1,11 @@
 import { ActionContext } from "vuex";
 
+import { AnalyticsResult, JobEvent } from "@/interfaces/event";
 import { File } from "@/interfaces/file";
 import { AnalyticsState, RootState } from "@/interfaces/state";
+import { readEvents } from "@/store/events";
+
+const reconnectInterval = 1000;
 
 export default {
   async uploadData(context: ActionContext<AnalyticsState, RootState>, data: string): Promise<void> {
@@ -19,6 +23,49 @@ export default {
       throw error;
     }
 
+    // Stream ends with the final event of the job, it's resumed after the last received event
+    // when connection is lost.
+    const jobId = responseData.job.id;
+    const stream: { lastEventId: string; final: JobEvent | null } = { lastEventId: "", final: null };
+    while (!stream.final) {
+      const headers: Record<string, string> = {
+        token: localStorage.getItem("token")!,
+      };
+      if (stream.lastEventId) {
+        headers["Last-Event-ID"] = stream.lastEventId;
+      }
+      const eventsResponse = await fetch(`/api/v1/jobs/${jobId}/events`, { headers });
+
+      if (!eventsResponse.ok) {
+        const eventsResponseData = await eventsResponse.json();
+        const error = new Error(eventsResponseData.message || "Failed to fill data");
+        throw error;
+      }
+
+      await readEvents(eventsResponse, (event) => {
+        const jobEvent: JobEvent = JSON.parse(event.data);
+        stream.lastEventId = event.id || stream.lastEventId;
+
+        if (event.event === "progress" || event.event === "warning") {
+          context.commit("setProgress", jobEvent.message);
+        }
+        if (event.event === "succeeded" || event.event === "failed") {
+          stream.final = jobEvent;
+        }
+      });
+
+      if (!stream.final) {
+        await new Promise((resolve) => setTimeout(resolve, reconnectInterval));
+      }
+    }
+    context.commit("setProgress", null);
+
+    if (stream.final.type === "failed") {
+      const errors = stream.final.errors || [];
+      const error = new Error(errors.join("\n") || "Failed to fill data");
+      throw error;
+    }
+
     context.commit("setFile", responseData.filename);
   },
   async loadData(
@@ -30,14 +77,40 @@ export default {
       body: JSON.stringify(data),
       headers: {
         token: localStorage.getItem("token")!,
+        Accept: "text/event-stream",
       },
     });
-    const responseData = await response.json();
 
     if (!response.ok) {
-      const error = new Error(responseData.message || "Failed to load analytics.");
+      const errorData = await response.json();
+      const error = new Error(errorData.message || "Failed to load analytics.");
+      throw error;
+    }
+
+    const stream: { result: AnalyticsResult | null; failure: string | null } = {
+      result: null,
+      failure: null,
+    };
+    await readEvents(response, (event) => {
+      const eventData = JSON.parse(event.data);
+
+      if (event.event === "progress") {
+        context.commit("setProgress", eventData.message);
+      }
+      if (event.event === "result") {
+        stream.result = eventData;
+      }
+      if (event.event === "failed") {
+        stream.failure = eventData.message;
+      }
+    });
+    context.commit("setProgress", null);
+
+    if (!stream.result) {
+      const error = new Error(stream.failure || "Failed to load analytics.");
       throw error;
     }
+    const responseData = stream.result;
 
     const curData = { ...context.rootGetters["analytics/data"] };
     const mrr = responseData.mrr;
@@ -25,4 +25,7 @@ export default {
   dataOptions(state: AnalyticsState): BarOptions {
     return state.dataOptions;
   },
+  progress(state: AnalyticsState): string | null {
+    return state.progress;
+  },
 };
@@ -10,6 +10,7 @@ const state = {
   periodEnd: "2021-01",
   files: [],
   file: null,
+  progress: null,
   grid: {
     title: null,
     cols: null,
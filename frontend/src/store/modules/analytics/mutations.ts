@@ -22,4 +22,7 @@ export default {
   setFiles(state: AnalyticsState, data: File[]): void {
     state.files = data;
   },
+  setProgress(state: AnalyticsState, data: string | null): void {
+    state.progress = data;
+  },
 };